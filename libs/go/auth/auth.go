@@ -1,18 +1,59 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// KeySet resolves a JWT "kid" header to the public key used to verify an asymmetric signature.
+// NewJWKSKeySet builds the production implementation; tests may supply a fake.
+type KeySet interface {
+	Key(ctx context.Context, kid string) (interface{}, error)
+}
+
 // Config holds signer verification parameters shared by backend services.
 type Config struct {
 	Secret string
 	Issuer string
+
+	// Audiences, when non-empty, restricts OIDC/JWKS verification to tokens whose aud claim
+	// contains at least one of these values. Ignored in static-secret mode.
+	Audiences []string
+	// JWKSRefreshInterval controls how often the background loop re-fetches the JWKS in
+	// OIDC/JWKS mode. Defaults to 15 minutes when zero.
+	JWKSRefreshInterval time.Duration
+	// JWKSNegativeCacheTTL bounds how often an unknown kid triggers an on-demand JWKS refetch,
+	// so a burst of tokens with a bogus kid can't force repeated refreshes. Defaults to 30
+	// seconds when zero.
+	JWKSNegativeCacheTTL time.Duration
+	// HTTPClient overrides the client used to fetch OIDC discovery documents and JWKS,
+	// primarily for tests. Defaults to an http.Client with a short timeout.
+	HTTPClient *http.Client
+
+	// JWKSURL, when set, is fetched directly as the JWKS document instead of being derived via
+	// OIDC discovery from Issuer. Issuer may still be set alongside it to populate the iss claim
+	// check; NewJWKSKeySet prefers JWKSURL over discovery whenever both are present.
+	JWKSURL string
+	// AllowedAlgorithms restricts which JWT "alg" values Parse accepts, validated against the
+	// token's own alg header before either keyfunc branch below runs. Defaults to HS256 only
+	// when empty, preserving the historical static-secret behavior.
+	AllowedAlgorithms []string
+	// KeySet, when set, resolves kid headers to public keys for asymmetric-algorithm tokens
+	// presented to Parse. Build one with NewJWKSKeySet. Middleware manages its own JWKS
+	// verifier internally and does not consult this field.
+	KeySet KeySet
+}
+
+// UseJWKS reports whether cfg should verify tokens against an issuer's JWKS rather than a
+// static shared secret: an Issuer or JWKSURL is configured and no static Secret was provided.
+func (c Config) UseJWKS() bool {
+	return (c.Issuer != "" || c.JWKSURL != "") && c.Secret == ""
 }
 
 // Claims represents the payload extracted from a JWT.
@@ -29,37 +70,67 @@ var ErrMissingToken = errors.New("missing bearer token")
 // ErrInvalidToken wraps parsing/validation errors.
 var ErrInvalidToken = errors.New("invalid bearer token")
 
-// Parse validates a JWT and returns normalized claims.
+// Parse validates a JWT and returns normalized claims. An HMAC-signed token is checked against
+// cfg.Secret; an RS*/ES*-signed token is resolved against cfg.KeySet by its kid header. The
+// token's own alg header picks which branch runs and must appear in cfg.AllowedAlgorithms
+// (defaulting to HS256 only), so an HS256 token is never checked against KeySet material and an
+// RS*/ES* token is never checked against Secret - a forged alg header can't repurpose one key
+// type's bytes as the other's. Middleware instead verifies against an issuer's JWKS directly
+// when Config.UseJWKS reports true.
 func Parse(token string, cfg Config) (*Claims, error) {
 	token = strings.TrimSpace(token)
 	if token == "" {
 		return nil, ErrMissingToken
 	}
 
+	allowed := cfg.AllowedAlgorithms
+	if len(allowed) == 0 {
+		allowed = []string{jwt.SigningMethodHS256.Name}
+	}
+
+	opts := []jwt.ParserOption{jwt.WithValidMethods(allowed)}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+
 	parsed, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); ok {
+			if cfg.Secret == "" {
+				return nil, fmt.Errorf("HMAC-signed token presented but no static secret is configured")
+			}
+			return []byte(cfg.Secret), nil
+		}
+		if cfg.KeySet == nil {
 			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
 		}
-		return []byte(cfg.Secret), nil
-	}, jwt.WithIssuer(cfg.Issuer), jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}))
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			return nil, fmt.Errorf("token missing kid header")
+		}
+		return cfg.KeySet.Key(context.Background(), kid)
+	}, opts...)
 	if err != nil {
+		recordValidationError("token_parse")
 		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
 	}
 
 	claims, ok := parsed.Claims.(jwt.MapClaims)
 	if !ok || !parsed.Valid {
+		recordValidationError("token_parse")
 		return nil, ErrInvalidToken
 	}
 
 	subject, _ := claims["sub"].(string)
 	tenantID, _ := claims["tenant_id"].(string)
 	if subject == "" || tenantID == "" {
+		recordValidationError("missing_claims")
 		return nil, ErrInvalidToken
 	}
 
-	scopes := normalizeScopes(claims["scopes"])
+	scopes := scopesFromClaims(claims)
 	exp, err := claims.GetExpirationTime()
 	if err != nil {
+		recordValidationError("missing_expiry")
 		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
 	}
 
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// jsonWebKey is the subset of RFC 7517 fields needed to reconstruct RSA and EC public keys.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jwksDocument is the RFC 7517 JWK Set response body.
+type jwksDocument struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// publicKey reconstructs the Go crypto public key represented by k.
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		return k.rsaPublicKey()
+	case "EC":
+		return k.ecPublicKey()
+	default:
+		return nil, fmt.Errorf("unsupported JWK key type: %s", k.Kty)
+	}
+}
+
+func (k jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	nBytes, err := base64URLDecode(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("decode RSA modulus: %w", err)
+	}
+	eBytes, err := base64URLDecode(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("decode RSA exponent: %w", err)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
+
+func (k jsonWebKey) ecPublicKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported EC curve: %s", k.Crv)
+	}
+
+	xBytes, err := base64URLDecode(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("decode EC x coordinate: %w", err)
+	}
+	yBytes, err := base64URLDecode(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("decode EC y coordinate: %w", err)
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(xBytes),
+		Y:     new(big.Int).SetBytes(yBytes),
+	}, nil
+}
+
+func base64URLDecode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+func parseJWKSDocument(body []byte) (map[string]interface{}, error) {
+	var doc jwksDocument
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("decode JWKS document: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(doc.Keys))
+	for _, key := range doc.Keys {
+		if key.Kid == "" {
+			continue
+		}
+		pub, err := key.publicKey()
+		if err != nil {
+			// Skip keys we can't reconstruct (e.g. a future key type) rather than failing the
+			// whole refresh; other kids may still be usable.
+			continue
+		}
+		keys[key.Kid] = pub
+	}
+	return keys, nil
+}
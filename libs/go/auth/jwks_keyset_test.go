@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate RSA key: %v", err)
+	}
+	return key
+}
+
+func jwkFromRSA(kid string, key *rsa.PrivateKey) jsonWebKey {
+	return jsonWebKey{
+		Kty: "RSA",
+		Kid: kid,
+		Alg: "RS256",
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+	}
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+// jwksServer serves a JWKS document that can be swapped out mid-test to simulate rotation, and
+// counts requests so tests can assert on refresh/singleflight behavior.
+type jwksServer struct {
+	*httptest.Server
+	requests int32
+	keys     atomic.Value // []jsonWebKey
+	maxAge   int32
+}
+
+func newJWKSServer(keys ...jsonWebKey) *jwksServer {
+	s := &jwksServer{}
+	s.keys.Store(keys)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.requests, 1)
+		if maxAge := atomic.LoadInt32(&s.maxAge); maxAge > 0 {
+			w.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", maxAge))
+		}
+		doc := jwksDocument{Keys: s.keys.Load().([]jsonWebKey)}
+		_ = json.NewEncoder(w).Encode(doc)
+	}))
+	return s
+}
+
+func (s *jwksServer) setKeys(keys ...jsonWebKey) {
+	s.keys.Store(keys)
+}
+
+func (s *jwksServer) requestCount() int32 {
+	return atomic.LoadInt32(&s.requests)
+}
+
+func TestNewJWKSKeySetKeyRotationOverlap(t *testing.T) {
+	oldKey := newTestRSAKey(t)
+	newKey := newTestRSAKey(t)
+
+	server := newJWKSServer(jwkFromRSA("old", oldKey))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	keySet, err := NewJWKSKeySet(ctx, Config{JWKSURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewJWKSKeySet: %v", err)
+	}
+
+	// The IdP starts rotating: it now serves both the old and new key during the overlap window.
+	server.setKeys(jwkFromRSA("old", oldKey), jwkFromRSA("new", newKey))
+
+	oldToken := signRS256(t, oldKey, "old", jwt.MapClaims{"sub": "u1", "tenant_id": "t1", "exp": time.Now().Add(time.Hour).Unix()})
+	if _, err := Parse(oldToken, Config{KeySet: keySet, AllowedAlgorithms: []string{"RS256"}}); err != nil {
+		t.Fatalf("old kid should still verify before any refresh: %v", err)
+	}
+
+	newToken := signRS256(t, newKey, "new", jwt.MapClaims{"sub": "u1", "tenant_id": "t1", "exp": time.Now().Add(time.Hour).Unix()})
+	if _, err := Parse(newToken, Config{KeySet: keySet, AllowedAlgorithms: []string{"RS256"}}); err != nil {
+		t.Fatalf("unknown new kid should trigger a refresh and then verify: %v", err)
+	}
+
+	// Old kid must remain valid during the overlap window, i.e. the refresh triggered by the new
+	// kid must not have evicted it.
+	if _, err := Parse(oldToken, Config{KeySet: keySet, AllowedAlgorithms: []string{"RS256"}}); err != nil {
+		t.Fatalf("old kid should remain valid during rotation overlap: %v", err)
+	}
+}
+
+func TestNewJWKSKeySetUnknownKidSingleflight(t *testing.T) {
+	key := newTestRSAKey(t)
+	server := newJWKSServer()
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	keySet, err := NewJWKSKeySet(ctx, Config{JWKSURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewJWKSKeySet: %v", err)
+	}
+	baseline := server.requestCount()
+
+	server.setKeys(jwkFromRSA("rotated", key))
+
+	const concurrency = 20
+	errs := make(chan error, concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			_, err := keySet.Key(context.Background(), "rotated")
+			errs <- err
+		}()
+	}
+	for i := 0; i < concurrency; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("concurrent Key lookup failed: %v", err)
+		}
+	}
+
+	if got := server.requestCount() - baseline; got != 1 {
+		t.Fatalf("expected exactly one coalesced refresh for the unknown kid, got %d", got)
+	}
+}
+
+func TestParseRejectsAlgorithmConfusion(t *testing.T) {
+	key := newTestRSAKey(t)
+	server := newJWKSServer(jwkFromRSA("k1", key))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	keySet, err := NewJWKSKeySet(ctx, Config{JWKSURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewJWKSKeySet: %v", err)
+	}
+
+	cfg := Config{
+		Secret:            "hmac-secret",
+		KeySet:            keySet,
+		AllowedAlgorithms: []string{"HS256", "RS256"},
+	}
+
+	// Forge an HS256 token whose signature is computed using the RSA public key's modulus bytes
+	// as the HMAC secret - the classic alg-confusion attack. It must never reach cfg.KeySet, and
+	// since the real HMAC secret is different, it must fail.
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "attacker", "tenant_id": "t1", "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	forged.Header["kid"] = "k1"
+	signed, err := forged.SignedString(key.PublicKey.N.Bytes())
+	if err != nil {
+		t.Fatalf("sign forged token: %v", err)
+	}
+
+	if _, err := Parse(signed, cfg); err == nil {
+		t.Fatal("expected algorithm-confusion token to be rejected")
+	}
+
+	// A legitimately HMAC-signed token using the real secret still works.
+	legit := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "u1", "tenant_id": "t1", "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	legitSigned, err := legit.SignedString([]byte(cfg.Secret))
+	if err != nil {
+		t.Fatalf("sign legit token: %v", err)
+	}
+	if _, err := Parse(legitSigned, cfg); err != nil {
+		t.Fatalf("legitimate HMAC token should verify: %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package auth
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	jwksRefreshTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "platform",
+		Subsystem: "auth",
+		Name:      "jwks_refresh_total",
+		Help:      "Number of JWKS refresh attempts, scheduled or on-demand.",
+	})
+
+	jwksRefreshFailuresTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "platform",
+		Subsystem: "auth",
+		Name:      "jwks_refresh_failures_total",
+		Help:      "Number of JWKS refresh attempts that failed.",
+	})
+
+	tokenValidationErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "platform",
+		Subsystem: "auth",
+		Name:      "token_validation_errors_total",
+		Help:      "Number of bearer token validation failures by reason.",
+	}, []string{"reason"})
+)
+
+func init() {
+	prometheus.MustRegister(jwksRefreshTotal, jwksRefreshFailuresTotal, tokenValidationErrorsTotal)
+}
+
+func recordValidationError(reason string) {
+	tokenValidationErrorsTotal.WithLabelValues(reason).Inc()
+}
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"net/http"
 	"strings"
 )
@@ -10,13 +11,23 @@ type Skipper func(r *http.Request) bool
 
 // Middleware provides HTTP middleware for bearer-token validation.
 type Middleware struct {
-	Config  Config
-	Skipper Skipper
+	Config   Config
+	Skipper  Skipper
+	verifier *jwksVerifier
 }
 
-// NewMiddleware constructs a middleware with optional skipper.
+// NewMiddleware constructs a middleware with optional skipper. When cfg.UseJWKS is true, it
+// fetches the issuer's OIDC discovery document and JWKS once synchronously and starts a
+// background refresh loop for the process lifetime. A failed initial fetch does not stop
+// construction: it is counted in jwks_refresh_failures_total, and keys are fetched again by the
+// background loop or on demand when a token presents an unknown kid.
 func NewMiddleware(cfg Config, skipper Skipper) Middleware {
-	return Middleware{Config: cfg, Skipper: skipper}
+	m := Middleware{Config: cfg, Skipper: skipper}
+	if cfg.UseJWKS() {
+		m.verifier = newJWKSVerifier(cfg)
+		m.verifier.start(context.Background())
+	}
+	return m
 }
 
 // Wrap wraps an http.Handler with authentication.
@@ -43,8 +54,18 @@ func (m Middleware) parseRequest(r *http.Request) (*Claims, error) {
 		return nil, ErrMissingToken
 	}
 	if !strings.HasPrefix(strings.ToLower(header), "bearer ") {
+		recordValidationError("malformed_header")
 		return nil, ErrInvalidToken
 	}
 	token := strings.TrimSpace(header[len("Bearer "):])
+
+	if m.verifier != nil {
+		claims, err := m.verifier.verify(r.Context(), token)
+		if err != nil {
+			recordValidationError("jwks_verification")
+			return nil, err
+		}
+		return claims, nil
+	}
 	return Parse(token, m.Config)
 }
@@ -0,0 +1,421 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	defaultJWKSRefreshInterval  = 15 * time.Minute
+	defaultJWKSNegativeCacheTTL = 30 * time.Second
+	defaultJWKSFetchTimeout     = 5 * time.Second
+)
+
+// jwksAllowedAlgorithms excludes "none" and the HS* family: an OIDC-configured verifier only
+// trusts asymmetric signatures backed by keys it fetched from the issuer's JWKS.
+var jwksAllowedAlgorithms = []string{
+	jwt.SigningMethodRS256.Name,
+	jwt.SigningMethodRS384.Name,
+	jwt.SigningMethodRS512.Name,
+	jwt.SigningMethodES256.Name,
+	jwt.SigningMethodES384.Name,
+	jwt.SigningMethodES512.Name,
+}
+
+type oidcDiscoveryDocument struct {
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// jwksVerifier validates OIDC-issued JWTs using keys fetched from the issuer's JWKS endpoint,
+// refreshed periodically and on-demand when an unknown kid is encountered. It also implements
+// KeySet, so the same fetch/cache/refresh machinery backs both Middleware's direct verify path
+// and Parse's cfg.KeySet path.
+type jwksVerifier struct {
+	issuer            string
+	audiences         []string
+	client            *http.Client
+	allowedAlgorithms []string
+
+	refreshInterval  time.Duration
+	negativeCacheTTL time.Duration
+
+	mu             sync.RWMutex
+	jwksURI        string
+	keys           map[string]interface{}
+	negativeKids   map[string]time.Time
+	cacheExpiresAt time.Time // zero when the last JWKS response carried no Cache-Control max-age
+
+	inflightMu sync.Mutex
+	inflight   map[string]*keyRefresh
+}
+
+// keyRefresh tracks a single in-flight JWKS refresh triggered by an unknown kid, so concurrent
+// lookups for that kid coalesce into one fetch against the issuer.
+type keyRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+func newJWKSVerifier(cfg Config) *jwksVerifier {
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: defaultJWKSFetchTimeout}
+	}
+
+	refreshInterval := cfg.JWKSRefreshInterval
+	if refreshInterval <= 0 {
+		refreshInterval = defaultJWKSRefreshInterval
+	}
+	negativeCacheTTL := cfg.JWKSNegativeCacheTTL
+	if negativeCacheTTL <= 0 {
+		negativeCacheTTL = defaultJWKSNegativeCacheTTL
+	}
+	allowedAlgorithms := cfg.AllowedAlgorithms
+	if len(allowedAlgorithms) == 0 {
+		allowedAlgorithms = jwksAllowedAlgorithms
+	}
+
+	return &jwksVerifier{
+		issuer:            cfg.Issuer,
+		audiences:         cfg.Audiences,
+		client:            client,
+		allowedAlgorithms: allowedAlgorithms,
+		refreshInterval:   refreshInterval,
+		negativeCacheTTL:  negativeCacheTTL,
+		// JWKSURL is pre-seeded as the cached jwksURI, so refresh skips OIDC discovery entirely
+		// whenever it's set; Issuer-based discovery still runs lazily otherwise.
+		jwksURI:      cfg.JWKSURL,
+		keys:         make(map[string]interface{}),
+		negativeKids: make(map[string]time.Time),
+		inflight:     make(map[string]*keyRefresh),
+	}
+}
+
+// NewJWKSKeySet builds a KeySet backed by cfg.JWKSURL (fetched directly) or, if JWKSURL is
+// empty, cfg.Issuer's OIDC discovery document. It fetches once synchronously so a misconfigured
+// endpoint fails fast, then refreshes in the background for the life of ctx - callers typically
+// assign the result to Config.KeySet once at startup and pass that Config to Parse per request.
+func NewJWKSKeySet(ctx context.Context, cfg Config) (KeySet, error) {
+	v := newJWKSVerifier(cfg)
+	fetchCtx, cancel := context.WithTimeout(ctx, defaultJWKSFetchTimeout)
+	defer cancel()
+	if err := v.refresh(fetchCtx); err != nil {
+		jwksRefreshFailuresTotal.Inc()
+		return nil, err
+	}
+	go v.backgroundRefresh(ctx)
+	return v, nil
+}
+
+// start fetches the JWKS once synchronously and then launches a background refresh loop for the
+// life of ctx. A failed initial fetch is only counted in jwks_refresh_failures_total; start
+// returns no error.
+func (v *jwksVerifier) start(ctx context.Context) {
+	fetchCtx, cancel := context.WithTimeout(ctx, defaultJWKSFetchTimeout)
+	defer cancel()
+	if err := v.refresh(fetchCtx); err != nil {
+		jwksRefreshFailuresTotal.Inc()
+	}
+	go v.backgroundRefresh(ctx)
+}
+
+// backgroundRefresh re-fetches the JWKS at refreshInterval until ctx is cancelled, skipping a
+// tick when the issuer's Cache-Control max-age on the last fetch hasn't elapsed yet.
+func (v *jwksVerifier) backgroundRefresh(ctx context.Context) {
+	ticker := time.NewTicker(v.refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if until := v.cachedExpiry(); !until.IsZero() && time.Now().Before(until) {
+				continue
+			}
+			if err := v.refresh(ctx); err != nil {
+				jwksRefreshFailuresTotal.Inc()
+			}
+		}
+	}
+}
+
+// refresh re-resolves the discovery document (if not already known, i.e. JWKSURL wasn't set)
+// and re-fetches the JWKS, replacing the cached key set on success.
+func (v *jwksVerifier) refresh(ctx context.Context) error {
+	jwksRefreshTotal.Inc()
+
+	jwksURI := v.cachedJWKSURI()
+	if jwksURI == "" {
+		discovered, err := v.discoverJWKSURI(ctx)
+		if err != nil {
+			return err
+		}
+		jwksURI = discovered
+	}
+
+	keys, maxAge, err := v.fetchJWKS(ctx, jwksURI)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	v.jwksURI = jwksURI
+	v.keys = keys
+	v.negativeKids = make(map[string]time.Time)
+	if maxAge > 0 {
+		v.cacheExpiresAt = time.Now().Add(maxAge)
+	} else {
+		v.cacheExpiresAt = time.Time{}
+	}
+	v.mu.Unlock()
+	return nil
+}
+
+func (v *jwksVerifier) cachedExpiry() time.Time {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.cacheExpiresAt
+}
+
+func (v *jwksVerifier) cachedJWKSURI() string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.jwksURI
+}
+
+func (v *jwksVerifier) discoverJWKSURI(ctx context.Context) (string, error) {
+	url := strings.TrimRight(v.issuer, "/") + "/.well-known/openid-configuration"
+	body, err := v.get(ctx, url)
+	if err != nil {
+		return "", fmt.Errorf("fetch OIDC discovery document: %w", err)
+	}
+
+	var doc oidcDiscoveryDocument
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return "", fmt.Errorf("decode OIDC discovery document: %w", err)
+	}
+	if doc.JWKSURI == "" {
+		return "", fmt.Errorf("OIDC discovery document missing jwks_uri")
+	}
+	return doc.JWKSURI, nil
+}
+
+// fetchJWKS returns the parsed key set along with the max-age the issuer's Cache-Control header
+// advertised for the response, so the background refresh loop can avoid refetching before the
+// issuer says the document may have changed.
+func (v *jwksVerifier) fetchJWKS(ctx context.Context, jwksURI string) (map[string]interface{}, time.Duration, error) {
+	body, maxAge, err := v.getWithCacheControl(ctx, jwksURI)
+	if err != nil {
+		return nil, 0, fmt.Errorf("fetch JWKS: %w", err)
+	}
+	keys, err := parseJWKSDocument(body)
+	if err != nil {
+		return nil, 0, err
+	}
+	return keys, maxAge, nil
+}
+
+func (v *jwksVerifier) get(ctx context.Context, url string) ([]byte, error) {
+	body, _, err := v.getWithCacheControl(ctx, url)
+	return body, err
+}
+
+func (v *jwksVerifier) getWithCacheControl(ctx context.Context, url string) ([]byte, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, cacheControlMaxAge(resp.Header.Get("Cache-Control")), nil
+}
+
+// cacheControlMaxAge extracts the max-age directive from a Cache-Control header value, returning
+// 0 when absent or unparseable.
+func cacheControlMaxAge(header string) time.Duration {
+	for _, directive := range strings.Split(header, ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(directive), "=")
+		if !found || !strings.EqualFold(strings.TrimSpace(name), "max-age") {
+			continue
+		}
+		seconds, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	return 0
+}
+
+// keyFor resolves the public key for kid, triggering an on-demand refresh when kid is unknown.
+// A kid that fails to resolve is held in a short negative cache so a burst of tokens carrying an
+// unknown or forged kid can't force a refresh storm against the issuer.
+func (v *jwksVerifier) keyFor(ctx context.Context, kid string) (interface{}, error) {
+	if key, ok := v.lookupKey(kid); ok {
+		return key, nil
+	}
+
+	if until, ok := v.lookupNegative(kid); ok && time.Now().Before(until) {
+		return nil, fmt.Errorf("unknown key id (negatively cached): %s", kid)
+	}
+
+	if err := v.refreshForKid(ctx, kid); err != nil {
+		jwksRefreshFailuresTotal.Inc()
+		return nil, fmt.Errorf("refresh JWKS for unknown kid %s: %w", kid, err)
+	}
+
+	if key, ok := v.lookupKey(kid); ok {
+		return key, nil
+	}
+
+	v.mu.Lock()
+	v.negativeKids[kid] = time.Now().Add(v.negativeCacheTTL)
+	v.mu.Unlock()
+	return nil, fmt.Errorf("unknown key id: %s", kid)
+}
+
+// refreshForKid coalesces concurrent refreshes triggered by the same unknown kid into a single
+// underlying JWKS fetch, so a burst of requests arriving after a key rotation doesn't each hit
+// the issuer while the fetch is already in flight.
+func (v *jwksVerifier) refreshForKid(ctx context.Context, kid string) error {
+	v.inflightMu.Lock()
+	if r, ok := v.inflight[kid]; ok {
+		v.inflightMu.Unlock()
+		<-r.done
+		return r.err
+	}
+	r := &keyRefresh{done: make(chan struct{})}
+	v.inflight[kid] = r
+	v.inflightMu.Unlock()
+
+	r.err = v.refresh(ctx)
+	close(r.done)
+
+	v.inflightMu.Lock()
+	delete(v.inflight, kid)
+	v.inflightMu.Unlock()
+	return r.err
+}
+
+// Key implements KeySet, resolving kid against the cached (and refreshed-as-needed) JWKS. It
+// lets Parse use the same verifier a Middleware would build for its own cfg.KeySet.
+func (v *jwksVerifier) Key(ctx context.Context, kid string) (interface{}, error) {
+	return v.keyFor(ctx, kid)
+}
+
+func (v *jwksVerifier) lookupKey(kid string) (interface{}, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+func (v *jwksVerifier) lookupNegative(kid string) (time.Time, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	until, ok := v.negativeKids[kid]
+	return until, ok
+}
+
+// verify validates token against the cached JWKS and returns normalized claims. It checks
+// issuer, audience (when configured), expiry, not-before, and restricts the signing algorithm
+// to the asymmetric allowlist.
+func (v *jwksVerifier) verify(ctx context.Context, token string) (*Claims, error) {
+	opts := []jwt.ParserOption{jwt.WithValidMethods(v.allowedAlgorithms)}
+	if v.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.issuer))
+	}
+
+	parsed, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			return nil, fmt.Errorf("token missing kid header")
+		}
+		return v.keyFor(ctx, kid)
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok || !parsed.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	if len(v.audiences) > 0 && !audienceMatches(claims, v.audiences) {
+		return nil, fmt.Errorf("%w: audience not accepted", ErrInvalidToken)
+	}
+
+	subject, _ := claims["sub"].(string)
+	tenantID, _ := claims["tenant_id"].(string)
+	if subject == "" {
+		return nil, ErrInvalidToken
+	}
+
+	exp, err := claims.GetExpirationTime()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	return &Claims{
+		Subject:   subject,
+		TenantID:  tenantID,
+		Scopes:    scopesFromClaims(claims),
+		ExpiresAt: exp.Time,
+	}, nil
+}
+
+func audienceMatches(claims jwt.MapClaims, expected []string) bool {
+	actual, err := claims.GetAudience()
+	if err != nil {
+		return false
+	}
+	for _, want := range expected {
+		for _, got := range actual {
+			if want == got {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scopesFromClaims extracts scopes from whichever claim the issuer populates: a space-delimited
+// "scope" string (the OAuth2 convention), or a "scp"/"permissions" array (common Auth0/Okta
+// conventions), falling back to the "scopes" claim used by the static-secret path.
+func scopesFromClaims(claims jwt.MapClaims) map[string]struct{} {
+	if scope, ok := claims["scope"].(string); ok && scope != "" {
+		return normalizeScopes(scope)
+	}
+	if scp, ok := claims["scp"]; ok {
+		if scopes := normalizeScopes(scp); len(scopes) > 0 {
+			return scopes
+		}
+	}
+	if permissions, ok := claims["permissions"]; ok {
+		if scopes := normalizeScopes(permissions); len(scopes) > 0 {
+			return scopes
+		}
+	}
+	return normalizeScopes(claims["scopes"])
+}
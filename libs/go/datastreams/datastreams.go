@@ -0,0 +1,220 @@
+// Package datastreams implements lightweight data-streams monitoring checkpoints for Kafka
+// producers and consumers, modelled after how dd-trace-go instruments confluent-kafka:
+// each hop along a pipeline hashes a "pathway" identifier into a Kafka header so that
+// edge and end-to-end latency can be reconstructed without a central coordinator.
+package datastreams
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PathwayHeader is the Kafka header key carrying the encoded checkpoint context.
+const PathwayHeader = "dd-pathway-ctx"
+
+// Direction distinguishes produce from consume checkpoints when hashing.
+type Direction string
+
+const (
+	DirectionOut Direction = "out"
+	DirectionIn  Direction = "in"
+)
+
+// Checkpoint is the decoded pathway context propagated on a message.
+type Checkpoint struct {
+	Hash      uint64
+	OriginAt  time.Time
+	EdgeAt    time.Time
+}
+
+// Recorder receives aggregated latency samples. Implementations should be cheap; Processor
+// buckets samples itself and only calls Recorder from its periodic flush goroutine.
+type Recorder interface {
+	RecordLag(topic string, partition int, seconds float64)
+	RecordPathwayLatency(topic string, seconds float64)
+}
+
+// Processor computes and propagates pathway checkpoints for a single service. A nil
+// *Processor is valid and treats all operations as no-ops, so it can be disabled in tests
+// by simply not constructing one.
+type Processor struct {
+	service  string
+	recorder Recorder
+
+	mu      sync.Mutex
+	samples map[string][]float64
+	lags    map[lagKey]float64
+
+	flushInterval time.Duration
+	stop          chan struct{}
+	stopOnce      sync.Once
+}
+
+type lagKey struct {
+	topic     string
+	partition int
+}
+
+// NewProcessor constructs a Processor for the given service name, flushing bucketed stats
+// to recorder every flushInterval. Pass a nil recorder to collect samples without
+// publishing them (useful for tests that only care about header propagation).
+func NewProcessor(service string, recorder Recorder, flushInterval time.Duration) *Processor {
+	if flushInterval <= 0 {
+		flushInterval = 10 * time.Second
+	}
+	p := &Processor{
+		service:       service,
+		recorder:      recorder,
+		samples:       make(map[string][]float64),
+		lags:          make(map[lagKey]float64),
+		flushInterval: flushInterval,
+		stop:          make(chan struct{}),
+	}
+	go p.flushLoop()
+	return p
+}
+
+// Close stops the aggregation goroutine.
+func (p *Processor) Close() {
+	if p == nil {
+		return
+	}
+	p.stopOnce.Do(func() { close(p.stop) })
+}
+
+// CheckpointOut computes a new pathway hash for a produce call and returns the encoded
+// header value to attach to the outgoing message.
+func (p *Processor) CheckpointOut(topic, partitionKey string, parent *Checkpoint) []byte {
+	if p == nil {
+		return nil
+	}
+	now := time.Now().UTC()
+	parentHash := uint64(0)
+	origin := now
+	if parent != nil {
+		parentHash = parent.Hash
+		origin = parent.OriginAt
+	}
+	hash := pathwayHash(p.service, topic, DirectionOut, parentHash)
+	return encode(Checkpoint{Hash: hash, OriginAt: origin, EdgeAt: now})
+}
+
+// CheckpointIn decodes the header from a consumed message (if present), records edge and
+// pathway latency against the produce/origin timestamps, and returns the checkpoint with a
+// refreshed hash incorporating this service+group as the new parent for downstream hops.
+func (p *Processor) CheckpointIn(topic string, partition int, group string, header []byte) *Checkpoint {
+	if p == nil {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	parent, ok := decode(header)
+	if !ok {
+		parent = Checkpoint{OriginAt: now, EdgeAt: now}
+	}
+
+	edgeLatency := now.Sub(parent.EdgeAt).Seconds()
+	pathwayLatency := now.Sub(parent.OriginAt).Seconds()
+	if edgeLatency < 0 {
+		edgeLatency = 0
+	}
+	if pathwayLatency < 0 {
+		pathwayLatency = 0
+	}
+
+	p.mu.Lock()
+	p.samples[topic] = append(p.samples[topic], pathwayLatency)
+	p.lags[lagKey{topic: topic, partition: partition}] = edgeLatency
+	p.mu.Unlock()
+
+	hash := pathwayHash(p.service+":"+group, topic, DirectionIn, parent.Hash)
+	return &Checkpoint{Hash: hash, OriginAt: parent.OriginAt, EdgeAt: now}
+}
+
+func (p *Processor) flushLoop() {
+	ticker := time.NewTicker(p.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.flush()
+		}
+	}
+}
+
+func (p *Processor) flush() {
+	if p.recorder == nil {
+		return
+	}
+
+	p.mu.Lock()
+	samples := p.samples
+	lags := p.lags
+	p.samples = make(map[string][]float64)
+	p.lags = make(map[lagKey]float64)
+	p.mu.Unlock()
+
+	for topic, values := range samples {
+		for _, v := range values {
+			p.recorder.RecordPathwayLatency(topic, v)
+		}
+	}
+	for key, v := range lags {
+		p.recorder.RecordLag(key.topic, key.partition, v)
+	}
+}
+
+// pathwayHash is FNV-64 over (service, topic, direction, parent_hash), matching the hashing
+// scheme dd-trace-go uses so pathways propagating across services form a stable DAG.
+func pathwayHash(service, topic string, direction Direction, parentHash uint64) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(service))
+	h.Write([]byte{0})
+	h.Write([]byte(topic))
+	h.Write([]byte{0})
+	h.Write([]byte(direction))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.FormatUint(parentHash, 16)))
+	return h.Sum64()
+}
+
+// encode/decode use a simple pipe-delimited wire format: hash|origin_unix_nanos|edge_unix_nanos.
+func encode(c Checkpoint) []byte {
+	return []byte(strings.Join([]string{
+		strconv.FormatUint(c.Hash, 16),
+		strconv.FormatInt(c.OriginAt.UnixNano(), 10),
+		strconv.FormatInt(c.EdgeAt.UnixNano(), 10),
+	}, "|"))
+}
+
+func decode(raw []byte) (Checkpoint, bool) {
+	if len(raw) == 0 {
+		return Checkpoint{}, false
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 {
+		return Checkpoint{}, false
+	}
+	hash, err := strconv.ParseUint(parts[0], 16, 64)
+	if err != nil {
+		return Checkpoint{}, false
+	}
+	originNanos, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return Checkpoint{}, false
+	}
+	edgeNanos, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return Checkpoint{}, false
+	}
+	return Checkpoint{
+		Hash:     hash,
+		OriginAt: time.Unix(0, originNanos).UTC(),
+		EdgeAt:   time.Unix(0, edgeNanos).UTC(),
+	}, true
+}
@@ -0,0 +1,90 @@
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// subscriberBufferSize bounds each subscriber's channel so one slow consumer can't block
+// delivery to the others.
+const subscriberBufferSize = 100
+
+// ConnectFunc lazily establishes the single upstream source a Broadcaster fans out from. It
+// runs once, when the first subscriber arrives; the returned channel is drained until it is
+// closed, at which point the Broadcaster goes back to idle and will reconnect on the next
+// Subscribe call.
+type ConnectFunc[T any] func(ctx context.Context) (<-chan T, error)
+
+// Broadcaster fans a single upstream stream of T out to any number of subscribers, modelled
+// on k3s-io/kine's broadcaster package. It's the shared primitive behind in-process pub/sub:
+// multiple independent consumers (cache invalidation, metrics, an SSE endpoint, an outbox
+// publisher) each get their own channel and their own failure mode, without the producer
+// knowing or caring how many are listening.
+type Broadcaster[T any] struct {
+	mu      sync.Mutex
+	running bool
+	subs    map[chan T]struct{}
+}
+
+// NewBroadcaster constructs an empty Broadcaster. The upstream connection starts lazily on
+// the first Subscribe call.
+func NewBroadcaster[T any]() *Broadcaster[T] {
+	return &Broadcaster[T]{subs: make(map[chan T]struct{})}
+}
+
+// Subscribe registers a new subscriber, starting the upstream connection via connect if this
+// is the first one. The returned channel is unregistered and closed automatically when ctx
+// is cancelled.
+func (b *Broadcaster[T]) Subscribe(ctx context.Context, connect ConnectFunc[T]) (<-chan T, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.running {
+		upstream, err := connect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		b.running = true
+		go b.stream(upstream)
+	}
+
+	sub := make(chan T, subscriberBufferSize)
+	b.subs[sub] = struct{}{}
+
+	context.AfterFunc(ctx, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[sub]; ok {
+			delete(b.subs, sub)
+			close(sub)
+		}
+	})
+
+	return sub, nil
+}
+
+// stream drains upstream and fans each item out to every current subscriber, dropping (and
+// logging) for any subscriber whose buffer is full rather than blocking the others or the
+// upstream producer.
+func (b *Broadcaster[T]) stream(upstream <-chan T) {
+	for item := range upstream {
+		b.mu.Lock()
+		for sub := range b.subs {
+			select {
+			case sub <- item:
+			default:
+				log.Printf("events: dropping message for slow subscriber (buffer cap %d)", subscriberBufferSize)
+			}
+		}
+		b.mu.Unlock()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.running = false
+	for sub := range b.subs {
+		close(sub)
+	}
+	b.subs = make(map[chan T]struct{})
+}
@@ -0,0 +1,127 @@
+// Package health aggregates readiness checks from a service's dependencies (a datastore, a
+// Schema Registry, an outbox relay's publish lag) behind one HTTP endpoint, so a load balancer or
+// Kubernetes readiness probe has a single place to ask "is this instance ready" instead of poking
+// each dependency's own ad-hoc endpoint.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CheckFunc reports a dependency's status. detail, when non-empty, is surfaced verbatim in the
+// aggregate response (e.g. "lag=12") instead of the default "ok"; a non-nil err marks the check
+// failed and detail is ignored.
+type CheckFunc func(ctx context.Context) (detail string, err error)
+
+// Check is a single named, registered health check. Critical checks failing makes the aggregate
+// HTTP response 503; non-critical checks are reported but never flip the overall status.
+type Check struct {
+	Name     string
+	Func     CheckFunc
+	Critical bool
+}
+
+type checkResult struct {
+	detail string
+	err    error
+	polled bool
+}
+
+// Registry runs a fixed set of Checks on a timer and serves their most recently polled results.
+// The zero value is not usable; construct one with NewRegistry.
+type Registry struct {
+	mu      sync.RWMutex
+	checks  []Check
+	results map[string]checkResult
+}
+
+// NewRegistry constructs an empty Registry. Register every Check before calling Run, since
+// Register is not safe to call concurrently with Run or ServeHTTP.
+func NewRegistry() *Registry {
+	return &Registry{results: make(map[string]checkResult)}
+}
+
+// Register adds check to the registry.
+func (r *Registry) Register(check Check) {
+	r.checks = append(r.checks, check)
+}
+
+// Run polls every registered check immediately, then again every interval, until ctx is
+// cancelled. Intended to be started with `go registry.Run(ctx, interval)`; ServeHTTP always
+// serves the result of the most recent poll rather than probing dependencies inline, so a slow or
+// wedged dependency can't turn a health check request itself into a hung request.
+func (r *Registry) Run(ctx context.Context, interval time.Duration) {
+	r.pollAll(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.pollAll(ctx)
+		}
+	}
+}
+
+func (r *Registry) pollAll(ctx context.Context) {
+	r.mu.RLock()
+	checks := make([]Check, len(r.checks))
+	copy(checks, r.checks)
+	r.mu.RUnlock()
+
+	for _, check := range checks {
+		detail, err := check.Func(ctx)
+		r.mu.Lock()
+		r.results[check.Name] = checkResult{detail: detail, err: err, polled: true}
+		r.mu.Unlock()
+	}
+}
+
+// ServeHTTP writes {"checks": {name: "ok" | detail | "error: ..." | "pending"}}, responding 503 if any
+// critical check's last poll failed (or hasn't run yet) and 200 otherwise.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.RLock()
+	checks := make([]Check, len(r.checks))
+	copy(checks, r.checks)
+	results := make(map[string]checkResult, len(r.results))
+	for name, res := range r.results {
+		results[name] = res
+	}
+	r.mu.RUnlock()
+
+	body := make(map[string]string, len(checks))
+	healthy := true
+	for _, check := range checks {
+		res, ok := results[check.Name]
+		switch {
+		case !ok:
+			body[check.Name] = "pending"
+			if check.Critical {
+				healthy = false
+			}
+		case res.err != nil:
+			body[check.Name] = "error: " + res.err.Error()
+			if check.Critical {
+				healthy = false
+			}
+		case res.detail != "":
+			body[check.Name] = res.detail
+		default:
+			body[check.Name] = "ok"
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(struct {
+		Checks map[string]string `json:"checks"`
+	}{Checks: body})
+}
@@ -0,0 +1,90 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeHTTPReturns200WhenAllChecksPass(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(Check{
+		Name:     "dgraph",
+		Critical: true,
+		Func:     func(ctx context.Context) (string, error) { return "", nil },
+	})
+	registry.Register(Check{
+		Name: "repositorySize",
+		Func: func(ctx context.Context) (string, error) { return "exercises=3", nil },
+	})
+	registry.pollAll(context.Background())
+
+	rec := httptest.NewRecorder()
+	registry.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		Checks map[string]string `json:"checks"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	require.Equal(t, "ok", body.Checks["dgraph"])
+	require.Equal(t, "exercises=3", body.Checks["repositorySize"])
+}
+
+func TestServeHTTPReturns503WhenCriticalCheckFails(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(Check{
+		Name:     "schemaRegistry",
+		Critical: true,
+		Func:     func(ctx context.Context) (string, error) { return "", errors.New("connection refused") },
+	})
+	registry.pollAll(context.Background())
+
+	rec := httptest.NewRecorder()
+	registry.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	var body struct {
+		Checks map[string]string `json:"checks"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	require.Equal(t, "error: connection refused", body.Checks["schemaRegistry"])
+}
+
+func TestServeHTTPIgnoresNonCriticalFailures(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(Check{
+		Name: "repositorySize",
+		Func: func(ctx context.Context) (string, error) { return "", errors.New("boom") },
+	})
+	registry.pollAll(context.Background())
+
+	rec := httptest.NewRecorder()
+	registry.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestServeHTTPReportsPendingBeforeFirstPoll(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(Check{
+		Name:     "dgraph",
+		Critical: true,
+		Func:     func(ctx context.Context) (string, error) { return "", nil },
+	})
+
+	rec := httptest.NewRecorder()
+	registry.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	var body struct {
+		Checks map[string]string `json:"checks"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	require.Equal(t, "pending", body.Checks["dgraph"])
+}
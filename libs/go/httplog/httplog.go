@@ -0,0 +1,64 @@
+// Package httplog provides the shared HTTP request-logging middleware used by every backend
+// service's mux, so request records have the same shape (method, path, status, duration, tenant,
+// request ID) regardless of which service emits them.
+package httplog
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"example.com/platform/libs/go/auth"
+)
+
+// RequestIDHeader is read from an inbound request (so a caller or gateway can supply its own
+// correlation ID) and always echoed back on the response, generating a UUID when absent.
+const RequestIDHeader = "X-Request-Id"
+
+// Middleware logs one structured record per request via logger: method, path, status, duration,
+// the request's tenant ID (populated by the auth middleware further up the chain, if any, so
+// Middleware must be wrapped inside it to see claims in context), and the request ID.
+func Middleware(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(RequestIDHeader)
+			if requestID == "" {
+				requestID = uuid.NewString()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			start := time.Now()
+
+			next.ServeHTTP(rec, r)
+
+			var tenantID string
+			if claims, ok := auth.FromContext(r.Context()); ok {
+				tenantID = claims.TenantID
+			}
+
+			logger.Info("http request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", rec.status,
+				"duration_ms", time.Since(start).Milliseconds(),
+				"tenant_id", tenantID,
+				"request_id", requestID,
+			)
+		})
+	}
+}
+
+// statusRecorder captures the status code written by the wrapped handler, defaulting to 200 if
+// the handler never calls WriteHeader explicitly.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
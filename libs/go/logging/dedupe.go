@@ -0,0 +1,81 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dedupeState is shared across the handlers WithAttrs/WithGroup derive from a dedupeHandler, so
+// a fingerprint seen through one derived handler suppresses it on the others too.
+type dedupeState struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// dedupeHandler wraps a slog.Handler and drops records that repeat an identical (level, message,
+// attribute key set) fingerprint within window, so a noisy failure loop - e.g. a DLQ poller
+// hitting a downed Postgres every tick - logs once per window instead of flooding the sink.
+type dedupeHandler struct {
+	next   slog.Handler
+	window time.Duration
+	state  *dedupeState
+}
+
+func newDedupeHandler(next slog.Handler, window time.Duration) *dedupeHandler {
+	return &dedupeHandler{
+		next:   next,
+		window: window,
+		state:  &dedupeState{seen: make(map[string]time.Time)},
+	}
+}
+
+func (h *dedupeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *dedupeHandler) Handle(ctx context.Context, record slog.Record) error {
+	key := fingerprint(record)
+
+	h.state.mu.Lock()
+	last, ok := h.state.seen[key]
+	now := time.Now()
+	if ok && now.Sub(last) < h.window {
+		h.state.mu.Unlock()
+		return nil
+	}
+	h.state.seen[key] = now
+	h.state.mu.Unlock()
+
+	return h.next.Handle(ctx, record)
+}
+
+func (h *dedupeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &dedupeHandler{next: h.next.WithAttrs(attrs), window: h.window, state: h.state}
+}
+
+func (h *dedupeHandler) WithGroup(name string) slog.Handler {
+	return &dedupeHandler{next: h.next.WithGroup(name), window: h.window, state: h.state}
+}
+
+// fingerprint identifies a record by level, message, and attribute key set (not values), since
+// it's the *shape* of a repeated error - not the specific offending ID - that signals a flood.
+func fingerprint(record slog.Record) string {
+	keys := make([]string, 0, record.NumAttrs())
+	record.Attrs(func(a slog.Attr) bool {
+		keys = append(keys, a.Key)
+		return true
+	})
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(record.Level.String())
+	b.WriteByte('|')
+	b.WriteString(record.Message)
+	b.WriteByte('|')
+	b.WriteString(strings.Join(keys, ","))
+	return b.String()
+}
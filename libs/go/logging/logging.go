@@ -0,0 +1,84 @@
+// Package logging builds the structured *slog.Logger shared by backend services, so DLQ and
+// consumer error handling carries consistent tenant/event context regardless of which service
+// emits it.
+package logging
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// Config controls how New builds a logger.
+type Config struct {
+	Format       string        // "json" (default) or "text".
+	Level        string        // debug, info, warn, or error; defaults to info.
+	DedupeWindow time.Duration // suppresses repeated (level, msg, key-set) records within this window; 0 disables deduping.
+}
+
+// FromEnv builds a Config from LOG_FORMAT, LOG_LEVEL, and LOG_DEDUPE_WINDOW, defaulting to
+// JSON output at info level with a 30 second dedupe window.
+func FromEnv() Config {
+	return Config{
+		Format:       getEnv("LOG_FORMAT", "json"),
+		Level:        getEnv("LOG_LEVEL", "info"),
+		DedupeWindow: getDurationEnv("LOG_DEDUPE_WINDOW", 30*time.Second),
+	}
+}
+
+// New builds a *slog.Logger writing to stderr according to cfg.
+func New(cfg Config) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: parseLevel(cfg.Level)}
+
+	var handler slog.Handler
+	if strings.EqualFold(cfg.Format, "text") {
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	} else {
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	}
+
+	if cfg.DedupeWindow > 0 {
+		handler = newDedupeHandler(handler, cfg.DedupeWindow)
+	}
+
+	return slog.New(handler)
+}
+
+// OrDefault returns logger, falling back to slog.Default() when logger is nil so constructors
+// that receive an unconfigured logger never panic on first use.
+func OrDefault(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.Default()
+	}
+	return logger
+}
+
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getDurationEnv(key string, fallback time.Duration) time.Duration {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,85 @@
+// Package schemaregistry provides a minimal Confluent Schema Registry HTTP client shared by
+// producer- and consumer-side Kafka schema integrations across services.
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Config captures how to reach and authenticate against a Schema Registry instance.
+type Config struct {
+	BaseURL     string
+	Username    string
+	Password    string
+	BearerToken string
+}
+
+// FromEnv builds a Config from SCHEMA_REGISTRY_* environment variables.
+func FromEnv() Config {
+	return Config{
+		BaseURL:     os.Getenv("SCHEMA_REGISTRY_URL"),
+		Username:    os.Getenv("SCHEMA_REGISTRY_USERNAME"),
+		Password:    os.Getenv("SCHEMA_REGISTRY_PASSWORD"),
+		BearerToken: os.Getenv("SCHEMA_REGISTRY_BEARER_TOKEN"),
+	}
+}
+
+// Client reads writer schemas from a Confluent-compatible Schema Registry.
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+}
+
+// NewClient constructs a Client with sane defaults.
+func NewClient(cfg Config) *Client {
+	return &Client{
+		cfg: cfg,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (c *Client) authenticate(req *http.Request) {
+	if c.cfg.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.BearerToken)
+		return
+	}
+	if c.cfg.Username != "" {
+		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+	}
+}
+
+// SchemaByID fetches the writer schema registered under id.
+func (c *Client) SchemaByID(ctx context.Context, id int) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/schemas/ids/%d", c.cfg.BaseURL, id), nil)
+	if err != nil {
+		return "", err
+	}
+	c.authenticate(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("schema registry error: %s", body)
+	}
+
+	var payload struct {
+		Schema string `json:"schema"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", err
+	}
+	return payload.Schema, nil
+}
@@ -0,0 +1,190 @@
+// Package token provides a cached, auto-refreshing OAuth2 client-credentials token source, so
+// Kafka SASL/OAUTHBEARER authentication and downstream HTTP clients can share a single IdP
+// session instead of each negotiating (and rate-limiting against) the token endpoint separately.
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// refreshSkew is subtracted from a token's reported expiry so Token refreshes slightly before
+// the IdP actually rejects it, rather than racing a request against expiry.
+const refreshSkew = 60 * time.Second
+
+// defaultExpiry is assumed when the token endpoint omits expires_in.
+const defaultExpiry = 5 * time.Minute
+
+// Config describes how to obtain tokens via the OAuth2 client-credentials grant.
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	TokenURL     string
+	Scopes       []string
+	// HTTPClient overrides the client used to call TokenURL, primarily for tests.
+	HTTPClient *http.Client
+}
+
+// FromEnv builds a Config from OAUTH2_CLIENT_ID, OAUTH2_CLIENT_SECRET, OAUTH2_TOKEN_URL and
+// OAUTH2_SCOPES (comma-separated). A zero-value Config (empty ClientID/TokenURL) means OAuth2 is
+// not configured; callers should fall back to their existing static-secret behaviour.
+func FromEnv() Config {
+	return Config{
+		ClientID:     os.Getenv("OAUTH2_CLIENT_ID"),
+		ClientSecret: os.Getenv("OAUTH2_CLIENT_SECRET"),
+		TokenURL:     os.Getenv("OAUTH2_TOKEN_URL"),
+		Scopes:       splitAndTrim(os.Getenv("OAUTH2_SCOPES")),
+	}
+}
+
+func splitAndTrim(value string) []string {
+	parts := strings.Split(value, ",")
+	out := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			out = append(out, trimmed)
+		}
+	}
+	return out
+}
+
+// Option configures optional Source behaviour.
+type Option func(*Source)
+
+// WithOnRefreshError registers a callback invoked whenever a refresh attempt fails, so callers
+// can surface it as a metric without Source taking a Prometheus dependency itself.
+func WithOnRefreshError(fn func(error)) Option {
+	return func(s *Source) {
+		s.onRefreshError = fn
+	}
+}
+
+// Source caches an OAuth2 client-credentials access token and refreshes it shortly before
+// expiry. A single Source is safe to share across a Kafka SASL mechanism and one or more HTTP
+// clients, which is the point: all three surfaces authenticate as the same IdP session.
+type Source struct {
+	cfg            Config
+	client         *http.Client
+	onRefreshError func(error)
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewSource constructs a Source from cfg.
+func NewSource(cfg Config, opts ...Option) *Source {
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	s := &Source{cfg: cfg, client: client}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Token returns a cached access token, fetching a fresh one if absent or within refreshSkew of
+// expiry. If refreshing fails but a still-cached token exists, Token returns the stale token
+// rather than failing every caller outright, on the bet that the IdP session outlives its
+// reported expiry more often than not.
+func (s *Source) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.accessToken != "" && time.Now().Before(s.expiresAt.Add(-refreshSkew)) {
+		return s.accessToken, nil
+	}
+
+	accessToken, expiresIn, err := s.fetch(ctx)
+	if err != nil {
+		if s.onRefreshError != nil {
+			s.onRefreshError(err)
+		}
+		if s.accessToken != "" {
+			return s.accessToken, nil
+		}
+		return "", err
+	}
+
+	s.accessToken = accessToken
+	s.expiresAt = time.Now().Add(expiresIn)
+	return s.accessToken, nil
+}
+
+func (s *Source) fetch(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", s.cfg.ClientID)
+	form.Set("client_secret", s.cfg.ClientSecret)
+	if len(s.cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.cfg.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("token: requesting client-credentials token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return "", 0, fmt.Errorf("token: token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", 0, fmt.Errorf("token: decoding token response: %w", err)
+	}
+	if body.AccessToken == "" {
+		return "", 0, fmt.Errorf("token: token endpoint response missing access_token")
+	}
+
+	expiresIn := defaultExpiry
+	if body.ExpiresIn > 0 {
+		expiresIn = time.Duration(body.ExpiresIn) * time.Second
+	}
+	return body.AccessToken, expiresIn, nil
+}
+
+// RoundTripper wraps next to inject an Authorization: Bearer header carrying the source's
+// current token, so an http.Client authenticates the same way as Kafka's SASL/OAUTHBEARER
+// handshake. A nil next defaults to http.DefaultTransport.
+func (s *Source) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &roundTripper{source: s, next: next}
+}
+
+type roundTripper struct {
+	source *Source
+	next   http.RoundTripper
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	accessToken, err := rt.source.Token(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("token: obtaining bearer token: %w", err)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	return rt.next.RoundTrip(req)
+}
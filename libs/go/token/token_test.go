@@ -0,0 +1,85 @@
+package token
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceFetchesAndCachesToken(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"tok-1","expires_in":3600}`))
+	}))
+	defer srv.Close()
+
+	source := NewSource(Config{ClientID: "client", ClientSecret: "secret", TokenURL: srv.URL})
+
+	for i := 0; i < 3; i++ {
+		got, err := source.Token(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "tok-1", got)
+	}
+	require.Equal(t, 1, requests)
+}
+
+func TestSourceFallsBackToStaleTokenOnRefreshError(t *testing.T) {
+	fail := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"tok-1","expires_in":0}`))
+	}))
+	defer srv.Close()
+
+	var refreshErrors int
+	source := NewSource(Config{ClientID: "client", ClientSecret: "secret", TokenURL: srv.URL},
+		WithOnRefreshError(func(error) { refreshErrors++ }))
+
+	got, err := source.Token(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "tok-1", got)
+
+	// Force the cached token to look expired so the next call refreshes, then fail the
+	// refresh and confirm the stale token is still served.
+	source.expiresAt = source.expiresAt.Add(-time.Hour)
+	fail = true
+
+	got, err = source.Token(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "tok-1", got)
+	require.Equal(t, 1, refreshErrors)
+}
+
+func TestRoundTripperInjectsBearerHeader(t *testing.T) {
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"tok-2","expires_in":3600}`))
+	}))
+	defer tokenSrv.Close()
+
+	source := NewSource(Config{ClientID: "client", ClientSecret: "secret", TokenURL: tokenSrv.URL})
+	client := &http.Client{Transport: source.RoundTripper(nil)}
+
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, "Bearer tok-2", gotAuth)
+}
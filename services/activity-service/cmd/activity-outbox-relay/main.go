@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/segmentio/kafka-go"
+
+	"example.com/activity/internal/config"
+	"example.com/activity/internal/observability"
+	"example.com/activity/internal/outbox"
+	"example.com/activity/internal/persistence/postgres"
+	"example.com/platform/libs/go/datastreams"
+	"example.com/platform/libs/go/health"
+	"example.com/platform/libs/go/logging"
+)
+
+// outboxProducer is the set of methods main needs from whichever producer backend
+// OutboxExactlyOnce selects, so both outbox.KafkaProducer and outbox.TransactionalWriter can be
+// assigned to the same variable below.
+type outboxProducer interface {
+	WriteMessages(ctx context.Context, topic string, msgs ...kafka.Message) error
+	Close() error
+}
+
+func main() {
+	cfg := config.Load()
+	logger := logging.New(cfg.Logging)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, cfg.PostgresURL)
+	if err != nil {
+		logger.Error("failed to connect to postgres", "error", err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	dsp := datastreams.NewProcessor("activity-outbox-relay", observability.DataStreamsRecorder{}, 10*time.Second)
+	defer dsp.Close()
+	var producer outboxProducer
+	if cfg.OutboxExactlyOnce {
+		txProducer, err := outbox.NewTransactionalWriter(cfg.KafkaBrokers, cfg.OutboxInstanceID)
+		if err != nil {
+			logger.Error("failed to construct transactional kafka producer", "error", err)
+			os.Exit(1)
+		}
+		producer = txProducer
+	} else {
+		producer = outbox.NewKafkaProducer(cfg.KafkaBrokers, outbox.WithDataStreamsProcessor(dsp), outbox.WithSecurityConfig(cfg.KafkaSecurity))
+	}
+	defer producer.Close()
+
+	var registryOpts []outbox.SchemaRegistryClientOption
+	if cfg.SchemaRegistryToken != "" {
+		registryOpts = append(registryOpts, outbox.WithBearerToken(cfg.SchemaRegistryToken))
+	} else if cfg.SchemaRegistryUser != "" {
+		registryOpts = append(registryOpts, outbox.WithBasicAuth(cfg.SchemaRegistryUser, cfg.SchemaRegistryPass))
+	}
+	registry := outbox.NewSchemaRegistryClient(cfg.SchemaRegistryURL, registryOpts...)
+
+	dispatcher := outbox.NewDispatcher(pool, producer, registry, cfg.OutboxPollInterval, cfg.OutboxBatchSize, outbox.WithDispatcherLogger(logger))
+	replayer := outbox.NewReplayer(pool, cfg.DLQReplayBackoff, cfg.DLQReplayInterval)
+
+	if cfg.SchemaCompatibilityCheckEnabled {
+		if err := dispatcher.CheckCatalogCompatibility(ctx, postgres.EventSchemaSubjects()); err != nil {
+			logger.Error("schema compatibility check failed", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	healthRegistry := health.NewRegistry()
+	healthRegistry.Register(health.Check{
+		Name:     "schemaRegistry",
+		Critical: true,
+		Func: func(checkCtx context.Context) (string, error) {
+			return "", registry.Ping(checkCtx)
+		},
+	})
+	healthRegistry.Register(health.Check{
+		Name:     "outbox",
+		Critical: true,
+		Func: func(checkCtx context.Context) (string, error) {
+			lag, err := dispatcher.Lag(checkCtx, cfg.OutboxLagThreshold)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("lag=%d", lag), nil
+		},
+	})
+	go healthRegistry.Run(ctx, 15*time.Second)
+
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", promhttp.Handler())
+	metricsMux.Handle("/healthz", healthRegistry)
+	metricsMux.Handle("/readyz", healthRegistry)
+	metricsSrv := &http.Server{Addr: cfg.MetricsAddress, Handler: metricsMux}
+
+	go func() {
+		logger.Info("outbox relay metrics listening", "address", cfg.MetricsAddress)
+		if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("metrics server error", "error", err)
+		}
+	}()
+
+	go dispatcher.Start(ctx)
+	go replayer.Start(ctx)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	logger.Info("outbox relay started")
+	<-stop
+	logger.Info("outbox relay shutdown requested")
+	cancel()
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+	if err := metricsSrv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("metrics server shutdown error", "error", err)
+	}
+
+	dispatcher.Wait()
+	replayer.Wait()
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"log"
 	"net/http"
 	"os"
 	"os/signal"
@@ -14,39 +13,50 @@ import (
 
 	"example.com/activity/internal/api"
 	"example.com/activity/internal/auth"
+	"example.com/activity/internal/auth/ratelimit"
 	"example.com/activity/internal/config"
 	"example.com/activity/internal/domain"
 	"example.com/activity/internal/outbox"
-	persistence "example.com/activity/internal/persistence/postgres"
+	"example.com/activity/internal/persistence"
+	pgrepo "example.com/activity/internal/persistence/postgres"
 	httptransport "example.com/activity/internal/transport/http"
+	"example.com/platform/libs/go/httplog"
+	"example.com/platform/libs/go/logging"
 )
 
 func main() {
 	cfg := config.Load()
+	logger := logging.New(cfg.Logging)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	pool, err := pgxpool.New(ctx, cfg.PostgresURL)
 	if err != nil {
-		log.Fatalf("failed to connect to postgres: %v", err)
+		logger.Error("failed to connect to postgres", "error", err)
+		os.Exit(1)
 	}
 	defer pool.Close()
 
-	repo := persistence.NewRepository(pool)
-	producer := outbox.NewKafkaProducer(cfg.KafkaBrokers)
-	defer producer.Close()
+	repo := pgrepo.NewRepository(pool)
 
-	registry := outbox.NewSchemaRegistryClient(cfg.SchemaRegistryURL)
-	dispatcher := outbox.NewDispatcher(pool, producer, registry, cfg.OutboxPollInterval, cfg.OutboxBatchSize)
-
-	go dispatcher.Start(ctx)
+	// Outbox delivery and automated replay run out-of-process in cmd/activity-outbox-relay;
+	// the replayer here only backs the admin DLQ endpoints below.
+	replayer := outbox.NewReplayer(pool, cfg.DLQReplayBackoff, cfg.DLQReplayInterval)
 
 	service := domain.NewService(repo)
 
-	handler := api.NewHandler(service)
+	verifyKeys := make([][]byte, 0, len(cfg.CursorVerifyKeys))
+	for _, key := range cfg.CursorVerifyKeys {
+		verifyKeys = append(verifyKeys, []byte(key))
+	}
+	cursorCodec := persistence.NewCursorCodec([]byte(cfg.CursorSigningKey), verifyKeys...)
+
+	handler := api.NewHandler(service, cursorCodec)
+	adminHandler := api.NewDLQAdminHandler(replayer, cursorCodec)
 	mux := http.NewServeMux()
 	handler.RegisterRoutes(mux)
+	adminHandler.RegisterRoutes(mux)
 	mux.Handle("/metrics", promhttp.Handler())
 
 	// Simple CORS middleware for local dev
@@ -64,30 +74,53 @@ func main() {
 		})
 	}
 
-	// Basic request logger
-	logger := func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			log.Printf("%s %s", r.Method, r.URL.Path)
-			next.ServeHTTP(w, r)
-		})
+	requestLogger := httplog.Middleware(logger)
+
+	authCfg := auth.Config{Secret: cfg.JWTSecret, Issuer: cfg.JWTIssuer}
+	if cfg.OIDCIssuer != "" {
+		authCfg = auth.Config{
+			Issuer:               cfg.OIDCIssuer,
+			Audiences:            cfg.OIDCAudiences,
+			JWKSRefreshInterval:  cfg.JWKSRefreshInterval,
+			JWKSNegativeCacheTTL: cfg.JWKSNegativeCacheTTL,
+		}
 	}
+	authMiddleware := auth.NewMiddleware(authCfg)
+
+	var rateLimitStore ratelimit.Store
+	if cfg.RateLimitRedisAddr != "" {
+		rateLimitStore = ratelimit.NewRedisStore(ratelimit.NewGoRedisClient(cfg.RateLimitRedisAddr), cfg.RateLimitWindow)
+	} else {
+		rateLimitStore = ratelimit.NewInProcessStore()
+	}
+
+	quotaSource := ratelimit.NewPostgresQuotaSource(pool, cfg.RateLimitQuotaRefreshInterval)
+	go quotaSource.Start(ctx)
 
-	authMiddleware := auth.NewMiddleware(auth.Config{Secret: cfg.JWTSecret, Issuer: cfg.JWTIssuer})
+	rateLimitMiddleware := ratelimit.NewMiddleware(rateLimitStore, quotaSource, ratelimit.Config{
+		Default:  ratelimit.Limit{RPS: cfg.RateLimitDefaultRPS, Burst: cfg.RateLimitDefaultBurst},
+		PerRoute: cfg.RateLimitPerRoute,
+		Skipper: func(r *http.Request) bool {
+			return r.URL.Path == "/healthz" || r.URL.Path == "/metrics"
+		},
+		Logger: logger,
+	})
 
 	server := httptransport.NewServer(httptransport.ServerConfig{
 		Address:      cfg.HTTPAddress,
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  60 * time.Second,
-	}, authMiddleware.Wrap(logger(cors(mux))))
+	}, authMiddleware.Wrap(rateLimitMiddleware.Wrap(requestLogger(cors(mux)))))
 
 	shutdownCh := make(chan os.Signal, 1)
 	signal.Notify(shutdownCh, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
-		log.Printf("activity-service listening on %s", cfg.HTTPAddress)
+		logger.Info("activity-service listening", "address", cfg.HTTPAddress)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("server error: %v", err)
+			logger.Error("server error", "error", err)
+			os.Exit(1)
 		}
 	}()
 
@@ -98,8 +131,8 @@ func main() {
 	defer shutdownCancel()
 
 	if err := server.Shutdown(shutdownCtx); err != nil {
-		log.Printf("graceful shutdown failed: %v", err)
+		logger.Error("graceful shutdown failed", "error", err)
 	}
 
-	dispatcher.Wait()
+	replayer.Wait()
 }
@@ -32,7 +32,13 @@ func main() {
 
 	handler := consumer.NewPersistenceHandler(pool)
 
-	metricsSrv := &http.Server{Addr: cfg.MetricsAddress, Handler: promhttp.Handler()}
+	deadLetter := consumer.NewDeadLetterProducer(cfg.KafkaBrokers, "")
+	defer deadLetter.Close()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	consumer.NewAdminHandler(consumer.NewDLQReplayer(cfg.KafkaBrokers, "")).RegisterRoutes(mux)
+	metricsSrv := &http.Server{Addr: cfg.MetricsAddress, Handler: mux}
 
 	go func() {
 		log.Printf("consumer metrics listening on %s", cfg.MetricsAddress)
@@ -57,7 +63,12 @@ func main() {
 			ReadLagInterval: -1,
 		})
 
-		proc := consumer.NewProcessor(reader, handler)
+		proc := consumer.NewProcessor(reader, handler,
+			consumer.WithRetryPolicy(consumer.DefaultRetryPolicy()),
+			consumer.WithDeadLetterProducer(deadLetter),
+			consumer.WithConcurrency(cfg.ConsumerConcurrency),
+			consumer.WithQueueDepth(cfg.ConsumerQueueDepth),
+		)
 
 		wg.Add(1)
 		go func(topic string, r *kafka.Reader) {
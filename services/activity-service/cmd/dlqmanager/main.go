@@ -2,7 +2,7 @@ package main
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,8 +12,10 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"example.com/activity/internal/alerts"
 	"example.com/activity/internal/config"
 	"example.com/activity/internal/outbox"
+	"example.com/platform/libs/go/logging"
 )
 
 const (
@@ -22,30 +24,54 @@ const (
 
 func main() {
 	cfg := config.Load()
+	logger := logging.New(cfg.Logging)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	pool, err := pgxpool.New(ctx, cfg.PostgresURL)
 	if err != nil {
-		log.Fatalf("failed to connect to postgres: %v", err)
+		logger.Error("failed to connect to postgres", "error", err)
+		os.Exit(1)
 	}
 	defer pool.Close()
 
-	manager := outbox.NewDLQManager(pool, cfg.DLQMaxRetries, cfg.DLQBaseDelay)
+	alertNotifier := alerts.NewNotifier(alerts.Config{
+		URL:          cfg.AlertmanagerURL,
+		DedupeWindow: cfg.AlertmanagerDedupeWindow,
+	}, logger)
+	defer alertNotifier.Close()
 
-	metricsSrv := &http.Server{Addr: cfg.MetricsAddress, Handler: promhttp.Handler()}
+	manager := outbox.NewDLQManager(pool, cfg.DLQMaxRetries, cfg.DLQBaseDelay,
+		outbox.WithLogger(logger),
+		outbox.WithAlertNotifier(alertNotifier),
+		outbox.WithQuarantineAlertThreshold(cfg.DLQQuarantineAlertThreshold, cfg.DLQQuarantineAlertWindow),
+	)
+	elector := outbox.NewLeaderElector(pool, logger)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+	metricsSrv := &http.Server{Addr: cfg.MetricsAddress, Handler: mux}
 	go func() {
-		log.Printf("dlq manager metrics listening on %s", cfg.MetricsAddress)
+		logger.Info("dlq manager metrics listening", "address", cfg.MetricsAddress)
 		if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Printf("metrics server error: %v", err)
+			logger.Error("metrics server error", "error", err)
 		}
 	}()
 
+	// Leadership runs on its own loop, independent of the poll ticker, so a standby
+	// acquires within DLQLeaderRetryBackoff..DLQPollInterval of the previous leader
+	// releasing rather than waiting for the next poll tick.
+	go runElection(ctx, elector, logger, cfg.DLQLeaderRetryBackoff, cfg.DLQPollInterval)
+
 	ticker := time.NewTicker(cfg.DLQPollInterval)
 	defer ticker.Stop()
 
-	log.Printf("DLQ manager started (interval=%s, maxRetries=%d)", cfg.DLQPollInterval, cfg.DLQMaxRetries)
+	logger.Info("dlq manager started", "interval", cfg.DLQPollInterval, "max_retries", cfg.DLQMaxRetries)
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
@@ -55,14 +81,17 @@ func main() {
 		case <-ctx.Done():
 			goto shutdown
 		case <-ticker.C:
+			if !elector.IsLeader() {
+				continue
+			}
 			processed, err := manager.RunOnce(ctx, defaultDLQBatchSize)
 			if err != nil {
-				log.Printf("dlq manager error: %v", err)
+				logger.Error("dlq manager poll error", "error", err)
 			} else if processed > 0 {
-				log.Printf("dlq manager processed %d entries", processed)
+				logger.Info("dlq manager processed entries", "count", processed)
 			}
 		case <-stop:
-			log.Println("dlq manager received shutdown signal")
+			logger.Info("dlq manager received shutdown signal")
 			cancel()
 			goto shutdown
 		}
@@ -71,7 +100,58 @@ func main() {
 shutdown:
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer shutdownCancel()
+
+	// Voluntary handoff: release the advisory lock explicitly so a standby can become
+	// leader on its very next acquisition attempt instead of waiting for this
+	// connection's TCP timeout to expire.
+	if elector.IsLeader() {
+		elector.Release(shutdownCtx)
+	}
+
 	if err := metricsSrv.Shutdown(shutdownCtx); err != nil {
-		log.Printf("metrics server shutdown error: %v", err)
+		logger.Error("metrics server shutdown error", "error", err)
+	}
+}
+
+// runElection repeatedly attempts to acquire (or confirm) DLQ manager leadership until ctx is
+// cancelled. Failed attempts back off exponentially from baseBackoff up to pollInterval;
+// successful attempts simply re-check at pollInterval, since a held advisory lock only needs
+// a cheap liveness ping rather than a fresh acquisition attempt.
+func runElection(ctx context.Context, elector *outbox.LeaderElector, logger *slog.Logger, baseBackoff, pollInterval time.Duration) {
+	if baseBackoff <= 0 {
+		baseBackoff = time.Second
+	}
+	maxBackoff := pollInterval
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+
+	backoff := baseBackoff
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		leading, err := elector.TryAcquire(ctx)
+		if err != nil {
+			logger.Error("dlq leader election error", "error", err)
+		}
+
+		wait := pollInterval
+		if !leading {
+			wait = backoff
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		} else {
+			backoff = baseBackoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
 	}
 }
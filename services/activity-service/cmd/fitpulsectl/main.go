@@ -0,0 +1,69 @@
+// Command fitpulsectl provides operator subcommands for activity-service maintenance tasks
+// that don't belong in a long-running server, starting with per-tenant bucket provisioning.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"example.com/activity/internal/config"
+	"example.com/activity/internal/persistence/postgres"
+	"example.com/platform/libs/go/logging"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	switch os.Args[1] {
+	case "buckets":
+		runBuckets(os.Args[2:])
+	default:
+		usage()
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: fitpulsectl buckets upgrade <tenant-id>")
+}
+
+// runBuckets handles the "buckets" subcommand family; today that's just "upgrade", which
+// provisions (or re-provisions, if migrations were added since) a tenant's dedicated schema.
+func runBuckets(args []string) {
+	if len(args) != 2 || args[0] != "upgrade" {
+		usage()
+		os.Exit(1)
+	}
+	tenantID := args[1]
+
+	cfg := config.Load()
+	logger := logging.New(cfg.Logging)
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, cfg.PostgresURL)
+	if err != nil {
+		logger.Error("failed to connect to postgres", "error", err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	migrationsDir := os.Getenv("FITPULSECTL_MIGRATIONS_DIR")
+	if migrationsDir == "" {
+		migrationsDir = "db/postgres/migrations"
+	}
+
+	manager := postgres.NewBucketManager(pool, migrationsDir)
+	schema, err := manager.EnsureBucket(ctx, tenantID)
+	if err != nil {
+		logger.Error("failed to provision bucket", "tenant_id", tenantID, "error", err)
+		os.Exit(1)
+	}
+
+	logger.Info("bucket provisioned", "tenant_id", tenantID, "schema", schema)
+}
@@ -0,0 +1,257 @@
+// Package alerts provides a direct Alertmanager v2 client so operators still get paged when a
+// service's own Prometheus scrape is broken, complementing the PrometheusRule YAMLs vendored
+// under rules/ that alert through the normal scrape-based path.
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/platform/libs/go/logging"
+)
+
+const (
+	defaultSendTimeout   = 5 * time.Second
+	defaultDedupeWindow  = 5 * time.Minute
+	defaultQueueCapacity = 256
+	retryBackoff         = 10 * time.Second
+)
+
+// Config controls how NewNotifier builds a Notifier.
+type Config struct {
+	// URL is the Alertmanager base URL, e.g. "http://alertmanager:9093". A Notifier built with
+	// an empty URL accepts Fire/Resolve calls as no-ops, so callers can construct one
+	// unconditionally and skip a nil check at every call site.
+	URL string
+	// DedupeWindow suppresses re-sending a still-firing alert with the same label fingerprint
+	// within this window. Defaults to 5 minutes when zero.
+	DedupeWindow time.Duration
+	// QueueCapacity bounds the number of pending sends buffered while Alertmanager is
+	// unreachable; the oldest pending send is dropped once full. Defaults to 256 when zero.
+	QueueCapacity int
+	// HTTPClient overrides the client used to POST to Alertmanager, primarily for tests.
+	HTTPClient *http.Client
+}
+
+// Alert is a single Alertmanager v2 alert. Set EndsAt to resolve a previously fired alert with
+// the same Labels; leave it zero to fire or keep firing.
+type Alert struct {
+	Labels       map[string]string
+	Annotations  map[string]string
+	StartsAt     time.Time
+	EndsAt       time.Time
+	GeneratorURL string
+}
+
+// Notifier posts alerts directly to Alertmanager's v2 API. It deduplicates by label fingerprint
+// within DedupeWindow and retries failed sends through a bounded in-memory queue so a transient
+// Alertmanager outage doesn't lose alerts raised while it's down.
+type Notifier struct {
+	url          string
+	client       *http.Client
+	logger       *slog.Logger
+	dedupeWindow time.Duration
+
+	mu   sync.Mutex
+	sent map[string]time.Time // fingerprint -> last time an unresolved instance was sent
+
+	queue chan Alert
+	done  chan struct{}
+}
+
+// NewNotifier builds a Notifier from cfg. When cfg.URL is empty, Fire and Resolve are no-ops and
+// no background delivery goroutine is started.
+func NewNotifier(cfg Config, logger *slog.Logger) *Notifier {
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: defaultSendTimeout}
+	}
+	dedupeWindow := cfg.DedupeWindow
+	if dedupeWindow <= 0 {
+		dedupeWindow = defaultDedupeWindow
+	}
+	capacity := cfg.QueueCapacity
+	if capacity <= 0 {
+		capacity = defaultQueueCapacity
+	}
+
+	n := &Notifier{
+		url:          cfg.URL,
+		client:       client,
+		logger:       logging.OrDefault(logger),
+		dedupeWindow: dedupeWindow,
+		sent:         make(map[string]time.Time),
+		queue:        make(chan Alert, capacity),
+		done:         make(chan struct{}),
+	}
+	if n.url != "" {
+		go n.drain()
+	}
+	return n
+}
+
+// Fire enqueues alert for delivery, unless an unresolved instance with the same label
+// fingerprint was already sent within DedupeWindow. A resolve (EndsAt at or before now) always
+// goes out and clears the dedupe entry, so the fingerprint's next firing isn't suppressed.
+func (n *Notifier) Fire(alert Alert) {
+	if n.url == "" {
+		return
+	}
+
+	fp := fingerprint(alert.Labels)
+	resolved := !alert.EndsAt.IsZero() && !alert.EndsAt.After(time.Now())
+
+	n.mu.Lock()
+	if resolved {
+		delete(n.sent, fp)
+	} else if last, ok := n.sent[fp]; ok && time.Since(last) < n.dedupeWindow {
+		n.mu.Unlock()
+		return
+	} else {
+		n.sent[fp] = time.Now()
+	}
+	n.mu.Unlock()
+
+	n.enqueue(alert)
+}
+
+// Resolve is a convenience for Fire with EndsAt set to now.
+func (n *Notifier) Resolve(labels map[string]string) {
+	n.Fire(Alert{Labels: labels, EndsAt: time.Now()})
+}
+
+// Close stops the delivery goroutine. Queued alerts are discarded.
+func (n *Notifier) Close() {
+	if n.url == "" {
+		return
+	}
+	close(n.done)
+}
+
+func (n *Notifier) enqueue(alert Alert) {
+	select {
+	case n.queue <- alert:
+		return
+	default:
+	}
+
+	// Queue is full: drop the oldest pending alert to make room rather than blocking the
+	// caller (DLQManager.RunOnce) on a stuck Alertmanager.
+	select {
+	case <-n.queue:
+	default:
+	}
+	select {
+	case n.queue <- alert:
+	default:
+	}
+	n.logger.Warn("alertmanager queue full, dropped oldest pending alert")
+}
+
+func (n *Notifier) drain() {
+	for {
+		select {
+		case <-n.done:
+			return
+		case alert := <-n.queue:
+			if err := n.send(alert); err != nil {
+				n.logger.Warn("alertmanager delivery failed, will retry", "error", err)
+				go n.retryAfterBackoff(alert)
+			}
+		}
+	}
+}
+
+// retryAfterBackoff re-enqueues alert once retryBackoff has elapsed. The retry still goes
+// through enqueue's bounded drop-oldest policy, so a sustained outage can't grow memory
+// unbounded.
+func (n *Notifier) retryAfterBackoff(alert Alert) {
+	select {
+	case <-n.done:
+		return
+	case <-time.After(retryBackoff):
+		n.enqueue(alert)
+	}
+}
+
+func (n *Notifier) send(alert Alert) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultSendTimeout)
+	defer cancel()
+
+	body, err := json.Marshal([]alertPayload{toPayload(alert)})
+	if err != nil {
+		return fmt.Errorf("encode alert payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(n.url, "/")+"/api/v2/alerts", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post alert to alertmanager: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("alertmanager returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// alertPayload is the Alertmanager v2 API's wire shape for a single alert.
+type alertPayload struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     string            `json:"startsAt,omitempty"`
+	EndsAt       string            `json:"endsAt,omitempty"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+func toPayload(a Alert) alertPayload {
+	startsAt := a.StartsAt
+	if startsAt.IsZero() {
+		startsAt = time.Now()
+	}
+	payload := alertPayload{
+		Labels:       a.Labels,
+		Annotations:  a.Annotations,
+		StartsAt:     startsAt.UTC().Format(time.RFC3339),
+		GeneratorURL: a.GeneratorURL,
+	}
+	if !a.EndsAt.IsZero() {
+		payload.EndsAt = a.EndsAt.UTC().Format(time.RFC3339)
+	}
+	return payload
+}
+
+// fingerprint hashes labels' sorted key-value pairs, mirroring how Alertmanager itself identifies
+// distinct alert instances for deduplication.
+func fingerprint(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := fnv.New64a()
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte{0})
+		h.Write([]byte(labels[k]))
+		h.Write([]byte{0})
+	}
+	return strconv.FormatUint(h.Sum64(), 16)
+}
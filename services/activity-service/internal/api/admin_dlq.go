@@ -0,0 +1,298 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"example.com/activity/internal/auth"
+	"example.com/activity/internal/outbox"
+	"example.com/activity/internal/persistence"
+)
+
+// defaultDrainRate paces Drain requests that don't specify rate_ms, so an operator-triggered
+// drain of a large backlog doesn't slam the outbox dispatcher all at once by default.
+const defaultDrainRate = 100 * time.Millisecond
+
+// DLQAdminHandler exposes operator endpoints for inspecting and force-replaying
+// dead-lettered outbox events.
+type DLQAdminHandler struct {
+	replayer *outbox.Replayer
+	cursors  *persistence.CursorCodec
+}
+
+// NewDLQAdminHandler builds a DLQAdminHandler backed by the given replayer.
+func NewDLQAdminHandler(replayer *outbox.Replayer, cursors *persistence.CursorCodec) *DLQAdminHandler {
+	return &DLQAdminHandler{replayer: replayer, cursors: cursors}
+}
+
+// RegisterRoutes wires admin DLQ endpoints to the mux, alongside activity metrics.
+func (h *DLQAdminHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/v1/admin/dlq", h.listDLQ)
+	mux.HandleFunc("/v1/admin/dlq/replay", h.replayDLQ)
+	mux.HandleFunc("/v1/admin/dlq/replay/batch", h.replayDLQBatch)
+	mux.HandleFunc("/v1/admin/dlq/drain", h.drainDLQ)
+}
+
+func (h *DLQAdminHandler) listDLQ(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "unsupported method")
+		return
+	}
+
+	claims, ok := auth.FromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "missing bearer token")
+		return
+	}
+	if !claims.HasScope(auth.ScopeDLQRead) {
+		writeError(w, http.StatusForbidden, "forbidden", "scope dlq:read required")
+		return
+	}
+
+	limit := 20
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	cursor, err := h.cursors.Decode(r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "validation_failed", "invalid cursor")
+		return
+	}
+
+	filter, err := parseDLQFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "validation_failed", err.Error())
+		return
+	}
+
+	entries, next, err := h.replayer.ListDLQ(r.Context(), filter, cursor, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+
+	items := make([]DLQEntryView, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, toDLQEntryView(entry))
+	}
+
+	writeJSON(w, http.StatusOK, ListDLQResponse{
+		Items:      items,
+		NextCursor: h.cursors.Encode(next),
+	})
+}
+
+func (h *DLQAdminHandler) replayDLQ(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "unsupported method")
+		return
+	}
+
+	claims, ok := auth.FromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "missing bearer token")
+		return
+	}
+	if !claims.HasScope(auth.ScopeDLQReplay) {
+		writeError(w, http.StatusForbidden, "forbidden", "scope dlq:replay required")
+		return
+	}
+
+	var req ReplayDLQRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "unable to parse body")
+		return
+	}
+	if req.EventID == 0 {
+		writeError(w, http.StatusBadRequest, "validation_failed", "event_id is required")
+		return
+	}
+
+	if err := h.replayer.ReplayByEventID(r.Context(), req.EventID); err != nil {
+		if errors.Is(err, outbox.ErrDLQEntryNotFound) {
+			writeError(w, http.StatusNotFound, "not_found", "dlq entry not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "replayed"})
+}
+
+func (h *DLQAdminHandler) replayDLQBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "unsupported method")
+		return
+	}
+
+	claims, ok := auth.FromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "missing bearer token")
+		return
+	}
+	if !claims.HasScope(auth.ScopeDLQReplay) {
+		writeError(w, http.StatusForbidden, "forbidden", "scope dlq:replay required")
+		return
+	}
+
+	var req ReplayDLQBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "unable to parse body")
+		return
+	}
+	if len(req.EventIDs) == 0 {
+		writeError(w, http.StatusBadRequest, "validation_failed", "event_ids is required")
+		return
+	}
+
+	replayed, err := h.replayer.ReplayByEventIDs(r.Context(), req.EventIDs)
+	if err != nil {
+		if errors.Is(err, outbox.ErrDLQEntryNotFound) {
+			writeError(w, http.StatusNotFound, "not_found", "one or more dlq entries not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, ReplayDLQBatchResponse{Replayed: replayed})
+}
+
+func (h *DLQAdminHandler) drainDLQ(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "unsupported method")
+		return
+	}
+
+	claims, ok := auth.FromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "missing bearer token")
+		return
+	}
+	if !claims.HasScope(auth.ScopeDLQReplay) {
+		writeError(w, http.StatusForbidden, "forbidden", "scope dlq:replay required")
+		return
+	}
+
+	var req DrainDLQRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "unable to parse body")
+		return
+	}
+	if req.Topic == "" {
+		writeError(w, http.StatusBadRequest, "validation_failed", "topic is required")
+		return
+	}
+
+	rate := defaultDrainRate
+	if req.RateMillis > 0 {
+		rate = time.Duration(req.RateMillis) * time.Millisecond
+	}
+
+	replayed, err := h.replayer.Drain(r.Context(), req.Topic, rate)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, DrainDLQResponse{Replayed: replayed})
+}
+
+// parseDLQFilter builds a DLQFilter from listDLQ's query parameters. from/to are RFC3339
+// timestamps; any other param is matched verbatim (reason is a substring match).
+func parseDLQFilter(query map[string][]string) (outbox.DLQFilter, error) {
+	get := func(key string) string {
+		if values := query[key]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	filter := outbox.DLQFilter{
+		Topic:          get("topic"),
+		EventType:      get("event_type"),
+		TenantID:       get("tenant_id"),
+		ReasonContains: get("reason"),
+	}
+
+	if raw := get("from"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return outbox.DLQFilter{}, errors.New("from must be an RFC3339 timestamp")
+		}
+		filter.From = parsed
+	}
+	if raw := get("to"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return outbox.DLQFilter{}, errors.New("to must be an RFC3339 timestamp")
+		}
+		filter.To = parsed
+	}
+
+	return filter, nil
+}
+
+// DLQEntryView exposes a dead-lettered outbox event for operator review.
+type DLQEntryView struct {
+	EventID    int64     `json:"event_id"`
+	TenantID   string    `json:"tenant_id"`
+	EventType  string    `json:"event_type"`
+	Topic      string    `json:"topic"`
+	Reason     string    `json:"reason"`
+	RetryCount int       `json:"retry_count"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ListDLQResponse packages a paginated page of DLQ entries.
+type ListDLQResponse struct {
+	Items      []DLQEntryView `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// ReplayDLQRequest identifies a DLQ entry to force-replay by its original event_id.
+type ReplayDLQRequest struct {
+	EventID int64 `json:"event_id"`
+}
+
+// ReplayDLQBatchRequest identifies a set of DLQ entries to force-replay together, atomically.
+type ReplayDLQBatchRequest struct {
+	EventIDs []int64 `json:"event_ids"`
+}
+
+// ReplayDLQBatchResponse reports how many entries from a batch replay request were requeued.
+type ReplayDLQBatchResponse struct {
+	Replayed int `json:"replayed"`
+}
+
+// DrainDLQRequest requests every DLQ entry for Topic be replayed, oldest first. RateMillis
+// paces requeues that many milliseconds apart; omitted or zero uses defaultDrainRate.
+type DrainDLQRequest struct {
+	Topic      string `json:"topic"`
+	RateMillis int    `json:"rate_ms"`
+}
+
+// DrainDLQResponse reports how many entries a finished drain request requeued. A drain that
+// fails responds with an error body instead, without a count.
+type DrainDLQResponse struct {
+	Replayed int `json:"replayed"`
+}
+
+func toDLQEntryView(entry outbox.DLQEntry) DLQEntryView {
+	return DLQEntryView{
+		EventID:    entry.EventID,
+		TenantID:   entry.TenantID,
+		EventType:  entry.EventType,
+		Topic:      entry.Topic,
+		Reason:     entry.Reason,
+		RetryCount: entry.RetryCount,
+		CreatedAt:  entry.CreatedAt,
+	}
+}
@@ -17,11 +17,12 @@ import (
 // Handler coordinates HTTP requests with the domain service.
 type Handler struct {
 	service *domain.Service
+	cursors *persistence.CursorCodec
 }
 
 // NewHandler builds a Handler.
-func NewHandler(service *domain.Service) *Handler {
-	return &Handler{service: service}
+func NewHandler(service *domain.Service, cursors *persistence.CursorCodec) *Handler {
+	return &Handler{service: service, cursors: cursors}
 }
 
 // RegisterRoutes wires endpoints to the mux.
@@ -165,7 +166,7 @@ func (h *Handler) listActivities(w http.ResponseWriter, r *http.Request) {
 	}
 
 	cursorToken := r.URL.Query().Get("cursor")
-	cursor, err := persistence.DecodeCursor(cursorToken)
+	cursor, err := h.cursors.Decode(cursorToken)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, "validation_failed", "invalid cursor")
 		return
@@ -184,7 +185,7 @@ func (h *Handler) listActivities(w http.ResponseWriter, r *http.Request) {
 
 	resp := ListActivitiesResponse{
 		Items:      items,
-		NextCursor: persistence.EncodeCursor(next),
+		NextCursor: h.cursors.Encode(next),
 	}
 	writeJSON(w, http.StatusOK, resp)
 }
@@ -228,8 +229,28 @@ func (h *Handler) activityMetrics(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	bucket := r.URL.Query().Get("bucket")
+	if bucket == "" {
+		bucket = "day"
+	}
+	switch bucket {
+	case "hour", "day", "week":
+	default:
+		writeError(w, http.StatusBadRequest, "validation_failed", "bucket must be one of hour, day, week")
+		return
+	}
+
+	bucketTZ := r.URL.Query().Get("bucket_tz")
+	if bucketTZ == "" {
+		bucketTZ = "UTC"
+	}
+	if _, err := time.LoadLocation(bucketTZ); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_failed", "invalid bucket_tz")
+		return
+	}
+
 	window := time.Duration(windowHours) * time.Hour
-	metrics, err := h.service.GetActivityMetrics(r.Context(), claims.TenantID, userID, window, timelineLimit)
+	metrics, err := h.service.GetActivityMetrics(r.Context(), claims.TenantID, userID, window, timelineLimit, bucket, bucketTZ)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
 		return
@@ -251,12 +272,24 @@ func (h *Handler) activityMetrics(w http.ResponseWriter, r *http.Request) {
 		WindowSeconds: metrics.WindowSeconds,
 		TimelineLimit: timelineLimit,
 		Timeline:      make([]ActivityView, 0, len(metrics.Timeline)),
+		Buckets:       make([]TimelineBucket, 0, len(metrics.Buckets)),
 	}
 
 	for _, agg := range metrics.Timeline {
 		resp.Timeline = append(resp.Timeline, toActivityView(agg))
 	}
 
+	for _, b := range metrics.Buckets {
+		resp.Buckets = append(resp.Buckets, TimelineBucket{
+			BucketStart:              b.BucketStart,
+			Count:                    b.Count,
+			SyncedCount:              b.SyncedCount,
+			FailedCount:              b.FailedCount,
+			AverageDurationMinutes:   b.AverageDurationMinutes,
+			AverageProcessingSeconds: b.AverageProcessingSeconds,
+		})
+	}
+
 	writeJSON(w, http.StatusOK, resp)
 }
 
@@ -334,12 +367,25 @@ type ActivityMetricsSummary struct {
 	LastActivityAt           *time.Time `json:"last_activity_at,omitempty"`
 }
 
-// ActivityMetricsResponse merges summary metrics with recent timeline entries.
+// TimelineBucket summarizes activity counts within a single bucketed time window. Buckets with
+// no activity still appear, with their counts at zero.
+type TimelineBucket struct {
+	BucketStart              time.Time `json:"bucket_start"`
+	Count                    int       `json:"count"`
+	SyncedCount              int       `json:"synced_count"`
+	FailedCount              int       `json:"failed_count"`
+	AverageDurationMinutes   float64   `json:"average_duration_minutes"`
+	AverageProcessingSeconds float64   `json:"average_processing_seconds"`
+}
+
+// ActivityMetricsResponse merges summary metrics with recent timeline entries and time-bucketed
+// counts (daily unless the bucket parameter says otherwise; omitted when window_hours is 0).
 type ActivityMetricsResponse struct {
 	Summary       ActivityMetricsSummary `json:"summary"`
 	Timeline      []ActivityView         `json:"timeline"`
 	TimelineLimit int                    `json:"timeline_limit"`
 	WindowSeconds int64                  `json:"window_seconds"`
+	Buckets       []TimelineBucket       `json:"buckets,omitempty"`
 }
 
 func writeError(w http.ResponseWriter, status int, code, detail string) {
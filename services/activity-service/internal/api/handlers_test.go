@@ -5,15 +5,22 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
 	"example.com/activity/internal/auth"
 	"example.com/activity/internal/domain"
+	"example.com/activity/internal/persistence"
 )
 
 func TestActivityMetricsSuccess(t *testing.T) {
 	now := time.Date(2025, time.October, 27, 20, 0, 0, 0, time.UTC)
+	buckets := []domain.ActivityBucket{
+		{BucketStart: now.Add(-2 * time.Hour), Count: 1, SyncedCount: 0, FailedCount: 0, AverageDurationMinutes: 30},
+		{BucketStart: now.Add(-1 * time.Hour), Count: 0},
+		{BucketStart: now, Count: 1, SyncedCount: 1, AverageDurationMinutes: 45, AverageProcessingSeconds: 1140},
+	}
 	repo := &mockRepo{
 		summary: domain.ActivitySummary{
 			Total:                    5,
@@ -25,6 +32,7 @@ func TestActivityMetricsSuccess(t *testing.T) {
 			OldestPendingAgeSeconds:  5400,
 			LastActivityAt:           &now,
 		},
+		buckets: buckets,
 		timeline: []domain.ActivityAggregate{
 			{
 				ID:              "act-1",
@@ -56,9 +64,9 @@ func TestActivityMetricsSuccess(t *testing.T) {
 		},
 	}
 	service := domain.NewService(repo)
-	handler := NewHandler(service)
+	handler := NewHandler(service, persistence.NewCursorCodec([]byte("test-signing-key")))
 
-	req := httptest.NewRequest(http.MethodGet, "/v1/activities/metrics?user_id=user-1&timeline_limit=2&window_hours=0", nil)
+	req := httptest.NewRequest(http.MethodGet, "/v1/activities/metrics?user_id=user-1&timeline_limit=2&window_hours=48&bucket=hour", nil)
 	claims := &auth.Claims{
 		Subject:  "tester",
 		TenantID: "tenant-1",
@@ -87,8 +95,8 @@ func TestActivityMetricsSuccess(t *testing.T) {
 	if resp.Summary.SuccessRate <= 0.59 || resp.Summary.SuccessRate >= 0.61 {
 		t.Fatalf("unexpected success rate %f", resp.Summary.SuccessRate)
 	}
-	if resp.WindowSeconds != 0 {
-		t.Fatalf("expected window_seconds 0 got %d", resp.WindowSeconds)
+	if resp.WindowSeconds != int64((48 * time.Hour).Seconds()) {
+		t.Fatalf("expected window_seconds %d got %d", int64((48*time.Hour).Seconds()), resp.WindowSeconds)
 	}
 	if len(resp.Timeline) != 2 {
 		t.Fatalf("expected timeline length 2 got %d", len(resp.Timeline))
@@ -96,11 +104,24 @@ func TestActivityMetricsSuccess(t *testing.T) {
 	if resp.Timeline[0].ActivityID != "act-1" {
 		t.Fatalf("unexpected first timeline id %s", resp.Timeline[0].ActivityID)
 	}
+
+	if len(resp.Buckets) != 3 {
+		t.Fatalf("expected 3 buckets got %d", len(resp.Buckets))
+	}
+	if resp.Buckets[1].Count != 0 {
+		t.Fatalf("expected empty bucket to have count 0, got %d", resp.Buckets[1].Count)
+	}
+	if !resp.Buckets[1].BucketStart.Equal(now.Add(-1 * time.Hour)) {
+		t.Fatalf("unexpected empty bucket start %v", resp.Buckets[1].BucketStart)
+	}
+	if resp.Buckets[2].SyncedCount != 1 {
+		t.Fatalf("expected last bucket synced_count 1 got %d", resp.Buckets[2].SyncedCount)
+	}
 }
 
 func TestActivityMetricsRequiresUserID(t *testing.T) {
 	service := domain.NewService(&mockRepo{})
-	handler := NewHandler(service)
+	handler := NewHandler(service, persistence.NewCursorCodec([]byte("test-signing-key")))
 
 	req := httptest.NewRequest(http.MethodGet, "/v1/activities/metrics", nil)
 	req = req.WithContext(auth.WithClaims(req.Context(), &auth.Claims{
@@ -120,9 +141,36 @@ func TestActivityMetricsRequiresUserID(t *testing.T) {
 	}
 }
 
+func TestListActivitiesRejectsTamperedCursor(t *testing.T) {
+	codec := persistence.NewCursorCodec([]byte("test-signing-key"))
+	service := domain.NewService(&mockRepo{})
+	handler := NewHandler(service, codec)
+
+	genuine := codec.Encode(&domain.Cursor{StartedAt: time.Now().UTC(), ID: "act-1"})
+	tampered := genuine[:len(genuine)-1] + "x"
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/activities?user_id=user-1&cursor="+url.QueryEscape(tampered), nil)
+	req = req.WithContext(auth.WithClaims(req.Context(), &auth.Claims{
+		Subject:  "tester",
+		TenantID: "tenant-1",
+		Scopes: map[string]struct{}{
+			auth.ScopeActivitiesRead: {},
+		},
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.listActivities(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for tampered cursor, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
 type mockRepo struct {
 	summary  domain.ActivitySummary
 	timeline []domain.ActivityAggregate
+	buckets  []domain.ActivityBucket
 }
 
 func (m *mockRepo) FindByIdempotency(ctx context.Context, tenantID, userID, idempotencyKey string) (*domain.ActivityAggregate, error) {
@@ -149,3 +197,7 @@ func (m *mockRepo) ListByUser(ctx context.Context, tenantID, userID string, curs
 func (m *mockRepo) SummaryByUser(ctx context.Context, tenantID, userID string, window time.Duration) (domain.ActivitySummary, error) {
 	return m.summary, nil
 }
+
+func (m *mockRepo) BucketsByUser(ctx context.Context, tenantID, userID string, window time.Duration, bucket, tz string) ([]domain.ActivityBucket, error) {
+	return m.buckets, nil
+}
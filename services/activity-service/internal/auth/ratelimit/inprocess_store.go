@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// InProcessStore tracks token buckets in memory, keyed by whatever the caller passes as key
+// (a tenant ID, or a tenant+route composite). It suits single-instance dev deployments; API
+// pods running behind a load balancer should use RedisStore instead so budgets are shared.
+type InProcessStore struct {
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+	limits   map[string]Limit
+}
+
+// NewInProcessStore constructs an empty InProcessStore.
+func NewInProcessStore() *InProcessStore {
+	return &InProcessStore{
+		limiters: make(map[string]*rate.Limiter),
+		limits:   make(map[string]Limit),
+	}
+}
+
+// Allow implements Store.
+func (s *InProcessStore) Allow(ctx context.Context, key string, limit Limit) (Decision, error) {
+	limiter := s.limiterFor(key, limit)
+
+	reservation := limiter.Reserve()
+	if !reservation.OK() {
+		return Decision{Allowed: false, Limit: limit.Burst}, nil
+	}
+
+	if delay := reservation.Delay(); delay > 0 {
+		reservation.Cancel()
+		return Decision{Allowed: false, Limit: limit.Burst, RetryAfter: delay}, nil
+	}
+
+	remaining := int(limiter.Tokens())
+	if remaining < 0 {
+		remaining = 0
+	}
+	return Decision{Allowed: true, Limit: limit.Burst, Remaining: remaining}, nil
+}
+
+// limiterFor returns the limiter for key, replacing it if limit has changed since it was
+// created (e.g. a quota override refreshed from Postgres).
+func (s *InProcessStore) limiterFor(key string, limit Limit) *rate.Limiter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	limiter, ok := s.limiters[key]
+	if ok && s.limits[key] == limit {
+		return limiter
+	}
+
+	limiter = rate.NewLimiter(rate.Limit(limit.RPS), limit.Burst)
+	s.limiters[key] = limiter
+	s.limits[key] = limit
+	return limiter
+}
@@ -0,0 +1,39 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// decisionDuration and rejectedCounter are labelled by route rather than tenant: tenant IDs
+// are effectively unbounded cardinality for a Prometheus label, while the set of routes is
+// fixed and small. Per-tenant rejection counts belong in the structured log line instead.
+var (
+	decisionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "activity_service",
+		Subsystem: "ratelimit",
+		Name:      "decision_duration_seconds",
+		Help:      "Time spent evaluating a rate limit decision, grouped by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route"})
+
+	rejectedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "activity_service",
+		Subsystem: "ratelimit",
+		Name:      "rejected_total",
+		Help:      "Number of requests rejected for exceeding their tenant's rate limit, grouped by route.",
+	}, []string{"route"})
+)
+
+func init() {
+	prometheus.MustRegister(decisionDuration, rejectedCounter)
+}
+
+func recordDecisionDuration(route string, d time.Duration) {
+	decisionDuration.WithLabelValues(route).Observe(d.Seconds())
+}
+
+func recordRejected(route string) {
+	rejectedCounter.WithLabelValues(route).Inc()
+}
@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// StaticQuotaSource serves per-tenant overrides from a fixed, config-file-sourced map. It
+// suits deployments where overrides change rarely enough to ship with a redeploy.
+type StaticQuotaSource map[string]Limit
+
+// Limits implements QuotaSource.
+func (s StaticQuotaSource) Limits(ctx context.Context, tenantID string) (Limit, bool) {
+	limit, ok := s[tenantID]
+	return limit, ok
+}
+
+// PostgresQuotaSource serves per-tenant overrides from the tenant_rate_limits table,
+// refreshing its in-memory snapshot on a fixed interval so reads never block on the
+// database.
+type PostgresQuotaSource struct {
+	pool            *pgxpool.Pool
+	refreshInterval time.Duration
+
+	mu     sync.RWMutex
+	limits map[string]Limit
+}
+
+// NewPostgresQuotaSource constructs a PostgresQuotaSource. Call Start to begin the refresh
+// loop before relying on Limits returning overrides.
+func NewPostgresQuotaSource(pool *pgxpool.Pool, refreshInterval time.Duration) *PostgresQuotaSource {
+	return &PostgresQuotaSource{
+		pool:            pool,
+		refreshInterval: refreshInterval,
+		limits:          make(map[string]Limit),
+	}
+}
+
+// Start loads the current overrides and launches the background refresh loop. It should be
+// called in a goroutine.
+func (s *PostgresQuotaSource) Start(ctx context.Context) {
+	s.refresh(ctx)
+
+	ticker := time.NewTicker(s.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.refresh(ctx)
+		}
+	}
+}
+
+func (s *PostgresQuotaSource) refresh(ctx context.Context) {
+	rows, err := s.pool.Query(ctx, `SELECT tenant_id, requests_per_second, burst FROM tenant_rate_limits`)
+	if err != nil {
+		log.Printf("ratelimit: failed to refresh tenant quotas: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	limits := make(map[string]Limit)
+	for rows.Next() {
+		var tenantID string
+		var limit Limit
+		if err := rows.Scan(&tenantID, &limit.RPS, &limit.Burst); err != nil {
+			log.Printf("ratelimit: failed to scan tenant quota row: %v", err)
+			continue
+		}
+		limits[tenantID] = limit
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("ratelimit: error iterating tenant quota rows: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	s.limits = limits
+	s.mu.Unlock()
+}
+
+// Limits implements QuotaSource.
+func (s *PostgresQuotaSource) Limits(ctx context.Context, tenantID string) (Limit, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	limit, ok := s.limits[tenantID]
+	return limit, ok
+}
@@ -0,0 +1,131 @@
+// Package ratelimit enforces per-tenant request budgets on top of auth.Middleware. It is
+// composed after Middleware.Wrap so it can read the TenantID that authentication already
+// resolved, rather than re-deriving identity from the request itself.
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"example.com/activity/internal/auth"
+	"example.com/platform/libs/go/logging"
+)
+
+// Limit describes the token-bucket parameters applied to a single key.
+type Limit struct {
+	RPS   float64
+	Burst int
+}
+
+// Decision is the outcome of evaluating a single request against a Limit.
+type Decision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Store evaluates whether the request identified by key is within limit, tracking
+// consumption however it sees fit (in-process counters, a shared cache, etc).
+type Store interface {
+	Allow(ctx context.Context, key string, limit Limit) (Decision, error)
+}
+
+// QuotaSource resolves a tenant's configured Limit, falling back to Config.Default when ok
+// is false.
+type QuotaSource interface {
+	Limits(ctx context.Context, tenantID string) (limit Limit, ok bool)
+}
+
+// Skipper reports whether rate limiting should be bypassed for a request, mirroring the
+// escape hatch auth.Middleware already offers for health and metrics routes.
+type Skipper func(r *http.Request) bool
+
+// Config controls how Middleware evaluates and reports rate limit decisions.
+type Config struct {
+	// Default is applied to any tenant without a QuotaSource override.
+	Default Limit
+	// PerRoute, when true, tracks budgets per (tenant, route) instead of per tenant alone.
+	PerRoute bool
+	// Skipper, when non-nil and true for a request, bypasses rate limiting entirely.
+	Skipper Skipper
+	// Logger receives store-error and rejected-request records. A nil Logger falls back to
+	// slog.Default().
+	Logger *slog.Logger
+}
+
+// Middleware applies Config's token-bucket budget to each authenticated request.
+type Middleware struct {
+	store  Store
+	quotas QuotaSource
+	cfg    Config
+	logger *slog.Logger
+}
+
+// NewMiddleware constructs Middleware. quotas may be nil, in which case every tenant uses
+// cfg.Default.
+func NewMiddleware(store Store, quotas QuotaSource, cfg Config) Middleware {
+	return Middleware{store: store, quotas: quotas, cfg: cfg, logger: logging.OrDefault(cfg.Logger)}
+}
+
+// Wrap attaches rate limiting to an http.Handler. It must be composed inside
+// auth.Middleware.Wrap so that Claims are already present in the request context.
+func (m Middleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.cfg.Skipper != nil && m.cfg.Skipper(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		claims, ok := auth.FromContext(r.Context())
+		if !ok || claims.TenantID == "" {
+			// No tenant to budget against; defer to auth.Middleware, which already
+			// rejects unauthenticated requests upstream.
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limit := m.cfg.Default
+		if m.quotas != nil {
+			if override, ok := m.quotas.Limits(r.Context(), claims.TenantID); ok {
+				limit = override
+			}
+		}
+
+		key := claims.TenantID
+		if m.cfg.PerRoute {
+			key = claims.TenantID + ":" + r.URL.Path
+		}
+
+		start := time.Now()
+		decision, err := m.store.Allow(r.Context(), key, limit)
+		recordDecisionDuration(r.URL.Path, time.Since(start))
+		if err != nil {
+			m.logger.Error("ratelimit store error",
+				"tenant_id", claims.TenantID, "route", r.URL.Path, "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(decision.Limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+
+		if !decision.Allowed {
+			retryAfter := int(decision.RetryAfter.Seconds())
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			recordRejected(r.URL.Path)
+			m.logger.Warn("ratelimit request rejected",
+				"tenant_id", claims.TenantID, "route", r.URL.Path, "retry_after", decision.RetryAfter)
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
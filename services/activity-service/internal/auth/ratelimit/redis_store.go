@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisClient is the subset of a Redis client RedisStore needs, so tests can substitute a
+// fake without depending on a real server.
+type RedisClient interface {
+	Incr(ctx context.Context, key string) (int64, error)
+	Expire(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	Get(ctx context.Context, key string) (string, error)
+}
+
+// goRedisClient adapts *redis.Client to RedisClient.
+type goRedisClient struct {
+	client *redis.Client
+}
+
+// NewGoRedisClient wraps a go-redis client for use as a RedisClient.
+func NewGoRedisClient(addr string) RedisClient {
+	return goRedisClient{client: redis.NewClient(&redis.Options{Addr: addr})}
+}
+
+func (c goRedisClient) Incr(ctx context.Context, key string) (int64, error) {
+	return c.client.Incr(ctx, key).Result()
+}
+
+func (c goRedisClient) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return c.client.Expire(ctx, key, ttl).Result()
+}
+
+func (c goRedisClient) Get(ctx context.Context, key string) (string, error) {
+	value, err := c.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return value, err
+}
+
+// RedisStore estimates a sliding window of requests per key by combining a counter for the
+// current fixed window with a weighted contribution from the previous one, so API pods
+// scaled horizontally share one budget per tenant instead of one per pod.
+type RedisStore struct {
+	client RedisClient
+	window time.Duration
+}
+
+// NewRedisStore constructs a RedisStore that buckets requests into windows of the given
+// duration.
+func NewRedisStore(client RedisClient, window time.Duration) *RedisStore {
+	return &RedisStore{client: client, window: window}
+}
+
+// Allow implements Store.
+func (s *RedisStore) Allow(ctx context.Context, key string, limit Limit) (Decision, error) {
+	now := time.Now()
+	windowID := now.UnixNano() / int64(s.window)
+	currKey := fmt.Sprintf("ratelimit:{%s}:%d", key, windowID)
+	prevKey := fmt.Sprintf("ratelimit:{%s}:%d", key, windowID-1)
+
+	count, err := s.client.Incr(ctx, currKey)
+	if err != nil {
+		return Decision{}, fmt.Errorf("ratelimit: incrementing %s: %w", currKey, err)
+	}
+	if count == 1 {
+		if _, err := s.client.Expire(ctx, currKey, s.window*2); err != nil {
+			return Decision{}, fmt.Errorf("ratelimit: setting expiry on %s: %w", currKey, err)
+		}
+	}
+
+	var prevCount int64
+	if raw, err := s.client.Get(ctx, prevKey); err != nil {
+		return Decision{}, fmt.Errorf("ratelimit: reading %s: %w", prevKey, err)
+	} else if raw != "" {
+		prevCount, _ = strconv.ParseInt(raw, 10, 64)
+	}
+
+	elapsed := time.Duration(now.UnixNano() % int64(s.window))
+	weight := 1 - float64(elapsed)/float64(s.window)
+	estimate := float64(prevCount)*weight + float64(count)
+
+	remaining := limit.Burst - int(estimate)
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	decision := Decision{Allowed: estimate <= float64(limit.Burst), Limit: limit.Burst, Remaining: remaining}
+	if !decision.Allowed {
+		decision.RetryAfter = s.window - elapsed
+	}
+	return decision, nil
+}
@@ -4,4 +4,6 @@ package auth
 const (
 	ScopeActivitiesWrite = "activities:write"
 	ScopeActivitiesRead  = "activities:read"
+	ScopeDLQRead         = "dlq:read"
+	ScopeDLQReplay       = "dlq:replay"
 )
@@ -0,0 +1,57 @@
+package consumer
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// AdminHandler exposes operational HTTP endpoints for the consumer runtime.
+type AdminHandler struct {
+	replayer *DLQReplayer
+}
+
+// NewAdminHandler constructs an AdminHandler backed by replayer.
+func NewAdminHandler(replayer *DLQReplayer) *AdminHandler {
+	return &AdminHandler{replayer: replayer}
+}
+
+// RegisterRoutes wires admin endpoints to mux.
+func (h *AdminHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/admin/dlq/replay", h.replayDLQ)
+}
+
+// replayDLQ replays a bounded batch of a topic's dead-letter queue back onto its source topic.
+//
+//	POST /admin/dlq/replay?topic=<topic>&limit=<n>
+func (h *AdminHandler) replayDLQ(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	topic := r.URL.Query().Get("topic")
+	if topic == "" {
+		http.Error(w, "missing topic parameter", http.StatusBadRequest)
+		return
+	}
+
+	limit := 100
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			if parsed > 1000 {
+				parsed = 1000
+			}
+			limit = parsed
+		}
+	}
+
+	replayed, err := h.replayer.ReplayBatch(r.Context(), topic, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]int{"replayed": replayed})
+}
@@ -0,0 +1,112 @@
+package consumer
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// commitBatcher defers CommitMessages calls so a Processor can amortize commit round-trips
+// across many completed offsets instead of issuing one commit per partition watermark advance.
+// It flushes whichever comes first: maxMessages staged commits, or maxInterval elapsing since
+// the oldest unflushed one. A zero-value commitBatcher (maxMessages <= 1) flushes immediately,
+// preserving the processor's original per-message commit behaviour.
+type commitBatcher struct {
+	reader      Reader
+	maxMessages int
+	maxInterval time.Duration
+	logger      *log.Logger
+
+	mu      sync.Mutex
+	pending map[partitionKey]kafka.Message
+	staged  int
+	oldest  time.Time
+}
+
+// newCommitBatcher builds a commitBatcher that flushes to reader. maxMessages <= 1 disables
+// batching: stage immediately flushes every call.
+func newCommitBatcher(reader Reader, maxMessages int, maxInterval time.Duration, logger *log.Logger) *commitBatcher {
+	return &commitBatcher{
+		reader:      reader,
+		maxMessages: maxMessages,
+		maxInterval: maxInterval,
+		logger:      logger,
+		pending:     make(map[partitionKey]kafka.Message),
+	}
+}
+
+// stage records msg as the new commit watermark for its partition, flushing immediately if
+// batching is disabled or the batch has grown large enough.
+func (b *commitBatcher) stage(ctx context.Context, msg kafka.Message) error {
+	if b.maxMessages <= 1 {
+		return b.reader.CommitMessages(ctx, msg)
+	}
+
+	b.mu.Lock()
+	key := partitionKey{topic: msg.Topic, partition: msg.Partition}
+	if _, ok := b.pending[key]; !ok && len(b.pending) == 0 {
+		b.oldest = time.Now()
+	}
+	b.pending[key] = msg
+	b.staged++
+	full := b.staged >= b.maxMessages
+	b.mu.Unlock()
+
+	if full {
+		return b.flush(ctx)
+	}
+	return nil
+}
+
+// flush commits every staged watermark and clears the batch, regardless of whether the size or
+// time threshold triggered it.
+func (b *commitBatcher) flush(ctx context.Context) error {
+	b.mu.Lock()
+	if len(b.pending) == 0 {
+		b.mu.Unlock()
+		return nil
+	}
+	batch := make([]kafka.Message, 0, len(b.pending))
+	for _, msg := range b.pending {
+		batch = append(batch, msg)
+	}
+	b.pending = make(map[partitionKey]kafka.Message)
+	b.staged = 0
+	b.oldest = time.Time{}
+	b.mu.Unlock()
+
+	return b.reader.CommitMessages(ctx, batch...)
+}
+
+// run periodically flushes the batch every maxInterval until ctx is cancelled, so a commit
+// watermark isn't held back indefinitely waiting for maxMessages messages to accumulate. It does
+// a final best-effort flush against a detached context before returning.
+func (b *commitBatcher) run(ctx context.Context) {
+	if b.maxMessages <= 1 || b.maxInterval <= 0 {
+		<-ctx.Done()
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_ = b.flush(flushCtx)
+		cancel()
+		return
+	}
+
+	ticker := time.NewTicker(b.maxInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			_ = b.flush(flushCtx)
+			cancel()
+			return
+		case <-ticker.C:
+			if err := b.flush(ctx); err != nil && b.logger != nil {
+				b.logger.Printf("batched commit error: %v", err)
+			}
+		}
+	}
+}
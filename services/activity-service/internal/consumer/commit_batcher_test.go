@@ -0,0 +1,47 @@
+package consumer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitBatcherFlushesOnMaxMessages(t *testing.T) {
+	reader := &stubReader{}
+	batcher := newCommitBatcher(reader, 3, time.Hour, nil)
+
+	ctx := context.Background()
+	require.NoError(t, batcher.stage(ctx, kafka.Message{Topic: "t", Partition: 0, Offset: 1}))
+	require.NoError(t, batcher.stage(ctx, kafka.Message{Topic: "t", Partition: 0, Offset: 2}))
+	require.Equal(t, 0, reader.commitCalls, "batch of 2 should not flush under a threshold of 3")
+
+	require.NoError(t, batcher.stage(ctx, kafka.Message{Topic: "t", Partition: 1, Offset: 5}))
+	require.Equal(t, 1, reader.commitCalls, "the third staged offset should trigger a flush")
+}
+
+func TestCommitBatcherFlushesOnInterval(t *testing.T) {
+	reader := &stubReader{}
+	batcher := newCommitBatcher(reader, 100, 5*time.Millisecond, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, batcher.stage(ctx, kafka.Message{Topic: "t", Partition: 0, Offset: 1}))
+
+	go batcher.run(ctx)
+
+	require.Eventually(t, func() bool {
+		return reader.commitCalls > 0
+	}, time.Second, time.Millisecond, "the interval ticker should flush the staged offset")
+}
+
+func TestCommitBatcherDisabledCommitsImmediately(t *testing.T) {
+	reader := &stubReader{}
+	batcher := newCommitBatcher(reader, 0, 0, nil)
+
+	require.NoError(t, batcher.stage(context.Background(), kafka.Message{Topic: "t", Partition: 0, Offset: 1}))
+	require.Equal(t, 1, reader.commitCalls)
+}
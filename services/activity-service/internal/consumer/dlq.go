@@ -0,0 +1,93 @@
+package consumer
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// PermanentError marks a handler error as non-retriable: the processor routes it straight to
+// the dead-letter topic instead of spending the RetryPolicy's remaining attempts on it.
+type PermanentError struct {
+	Err error
+}
+
+// NewPermanentError wraps err so the processor treats it as non-retriable.
+func NewPermanentError(err error) error {
+	return &PermanentError{Err: err}
+}
+
+// Error implements error.
+func (e *PermanentError) Error() string { return e.Err.Error() }
+
+// Unwrap supports errors.Is/errors.As against the wrapped cause.
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// DLQMetadata describes why, and from where, a message is being routed to the dead-letter
+// topic.
+type DLQMetadata struct {
+	OriginalTopic  string
+	Partition      int
+	Offset         int64
+	ErrorClass     string
+	Attempt        int
+	FirstFailureAt time.Time
+}
+
+// DeadLetterProducer publishes messages that exhausted retries, or failed permanently, to a
+// dead-letter topic.
+type DeadLetterProducer interface {
+	PublishDLQ(ctx context.Context, msg kafka.Message, meta DLQMetadata) error
+	Close() error
+}
+
+// dlqHeaderPrefix namespaces the headers PublishDLQ attaches, so DLQReplayer can strip them
+// back out before republishing a message onto its source topic.
+const dlqHeaderPrefix = "dlq_"
+
+// kafkaDeadLetterProducer publishes failed messages to "<topic><suffix>" via a kafka.Writer.
+type kafkaDeadLetterProducer struct {
+	writer *kafka.Writer
+	suffix string
+}
+
+// NewDeadLetterProducer builds a DeadLetterProducer that writes to brokers. suffix defaults to
+// ".dlq" when empty, so a topic "activity_events" is dead-lettered to "activity_events.dlq".
+func NewDeadLetterProducer(brokers []string, suffix string) DeadLetterProducer {
+	if suffix == "" {
+		suffix = ".dlq"
+	}
+	return &kafkaDeadLetterProducer{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Balancer:               &kafka.LeastBytes{},
+			AllowAutoTopicCreation: true,
+		},
+		suffix: suffix,
+	}
+}
+
+// PublishDLQ implements DeadLetterProducer.
+func (p *kafkaDeadLetterProducer) PublishDLQ(ctx context.Context, msg kafka.Message, meta DLQMetadata) error {
+	headers := append([]kafka.Header{}, msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: dlqHeaderPrefix + "original_topic", Value: []byte(meta.OriginalTopic)},
+		kafka.Header{Key: dlqHeaderPrefix + "original_partition", Value: []byte(strconv.Itoa(meta.Partition))},
+		kafka.Header{Key: dlqHeaderPrefix + "original_offset", Value: []byte(strconv.FormatInt(meta.Offset, 10))},
+		kafka.Header{Key: dlqHeaderPrefix + "error_class", Value: []byte(meta.ErrorClass)},
+		kafka.Header{Key: dlqHeaderPrefix + "attempt", Value: []byte(strconv.Itoa(meta.Attempt))},
+		kafka.Header{Key: dlqHeaderPrefix + "first_failure_at", Value: []byte(meta.FirstFailureAt.UTC().Format(time.RFC3339Nano))},
+	)
+
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Topic:   meta.OriginalTopic + p.suffix,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	})
+}
+
+// Close releases the underlying kafka.Writer.
+func (p *kafkaDeadLetterProducer) Close() error { return p.writer.Close() }
@@ -0,0 +1,139 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// GroupTopic pairs a topic with the Handler that processes its messages, letting a single
+// ConsumerGroup fan multiple topics out under one shared set of Processor options.
+type GroupTopic struct {
+	Topic   string
+	Handler Handler
+}
+
+// ConsumerGroup runs one Processor per topic concurrently, under the same broker/security
+// config and GroupID, and reports group-wide assigned_partitions and consumer_lag_seconds
+// gauges. Partition assignment, cooperative rebalancing, and revocation are handled by the
+// underlying kafka.Reader's own consumer group protocol (each Processor's reader already joins
+// GroupID); ConsumerGroup's contribution on top of that is letting several topics share one
+// lifecycle and exposing their combined partition/lag picture. Graceful handoff on revocation
+// comes from the Processor's own shutdown behaviour: runConcurrent stops fetching and waits for
+// every in-flight worker item to finish (or for its commit watermark to be abandoned) before
+// its reader is closed, so a revoked partition's in-flight work always drains first.
+type ConsumerGroup struct {
+	readerCfg ReaderConfig
+	topics    []GroupTopic
+	opts      []Option
+	logger    *log.Logger
+
+	mu         sync.Mutex
+	processors map[string]*Processor
+}
+
+// NewConsumerGroup builds a ConsumerGroup. readerCfg's Topic field is ignored; each GroupTopic
+// supplies its own. opts are applied to every topic's Processor, so pass WithConcurrency there
+// to get per-partition worker fan-out and WithCommitBatch to batch offset commits.
+func NewConsumerGroup(readerCfg ReaderConfig, topics []GroupTopic, opts ...Option) *ConsumerGroup {
+	return &ConsumerGroup{
+		readerCfg:  readerCfg,
+		topics:     topics,
+		opts:       opts,
+		logger:     log.New(log.Writer(), "[consumer-group] ", log.LstdFlags|log.Lshortfile),
+		processors: make(map[string]*Processor),
+	}
+}
+
+// Run starts a Processor per topic and blocks until ctx is cancelled or any topic's Processor
+// returns a non-cancellation error, in which case it cancels the rest and returns that error.
+func (g *ConsumerGroup) Run(ctx context.Context) error {
+	groupCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, len(g.topics))
+
+	for _, t := range g.topics {
+		reader, err := NewReader(groupCtx, ReaderConfig{
+			Brokers:  g.readerCfg.Brokers,
+			GroupID:  g.readerCfg.GroupID,
+			Topic:    t.Topic,
+			MinBytes: g.readerCfg.MinBytes,
+			MaxBytes: g.readerCfg.MaxBytes,
+			Security: g.readerCfg.Security,
+		})
+		if err != nil {
+			cancel()
+			wg.Wait()
+			return err
+		}
+
+		processor := NewProcessor(reader, t.Handler, g.opts...)
+		g.mu.Lock()
+		g.processors[t.Topic] = processor
+		g.mu.Unlock()
+
+		wg.Add(1)
+		go func(topic string, p *Processor) {
+			defer wg.Done()
+			defer func() { _ = reader.Close() }()
+			if err := p.Run(groupCtx); err != nil && !errors.Is(err, context.Canceled) {
+				g.logger.Printf("processor error (topic=%s): %v", topic, err)
+				select {
+				case errCh <- err:
+				default:
+				}
+				cancel()
+			}
+		}(t.Topic, processor)
+	}
+
+	stopMetrics := g.reportGroupMetrics(groupCtx)
+	wg.Wait()
+	stopMetrics()
+
+	select {
+	case err := <-errCh:
+		return err
+	default:
+		return groupCtx.Err()
+	}
+}
+
+// reportGroupMetrics periodically exports assigned_partitions and consumer_lag_seconds across
+// every topic's Processor until ctx is cancelled, returning a func that blocks until the
+// reporting goroutine has stopped.
+func (g *ConsumerGroup) reportGroupMetrics(ctx context.Context) func() {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				g.mu.Lock()
+				snapshot := make(map[string]*Processor, len(g.processors))
+				for topic, p := range g.processors {
+					snapshot[topic] = p
+				}
+				g.mu.Unlock()
+
+				for topic, p := range snapshot {
+					recordAssignedPartitions(topic, p.AssignedPartitions())
+					for partition, lag := range p.PartitionLags() {
+						recordConsumerLag(topic, partition, lag)
+					}
+				}
+			}
+		}
+	}()
+	return func() { <-done }
+}
@@ -0,0 +1,95 @@
+//go:build integration
+
+package consumer
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	kafkaContainer "github.com/testcontainers/testcontainers-go/modules/kafka"
+)
+
+// countingHandler counts the messages it receives, for ConsumerGroup tests that only need to
+// know each topic's Processor is actually running, not the richer ordering guarantees exercised
+// by workerpool_integration_test.go.
+type countingHandler struct {
+	count int64
+}
+
+func (h *countingHandler) Handle(context.Context, Message) error {
+	atomic.AddInt64(&h.count, 1)
+	return nil
+}
+
+func (h *countingHandler) seen() int64 {
+	return atomic.LoadInt64(&h.count)
+}
+
+func TestConsumerGroupRunsEachTopicIndependently(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Minute)
+	defer cancel()
+
+	kafkaC, err := kafkaContainer.RunContainer(ctx, testcontainers.WithEnv(map[string]string{
+		"KAFKA_AUTO_CREATE_TOPICS_ENABLE": "true",
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = kafkaC.Terminate(context.Background()) })
+
+	brokers, err := kafkaC.Brokers(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, brokers)
+	broker := brokers[0]
+
+	topicA := "activity_events_group_a"
+	topicB := "activity_events_group_b"
+
+	conn, err := kafka.Dial("tcp", broker)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.CreateTopics(
+		kafka.TopicConfig{Topic: topicA, NumPartitions: 2, ReplicationFactor: 1},
+		kafka.TopicConfig{Topic: topicB, NumPartitions: 1, ReplicationFactor: 1},
+	))
+
+	for _, topic := range []string{topicA, topicB} {
+		writer := &kafka.Writer{Addr: kafka.TCP(broker), Topic: topic, BatchTimeout: 10 * time.Millisecond, AllowAutoTopicCreation: true}
+		var messages []kafka.Message
+		for i := 0; i < 10; i++ {
+			messages = append(messages, encodeTestMessage("tenant-1", i, `{"seq":0}`))
+		}
+		require.NoError(t, writer.WriteMessages(ctx, messages...))
+		require.NoError(t, writer.Close())
+	}
+
+	handlerA := &countingHandler{}
+	handlerB := &countingHandler{}
+
+	group := NewConsumerGroup(
+		ReaderConfig{Brokers: []string{broker}, GroupID: "group-integration", MinBytes: 1, MaxBytes: 10e6},
+		[]GroupTopic{{Topic: topicA, Handler: handlerA}, {Topic: topicB, Handler: handlerB}},
+		WithConcurrency(2),
+		WithCommitBatch(5, 50*time.Millisecond),
+	)
+
+	groupCtx, groupCancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = group.Run(groupCtx)
+	}()
+
+	require.Eventually(t, func() bool {
+		return handlerA.seen() >= 10 && handlerB.seen() >= 10
+	}, 60*time.Second, 200*time.Millisecond)
+
+	groupCancel()
+	wg.Wait()
+}
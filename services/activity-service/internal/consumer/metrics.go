@@ -1,6 +1,7 @@
 package consumer
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -34,10 +35,74 @@ var (
 		Name:      "last_message_timestamp_seconds",
 		Help:      "Unix timestamp of the most recent successfully processed message per topic.",
 	}, []string{"topic"})
+
+	retryCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "activity_service",
+		Subsystem: "consumer",
+		Name:      "retry_attempts_total",
+		Help:      "Number of handler retry attempts grouped by topic and event type.",
+	}, []string{"topic", "event_type"})
+
+	dlqCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "activity_service",
+		Subsystem: "consumer",
+		Name:      "dlq_messages_total",
+		Help:      "Number of messages routed to the dead-letter topic grouped by topic, event type and reason.",
+	}, []string{"topic", "event_type", "reason"})
+
+	permanentFailureCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "activity_service",
+		Subsystem: "consumer",
+		Name:      "permanent_failures_total",
+		Help:      "Number of handler errors classified as permanent grouped by topic and event type.",
+	}, []string{"topic", "event_type"})
+
+	// The following three gauges make up the worker pool's metrics: how deep each worker's
+	// backlog has grown, how long the oldest in-flight message per partition has been
+	// outstanding, and how many messages per partition are in flight waiting on a contiguous
+	// commit. They only report non-zero values when WithConcurrency(n > 1) is in use.
+	workerQueueDepthGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "activity_service",
+		Subsystem: "consumer",
+		Name:      "worker_queue_depth",
+		Help:      "Number of decoded messages buffered in a worker's input channel.",
+	}, []string{"worker"})
+
+	partitionInFlightGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "activity_service",
+		Subsystem: "consumer",
+		Name:      "partition_in_flight_messages",
+		Help:      "Number of messages per partition dispatched to a worker but not yet committed.",
+	}, []string{"topic", "partition"})
+
+	oldestInFlightAgeGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "activity_service",
+		Subsystem: "consumer",
+		Name:      "oldest_in_flight_age_seconds",
+		Help:      "Age of the oldest in-flight (dispatched, not yet committed) message per partition.",
+	}, []string{"topic", "partition"})
+
+	// The following two gauges are reported by ConsumerGroup across every topic's Processor:
+	// how many partitions are currently assigned to this pod, and how stale the newest message
+	// on each one is, so operators can tell a slow consumer apart from an idle topic.
+	assignedPartitionsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "activity_service",
+		Subsystem: "consumer",
+		Name:      "assigned_partitions",
+		Help:      "Number of partitions currently assigned to this consumer group member, per topic.",
+	}, []string{"topic"})
+
+	consumerLagSecondsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "activity_service",
+		Subsystem: "consumer",
+		Name:      "consumer_lag_seconds",
+		Help:      "Time since the most recently dispatched message's Kafka timestamp, per topic/partition.",
+	}, []string{"topic", "partition"})
 )
 
 func init() {
-	prometheus.MustRegister(processedCounter, handlerErrorCounter, decodeErrorCounter, lastMessageGauge)
+	prometheus.MustRegister(processedCounter, handlerErrorCounter, decodeErrorCounter, lastMessageGauge, retryCounter, dlqCounter, permanentFailureCounter,
+		workerQueueDepthGauge, partitionInFlightGauge, oldestInFlightAgeGauge, assignedPartitionsGauge, consumerLagSecondsGauge)
 }
 
 func recordProcessed(msg Message) {
@@ -55,6 +120,18 @@ func recordDecodeError(topic string) {
 	decodeErrorCounter.WithLabelValues(topic).Inc()
 }
 
+func recordRetry(msg Message) {
+	retryCounter.WithLabelValues(msg.Topic, msg.EventType).Inc()
+}
+
+func recordDLQ(msg Message, reason string) {
+	dlqCounter.WithLabelValues(msg.Topic, msg.EventType, reason).Inc()
+}
+
+func recordPermanentFailure(msg Message) {
+	permanentFailureCounter.WithLabelValues(msg.Topic, msg.EventType).Inc()
+}
+
 // RecordLag allows external callers (e.g. tests) to set the last timestamp gauge directly.
 func RecordLag(topic string, ts time.Time) {
 	if ts.IsZero() {
@@ -62,3 +139,23 @@ func RecordLag(topic string, ts time.Time) {
 	}
 	lastMessageGauge.WithLabelValues(topic).Set(float64(ts.Unix()))
 }
+
+func recordQueueDepth(worker int, depth int) {
+	workerQueueDepthGauge.WithLabelValues(strconv.Itoa(worker)).Set(float64(depth))
+}
+
+func recordPartitionLag(topic string, partition int, inFlight int) {
+	partitionInFlightGauge.WithLabelValues(topic, strconv.Itoa(partition)).Set(float64(inFlight))
+}
+
+func recordOldestInFlightAge(topic string, partition int, age time.Duration) {
+	oldestInFlightAgeGauge.WithLabelValues(topic, strconv.Itoa(partition)).Set(age.Seconds())
+}
+
+func recordAssignedPartitions(topic string, count int) {
+	assignedPartitionsGauge.WithLabelValues(topic).Set(float64(count))
+}
+
+func recordConsumerLag(topic string, partition int, lag time.Duration) {
+	consumerLagSecondsGauge.WithLabelValues(topic, strconv.Itoa(partition)).Set(lag.Seconds())
+}
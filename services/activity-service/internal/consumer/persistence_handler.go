@@ -25,10 +25,13 @@ func (h *PersistenceHandler) Handle(ctx context.Context, msg Message) error {
 	defer conn.Release()
 
 	_, err = conn.Exec(ctx,
-		`INSERT INTO activity_event_log (event_type, tenant_id, schema_id, schema_subject, topic, partition, record_offset, payload, received_at)
-         VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`,
+		`INSERT INTO activity_event_log (event_type, event_id, tenant_id, aggregate_type, aggregate_id, schema_id, schema_subject, topic, partition, record_offset, payload, received_at)
+         VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)`,
 		msg.EventType,
+		msg.EventID,
 		msg.TenantID,
+		msg.AggregateType,
+		msg.AggregateID,
 		msg.SchemaID,
 		msg.SchemaSubject,
 		msg.Topic,
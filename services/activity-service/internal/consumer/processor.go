@@ -8,6 +8,8 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/segmentio/kafka-go"
@@ -32,7 +34,10 @@ type Message struct {
 	Offset        int64
 	Timestamp     time.Time
 	EventType     string
+	EventID       int64
 	TenantID      string
+	AggregateType string
+	AggregateID   string
 	SchemaSubject string
 	SchemaID      int
 	Payload       json.RawMessage
@@ -48,19 +53,91 @@ func WithLogger(logger *log.Logger) Option {
 	}
 }
 
+// WithRetryPolicy overrides the bounded in-process retry policy applied to transient handler
+// errors before a message is routed to the dead-letter topic. Defaults to DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(p *Processor) {
+		p.retryPolicy = policy
+	}
+}
+
+// WithDeadLetterProducer configures where messages go once retries are exhausted or a handler
+// returns a PermanentError. Without one, such messages are logged and committed as before.
+func WithDeadLetterProducer(producer DeadLetterProducer) Option {
+	return func(p *Processor) {
+		p.deadLetter = producer
+	}
+}
+
+// WithConcurrency runs the Handler across n worker goroutines instead of the single,
+// sequential loop used when n <= 1. Messages are routed to a worker by PartitionRouter, so
+// the same key always lands on the same worker and per-key ordering is preserved even though
+// different keys within a partition may now be handled out of order.
+func WithConcurrency(n int) Option {
+	return func(p *Processor) {
+		p.concurrency = n
+	}
+}
+
+// WithPartitionRouter overrides how messages are assigned to worker goroutines under
+// WithConcurrency. Defaults to DefaultPartitionRouter, which hashes the Kafka message key.
+func WithPartitionRouter(router PartitionRouter) Option {
+	return func(p *Processor) {
+		p.partitionRouter = router
+	}
+}
+
+// WithQueueDepth bounds how many decoded messages may sit in a worker's input channel under
+// WithConcurrency, providing backpressure against a slow handler. Defaults to 64.
+func WithQueueDepth(n int) Option {
+	return func(p *Processor) {
+		p.queueDepth = n
+	}
+}
+
+// WithCommitBatch defers CommitMessages calls, flushing whichever comes first: maxMessages
+// staged watermarks, or maxInterval elapsing since the batch's oldest unflushed entry. This
+// trades a larger re-delivery window after a crash (up to maxMessages messages, or maxInterval
+// of them) for fewer round-trips to the broker's commit API under high throughput. The default,
+// maxMessages <= 1, commits every offset as soon as its watermark advances.
+func WithCommitBatch(maxMessages int, maxInterval time.Duration) Option {
+	return func(p *Processor) {
+		p.commitBatchSize = maxMessages
+		p.commitInterval = maxInterval
+	}
+}
+
 // Processor pulls messages from Kafka, decodes them, and dispatches to a Handler.
 type Processor struct {
-	reader  Reader
-	handler Handler
-	logger  *log.Logger
+	reader      Reader
+	handler     Handler
+	logger      *log.Logger
+	retryPolicy RetryPolicy
+	deadLetter  DeadLetterProducer
+
+	concurrency     int
+	partitionRouter PartitionRouter
+	queueDepth      int
+
+	commitBatchSize int
+	commitInterval  time.Duration
+	committer       *commitBatcher
+
+	partitionsMu sync.Mutex
+	partitions   map[partitionKey]*partitionState
 }
 
 // NewProcessor constructs a Processor with the provided reader and handler.
 func NewProcessor(reader Reader, handler Handler, opts ...Option) *Processor {
 	p := &Processor{
-		reader:  reader,
-		handler: handler,
-		logger:  log.New(log.Writer(), "[consumer] ", log.LstdFlags|log.Lshortfile),
+		reader:          reader,
+		handler:         handler,
+		logger:          log.New(log.Writer(), "[consumer] ", log.LstdFlags|log.Lshortfile),
+		retryPolicy:     DefaultRetryPolicy(),
+		concurrency:     1,
+		partitionRouter: DefaultPartitionRouter,
+		queueDepth:      64,
+		partitions:      make(map[partitionKey]*partitionState),
 	}
 	for _, opt := range opts {
 		opt(p)
@@ -68,8 +145,62 @@ func NewProcessor(reader Reader, handler Handler, opts ...Option) *Processor {
 	return p
 }
 
-// Run starts a blocking loop that processes Kafka messages until the context is cancelled.
+// Run starts a blocking loop that processes Kafka messages until the context is cancelled. With
+// the default concurrency of 1 it processes and commits messages one at a time, in fetch order.
+// With WithConcurrency(n > 1) it fans work out across n worker goroutines; see runConcurrent.
 func (p *Processor) Run(ctx context.Context) error {
+	p.committer = newCommitBatcher(p.reader, p.commitBatchSize, p.commitInterval, p.logger)
+	committerCtx, stopCommitter := context.WithCancel(context.Background())
+	committerDone := make(chan struct{})
+	go func() {
+		defer close(committerDone)
+		p.committer.run(committerCtx)
+	}()
+	defer func() {
+		stopCommitter()
+		<-committerDone
+	}()
+
+	if p.concurrency > 1 {
+		return p.runConcurrent(ctx)
+	}
+	return p.runSequential(ctx)
+}
+
+// commit stages msg's offset for commit, via the batcher configured by WithCommitBatch.
+func (p *Processor) commit(ctx context.Context, msg kafka.Message) error {
+	return p.committer.stage(ctx, msg)
+}
+
+// AssignedPartitions reports the number of distinct partitions this Processor has dispatched at
+// least one message for since it started. Only meaningful under WithConcurrency(n > 1), which is
+// the only mode that tracks partitions individually; it always reports 0 otherwise.
+func (p *Processor) AssignedPartitions() int {
+	p.partitionsMu.Lock()
+	defer p.partitionsMu.Unlock()
+	return len(p.partitions)
+}
+
+// PartitionLags reports, for every partition this Processor has dispatched a message for, how
+// long ago the most recently dispatched message was produced (its Kafka timestamp vs. now).
+func (p *Processor) PartitionLags() map[int]time.Duration {
+	p.partitionsMu.Lock()
+	snapshot := make(map[partitionKey]*partitionState, len(p.partitions))
+	for key, ps := range p.partitions {
+		snapshot[key] = ps
+	}
+	p.partitionsMu.Unlock()
+
+	lags := make(map[int]time.Duration, len(snapshot))
+	for key, ps := range snapshot {
+		lags[key.partition] = ps.lag()
+	}
+	return lags
+}
+
+// runSequential is the original single-goroutine processing loop, preserved as the default so
+// existing single-partition/low-throughput deployments see no behavioural change.
+func (p *Processor) runSequential(ctx context.Context) error {
 	for {
 		if err := ctx.Err(); err != nil {
 			return err
@@ -89,26 +220,136 @@ func (p *Processor) Run(ctx context.Context) error {
 			p.logger.Printf("decode error (topic=%s, partition=%d, offset=%d): %v", msg.Topic, msg.Partition, msg.Offset, decodeErr)
 			recordDecodeError(msg.Topic)
 			// Commit malformed messages to avoid poison-pill loops.
-			if commitErr := p.reader.CommitMessages(ctx, msg); commitErr != nil {
+			if commitErr := p.commit(ctx, msg); commitErr != nil {
 				p.logger.Printf("commit error after decode failure: %v", commitErr)
 			}
 			continue
 		}
 
-		if handleErr := p.handler.Handle(ctx, event); handleErr != nil {
-			p.logger.Printf("handler error (event_type=%s, tenant=%s): %v", event.EventType, event.TenantID, handleErr)
+		handled, lastErr, attempts, firstFailure := p.handleWithRetry(ctx, event)
+		if !handled {
+			if lastErr == nil {
+				// Context was cancelled mid-retry; leave the message uncommitted for redelivery.
+				return ctx.Err()
+			}
 			recordHandlerError(event)
-			continue
+			if !p.routeToDeadLetter(ctx, msg, event, lastErr, attempts, firstFailure) {
+				continue
+			}
 		}
 
-		if commitErr := p.reader.CommitMessages(ctx, msg); commitErr != nil {
+		if commitErr := p.commit(ctx, msg); commitErr != nil {
 			p.logger.Printf("commit error: %v", commitErr)
-		} else {
+		} else if handled {
 			recordProcessed(event)
 		}
 	}
 }
 
+// handleWithRetry calls the handler, retrying transient errors per the configured RetryPolicy.
+// It returns handled=true on success. On failure it returns the last error, the number of
+// attempts made, and the timestamp of the first failure, so the caller can route the message to
+// the dead-letter topic. A nil lastErr with handled=false means the context was cancelled.
+func (p *Processor) handleWithRetry(ctx context.Context, event Message) (handled bool, lastErr error, attempts int, firstFailure time.Time) {
+	maxAttempts := p.retryPolicy.maxAttempts()
+	for attempts = 1; attempts <= maxAttempts; attempts++ {
+		if err := ctx.Err(); err != nil {
+			return false, nil, attempts, firstFailure
+		}
+
+		handleErr := p.callHandler(ctx, event)
+		if handleErr == nil {
+			return true, nil, attempts, firstFailure
+		}
+
+		if firstFailure.IsZero() {
+			firstFailure = time.Now()
+		}
+		lastErr = handleErr
+
+		var permErr *PermanentError
+		if errors.As(handleErr, &permErr) {
+			p.logger.Printf("permanent handler error (event_type=%s, tenant=%s): %v", event.EventType, event.TenantID, handleErr)
+			recordPermanentFailure(event)
+			return false, lastErr, attempts, firstFailure
+		}
+
+		if attempts == maxAttempts {
+			p.logger.Printf("handler error (event_type=%s, tenant=%s) after %d attempts: %v", event.EventType, event.TenantID, attempts, handleErr)
+			break
+		}
+
+		p.logger.Printf("retrying handler error (event_type=%s, tenant=%s, attempt=%d): %v", event.EventType, event.TenantID, attempts, handleErr)
+		recordRetry(event)
+
+		select {
+		case <-time.After(p.retryPolicy.delay(attempts)):
+		case <-ctx.Done():
+			return false, nil, attempts, firstFailure
+		}
+	}
+	return false, lastErr, attempts, firstFailure
+}
+
+// callHandler invokes the handler, applying the retry policy's PerAttemptTimeout if set so a
+// single hung attempt can't stall the partition past that bound.
+func (p *Processor) callHandler(ctx context.Context, event Message) error {
+	timeout := p.retryPolicy.PerAttemptTimeout
+	if timeout <= 0 {
+		return p.handler.Handle(ctx, event)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return p.handler.Handle(attemptCtx, event)
+}
+
+// routeToDeadLetter publishes msg to the dead-letter topic and reports whether it is now safe to
+// commit the original offset. Without a configured DeadLetterProducer it logs and reports safe,
+// matching the processor's legacy behaviour.
+func (p *Processor) routeToDeadLetter(ctx context.Context, msg kafka.Message, event Message, lastErr error, attempts int, firstFailure time.Time) bool {
+	if p.deadLetter == nil {
+		p.logger.Printf("no dead-letter producer configured, dropping message (topic=%s, partition=%d, offset=%d): %v", msg.Topic, msg.Partition, msg.Offset, lastErr)
+		return true
+	}
+
+	meta := DLQMetadata{
+		OriginalTopic:  msg.Topic,
+		Partition:      msg.Partition,
+		Offset:         msg.Offset,
+		ErrorClass:     fmt.Sprintf("%T", errorCause(lastErr)),
+		Attempt:        attempts,
+		FirstFailureAt: firstFailure,
+	}
+	if err := p.deadLetter.PublishDLQ(ctx, msg, meta); err != nil {
+		p.logger.Printf("dlq publish error (topic=%s, partition=%d, offset=%d): %v", msg.Topic, msg.Partition, msg.Offset, err)
+		return false
+	}
+
+	recordDLQ(event, dlqReason(lastErr))
+	return true
+}
+
+// dlqReason classifies why a message reached the dead-letter topic, for the dlq_messages_total
+// reason label.
+func dlqReason(lastErr error) string {
+	var permErr *PermanentError
+	if errors.As(lastErr, &permErr) {
+		return "permanent_error"
+	}
+	return "retry_limit_exhausted"
+}
+
+// errorCause unwraps a PermanentError so callers can classify the underlying cause rather than
+// the wrapper type.
+func errorCause(err error) error {
+	var permErr *PermanentError
+	if errors.As(err, &permErr) {
+		return permErr.Err
+	}
+	return err
+}
+
 func decodeMessage(msg kafka.Message) (Message, error) {
 	if len(msg.Value) < 5 {
 		return Message{}, fmt.Errorf("invalid payload length: %d", len(msg.Value))
@@ -120,8 +361,23 @@ func decodeMessage(msg kafka.Message) (Message, error) {
 	}
 	tenantID, _ := headerValue(msg, "tenant_id")
 	schemaSubject, _ := headerValue(msg, "schema_subject")
+	aggregateType, _ := headerValue(msg, "aggregate_type")
+	aggregateID, _ := headerValue(msg, "aggregate_id")
+
+	var eventID int64
+	if raw, ok := headerValue(msg, "event_id"); ok {
+		eventID, _ = strconv.ParseInt(string(raw), 10, 64)
+	}
 
+	// schema_id is carried both in the header and the Confluent wire frame; prefer the header
+	// (set directly from the dispatcher's resolved schema ID) and fall back to the frame for
+	// messages produced before the header existed.
 	schemaID := int(binary.BigEndian.Uint32(msg.Value[1:5]))
+	if raw, ok := headerValue(msg, "schema_id"); ok {
+		if parsed, err := strconv.Atoi(string(raw)); err == nil {
+			schemaID = parsed
+		}
+	}
 	payload := json.RawMessage(append([]byte(nil), msg.Value[5:]...))
 
 	return Message{
@@ -130,7 +386,10 @@ func decodeMessage(msg kafka.Message) (Message, error) {
 		Offset:        msg.Offset,
 		Timestamp:     msg.Time,
 		EventType:     string(eventType),
+		EventID:       eventID,
 		TenantID:      string(tenantID),
+		AggregateType: string(aggregateType),
+		AggregateID:   string(aggregateID),
 		SchemaSubject: string(schemaSubject),
 		SchemaID:      schemaID,
 		Payload:       payload,
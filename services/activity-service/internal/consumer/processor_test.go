@@ -54,7 +54,7 @@ func TestProcessorCommitsOnSuccess(t *testing.T) {
 	require.JSONEq(t, string(payload), string(handler.last.Payload))
 }
 
-func TestProcessorSkipsCommitOnHandlerError(t *testing.T) {
+func TestProcessorRetriesTransientErrorThenCommitsOnSuccess(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -81,14 +81,108 @@ func TestProcessorSkipsCommitOnHandlerError(t *testing.T) {
 		messages: []kafka.Message{msg},
 		after:    contextCanceled,
 	}
-	handler := &stubHandler{err: errors.New("boom")}
+	handler := &failThenSucceedHandler{failures: 2, err: errors.New("transient boom")}
 
-	processor := NewProcessor(reader, handler, WithLogger(log.New(testWriter{t}, "", 0)))
+	processor := NewProcessor(reader, handler,
+		WithLogger(log.New(testWriter{t}, "", 0)),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 5, InitialDelay: time.Millisecond, Factor: 1}),
+	)
 
 	err := processor.Run(ctx)
 	require.ErrorIs(t, err, context.Canceled)
 
+	require.Equal(t, 3, handler.calls)
+	require.Equal(t, 1, reader.commitCalls)
+}
+
+func TestProcessorRoutesPermanentErrorToDeadLetterThenCommits(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	payload := []byte(`{"activity_id":"ghi"}`)
+	value := make([]byte, 5+len(payload))
+	value[0] = 0
+	binary.BigEndian.PutUint32(value[1:5], uint32(7))
+	copy(value[5:], payload)
+
+	msg := kafka.Message{
+		Topic:     "activity_events",
+		Partition: 0,
+		Offset:    30,
+		Time:      time.Now().UTC(),
+		Value:     value,
+		Headers: []kafka.Header{
+			{Key: "event_type", Value: []byte("activity.created")},
+			{Key: "tenant_id", Value: []byte("tenant-3")},
+			{Key: "schema_subject", Value: []byte("activity_events-value")},
+		},
+	}
+
+	reader := &stubReader{
+		messages: []kafka.Message{msg},
+		after:    contextCanceled,
+	}
+	handler := &stubHandler{err: NewPermanentError(errors.New("unrecoverable"))}
+	deadLetter := &stubDeadLetterProducer{}
+
+	processor := NewProcessor(reader, handler,
+		WithLogger(log.New(testWriter{t}, "", 0)),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 5, InitialDelay: time.Millisecond, Factor: 1}),
+		WithDeadLetterProducer(deadLetter),
+	)
+
+	err := processor.Run(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// A permanent error is never retried: exactly one Handle call before dead-lettering.
 	require.Equal(t, 1, handler.calls)
+	require.Equal(t, 1, reader.commitCalls)
+	require.Len(t, deadLetter.published, 1)
+	require.Equal(t, "activity_events", deadLetter.published[0].meta.OriginalTopic)
+	require.Equal(t, int64(30), deadLetter.published[0].meta.Offset)
+	require.Equal(t, 1, deadLetter.published[0].meta.Attempt)
+}
+
+func TestProcessorShutdownMidRetryPreservesOffset(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	payload := []byte(`{"activity_id":"jkl"}`)
+	value := make([]byte, 5+len(payload))
+	value[0] = 0
+	binary.BigEndian.PutUint32(value[1:5], uint32(1))
+	copy(value[5:], payload)
+
+	msg := kafka.Message{
+		Topic:     "activity_events",
+		Partition: 0,
+		Offset:    40,
+		Time:      time.Now().UTC(),
+		Value:     value,
+		Headers: []kafka.Header{
+			{Key: "event_type", Value: []byte("activity.created")},
+			{Key: "tenant_id", Value: []byte("tenant-4")},
+			{Key: "schema_subject", Value: []byte("activity_events-value")},
+		},
+	}
+
+	reader := &stubReader{
+		messages: []kafka.Message{msg},
+		after:    contextCanceled,
+	}
+	handler := &stubHandler{err: errors.New("still failing")}
+
+	processor := NewProcessor(reader, handler,
+		WithLogger(log.New(testWriter{t}, "", 0)),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 10, InitialDelay: time.Minute, Factor: 1}),
+	)
+
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	err := processor.Run(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	require.GreaterOrEqual(t, handler.calls, 1)
 	require.Equal(t, 0, reader.commitCalls)
 }
 
@@ -132,6 +226,37 @@ func (h *stubHandler) Handle(_ context.Context, msg Message) error {
 	return h.err
 }
 
+// failThenSucceedHandler fails the first `failures` calls with err, then succeeds.
+type failThenSucceedHandler struct {
+	calls    int
+	failures int
+	err      error
+}
+
+func (h *failThenSucceedHandler) Handle(_ context.Context, _ Message) error {
+	h.calls++
+	if h.calls <= h.failures {
+		return h.err
+	}
+	return nil
+}
+
+type publishedDLQ struct {
+	msg  kafka.Message
+	meta DLQMetadata
+}
+
+type stubDeadLetterProducer struct {
+	published []publishedDLQ
+}
+
+func (p *stubDeadLetterProducer) PublishDLQ(_ context.Context, msg kafka.Message, meta DLQMetadata) error {
+	p.published = append(p.published, publishedDLQ{msg: msg, meta: meta})
+	return nil
+}
+
+func (p *stubDeadLetterProducer) Close() error { return nil }
+
 type testWriter struct {
 	t *testing.T
 }
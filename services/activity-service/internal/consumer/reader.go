@@ -0,0 +1,48 @@
+package consumer
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"example.com/platform/libs/go/kafkasecurity"
+)
+
+// ReaderConfig captures the parameters needed to construct an authenticated kafka.Reader.
+type ReaderConfig struct {
+	Brokers  []string
+	GroupID  string
+	Topic    string
+	MinBytes int
+	MaxBytes int
+	Security kafkasecurity.Config
+}
+
+// NewReader builds a kafka.Reader authenticated according to cfg.Security. Passing the zero
+// kafkasecurity.Config preserves today's plaintext, no-auth behaviour.
+func NewReader(ctx context.Context, cfg ReaderConfig) (*kafka.Reader, error) {
+	dialer, err := cfg.Security.Dialer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	minBytes := cfg.MinBytes
+	if minBytes <= 0 {
+		minBytes = 1e3
+	}
+	maxBytes := cfg.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = 10e6
+	}
+
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:        cfg.Brokers,
+		GroupID:        cfg.GroupID,
+		Topic:          cfg.Topic,
+		MinBytes:       minBytes,
+		MaxBytes:       maxBytes,
+		CommitInterval: time.Second,
+		Dialer:         dialer,
+	}), nil
+}
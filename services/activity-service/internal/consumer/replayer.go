@@ -0,0 +1,87 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// DLQReplayer reads messages off a topic's dead-letter queue and republishes them onto the
+// original source topic, so an operator can retry a batch of failures after fixing whatever
+// caused them.
+type DLQReplayer struct {
+	brokers []string
+	suffix  string
+}
+
+// NewDLQReplayer constructs a DLQReplayer. suffix must match the one NewDeadLetterProducer was
+// configured with; it defaults to ".dlq" when empty.
+func NewDLQReplayer(brokers []string, suffix string) *DLQReplayer {
+	if suffix == "" {
+		suffix = ".dlq"
+	}
+	return &DLQReplayer{brokers: brokers, suffix: suffix}
+}
+
+// ReplayBatch reads up to limit messages from topic+suffix and republishes each onto topic,
+// stopping once limit is reached or the DLQ topic has nothing more ready within the fetch
+// deadline.
+func (r *DLQReplayer) ReplayBatch(ctx context.Context, topic string, limit int) (int, error) {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:  r.brokers,
+		Topic:    topic + r.suffix,
+		GroupID:  "dlq-replayer." + topic,
+		MinBytes: 1,
+		MaxBytes: 10e6,
+	})
+	defer reader.Close()
+
+	writer := &kafka.Writer{
+		Addr:                   kafka.TCP(r.brokers...),
+		Balancer:               &kafka.LeastBytes{},
+		AllowAutoTopicCreation: true,
+	}
+	defer writer.Close()
+
+	replayed := 0
+	for replayed < limit {
+		fetchCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		msg, err := reader.FetchMessage(fetchCtx)
+		cancel()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				break
+			}
+			return replayed, err
+		}
+
+		if err := writer.WriteMessages(ctx, kafka.Message{
+			Topic:   topic,
+			Key:     msg.Key,
+			Value:   msg.Value,
+			Headers: stripDLQHeaders(msg.Headers),
+		}); err != nil {
+			return replayed, err
+		}
+
+		if err := reader.CommitMessages(ctx, msg); err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+func stripDLQHeaders(headers []kafka.Header) []kafka.Header {
+	out := make([]kafka.Header, 0, len(headers))
+	for _, h := range headers {
+		if strings.HasPrefix(h.Key, dlqHeaderPrefix) {
+			continue
+		}
+		out = append(out, h)
+	}
+	return out
+}
@@ -0,0 +1,71 @@
+package consumer
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy configures the processor's bounded, in-process retry loop for transient handler
+// errors before a message is routed to the DeadLetterProducer.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of times Handle is called for a message, including the
+	// first attempt. A message still failing after MaxAttempts attempts is routed to the DLQ.
+	MaxAttempts int
+	// InitialDelay is the sleep before the second attempt.
+	InitialDelay time.Duration
+	// Factor multiplies the delay after each subsequent attempt.
+	Factor float64
+	// MaxDelay caps the computed delay regardless of attempt count.
+	MaxDelay time.Duration
+	// Jitter randomizes the computed delay by up to this fraction in either direction, e.g. 0.2
+	// spreads retries across +/-20% of the base delay so many poisoned messages across
+	// partitions don't retry in lockstep.
+	Jitter float64
+	// PerAttemptTimeout bounds how long a single Handle call may run before it's treated as a
+	// failed attempt, so a handler that hangs (e.g. on a stuck downstream call) doesn't stall
+	// the partition indefinitely. Zero means no per-attempt deadline beyond ctx's own.
+	PerAttemptTimeout time.Duration
+}
+
+// DefaultRetryPolicy returns a conservative policy: 5 attempts, doubling from 500ms up to 30s,
+// with 20% jitter and a 10s per-attempt timeout.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:       5,
+		InitialDelay:      500 * time.Millisecond,
+		Factor:            2,
+		MaxDelay:          30 * time.Second,
+		Jitter:            0.2,
+		PerAttemptTimeout: 10 * time.Second,
+	}
+}
+
+// maxAttempts normalizes an unset MaxAttempts to a single attempt.
+func (p RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts <= 0 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// delay returns how long to sleep before retrying the given attempt number (the attempt that
+// just failed).
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	factor := p.Factor
+	if factor <= 0 {
+		factor = 1
+	}
+
+	d := float64(p.InitialDelay) * math.Pow(factor, float64(attempt-1))
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		d *= 1 + p.Jitter*(rand.Float64()*2-1)
+	}
+	if d < 0 {
+		d = 0
+	}
+	return time.Duration(d)
+}
@@ -0,0 +1,313 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// PartitionRouter assigns a decoded message to one of concurrency worker goroutines. The same
+// key must always map to the same worker so that per-key ordering (e.g. activity events keyed
+// by user_id) is preserved even though the Processor fans work out across partitions.
+type PartitionRouter func(key []byte, concurrency int) int
+
+// DefaultPartitionRouter hashes the Kafka message key with FNV-1a and reduces it modulo
+// concurrency. An empty key (or concurrency <= 1) always routes to worker 0.
+func DefaultPartitionRouter(key []byte, concurrency int) int {
+	if concurrency <= 1 || len(key) == 0 {
+		return 0
+	}
+	h := fnv.New32a()
+	h.Write(key)
+	return int(h.Sum32() % uint32(concurrency))
+}
+
+// partitionKey identifies a Kafka topic-partition for commit tracking.
+type partitionKey struct {
+	topic     string
+	partition int
+}
+
+// partitionState tracks, per topic-partition, which offsets dispatched to workers have
+// completed, so the Processor only commits once every offset up to and including the
+// commit point has been handled, even though workers may finish out of order.
+type partitionState struct {
+	mu            sync.Mutex
+	initialized   bool
+	nextOffset    int64 // lowest offset not yet confirmed complete
+	completed     map[int64]struct{}
+	inFlightSince map[int64]time.Time
+	lastMessageAt time.Time // Kafka timestamp of the most recently dispatched message
+}
+
+func newPartitionState() *partitionState {
+	return &partitionState{
+		completed:     make(map[int64]struct{}),
+		inFlightSince: make(map[int64]time.Time),
+	}
+}
+
+// dispatch records that offset has been handed to a worker and is now in flight. msgTime is the
+// message's Kafka timestamp, used to report consumer lag; a zero value leaves the lag unchanged.
+func (ps *partitionState) dispatch(offset int64, msgTime time.Time) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if !ps.initialized {
+		ps.nextOffset = offset
+		ps.initialized = true
+	}
+	ps.inFlightSince[offset] = time.Now()
+	if !msgTime.IsZero() {
+		ps.lastMessageAt = msgTime
+	}
+}
+
+// lag returns how long ago the most recently dispatched message was produced, or 0 if no message
+// with a timestamp has been dispatched yet.
+func (ps *partitionState) lag() time.Duration {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if ps.lastMessageAt.IsZero() {
+		return 0
+	}
+	return time.Since(ps.lastMessageAt)
+}
+
+// abandon drops offset from in-flight tracking without marking it complete, used when a
+// message can't be safely committed (e.g. the dead-letter publish itself failed). The commit
+// watermark for this partition then never advances past offset, which is deliberate: it stops
+// later offsets from being committed ahead of a message that at-least-once delivery still owes
+// a retry, at the cost of stalling the partition until the stuck message is resolved.
+func (ps *partitionState) abandon(offset int64) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	delete(ps.inFlightSince, offset)
+}
+
+// complete marks offset as done and returns the new commit watermark - the highest offset such
+// that every offset up to and including it has completed - along with whether it advanced.
+func (ps *partitionState) complete(offset int64) (watermark int64, advanced bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	delete(ps.inFlightSince, offset)
+	ps.completed[offset] = struct{}{}
+
+	before := ps.nextOffset
+	for {
+		if _, ok := ps.completed[ps.nextOffset]; !ok {
+			break
+		}
+		delete(ps.completed, ps.nextOffset)
+		ps.nextOffset++
+	}
+	if ps.nextOffset == before {
+		return 0, false
+	}
+	return ps.nextOffset - 1, true
+}
+
+func (ps *partitionState) inFlightCount() int {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return len(ps.inFlightSince)
+}
+
+func (ps *partitionState) oldestInFlightAge() time.Duration {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	var oldest time.Time
+	for _, t := range ps.inFlightSince {
+		if oldest.IsZero() || t.Before(oldest) {
+			oldest = t
+		}
+	}
+	if oldest.IsZero() {
+		return 0
+	}
+	return time.Since(oldest)
+}
+
+// workItem is a decoded message in flight to a worker.
+type workItem struct {
+	msg   kafka.Message
+	event Message
+}
+
+// trackerFor returns the partitionState for (topic, partition), creating it on first use.
+func (p *Processor) trackerFor(topic string, partition int) *partitionState {
+	key := partitionKey{topic: topic, partition: partition}
+
+	p.partitionsMu.Lock()
+	defer p.partitionsMu.Unlock()
+
+	ps, ok := p.partitions[key]
+	if !ok {
+		ps = newPartitionState()
+		p.partitions[key] = ps
+	}
+	return ps
+}
+
+// runConcurrent fans work out across p.concurrency worker goroutines, hashing each message to
+// a worker by key so a given key is always handled by the same worker and therefore in order.
+// A single fetch loop reads from Kafka and feeds bounded per-worker channels, which provides
+// backpressure: once a worker's channel is full, fetching pauses until it drains. Commits are
+// issued per partition only once every offset up to a point has completed, batching the commit
+// for offsets that finish out of order. On shutdown, the fetch loop stops pulling new messages
+// and this method waits for every worker to drain its queue before returning.
+func (p *Processor) runConcurrent(ctx context.Context) error {
+	channels := make([]chan workItem, p.concurrency)
+	for i := range channels {
+		channels[i] = make(chan workItem, p.queueDepth)
+	}
+
+	var workers sync.WaitGroup
+	for i, ch := range channels {
+		workers.Add(1)
+		go p.runWorker(ctx, i, ch, &workers)
+	}
+
+	go p.reportWorkerPoolMetrics(ctx, channels)
+
+	fetchErr := p.fetchLoop(ctx, channels)
+
+	for _, ch := range channels {
+		close(ch)
+	}
+	workers.Wait()
+
+	return fetchErr
+}
+
+// fetchLoop pulls and decodes messages from Kafka, routing each to a worker channel, until ctx
+// is cancelled. Fetch errors other than context.Canceled are logged and fetching continues.
+func (p *Processor) fetchLoop(ctx context.Context, channels []chan workItem) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		msg, err := p.reader.FetchMessage(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return err
+			}
+			p.logger.Printf("fetch error: %v", err)
+			continue
+		}
+
+		event, decodeErr := decodeMessage(msg)
+		if decodeErr != nil {
+			p.logger.Printf("decode error (topic=%s, partition=%d, offset=%d): %v", msg.Topic, msg.Partition, msg.Offset, decodeErr)
+			recordDecodeError(msg.Topic)
+			p.completeAndCommit(ctx, msg.Topic, msg.Partition, msg.Offset)
+			continue
+		}
+
+		ps := p.trackerFor(msg.Topic, msg.Partition)
+		ps.dispatch(msg.Offset, msg.Time)
+
+		worker := p.partitionRouter(msg.Key, len(channels))
+		if worker < 0 || worker >= len(channels) {
+			worker = 0
+		}
+
+		select {
+		case channels[worker] <- workItem{msg: msg, event: event}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// runWorker processes work items for a single worker slot until its channel is closed.
+func (p *Processor) runWorker(ctx context.Context, id int, items <-chan workItem, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for item := range items {
+		p.processItem(ctx, item)
+	}
+}
+
+// processItem handles a single message (with retry/DLQ routing, as in the sequential path) and
+// commits its partition's watermark once the offset completes contiguously.
+func (p *Processor) processItem(ctx context.Context, item workItem) {
+	ps := p.trackerFor(item.msg.Topic, item.msg.Partition)
+
+	handled, lastErr, attempts, firstFailure := p.handleWithRetry(ctx, item.event)
+	if !handled {
+		if lastErr == nil {
+			// Context cancelled mid-retry; leave the offset uncommitted for redelivery.
+			ps.abandon(item.msg.Offset)
+			return
+		}
+		recordHandlerError(item.event)
+		if !p.routeToDeadLetter(ctx, item.msg, item.event, lastErr, attempts, firstFailure) {
+			ps.abandon(item.msg.Offset)
+			return
+		}
+	}
+
+	watermark, advanced := ps.complete(item.msg.Offset)
+	if !advanced {
+		if handled {
+			recordProcessed(item.event)
+		}
+		return
+	}
+
+	if commitErr := p.commit(ctx, kafka.Message{Topic: item.msg.Topic, Partition: item.msg.Partition, Offset: watermark}); commitErr != nil {
+		p.logger.Printf("commit error (topic=%s, partition=%d, watermark=%d): %v", item.msg.Topic, item.msg.Partition, watermark, commitErr)
+	} else if handled {
+		recordProcessed(item.event)
+	}
+}
+
+// completeAndCommit marks offset complete for a message that never reached a worker (a decode
+// failure) and commits the partition's watermark if it advanced.
+func (p *Processor) completeAndCommit(ctx context.Context, topic string, partition int, offset int64) {
+	ps := p.trackerFor(topic, partition)
+	ps.dispatch(offset, time.Time{})
+	watermark, advanced := ps.complete(offset)
+	if !advanced {
+		return
+	}
+	if commitErr := p.commit(ctx, kafka.Message{Topic: topic, Partition: partition, Offset: watermark}); commitErr != nil {
+		p.logger.Printf("commit error after decode failure (topic=%s, partition=%d, watermark=%d): %v", topic, partition, watermark, commitErr)
+	}
+}
+
+// reportWorkerPoolMetrics periodically exports queue depth and per-partition in-flight gauges
+// until ctx is cancelled.
+func (p *Processor) reportWorkerPoolMetrics(ctx context.Context, channels []chan workItem) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for i, ch := range channels {
+				recordQueueDepth(i, len(ch))
+			}
+
+			p.partitionsMu.Lock()
+			snapshot := make(map[partitionKey]*partitionState, len(p.partitions))
+			for key, ps := range p.partitions {
+				snapshot[key] = ps
+			}
+			p.partitionsMu.Unlock()
+
+			for key, ps := range snapshot {
+				recordPartitionLag(key.topic, key.partition, ps.inFlightCount())
+				recordOldestInFlightAge(key.topic, key.partition, ps.oldestInFlightAge())
+			}
+		}
+	}
+}
@@ -0,0 +1,264 @@
+//go:build integration
+
+package consumer
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	kafkaContainer "github.com/testcontainers/testcontainers-go/modules/kafka"
+)
+
+// orderTrackingHandler records, per key, the sequence of offsets it observed (to assert
+// per-key ordering across worker goroutines), and separately every message's embedded sequence
+// number (SchemaID, repurposed in these tests as a message index) regardless of partition, to
+// assert no message is skipped.
+type orderTrackingHandler struct {
+	mu   sync.Mutex
+	seen map[string][]int64
+	seqs []int
+}
+
+func newOrderTrackingHandler() *orderTrackingHandler {
+	return &orderTrackingHandler{seen: make(map[string][]int64)}
+}
+
+func (h *orderTrackingHandler) Handle(_ context.Context, msg Message) error {
+	// A small artificial delay spreads handler work across goroutines so that, without correct
+	// per-key routing, messages for the same key would race and complete out of order.
+	time.Sleep(5 * time.Millisecond)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.seen[msg.TenantID] = append(h.seen[msg.TenantID], msg.Offset)
+	h.seqs = append(h.seqs, msg.SchemaID)
+	return nil
+}
+
+func (h *orderTrackingHandler) ordersFor(key string) []int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]int64, len(h.seen[key]))
+	copy(out, h.seen[key])
+	return out
+}
+
+func (h *orderTrackingHandler) totalSeen() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	total := 0
+	for _, offsets := range h.seen {
+		total += len(offsets)
+	}
+	return total
+}
+
+func (h *orderTrackingHandler) seenSeqs() []int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]int, len(h.seqs))
+	copy(out, h.seqs)
+	return out
+}
+
+func encodeTestMessage(key string, offsetHint int, payload string) kafka.Message {
+	value := make([]byte, 5+len(payload))
+	value[0] = 0
+	binary.BigEndian.PutUint32(value[1:5], uint32(offsetHint))
+	copy(value[5:], payload)
+
+	return kafka.Message{
+		Key:   []byte(key),
+		Value: value,
+		Headers: []kafka.Header{
+			{Key: "event_type", Value: []byte("activity.created")},
+			{Key: "tenant_id", Value: []byte(key)},
+			{Key: "schema_subject", Value: []byte("activity_events-value")},
+		},
+	}
+}
+
+func TestWorkerPoolPreservesPerKeyOrderUnderParallelLoad(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Minute)
+	defer cancel()
+
+	kafkaC, err := kafkaContainer.RunContainer(ctx, testcontainers.WithEnv(map[string]string{
+		"KAFKA_AUTO_CREATE_TOPICS_ENABLE": "true",
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = kafkaC.Terminate(context.Background()) })
+
+	brokers, err := kafkaC.Brokers(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, brokers)
+	broker := brokers[0]
+
+	topic := "activity_events_worker_pool"
+
+	conn, err := kafka.Dial("tcp", broker)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.CreateTopics(kafka.TopicConfig{
+		Topic:             topic,
+		NumPartitions:     3,
+		ReplicationFactor: 1,
+	}))
+
+	writer := &kafka.Writer{
+		Addr:                   kafka.TCP(broker),
+		Topic:                  topic,
+		Balancer:               &kafka.Hash{},
+		BatchTimeout:           10 * time.Millisecond,
+		AllowAutoTopicCreation: true,
+	}
+	defer writer.Close()
+
+	const keys = 5
+	const messagesPerKey = 10
+	var messages []kafka.Message
+	for i := 0; i < messagesPerKey; i++ {
+		for k := 0; k < keys; k++ {
+			key := fmt.Sprintf("tenant-%d", k)
+			messages = append(messages, encodeTestMessage(key, i, fmt.Sprintf(`{"seq":%d}`, i)))
+		}
+	}
+	require.NoError(t, writer.WriteMessages(ctx, messages...))
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     []string{broker},
+		GroupID:     "worker-pool-integration",
+		Topic:       topic,
+		MinBytes:    1,
+		MaxBytes:    10e6,
+		StartOffset: kafka.FirstOffset,
+	})
+	defer reader.Close()
+
+	handler := newOrderTrackingHandler()
+	proc := NewProcessor(reader, handler, WithConcurrency(8), WithQueueDepth(16))
+
+	consumerCtx, consumerCancel := context.WithCancel(ctx)
+	defer consumerCancel()
+	go func() { _ = proc.Run(consumerCtx) }()
+
+	require.Eventually(t, func() bool {
+		return handler.totalSeen() >= keys*messagesPerKey
+	}, 60*time.Second, 200*time.Millisecond)
+
+	for k := 0; k < keys; k++ {
+		key := fmt.Sprintf("tenant-%d", k)
+		offsets := handler.ordersFor(key)
+		require.Len(t, offsets, messagesPerKey, "key %s", key)
+		require.True(t, sort.SliceIsSorted(offsets, func(i, j int) bool { return offsets[i] < offsets[j] }), "offsets for key %s were not handled in order: %v", key, offsets)
+	}
+}
+
+// TestWorkerPoolCommitsLeaveNoGapsAfterForcedRebalance kills the processor mid-stream (as a
+// rebalance would, revoking its partitions) and starts a fresh one against the same consumer
+// group. Because commits only advance to the highest contiguous completed offset, the second
+// processor resumes from the first one's last committed offset, so every message is handled at
+// least once and no offset is skipped.
+func TestWorkerPoolCommitsLeaveNoGapsAfterForcedRebalance(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Minute)
+	defer cancel()
+
+	kafkaC, err := kafkaContainer.RunContainer(ctx, testcontainers.WithEnv(map[string]string{
+		"KAFKA_AUTO_CREATE_TOPICS_ENABLE": "true",
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = kafkaC.Terminate(context.Background()) })
+
+	brokers, err := kafkaC.Brokers(ctx)
+	require.NoError(t, err)
+	broker := brokers[0]
+
+	topic := "activity_events_rebalance"
+	group := "worker-pool-rebalance-integration"
+
+	conn, err := kafka.Dial("tcp", broker)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.CreateTopics(kafka.TopicConfig{
+		Topic:             topic,
+		NumPartitions:     2,
+		ReplicationFactor: 1,
+	}))
+
+	writer := &kafka.Writer{
+		Addr:                   kafka.TCP(broker),
+		Topic:                  topic,
+		BatchTimeout:           10 * time.Millisecond,
+		AllowAutoTopicCreation: true,
+	}
+	defer writer.Close()
+
+	const total = 40
+	var messages []kafka.Message
+	for i := 0; i < total; i++ {
+		key := fmt.Sprintf("tenant-%d", i%4)
+		messages = append(messages, encodeTestMessage(key, i, fmt.Sprintf(`{"seq":%d}`, i)))
+	}
+	require.NoError(t, writer.WriteMessages(ctx, messages...))
+
+	newReader := func() *kafka.Reader {
+		return kafka.NewReader(kafka.ReaderConfig{
+			Brokers:     []string{broker},
+			GroupID:     group,
+			Topic:       topic,
+			MinBytes:    1,
+			MaxBytes:    10e6,
+			StartOffset: kafka.FirstOffset,
+		})
+	}
+
+	firstHandler := newOrderTrackingHandler()
+	firstReader := newReader()
+	firstProc := NewProcessor(firstReader, firstHandler, WithConcurrency(4), WithQueueDepth(8))
+
+	firstCtx, firstCancel := context.WithCancel(ctx)
+	go func() { _ = firstProc.Run(firstCtx) }()
+
+	require.Eventually(t, func() bool {
+		return firstHandler.totalSeen() > 0
+	}, 30*time.Second, 100*time.Millisecond)
+
+	// Simulate a rebalance: stop the first processor before it has necessarily finished, then
+	// close its reader to leave the consumer group.
+	firstCancel()
+	require.NoError(t, firstReader.Close())
+
+	secondHandler := newOrderTrackingHandler()
+	secondReader := newReader()
+	defer secondReader.Close()
+	secondProc := NewProcessor(secondReader, secondHandler, WithConcurrency(4), WithQueueDepth(8))
+
+	secondCtx, secondCancel := context.WithCancel(ctx)
+	defer secondCancel()
+	go func() { _ = secondProc.Run(secondCtx) }()
+
+	require.Eventually(t, func() bool {
+		return firstHandler.totalSeen()+secondHandler.totalSeen() >= total
+	}, 60*time.Second, 200*time.Millisecond)
+
+	seenSeqs := make(map[int]int)
+	for _, seq := range firstHandler.seenSeqs() {
+		seenSeqs[seq]++
+	}
+	for _, seq := range secondHandler.seenSeqs() {
+		seenSeqs[seq]++
+	}
+	require.Len(t, seenSeqs, total, "expected every message 0..%d to be seen across both processors", total-1)
+	for seq := 0; seq < total; seq++ {
+		require.GreaterOrEqual(t, seenSeqs[seq], 1, "message %d was never handled by either processor", seq)
+	}
+}
@@ -27,17 +27,54 @@ const (
 
 // ActivityAggregate is the domain object stored in Postgres and replayed to downstream stores.
 type ActivityAggregate struct {
-	ID           string
-	TenantID     string
-	UserID       string
-	ActivityType string
-	StartedAt    time.Time
-	DurationMin  int
-	Source       string
-	Version      string
-	State        ActivityState
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	ID              string
+	TenantID        string
+	UserID          string
+	ActivityType    string
+	StartedAt       time.Time
+	DurationMin     int
+	Source          string
+	Version         string
+	State           ActivityState
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	FailureReason   *string
+	NextRetryAt     *time.Time
+	QuarantinedAt   *time.Time
+	ReplayAvailable bool
+}
+
+// ActivitySummary captures aggregate activity statistics for a user within a time window.
+type ActivitySummary struct {
+	Total                    int
+	Pending                  int
+	Synced                   int
+	Failed                   int
+	AverageDurationMinutes   float64
+	AverageProcessingSeconds float64
+	OldestPendingAgeSeconds  float64
+	SuccessRate              float64
+	LastActivityAt           *time.Time
+}
+
+// ActivityBucket summarizes activity counts within a single bucketed time window, e.g. an hour
+// or a day. Buckets with no activity still appear, with their counts at zero.
+type ActivityBucket struct {
+	BucketStart              time.Time
+	Count                    int
+	SyncedCount              int
+	FailedCount              int
+	AverageDurationMinutes   float64
+	AverageProcessingSeconds float64
+}
+
+// ActivityMetrics bundles summary stats, a recent timeline, and optional time-bucketed counts
+// for a user's activities.
+type ActivityMetrics struct {
+	Summary       ActivitySummary
+	Timeline      []ActivityAggregate
+	Buckets       []ActivityBucket
+	WindowSeconds int64
 }
 
 // ActivityRepository captures persistence operations.
@@ -46,6 +83,8 @@ type ActivityRepository interface {
 	Create(ctx context.Context, aggregate ActivityAggregate, idempotencyKey string) error
 	Get(ctx context.Context, tenantID, activityID string) (*ActivityAggregate, error)
 	ListByUser(ctx context.Context, tenantID, userID string, cursor *Cursor, limit int) ([]ActivityAggregate, *Cursor, error)
+	SummaryByUser(ctx context.Context, tenantID, userID string, window time.Duration) (ActivitySummary, error)
+	BucketsByUser(ctx context.Context, tenantID, userID string, window time.Duration, bucket, tz string) ([]ActivityBucket, error)
 }
 
 // Service orchestrates activity workflows.
@@ -119,3 +158,37 @@ func (s *Service) GetActivity(ctx context.Context, tenantID, activityID string)
 func (s *Service) ListActivitiesByUser(ctx context.Context, tenantID, userID string, cursor *Cursor, limit int) ([]ActivityAggregate, *Cursor, error) {
 	return s.repo.ListByUser(ctx, tenantID, userID, cursor, limit)
 }
+
+// GetActivityMetrics assembles summary statistics and a recent timeline for a user's
+// activities within window. When bucket is non-empty, it also fetches time-bucketed counts
+// (see ActivityRepository.BucketsByUser); an unbounded window (window <= 0) has no sensible
+// bucket range, so buckets are skipped in that case.
+func (s *Service) GetActivityMetrics(ctx context.Context, tenantID, userID string, window time.Duration, timelineLimit int, bucket, bucketTZ string) (ActivityMetrics, error) {
+	summary, err := s.repo.SummaryByUser(ctx, tenantID, userID, window)
+	if err != nil {
+		return ActivityMetrics{}, err
+	}
+	if summary.Total > 0 {
+		summary.SuccessRate = float64(summary.Synced) / float64(summary.Total)
+	}
+
+	timeline, _, err := s.repo.ListByUser(ctx, tenantID, userID, nil, timelineLimit)
+	if err != nil {
+		return ActivityMetrics{}, err
+	}
+
+	var buckets []ActivityBucket
+	if bucket != "" && window > 0 {
+		buckets, err = s.repo.BucketsByUser(ctx, tenantID, userID, window, bucket, bucketTZ)
+		if err != nil {
+			return ActivityMetrics{}, err
+		}
+	}
+
+	return ActivityMetrics{
+		Summary:       summary,
+		Timeline:      timeline,
+		Buckets:       buckets,
+		WindowSeconds: int64(window / time.Second),
+	}, nil
+}
@@ -1,6 +1,7 @@
 package observability
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -19,10 +20,39 @@ var (
 		Name:      "last_activity_synced_timestamp_seconds",
 		Help:      "Unix timestamp of the most recent activity transitioned to synced.",
 	})
+
+	kafkaConsumerLagSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "activity_service",
+		Subsystem: "kafka",
+		Name:      "consumer_lag_seconds",
+		Help:      "Edge latency between a message being produced and this consumer reading it, per topic/partition.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	}, []string{"topic", "partition"})
+
+	kafkaPathwayLatencySeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "activity_service",
+		Subsystem: "kafka",
+		Name:      "pathway_latency_seconds",
+		Help:      "End-to-end latency from a pathway's origin checkpoint to this hop, per topic.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	}, []string{"topic"})
 )
 
 func init() {
-	prometheus.MustRegister(activityPersistGauge, activitySyncedGauge)
+	prometheus.MustRegister(activityPersistGauge, activitySyncedGauge, kafkaConsumerLagSeconds, kafkaPathwayLatencySeconds)
+}
+
+// DataStreamsRecorder implements datastreams.Recorder by publishing to the kafka_* histograms.
+type DataStreamsRecorder struct{}
+
+// RecordLag observes the edge latency for a topic/partition.
+func (DataStreamsRecorder) RecordLag(topic string, partition int, seconds float64) {
+	kafkaConsumerLagSeconds.WithLabelValues(topic, strconv.Itoa(partition)).Observe(seconds)
+}
+
+// RecordPathwayLatency observes the end-to-end pathway latency for a topic.
+func (DataStreamsRecorder) RecordPathwayLatency(topic string, seconds float64) {
+	kafkaPathwayLatencySeconds.WithLabelValues(topic).Observe(seconds)
 }
 
 // RecordActivityPersisted updates the persistence watermark gauge.
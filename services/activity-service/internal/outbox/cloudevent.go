@@ -0,0 +1,81 @@
+package outbox
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// cloudEvent mirrors the CloudEvents 1.0 JSON envelope persistence/postgres.Repository wraps
+// outbox payloads in. It's a local type rather than an import of that package - this relay never
+// depends on persistence/postgres, the two communicate only through the outbox table's payload
+// column.
+type cloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Time            string          `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	DataSchema      string          `json:"dataschema,omitempty"`
+	TraceParent     string          `json:"traceparent,omitempty"`
+	TenantID        string          `json:"tenantid,omitempty"`
+	UserID          string          `json:"userid,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+	DataBase64      string          `json:"data_base64,omitempty"`
+}
+
+// decodeCloudEvent unmarshals payload as a CloudEvents envelope and returns it alongside its
+// unwrapped data field (decoding data_base64 when that's the one populated), so deliver can
+// validate and encode the event body rather than the envelope around it. A payload with no
+// specversion is treated as a pre-CloudEvents row written before this envelope existed: it's
+// passed through as its own data with a zero-value envelope, rather than rejected, so rows
+// already sitting in the outbox table at deploy time still deliver.
+func decodeCloudEvent(payload json.RawMessage) (cloudEvent, []byte, error) {
+	var envelope cloudEvent
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return cloudEvent{}, nil, err
+	}
+	if envelope.SpecVersion == "" {
+		return cloudEvent{}, payload, nil
+	}
+	if envelope.SpecVersion != "1.0" {
+		return cloudEvent{}, nil, fmt.Errorf("unsupported specversion %q", envelope.SpecVersion)
+	}
+
+	if len(envelope.Data) > 0 {
+		return envelope, envelope.Data, nil
+	}
+	if envelope.DataBase64 != "" {
+		data, err := base64.StdEncoding.DecodeString(envelope.DataBase64)
+		if err != nil {
+			return cloudEvent{}, nil, fmt.Errorf("decode data_base64: %w", err)
+		}
+		return envelope, data, nil
+	}
+	return envelope, nil, nil
+}
+
+// cloudEventHeaders returns the Kafka-CloudEvents-binding headers for envelope, so a consumer
+// can read id/source/type/time without deserializing the payload.
+func cloudEventHeaders(envelope cloudEvent) []kafkaHeaderPair {
+	headers := []kafkaHeaderPair{
+		{"ce_specversion", envelope.SpecVersion},
+		{"ce_id", envelope.ID},
+		{"ce_source", envelope.Source},
+		{"ce_type", envelope.Type},
+		{"ce_time", envelope.Time},
+		{"ce_datacontenttype", envelope.DataContentType},
+	}
+	if envelope.DataSchema != "" {
+		headers = append(headers, kafkaHeaderPair{"ce_dataschema", envelope.DataSchema})
+	}
+	return headers
+}
+
+// kafkaHeaderPair is a key/value pair destined for a kafka.Header, kept independent of the
+// segmentio/kafka-go type so cloudEventHeaders stays easy to unit test without constructing one.
+type kafkaHeaderPair struct {
+	Key   string
+	Value string
+}
@@ -0,0 +1,44 @@
+package outbox
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCloudEventUnwrapsJSONData(t *testing.T) {
+	payload := []byte(`{"specversion":"1.0","id":"evt-1","type":"activity.created","data":{"foo":"bar"}}`)
+
+	envelope, data, err := decodeCloudEvent(payload)
+	require.NoError(t, err)
+	require.Equal(t, "evt-1", envelope.ID)
+	require.JSONEq(t, `{"foo":"bar"}`, string(data))
+}
+
+func TestDecodeCloudEventUnwrapsDataBase64(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte(`{"foo":"bar"}`))
+	payload, err := json.Marshal(map[string]string{"specversion": "1.0", "data_base64": encoded})
+	require.NoError(t, err)
+
+	_, data, err := decodeCloudEvent(payload)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"foo":"bar"}`, string(data))
+}
+
+func TestDecodeCloudEventPassesThroughLegacyPayloadWithNoSpecVersion(t *testing.T) {
+	payload := []byte(`{"foo":"bar"}`)
+
+	envelope, data, err := decodeCloudEvent(payload)
+	require.NoError(t, err)
+	require.Empty(t, envelope.ID)
+	require.JSONEq(t, `{"foo":"bar"}`, string(data))
+}
+
+func TestDecodeCloudEventRejectsUnsupportedSpecVersion(t *testing.T) {
+	payload := []byte(`{"specversion":"0.3"}`)
+
+	_, _, err := decodeCloudEvent(payload)
+	require.Error(t, err)
+}
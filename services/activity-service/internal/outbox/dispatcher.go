@@ -7,46 +7,169 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"math/rand"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"example.com/platform/libs/go/logging"
 )
 
 type messageWriter interface {
 	WriteMessages(context.Context, string, ...kafka.Message) error
 }
 
-type schemaRegistrar interface {
-	EnsureSchema(context.Context, string, string) (int, error)
+// TransactionalProducer is implemented by messageWriter backends that support atomic,
+// exactly-once delivery of an entire batch across multiple topics (see TransactionalWriter).
+// When the configured producer satisfies this interface, processBatch wraps the batch's writes
+// in a single Kafka transaction and only flips published_at once that transaction has committed.
+type TransactionalProducer interface {
+	messageWriter
+	BeginTransaction(ctx context.Context) error
+	CommitTransaction(ctx context.Context) error
+	AbortTransaction(ctx context.Context) error
+}
+
+// Registry resolves a schema subject to a registry-assigned schema id, registering it under
+// format if it doesn't already exist.
+type Registry interface {
+	EnsureSchema(ctx context.Context, subject, schema string, format SchemaFormat) (id int, resolvedFormat SchemaFormat, err error)
+	CheckCompatibility(ctx context.Context, subject, schema string) (bool, error)
 }
 
 // Dispatcher drains the outbox table and delivers events to Kafka using Schema Registry metadata.
 type Dispatcher struct {
 	pool             *pgxpool.Pool
 	producer         messageWriter
-	registry         schemaRegistrar
+	registry         Registry
 	dlq              *DLQWriter
 	pollInterval     time.Duration
 	batchSize        int
+	maxAttempts      int
+	baseBackoff      time.Duration
+	maxBackoff       time.Duration
 	schemaIDCache    sync.Map
 	shutdownComplete chan struct{}
+	logger           *slog.Logger
+
+	jitterMu   sync.Mutex
+	jitterRand *rand.Rand
+}
+
+// DispatcherOption configures optional Dispatcher retry behaviour.
+type DispatcherOption func(*Dispatcher)
+
+// WithMaxAttempts overrides the number of delivery attempts a row gets before it's routed to
+// the DLQ. Defaults to 5.
+func WithMaxAttempts(n int) DispatcherOption {
+	return func(d *Dispatcher) { d.maxAttempts = n }
+}
+
+// WithDispatcherLogger overrides the logger used to report batch processing and delivery
+// outcomes. Defaults to slog.Default() if never set or set to nil.
+func WithDispatcherLogger(logger *slog.Logger) DispatcherOption {
+	return func(d *Dispatcher) { d.logger = logging.OrDefault(logger) }
+}
+
+// WithBaseBackoff overrides the base delay in the exponential backoff applied between a row's
+// delivery attempts. Defaults to 30s.
+func WithBaseBackoff(base time.Duration) DispatcherOption {
+	return func(d *Dispatcher) { d.baseBackoff = base }
+}
+
+// WithMaxBackoff caps the exponential backoff delay between a row's delivery attempts.
+// Defaults to 15m.
+func WithMaxBackoff(max time.Duration) DispatcherOption {
+	return func(d *Dispatcher) { d.maxBackoff = max }
 }
 
 // NewDispatcher constructs a Dispatcher.
-func NewDispatcher(pool *pgxpool.Pool, producer messageWriter, registry schemaRegistrar, pollInterval time.Duration, batchSize int) *Dispatcher {
-	return &Dispatcher{
+func NewDispatcher(pool *pgxpool.Pool, producer messageWriter, registry Registry, pollInterval time.Duration, batchSize int, opts ...DispatcherOption) *Dispatcher {
+	d := &Dispatcher{
 		pool:             pool,
 		producer:         producer,
 		registry:         registry,
-		dlq:              NewDLQWriter(pool),
+		dlq:              NewDLQWriter(pool, nil),
 		pollInterval:     pollInterval,
 		batchSize:        batchSize,
+		maxAttempts:      5,
+		baseBackoff:      30 * time.Second,
+		maxBackoff:       15 * time.Minute,
 		shutdownComplete: make(chan struct{}),
+		logger:           slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Lag reports how many unpublished outbox rows are older than maxAge, i.e. rows that have been
+// waiting longer than a healthy dispatcher should ever let them wait. A non-zero count usually
+// means the dispatcher has stalled or Kafka is unreachable.
+func (d *Dispatcher) Lag(ctx context.Context, maxAge time.Duration) (int, error) {
+	var count int
+	err := d.pool.QueryRow(ctx, `
+		SELECT count(*) FROM outbox
+		WHERE published_at IS NULL AND created_at <= NOW() - $1::interval`,
+		maxAge).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// backoff computes the delay before a row's next delivery attempt: exponential growth from
+// baseBackoff, capped at maxBackoff, with jitter so rows that failed in the same batch don't
+// all retry at exactly the same moment.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.baseBackoff * time.Duration(1<<uint(attempt-1))
+	if delay <= 0 || delay > d.maxBackoff {
+		delay = d.maxBackoff
+	}
+
+	d.jitterMu.Lock()
+	if d.jitterRand == nil {
+		d.jitterRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	jitter := time.Duration(d.jitterRand.Int63n(int64(delay/2) + 1))
+	d.jitterMu.Unlock()
+
+	delay = delay/2 + jitter
+	if delay > d.maxBackoff {
+		delay = d.maxBackoff
+	}
+	return delay
+}
+
+// CheckCatalogCompatibility validates every schemaCatalog entry whose event type has a subject
+// in subjects against that subject's latest registered version, so a breaking schema change
+// fails dispatcher startup instead of surfacing later as per-message delivery errors. Event
+// types missing from subjects are skipped rather than treated as a failure, since callers may
+// legitimately only track subjects for the event types they own.
+func (d *Dispatcher) CheckCatalogCompatibility(ctx context.Context, subjects map[string]string) error {
+	for eventType, meta := range schemaCatalog {
+		subject, ok := subjects[eventType]
+		if !ok {
+			continue
+		}
+		compatible, err := d.registry.CheckCompatibility(ctx, subject, meta.Schema)
+		if err != nil {
+			return fmt.Errorf("check compatibility for event_type=%s subject=%s: %w", eventType, subject, err)
+		}
+		if !compatible {
+			return fmt.Errorf("%w: event_type=%s subject=%s", ErrSchemaIncompatible, eventType, subject)
+		}
 	}
+	return nil
 }
 
 // Start launches the polling loop. It should be called in a goroutine.
@@ -59,7 +182,7 @@ func (d *Dispatcher) Start(ctx context.Context) {
 
 	for {
 		if err := d.processBatch(ctx); err != nil && !errors.Is(err, context.Canceled) {
-			log.Printf("outbox dispatcher error: %v", err)
+			d.logger.Error("outbox dispatcher error", "error", err)
 		}
 
 		select {
@@ -87,17 +210,110 @@ func (d *Dispatcher) processBatch(ctx context.Context) error {
 	}
 	defer batchDuration.Observe(time.Since(start).Seconds())
 
-	if err := d.deliver(ctx, messages); err != nil {
-		log.Printf("outbox: delivery failure: %v", err)
-		failedCounter.Add(float64(len(messages)))
-		if dlqErr := d.moveToDLQ(ctx, messages, err.Error()); dlqErr != nil {
-			return dlqErr
+	batchLogger := d.logger.With("batch_id", uuid.NewString())
+	batchLogger.Debug("claimed outbox batch", "size", len(messages))
+
+	if tp, ok := d.producer.(TransactionalProducer); ok {
+		return d.processBatchTransactional(ctx, batchLogger, tp, messages)
+	}
+
+	failures, err := d.deliver(ctx, batchLogger, messages)
+	if err != nil {
+		batchLogger.Error("outbox delivery failure", "error", err)
+		failures = make(map[int64]error, len(messages))
+		for _, msg := range messages {
+			failures[msg.EventID] = err
+		}
+	}
+
+	succeeded, retrying, dlqd := classifyOutcomes(messages, failures, d.maxAttempts)
+	return d.applyOutcomes(ctx, batchLogger, succeeded, retrying, dlqd)
+}
+
+// processBatchTransactional mirrors processBatch, but wraps the batch's Kafka writes in a
+// single transaction via tp so that either all of them become visible to consumers or none do.
+// published_at is only flipped after the transaction commits: if the commit itself fails, the
+// batch's rows are left exactly as fetchAndClaim found them (neither published nor DLQ'd) so the
+// next poll picks them back up.
+func (d *Dispatcher) processBatchTransactional(ctx context.Context, logger *slog.Logger, tp TransactionalProducer, messages []Message) error {
+	if err := tp.BeginTransaction(ctx); err != nil {
+		return fmt.Errorf("begin kafka transaction: %w", err)
+	}
+
+	failures, err := d.deliver(ctx, logger, messages)
+	if err != nil {
+		_ = tp.AbortTransaction(ctx)
+		return err
+	}
+
+	succeeded, retrying, dlqd := classifyOutcomes(messages, failures, d.maxAttempts)
+
+	if len(succeeded) == 0 {
+		_ = tp.AbortTransaction(ctx)
+	} else if err := tp.CommitTransaction(ctx); err != nil {
+		_ = tp.AbortTransaction(ctx)
+		return fmt.Errorf("commit kafka transaction: %w", err)
+	}
+
+	return d.applyOutcomes(ctx, logger, succeeded, retrying, dlqd)
+}
+
+// classifyOutcomes splits a delivered batch into rows that succeeded, rows that should be
+// retried, and rows that have exhausted maxAttempts and must go to the DLQ.
+func classifyOutcomes(messages []Message, failures map[int64]error, maxAttempts int) (succeeded, retrying, dlqd []Message) {
+	for _, msg := range messages {
+		failErr, failed := failures[msg.EventID]
+		if !failed {
+			succeeded = append(succeeded, msg)
+			continue
+		}
+		msg.LastError = failErr.Error()
+		if errors.Is(failErr, ErrSchemaValidationFailed) {
+			msg.LastError = "schema_validation_failed: " + failErr.Error()
+		}
+		if msg.AttemptCount+1 >= maxAttempts {
+			dlqd = append(dlqd, msg)
+		} else {
+			retrying = append(retrying, msg)
+		}
+	}
+	return succeeded, retrying, dlqd
+}
+
+// applyOutcomes persists the result of a resolved batch: marks successes published, schedules
+// retries, and routes exhausted rows to the DLQ.
+func (d *Dispatcher) applyOutcomes(ctx context.Context, logger *slog.Logger, succeeded, retrying, dlqd []Message) error {
+	if len(succeeded) > 0 {
+		deliveredCounter.Add(float64(len(succeeded)))
+		for _, msg := range succeeded {
+			attemptsToSuccess.Observe(float64(msg.AttemptCount + 1))
+		}
+		logger.Info("marking events published", "count", len(succeeded))
+		if err := d.markPublished(ctx, succeeded); err != nil {
+			return err
+		}
+	}
+
+	if len(retrying) > 0 {
+		failedCounter.Add(float64(len(retrying)))
+		logger.Warn("scheduling events for retry", "count", len(retrying))
+		if err := d.scheduleRetry(ctx, retrying); err != nil {
+			return err
 		}
-		return d.markPublished(ctx, messages)
 	}
 
-	deliveredCounter.Add(float64(len(messages)))
-	return d.markPublished(ctx, messages)
+	if len(dlqd) > 0 {
+		failedCounter.Add(float64(len(dlqd)))
+		logger.Warn("moving events to DLQ", "count", len(dlqd))
+		if err := d.moveToDLQ(ctx, logger, dlqd); err != nil {
+			return err
+		}
+		if err := d.markPublished(ctx, dlqd); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (d *Dispatcher) fetchAndClaim(ctx context.Context) ([]Message, error) {
@@ -111,9 +327,9 @@ func (d *Dispatcher) fetchAndClaim(ctx context.Context) ([]Message, error) {
 		}
 	}()
 
-	query := `SELECT event_id, tenant_id, aggregate_type, aggregate_id, event_type, topic, schema_subject, partition_key, payload
+	query := `SELECT event_id, tenant_id, aggregate_type, aggregate_id, event_type, topic, schema_subject, partition_key, payload, attempt_count
         FROM outbox
-        WHERE published_at IS NULL
+        WHERE published_at IS NULL AND (next_attempt_at IS NULL OR next_attempt_at <= NOW())
         ORDER BY event_id
         LIMIT $1
         FOR UPDATE SKIP LOCKED`
@@ -128,7 +344,7 @@ func (d *Dispatcher) fetchAndClaim(ctx context.Context) ([]Message, error) {
 	ids := make([]int64, 0)
 	for rows.Next() {
 		var msg Message
-		if err := rows.Scan(&msg.EventID, &msg.TenantID, &msg.AggregateType, &msg.AggregateID, &msg.EventType, &msg.Topic, &msg.SchemaSubject, &msg.PartitionKey, &msg.Payload); err != nil {
+		if err := rows.Scan(&msg.EventID, &msg.TenantID, &msg.AggregateType, &msg.AggregateID, &msg.EventType, &msg.Topic, &msg.SchemaSubject, &msg.PartitionKey, &msg.Payload, &msg.AttemptCount); err != nil {
 			return nil, err
 		}
 		messages = append(messages, msg)
@@ -154,18 +370,39 @@ func (d *Dispatcher) fetchAndClaim(ctx context.Context) ([]Message, error) {
 	return messages, nil
 }
 
-func (d *Dispatcher) deliver(ctx context.Context, messages []Message) error {
+// deliver validates, encodes, and publishes messages, batched per topic. Per-message failures
+// (no schema metadata, validation, encoding) are reported in the returned map without aborting
+// the rest of the batch; a non-nil error return indicates a systemic failure (e.g. the schema
+// registry is unreachable) that applies to the whole batch.
+func (d *Dispatcher) deliver(ctx context.Context, logger *slog.Logger, messages []Message) (map[int64]error, error) {
 	type topicBatch struct {
 		schemaID int
 		messages []kafka.Message
+		eventIDs []int64
 	}
 
+	failures := make(map[int64]error)
 	batches := make(map[string]*topicBatch)
+	traceParent, traceState := traceHeaders(ctx)
 
 	for _, msg := range messages {
 		meta, ok := schemaCatalog[msg.EventType]
 		if !ok {
-			return fmt.Errorf("no schema metadata for event_type=%s", msg.EventType)
+			failures[msg.EventID] = fmt.Errorf("no schema metadata for event_type=%s", msg.EventType)
+			continue
+		}
+
+		envelope, data, err := decodeCloudEvent(msg.Payload)
+		if err != nil {
+			failures[msg.EventID] = fmt.Errorf("decode cloudevents envelope: event_type=%s: %w", msg.EventType, err)
+			continue
+		}
+
+		if err := validatorFor(meta.Format).Validate(meta.Schema, data); err != nil {
+			schemaValidationFailures.WithLabelValues(msg.EventType).Inc()
+			logger.Warn("schema validation failed", "event_id", msg.EventID, "event_type", msg.EventType, "error", err)
+			failures[msg.EventID] = fmt.Errorf("%w: event_type=%s: %v", ErrSchemaValidationFailed, msg.EventType, err)
+			continue
 		}
 
 		cacheKey := fmt.Sprintf("%s::%s", msg.SchemaSubject, meta.Schema)
@@ -173,38 +410,54 @@ func (d *Dispatcher) deliver(ctx context.Context, messages []Message) error {
 		var schemaID int
 		if found {
 			schemaID = schemaIDVal.(int)
+			schemaCacheHits.WithLabelValues(msg.SchemaSubject).Inc()
 		} else {
-			id, err := d.registry.EnsureSchema(ctx, msg.SchemaSubject, meta.Schema)
+			id, _, err := d.registry.EnsureSchema(ctx, msg.SchemaSubject, meta.Schema, meta.Format)
 			if err != nil {
-				return err
+				return nil, err
 			}
 			d.schemaIDCache.Store(cacheKey, id)
 			schemaID = id
+			schemaCacheMisses.WithLabelValues(msg.SchemaSubject).Inc()
 		}
 
-		payload := []byte(msg.Payload)
-		encoded := encodeWireFormat(schemaID, payload)
+		encodedPayload, err := encoderFor(meta.Format).Encode(data)
+		if err != nil {
+			failures[msg.EventID] = fmt.Errorf("encode event_type=%s: %w", msg.EventType, err)
+			continue
+		}
+		encoded := encodeWireFormat(schemaID, encodedPayload)
+
+		msgTraceParent := envelope.TraceParent
+		if msgTraceParent == "" {
+			msgTraceParent = traceParent
+		}
 		record := kafka.Message{
-			Key:   []byte(msg.PartitionKey),
-			Value: encoded,
-			Time:  time.Now().UTC(),
+			Key:     []byte(msg.PartitionKey),
+			Value:   encoded,
+			Time:    time.Now().UTC(),
+			Headers: recordHeaders(msg, schemaID, msgTraceParent, traceState, envelope),
 		}
 
 		batch, exists := batches[msg.Topic]
 		if !exists {
-			batches[msg.Topic] = &topicBatch{schemaID: schemaID, messages: []kafka.Message{record}}
+			batches[msg.Topic] = &topicBatch{schemaID: schemaID, messages: []kafka.Message{record}, eventIDs: []int64{msg.EventID}}
 		} else {
 			batch.messages = append(batch.messages, record)
+			batch.eventIDs = append(batch.eventIDs, msg.EventID)
 		}
 	}
 
 	for topic, batch := range batches {
 		if err := d.producer.WriteMessages(ctx, topic, batch.messages...); err != nil {
-			return err
+			logger.Error("kafka write failed", "topic", topic, "count", len(batch.messages), "error", err)
+			for _, id := range batch.eventIDs {
+				failures[id] = err
+			}
 		}
 	}
 
-	return nil
+	return failures, nil
 }
 
 func (d *Dispatcher) markPublished(ctx context.Context, messages []Message) error {
@@ -247,12 +500,67 @@ func (d *Dispatcher) markPublished(ctx context.Context, messages []Message) erro
 	return nil
 }
 
-func (d *Dispatcher) moveToDLQ(ctx context.Context, messages []Message, reason string) error {
+// scheduleRetry records a delivery failure against each row: bumps attempt_count, schedules
+// next_attempt_at using exponential backoff with jitter, and releases the claim so a future
+// poll can pick the row back up once its backoff elapses.
+func (d *Dispatcher) scheduleRetry(ctx context.Context, messages []Message) error {
+	groups := make(map[string][]Message)
+	for _, msg := range messages {
+		groups[msg.TenantID] = append(groups[msg.TenantID], msg)
+	}
+
+	for tenantID, msgs := range groups {
+		conn, err := d.pool.Acquire(ctx)
+		if err != nil {
+			return err
+		}
+
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			conn.Release()
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, "SELECT set_config('app.tenant_id', $1, true)", tenantID); err != nil {
+			tx.Rollback(ctx)
+			conn.Release()
+			return err
+		}
+
+		for _, msg := range msgs {
+			delay := d.backoff(msg.AttemptCount + 1)
+			if _, err := tx.Exec(ctx,
+				`UPDATE outbox
+                   SET attempt_count = attempt_count + 1,
+                       next_attempt_at = NOW() + $1::interval,
+                       last_error = $2,
+                       claimed_at = NULL
+                 WHERE event_id = $3`,
+				delay, msg.LastError, msg.EventID,
+			); err != nil {
+				tx.Rollback(ctx)
+				conn.Release()
+				return err
+			}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			conn.Release()
+			return err
+		}
+		conn.Release()
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) moveToDLQ(ctx context.Context, logger *slog.Logger, messages []Message) error {
 	for _, msg := range messages {
-		entryReason := fmt.Sprintf("%s (topic=%s)", reason, msg.Topic)
+		entryReason := fmt.Sprintf("%s (topic=%s, attempts=%d)", msg.LastError, msg.Topic, msg.AttemptCount+1)
 		if err := d.dlq.Write(ctx, msg, entryReason); err != nil {
 			return err
 		}
+		logger.Warn("event moved to DLQ", "event_id", msg.EventID, "topic", msg.Topic, "attempts", msg.AttemptCount+1)
 		dlqCounter.WithLabelValues(msg.Topic).Inc()
 	}
 	return nil
@@ -269,6 +577,8 @@ type Message struct {
 	SchemaSubject string
 	PartitionKey  string
 	Payload       json.RawMessage
+	AttemptCount  int
+	LastError     string
 }
 
 // encodeWireFormat applies Confluent framing for Schema Registry aware payloads.
@@ -280,16 +590,61 @@ func encodeWireFormat(schemaID int, payload []byte) []byte {
 	return frame
 }
 
-// SchemaCatalogEntry maps event type to schema definition.
+// recordHeaders attaches the metadata a consumer needs for routing and filtering without
+// deserializing the Avro/JSON payload, the envelope's CloudEvents attributes (ce_id, ce_source,
+// ce_type, ...), plus W3C trace context so a downstream handler can continue the trace that
+// produced this event. traceParent prefers the one captured in envelope at write time over the
+// dispatcher's own ambient trace context, since the relay polls independently of any request
+// span and its own context is rarely the one that produced the event.
+func recordHeaders(msg Message, schemaID int, traceParent, traceState string, envelope cloudEvent) []kafka.Header {
+	headers := []kafka.Header{
+		{Key: "event_type", Value: []byte(msg.EventType)},
+		{Key: "event_id", Value: []byte(strconv.FormatInt(msg.EventID, 10))},
+		{Key: "tenant_id", Value: []byte(msg.TenantID)},
+		{Key: "aggregate_type", Value: []byte(msg.AggregateType)},
+		{Key: "aggregate_id", Value: []byte(msg.AggregateID)},
+		{Key: "schema_id", Value: []byte(strconv.Itoa(schemaID))},
+		{Key: "schema_subject", Value: []byte(msg.SchemaSubject)},
+	}
+	for _, pair := range cloudEventHeaders(envelope) {
+		if pair.Value != "" {
+			headers = append(headers, kafka.Header{Key: pair.Key, Value: []byte(pair.Value)})
+		}
+	}
+	if traceParent != "" {
+		headers = append(headers, kafka.Header{Key: "traceparent", Value: []byte(traceParent)})
+	}
+	if traceState != "" {
+		headers = append(headers, kafka.Header{Key: "tracestate", Value: []byte(traceState)})
+	}
+	return headers
+}
+
+// traceHeaders extracts the W3C traceparent/tracestate values for the span active on ctx (if
+// any), for stamping onto every record produced by this batch.
+func traceHeaders(ctx context.Context) (traceParent, traceState string) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return "", ""
+	}
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return carrier.Get("traceparent"), carrier.Get("tracestate")
+}
+
+// SchemaCatalogEntry maps event type to schema definition. Format selects both the Validator
+// used to check payloads before publish and the Encoder used to produce the wire payload.
 type SchemaCatalogEntry struct {
 	Schema string
+	Format SchemaFormat
 }
 
 var schemaCatalog = map[string]SchemaCatalogEntry{
 	"activity.created": {
 		Schema: activityCreatedSchema,
+		Format: SchemaFormatJSON,
 	},
 	"activity.state_changed": {
-		Schema: activityStateChangedSchema,
+		Schema: activityStateChangedAvroSchema,
+		Format: SchemaFormatAvro,
 	},
 }
@@ -4,33 +4,28 @@ package outbox
 
 import (
 	"context"
-	"encoding/json"
+	"encoding/binary"
 	"errors"
-	"os"
-	"path/filepath"
-	"runtime"
-	"sort"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/segmentio/kafka-go"
 	"github.com/stretchr/testify/require"
-	postgrescontainer "github.com/testcontainers/testcontainers-go/modules/postgres"
+
+	"example.com/activity/internal/testsupport"
 )
 
 func TestDispatcherPublishesMessages(t *testing.T) {
 	ctx := context.Background()
-	pool, cleanup := setupPostgres(t, ctx)
-	defer cleanup()
+	pool, _ := testsupport.StartPostgres(ctx, t)
 
 	tenantID := uuid.NewString()
 	aggregateID := uuid.NewString()
-	require.NotZero(t, seedOutbox(t, ctx, pool, tenantID, aggregateID, "activity.created"))
+	require.NotZero(t, testsupport.SeedOutbox(ctx, t, pool, tenantID, aggregateID, "activity.created", nil))
 
 	producer := &stubProducer{}
 	registry := &stubRegistry{id: 42}
@@ -55,19 +50,81 @@ func TestDispatcherPublishesMessages(t *testing.T) {
 	require.Equal(t, 1, published)
 }
 
-func TestDispatcherRoutesMessagesToDLQOnFailure(t *testing.T) {
+func TestDispatcherAttachesRoutingHeaders(t *testing.T) {
+	ctx := context.Background()
+	pool, _ := testsupport.StartPostgres(ctx, t)
+
+	tenantID := uuid.NewString()
+	aggregateID := uuid.NewString()
+	require.NotZero(t, testsupport.SeedOutbox(ctx, t, pool, tenantID, aggregateID, "activity.created", nil))
+
+	producer := &stubProducer{}
+	registry := &stubRegistry{id: 42}
+	dispatcher := NewDispatcher(pool, producer, registry, 10*time.Millisecond, 5)
+
+	require.NoError(t, dispatcher.processBatch(ctx))
+
+	require.Len(t, producer.writes, 1)
+	require.Len(t, producer.writes[0].messages, 1)
+
+	headers := make(map[string]string)
+	for _, h := range producer.writes[0].messages[0].Headers {
+		headers[h.Key] = string(h.Value)
+	}
+	require.Equal(t, "activity.created", headers["event_type"])
+	require.Equal(t, tenantID, headers["tenant_id"])
+	require.Equal(t, aggregateID, headers["aggregate_id"])
+	require.Equal(t, "42", headers["schema_id"])
+	require.NotEmpty(t, headers["schema_subject"])
+	require.NotEmpty(t, headers["event_id"])
+}
+
+func TestDispatcherSchedulesRetryBeforeDLQOnTransientFailure(t *testing.T) {
 	ctx := context.Background()
-	pool, cleanup := setupPostgres(t, ctx)
-	defer cleanup()
+	pool, _ := testsupport.StartPostgres(ctx, t)
 
 	tenantID := uuid.NewString()
 	aggregateID := uuid.NewString()
-	require.NotZero(t, seedOutbox(t, ctx, pool, tenantID, aggregateID, "activity.state_changed"))
+	eventID := testsupport.SeedOutbox(ctx, t, pool, tenantID, aggregateID, "activity.state_changed", nil)
+	require.NotZero(t, eventID)
 
 	producer := &stubProducer{err: errors.New("kafka write failed")}
 	registry := &stubRegistry{id: 7}
 	dispatcher := NewDispatcher(pool, producer, registry, 10*time.Millisecond, 5)
 
+	require.NoError(t, dispatcher.processBatch(ctx))
+
+	var attemptCount int
+	var claimedAt, nextAttemptAt, publishedAt *time.Time
+	var lastError string
+	err := pool.QueryRow(ctx,
+		`SELECT attempt_count, claimed_at, next_attempt_at, published_at, last_error FROM outbox WHERE event_id = $1`, eventID,
+	).Scan(&attemptCount, &claimedAt, &nextAttemptAt, &publishedAt, &lastError)
+	require.NoError(t, err)
+	require.Equal(t, 1, attemptCount)
+	require.Nil(t, claimedAt, "claim should be released so a later poll can retry")
+	require.NotNil(t, nextAttemptAt)
+	require.True(t, nextAttemptAt.After(time.Now()), "next attempt should be scheduled in the future")
+	require.Nil(t, publishedAt, "row should not be marked published while retries remain")
+	require.Contains(t, lastError, "kafka write failed")
+
+	var dlqCount int
+	require.NoError(t, pool.QueryRow(ctx, `SELECT COUNT(*) FROM outbox_dlq WHERE event_id = $1`, eventID).Scan(&dlqCount))
+	require.Zero(t, dlqCount, "row should not be dead-lettered before exhausting attempts")
+}
+
+func TestDispatcherRoutesMessagesToDLQOnFailure(t *testing.T) {
+	ctx := context.Background()
+	pool, _ := testsupport.StartPostgres(ctx, t)
+
+	tenantID := uuid.NewString()
+	aggregateID := uuid.NewString()
+	require.NotZero(t, testsupport.SeedOutbox(ctx, t, pool, tenantID, aggregateID, "activity.state_changed", nil))
+
+	producer := &stubProducer{err: errors.New("kafka write failed")}
+	registry := &stubRegistry{id: 7}
+	dispatcher := NewDispatcher(pool, producer, registry, 10*time.Millisecond, 5, WithMaxAttempts(1))
+
 	beforeFailed := testutil.ToFloat64(failedCounter)
 	beforeDLQ := testutil.ToFloat64(dlqCounter.WithLabelValues("activity_events"))
 
@@ -91,12 +148,11 @@ func TestDispatcherRoutesMessagesToDLQOnFailure(t *testing.T) {
 
 func TestDispatcherCachesSchemaIDsAcrossBatch(t *testing.T) {
 	ctx := context.Background()
-	pool, cleanup := setupPostgres(t, ctx)
-	defer cleanup()
+	pool, _ := testsupport.StartPostgres(ctx, t)
 
 	tenantID := uuid.NewString()
-	require.NotZero(t, seedOutbox(t, ctx, pool, tenantID, uuid.NewString(), "activity.created"))
-	require.NotZero(t, seedOutbox(t, ctx, pool, tenantID, uuid.NewString(), "activity.created"))
+	require.NotZero(t, testsupport.SeedOutbox(ctx, t, pool, tenantID, uuid.NewString(), "activity.created", nil))
+	require.NotZero(t, testsupport.SeedOutbox(ctx, t, pool, tenantID, uuid.NewString(), "activity.created", nil))
 
 	producer := &stubProducer{}
 	registry := &stubRegistry{id: 21}
@@ -118,18 +174,49 @@ func TestDispatcherCachesSchemaIDsAcrossBatch(t *testing.T) {
 	require.Greater(t, afterHistogram, beforeHistogram)
 }
 
+func TestDispatcherFramesMixedFormatBatch(t *testing.T) {
+	ctx := context.Background()
+	pool, _ := testsupport.StartPostgres(ctx, t)
+
+	tenantID := uuid.NewString()
+	require.NotZero(t, testsupport.SeedOutbox(ctx, t, pool, tenantID, uuid.NewString(), "activity.created", nil))
+	require.NotZero(t, testsupport.SeedOutbox(ctx, t, pool, tenantID, uuid.NewString(), "activity.state_changed", nil))
+
+	producer := &stubProducer{}
+	registry := &stubRegistry{id: 55}
+	dispatcher := NewDispatcher(pool, producer, registry, 10*time.Millisecond, 5)
+
+	require.NoError(t, dispatcher.processBatch(ctx))
+
+	require.Len(t, producer.writes, 1)
+	require.Len(t, producer.writes[0].messages, 2)
+
+	for _, msg := range producer.writes[0].messages {
+		require.Greater(t, len(msg.Value), 5, "message value should carry a Confluent framing header")
+		require.Equal(t, byte(0), msg.Value[0], "framing magic byte should be 0")
+		require.Equal(t, uint32(55), binary.BigEndian.Uint32(msg.Value[1:5]), "framing header should carry the registered schema id")
+	}
+
+	require.Len(t, registry.calls, 2, "each distinct event type should register its own schema")
+	formats := make(map[SchemaFormat]bool)
+	for _, call := range registry.calls {
+		formats[call.format] = true
+	}
+	require.True(t, formats[SchemaFormatJSON], "expected a JSON-format registration")
+	require.True(t, formats[SchemaFormatAvro], "expected an AVRO-format registration")
+}
+
 func TestDispatcherUnknownSchemaMovesEventsToDLQ(t *testing.T) {
 	ctx := context.Background()
-	pool, cleanup := setupPostgres(t, ctx)
-	defer cleanup()
+	pool, _ := testsupport.StartPostgres(ctx, t)
 
 	tenantID := uuid.NewString()
-	eventID := seedOutbox(t, ctx, pool, tenantID, uuid.NewString(), "activity.unknown")
+	eventID := testsupport.SeedOutbox(ctx, t, pool, tenantID, uuid.NewString(), "activity.unknown", nil)
 	require.NotZero(t, eventID)
 
 	producer := &stubProducer{}
 	registry := &stubRegistry{id: 99}
-	dispatcher := NewDispatcher(pool, producer, registry, 10*time.Millisecond, 5)
+	dispatcher := NewDispatcher(pool, producer, registry, 10*time.Millisecond, 5, WithMaxAttempts(1))
 
 	beforeFailed := testutil.ToFloat64(failedCounter)
 	beforeDLQ := testutil.ToFloat64(dlqCounter.WithLabelValues("activity_events"))
@@ -157,6 +244,64 @@ func TestDispatcherUnknownSchemaMovesEventsToDLQ(t *testing.T) {
 	require.InDelta(t, beforeDLQ+1, afterDLQ, 0.0001)
 }
 
+func TestDispatcherLagCountsOnlyStaleUnpublishedRows(t *testing.T) {
+	ctx := context.Background()
+	pool, _ := testsupport.StartPostgres(ctx, t)
+
+	tenantID := uuid.NewString()
+	staleEventID := testsupport.SeedOutbox(ctx, t, pool, tenantID, uuid.NewString(), "activity.created", nil)
+	require.NotZero(t, testsupport.SeedOutbox(ctx, t, pool, tenantID, uuid.NewString(), "activity.created", nil))
+	_, err := pool.Exec(ctx, `UPDATE outbox SET created_at = created_at - INTERVAL '1 hour' WHERE event_id = $1`, staleEventID)
+	require.NoError(t, err)
+
+	dispatcher := NewDispatcher(pool, &stubProducer{}, &stubRegistry{id: 1}, 10*time.Millisecond, 5)
+
+	lag, err := dispatcher.Lag(ctx, 10*time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 1, lag, "only the backdated row should count as lagging")
+
+	lag, err = dispatcher.Lag(ctx, 2*time.Hour)
+	require.NoError(t, err)
+	require.Zero(t, lag, "neither row is older than 2 hours")
+}
+
+// TestDispatcherResumesAfterCrashMidBatch simulates a relay process that is killed after
+// claiming a batch (claimed_at committed) but before it could deliver or mark the row
+// published. A fresh dispatcher run should pick the row back up via the published_at IS NULL
+// scan, deliver it exactly once more, and leave exactly one outbox row behind -- at-least-once
+// delivery with no duplicate database writes.
+func TestDispatcherResumesAfterCrashMidBatch(t *testing.T) {
+	ctx := context.Background()
+	pool, _ := testsupport.StartPostgres(ctx, t)
+
+	tenantID := uuid.NewString()
+	aggregateID := uuid.NewString()
+	eventID := testsupport.SeedOutbox(ctx, t, pool, tenantID, aggregateID, "activity.created", nil)
+	require.NotZero(t, eventID)
+
+	crashed := NewDispatcher(pool, &stubProducer{}, &stubRegistry{id: 1}, 10*time.Millisecond, 5)
+	claimed, err := crashed.fetchAndClaim(ctx)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1, "crashed process should have claimed the row before dying")
+
+	var publishedBefore int
+	require.NoError(t, pool.QueryRow(ctx, `SELECT COUNT(*) FROM outbox WHERE published_at IS NOT NULL`).Scan(&publishedBefore))
+	require.Zero(t, publishedBefore, "crashed process died before publishing")
+
+	producer := &stubProducer{}
+	resumed := NewDispatcher(pool, producer, &stubRegistry{id: 1}, 10*time.Millisecond, 5)
+	require.NoError(t, resumed.processBatch(ctx))
+
+	require.Len(t, producer.writes, 1)
+	require.Len(t, producer.writes[0].messages, 1)
+
+	var rowCount, publishedCount int
+	require.NoError(t, pool.QueryRow(ctx, `SELECT COUNT(*) FROM outbox WHERE event_id = $1`, eventID).Scan(&rowCount))
+	require.Equal(t, 1, rowCount, "no duplicate outbox row should be written")
+	require.NoError(t, pool.QueryRow(ctx, `SELECT COUNT(*) FROM outbox WHERE event_id = $1 AND published_at IS NOT NULL`, eventID).Scan(&publishedCount))
+	require.Equal(t, 1, publishedCount)
+}
+
 type stubProducer struct {
 	mu     sync.Mutex
 	err    error
@@ -196,46 +341,25 @@ type stubRegistry struct {
 type schemaCall struct {
 	subject string
 	schema  string
+	format  SchemaFormat
 }
 
-func (s *stubRegistry) EnsureSchema(ctx context.Context, subject string, schema string) (int, error) {
+func (s *stubRegistry) EnsureSchema(ctx context.Context, subject, schema string, format SchemaFormat) (int, SchemaFormat, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.calls = append(s.calls, schemaCall{subject: subject, schema: schema})
+	s.calls = append(s.calls, schemaCall{subject: subject, schema: schema, format: format})
 	if s.err != nil {
-		return 0, s.err
+		return 0, format, s.err
 	}
 	if s.id == 0 {
 		s.id = 1
 	}
-	return s.id, nil
+	return s.id, format, nil
 }
 
-func setupPostgres(t *testing.T, ctx context.Context) (*pgxpool.Pool, func()) {
-	t.Helper()
-
-	pg, err := postgrescontainer.RunContainer(ctx,
-		postgrescontainer.WithDatabase("fitness"),
-		postgrescontainer.WithUsername("platform"),
-		postgrescontainer.WithPassword("platform"),
-	)
-	require.NoError(t, err)
-
-	connStr, err := pg.ConnectionString(ctx, "sslmode=disable")
-	require.NoError(t, err)
-	require.NoError(t, waitForDatabase(ctx, connStr))
-
-	runMigrations(t, ctx, connStr)
-
-	pool, err := pgxpool.New(ctx, connStr)
-	require.NoError(t, err)
-
-	cleanup := func() {
-		pool.Close()
-		_ = pg.Terminate(ctx)
-	}
-	return pool, cleanup
+func (s *stubRegistry) CheckCompatibility(ctx context.Context, subject, schema string) (bool, error) {
+	return true, nil
 }
 
 func histogramSampleCount(t *testing.T) uint64 {
@@ -248,91 +372,3 @@ func histogramSampleCount(t *testing.T) uint64 {
 	return hist.GetSampleCount()
 }
 
-func seedOutbox(t *testing.T, ctx context.Context, pool *pgxpool.Pool, tenantID, aggregateID, eventType string) int64 {
-	t.Helper()
-
-	conn, err := pool.Acquire(ctx)
-	require.NoError(t, err)
-	defer conn.Release()
-
-	tx, err := conn.Begin(ctx)
-	require.NoError(t, err)
-	defer tx.Rollback(ctx)
-
-	_, err = tx.Exec(ctx, "SELECT set_config('app.tenant_id', $1, true)", tenantID)
-	require.NoError(t, err)
-
-	payloadBytes, err := json.Marshal(map[string]any{
-		"activity_id": aggregateID,
-		"tenant_id":   tenantID,
-	})
-	require.NoError(t, err)
-
-	row := tx.QueryRow(ctx,
-		`INSERT INTO outbox (tenant_id, aggregate_type, aggregate_id, event_type, topic, schema_subject, partition_key, payload)
-         VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
-         RETURNING event_id`,
-		tenantID,
-		"activity",
-		aggregateID,
-		eventType,
-		"activity_events",
-		"activity_events-value",
-		tenantID+":"+aggregateID,
-		payloadBytes,
-	)
-
-	var eventID int64
-	require.NoError(t, row.Scan(&eventID))
-	require.NoError(t, tx.Commit(ctx))
-	return eventID
-}
-
-func runMigrations(t *testing.T, ctx context.Context, connStr string) {
-	t.Helper()
-
-	pool, err := pgxpool.New(ctx, connStr)
-	require.NoError(t, err)
-	defer pool.Close()
-
-	migrationsDir := resolvePath(t, "../../../../db/postgres/migrations")
-	files, err := filepath.Glob(filepath.Join(migrationsDir, "*.up.sql"))
-	require.NoError(t, err)
-	require.NotEmpty(t, files, "expected at least one migration .up.sql file")
-
-	sort.Strings(files)
-
-	for _, file := range files {
-		contents, readErr := os.ReadFile(file)
-		require.NoErrorf(t, readErr, "read migration %s", file)
-
-		if _, execErr := pool.Exec(ctx, string(contents)); execErr != nil {
-			require.NoErrorf(t, execErr, "execute migration %s", file)
-		}
-	}
-}
-
-func resolvePath(t *testing.T, rel string) string {
-	t.Helper()
-	_, file, _, ok := runtime.Caller(0)
-	require.True(t, ok)
-	return filepath.Join(filepath.Dir(file), rel)
-}
-
-func waitForDatabase(ctx context.Context, connStr string) error {
-	deadline := time.Now().Add(30 * time.Second)
-	for {
-		pool, err := pgxpool.New(ctx, connStr)
-		if err == nil {
-			err = pool.Ping(ctx)
-			pool.Close()
-			if err == nil {
-				return nil
-			}
-		}
-		if time.Now().After(deadline) {
-			return err
-		}
-		time.Sleep(time.Second)
-	}
-}
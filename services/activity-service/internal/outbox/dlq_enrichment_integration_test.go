@@ -4,17 +4,15 @@ package outbox
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/segmentio/kafka-go"
 	"github.com/stretchr/testify/require"
 	kafkaContainer "github.com/testcontainers/testcontainers-go/modules/kafka"
 
+	"example.com/activity/internal/testsupport"
 	testhelpers "example.com/exerciseontology/pkg/testhelpers"
 	"testing"
 )
@@ -24,8 +22,7 @@ func TestDLQReplayTriggersEnrichmentPipeline(t *testing.T) {
 	defer cancel()
 
 	// Postgres setup for activity service outbox/DLQ tables.
-	pool, cleanup := setupPostgres(t, ctx)
-	defer cleanup()
+	pool, _ := testsupport.StartPostgres(ctx, t)
 
 	tenantID := uuid.NewString()
 	accountID := uuid.NewString()
@@ -41,13 +38,13 @@ func TestDLQReplayTriggersEnrichmentPipeline(t *testing.T) {
 		"source":        "integration-test",
 		"version":       "v1",
 	}
-	insertOutboxPayload(t, ctx, pool, tenantID, activityID, payload)
+	testsupport.SeedOutbox(ctx, t, pool, tenantID, activityID, "activity.created", payload)
 
 	registry := &stubRegistry{id: 100}
 
 	// 1. Initial dispatch fails and moves the message to DLQ.
 	failingProducer := &stubProducer{err: errors.New("upstream kafka unavailable")}
-	dispatcher := NewDispatcher(pool, failingProducer, registry, 5*time.Millisecond, 10)
+	dispatcher := NewDispatcher(pool, failingProducer, registry, 5*time.Millisecond, 10, WithMaxAttempts(1))
 	require.NoError(t, dispatcher.processBatch(ctx))
 
 	var dlqCount int
@@ -119,39 +116,6 @@ func TestDLQReplayTriggersEnrichmentPipeline(t *testing.T) {
 	require.True(t, sessionExists, "expected activity session to be persisted in Dgraph")
 }
 
-func insertOutboxPayload(t *testing.T, ctx context.Context, pool *pgxpool.Pool, tenantID, aggregateID string, payload map[string]any) {
-	t.Helper()
-
-	payloadBytes, err := json.Marshal(payload)
-	require.NoError(t, err)
-
-	conn, err := pool.Acquire(ctx)
-	require.NoError(t, err)
-	defer conn.Release()
-
-	tx, err := conn.Begin(ctx)
-	require.NoError(t, err)
-	defer tx.Rollback(ctx)
-
-	_, err = tx.Exec(ctx, "SELECT set_config('app.tenant_id', $1, true)", tenantID)
-	require.NoError(t, err)
-
-	_, err = tx.Exec(ctx,
-		`INSERT INTO outbox (tenant_id, aggregate_type, aggregate_id, event_type, topic, schema_subject, partition_key, payload)
-         VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`,
-		tenantID,
-		"activity",
-		aggregateID,
-		"activity.created",
-		"activity_events",
-		"activity_events-value",
-		fmt.Sprintf("%s:%s", tenantID, aggregateID),
-		payloadBytes,
-	)
-	require.NoError(t, err)
-	require.NoError(t, tx.Commit(ctx))
-}
-
 type jsonKafkaProducer struct {
 	writer *kafka.Writer
 }
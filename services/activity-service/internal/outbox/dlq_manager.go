@@ -4,34 +4,117 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"example.com/activity/internal/alerts"
+	"example.com/platform/libs/go/logging"
 )
 
+// ManagerOption configures optional DLQManager behaviour.
+type ManagerOption func(*DLQManager)
+
+// WithRetryPolicy overrides the default exponential RetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) ManagerOption {
+	return func(m *DLQManager) {
+		m.policy = policy
+	}
+}
+
+// WithMaxRetriesByEventType overrides maxRetries for specific event types, so e.g.
+// schema-validation failures can give up quickly while transient broker errors retry
+// longer. Event types not present in the map fall back to the manager's default maxRetries.
+func WithMaxRetriesByEventType(maxRetries map[string]int) ManagerOption {
+	return func(m *DLQManager) {
+		m.maxRetriesByEventType = maxRetries
+	}
+}
+
+// WithLogger overrides the logger used to report retry and quarantine decisions. Defaults to
+// slog.Default() if never set or set to nil.
+func WithLogger(logger *slog.Logger) ManagerOption {
+	return func(m *DLQManager) {
+		m.logger = logging.OrDefault(logger)
+	}
+}
+
+// WithAlertNotifier wires an Alertmanager notifier so RunOnce can page operators directly on a
+// quarantine-rate spike, in addition to whatever scrapes the quarantine metrics. A nil or
+// no-URL-configured notifier makes WithQuarantineAlertThreshold a no-op.
+func WithAlertNotifier(notifier *alerts.Notifier) ManagerOption {
+	return func(m *DLQManager) {
+		m.alertNotifier = notifier
+	}
+}
+
+// WithQuarantineAlertThreshold fires (and later resolves) a DLQQuarantineRateHigh alert through
+// the configured alert notifier once more than threshold entries are quarantined within window.
+// A threshold of 0 (the default) disables quarantine-rate alerting entirely.
+func WithQuarantineAlertThreshold(threshold int, window time.Duration) ManagerOption {
+	return func(m *DLQManager) {
+		m.quarantineThreshold = threshold
+		m.quarantineWindow = window
+	}
+}
+
 // DLQManager handles retrying failed outbox messages and quarantining exhausted entries.
 type DLQManager struct {
-    pool       *pgxpool.Pool
-    maxRetries int
-    baseDelay  time.Duration
+	pool                  *pgxpool.Pool
+	maxRetries            int
+	baseDelay             time.Duration
+	policy                RetryPolicy
+	maxRetriesByEventType map[string]int
+	logger                *slog.Logger
+
+	alertNotifier       *alerts.Notifier
+	quarantineThreshold int
+	quarantineWindow    time.Duration
+	quarantineTracker   *quarantineRateTracker
 }
 
-// NewDLQManager constructs a DLQManager with the provided pool and retry configuration.
-func NewDLQManager(pool *pgxpool.Pool, maxRetries int, baseDelay time.Duration) *DLQManager {
+// NewDLQManager constructs a DLQManager with the provided pool and retry configuration. By
+// default it retries with ExponentialRetryPolicy; pass WithRetryPolicy to use decorrelated
+// jitter or token-bucket throttling instead.
+func NewDLQManager(pool *pgxpool.Pool, maxRetries int, baseDelay time.Duration, opts ...ManagerOption) *DLQManager {
 	if maxRetries <= 0 {
 		maxRetries = 5
 	}
 	if baseDelay <= 0 {
 		baseDelay = time.Minute
 	}
-	return &DLQManager{pool: pool, maxRetries: maxRetries, baseDelay: baseDelay}
+	m := &DLQManager{
+		pool:       pool,
+		maxRetries: maxRetries,
+		baseDelay:  baseDelay,
+		policy:     ExponentialRetryPolicy{BaseDelay: baseDelay, Cap: time.Hour},
+		logger:     slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.quarantineThreshold > 0 {
+		m.quarantineTracker = newQuarantineRateTracker(m.quarantineThreshold, m.quarantineWindow)
+	}
+	return m
+}
+
+// maxRetriesFor returns the per-event-type retry budget, falling back to the manager default.
+func (m *DLQManager) maxRetriesFor(eventType string) int {
+	if m.maxRetriesByEventType != nil {
+		if max, ok := m.maxRetriesByEventType[eventType]; ok {
+			return max
+		}
+	}
+	return m.maxRetries
 }
 
 // RunOnce processes a batch of DLQ entries and returns the count of successfully
 // re-queued messages.
 func (m *DLQManager) RunOnce(ctx context.Context, batchSize int) (int, error) {
-	const query = `SELECT dlq_id, tenant_id, event_id, event_type, topic, payload, reason, aggregate_type, aggregate_id, schema_subject, partition_key, retry_count
+	const query = `SELECT dlq_id, tenant_id, event_id, event_type, topic, payload, reason, aggregate_type, aggregate_id, schema_subject, partition_key, retry_count, COALESCE(last_delay_ms, 0)
                     FROM outbox_dlq
                    WHERE quarantined_at IS NULL AND (next_retry_at IS NULL OR next_retry_at <= NOW())
                    ORDER BY created_at
@@ -59,9 +142,22 @@ func (m *DLQManager) RunOnce(ctx context.Context, batchSize int) (int, error) {
 	if rowsErr := rows.Err(); rowsErr != nil {
 		err = errors.Join(err, rowsErr)
 	}
+	m.checkQuarantineRateResolved()
+	updateBacklogGauge(ctx, m.pool)
 	return processed, err
 }
 
+// checkQuarantineRateResolved resolves a previously fired DLQQuarantineRateHigh alert once the
+// quarantine rate has dropped back under threshold.
+func (m *DLQManager) checkQuarantineRateResolved() {
+	if m.quarantineTracker == nil || m.alertNotifier == nil {
+		return
+	}
+	if labels, resolved := m.quarantineTracker.checkResolved(time.Now()); resolved {
+		m.alertNotifier.Resolve(labels)
+	}
+}
+
 // handleEntry applies retry/quarantine logic for a single DLQ entry.
 func (m *DLQManager) handleEntry(ctx context.Context, entry dlqEntry) error {
 	conn, err := m.pool.Acquire(ctx)
@@ -80,43 +176,81 @@ func (m *DLQManager) handleEntry(ctx context.Context, entry dlqEntry) error {
 		return err
 	}
 
-	if entry.RetryCount >= m.maxRetries {
+	if entry.RetryCount >= m.maxRetriesFor(entry.EventType) {
 		if _, err := tx.Exec(ctx, `UPDATE outbox_dlq SET quarantined_at = NOW(), quarantine_reason = $1 WHERE dlq_id = $2`, "retry limit reached", entry.ID); err != nil {
 			return err
 		}
-		return tx.Commit(ctx)
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+		m.logger.Warn("dlq entry quarantined",
+			"tenant_id", entry.TenantID, "event_id", entry.EventID, "event_type", entry.EventType,
+			"topic", entry.Topic, "retry_count", entry.RetryCount)
+		recordDLQQuarantined(entry)
+		m.maybeAlertQuarantineRate(entry)
+		return nil
 	}
 
 	insertErr := requeueOutbox(ctx, tx, entry)
 	if insertErr != nil {
-        delay := m.backoffDelay(entry.RetryCount + 1)
-        if _, err := tx.Exec(ctx,
-            `UPDATE outbox_dlq
+		lastDelay := time.Duration(entry.LastDelayMS) * time.Millisecond
+		delay := m.policy.Next(entry.RetryCount+1, lastDelay)
+		if _, err := tx.Exec(ctx,
+			`UPDATE outbox_dlq
                SET retry_count = retry_count + 1,
                    last_attempt_at = NOW(),
                    next_retry_at = NOW() + $1::interval,
-                   reason = $2
-             WHERE dlq_id = $3`,
-            delay, insertErr.Error(), entry.ID,
-        ); err != nil {
-            return err
-        }
-		return tx.Commit(ctx)
+                   last_delay_ms = $2,
+                   reason = $3
+             WHERE dlq_id = $4`,
+			delay, delay.Milliseconds(), insertErr.Error(), entry.ID,
+		); err != nil {
+			return err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+		m.logger.Info("dlq entry scheduled for retry",
+			"tenant_id", entry.TenantID, "event_id", entry.EventID, "event_type", entry.EventType,
+			"topic", entry.Topic, "retry_count", entry.RetryCount+1, "next_retry_delay", delay, "reason", insertErr)
+		return nil
 	}
 
 	if _, err := tx.Exec(ctx, `DELETE FROM outbox_dlq WHERE dlq_id = $1`, entry.ID); err != nil {
 		return err
 	}
-	return tx.Commit(ctx)
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	m.logger.Info("dlq entry requeued",
+		"tenant_id", entry.TenantID, "event_id", entry.EventID, "event_type", entry.EventType,
+		"topic", entry.Topic, "retry_count", entry.RetryCount)
+	return nil
 }
 
-// backoffDelay calculates exponential backoff capped at one hour.
-func (m *DLQManager) backoffDelay(attempt int) time.Duration {
-    delay := time.Duration(1<<uint(attempt-1)) * m.baseDelay
-    if delay > time.Hour {
-        delay = time.Hour
-    }
-    return delay
+// maybeAlertQuarantineRate fires a DLQQuarantineRateHigh alert the first time the trailing
+// quarantine count crosses quarantineThreshold within quarantineWindow.
+func (m *DLQManager) maybeAlertQuarantineRate(entry dlqEntry) {
+	if m.quarantineTracker == nil || m.alertNotifier == nil {
+		return
+	}
+
+	labels := map[string]string{
+		"alertname":  "DLQQuarantineRateHigh",
+		"tenant_id":  entry.TenantID,
+		"topic":      entry.Topic,
+		"event_type": entry.EventType,
+		"severity":   "warning",
+	}
+	if m.quarantineTracker.record(time.Now(), labels) {
+		m.alertNotifier.Fire(alerts.Alert{
+			Labels: labels,
+			Annotations: map[string]string{
+				"summary":     fmt.Sprintf("more than %d DLQ entries quarantined within %s", m.quarantineThreshold, m.quarantineWindow),
+				"runbook_url": "https://runbooks.internal.example.com/activity-service/dlq-quarantine-rate",
+			},
+		})
+	}
 }
 
 // requeueOutbox reinserts the payload into the primary outbox table for replay.
@@ -155,11 +289,14 @@ type dlqEntry struct {
 	SchemaSubject string
 	PartitionKey  string
 	RetryCount    int
+	// LastDelayMS is the previous retry's delay, persisted so a manager restart resumes the
+	// decorrelated-jitter sequence rather than resetting it.
+	LastDelayMS int64
 }
 
 func scanDLQEntry(rows pgx.Rows) (dlqEntry, error) {
 	var entry dlqEntry
-	if err := rows.Scan(&entry.ID, &entry.TenantID, &entry.EventID, &entry.EventType, &entry.Topic, &entry.Payload, &entry.Reason, &entry.AggregateType, &entry.AggregateID, &entry.SchemaSubject, &entry.PartitionKey, &entry.RetryCount); err != nil {
+	if err := rows.Scan(&entry.ID, &entry.TenantID, &entry.EventID, &entry.EventType, &entry.Topic, &entry.Payload, &entry.Reason, &entry.AggregateType, &entry.AggregateID, &entry.SchemaSubject, &entry.PartitionKey, &entry.RetryCount, &entry.LastDelayMS); err != nil {
 		return dlqEntry{}, err
 	}
 	return entry, nil
@@ -42,10 +42,52 @@ var (
 		Name:      "queued_messages",
 		Help:      "Current number of entries remaining in the DLQ.",
 	})
+
+	dlqReplayedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "activity_service",
+		Subsystem: "outbox",
+		Name:      "dlq_replayed_total",
+		Help:      "Number of DLQ entries requeued into outbox by the Replayer, labeled by topic and reason category.",
+	}, []string{"topic", "reason"})
+
+	dlqReplayFailedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "activity_service",
+		Subsystem: "outbox",
+		Name:      "dlq_replay_failed_total",
+		Help:      "Number of DLQ entries the Replayer attempted to requeue into outbox but failed, labeled by topic.",
+	}, []string{"topic"})
+
+	dlqLeaderGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "activity_service",
+		Subsystem: "dlq",
+		Name:      "leader",
+		Help:      "1 if this process currently holds DLQ manager leadership, 0 otherwise.",
+	})
+
+	dlqLockContentionCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "activity_service",
+		Subsystem: "dlq",
+		Name:      "lock_contention_total",
+		Help:      "Number of times a DLQ manager replica attempted to acquire leadership and found another replica already holding it.",
+	})
 )
 
 func init() {
-	prometheus.MustRegister(dlqProcessedCounter, dlqRequeuedCounter, dlqQuarantinedCounter, dlqRetryCounter, dlqBacklogGauge)
+	prometheus.MustRegister(dlqProcessedCounter, dlqRequeuedCounter, dlqQuarantinedCounter, dlqRetryCounter, dlqBacklogGauge,
+		dlqReplayedCounter, dlqReplayFailedCounter, dlqLeaderGauge, dlqLockContentionCounter)
+}
+
+// recordLeadership reports this process's current DLQ manager leadership state.
+func recordLeadership(leading bool) {
+	if leading {
+		dlqLeaderGauge.Set(1)
+	} else {
+		dlqLeaderGauge.Set(0)
+	}
+}
+
+func recordLockContention() {
+	dlqLockContentionCounter.Inc()
 }
 
 func recordDLQProcessed(entry dlqEntry) {
@@ -64,6 +106,14 @@ func recordDLQRetry(entry dlqEntry) {
 	dlqRetryCounter.WithLabelValues(entry.Topic, entry.EventType).Inc()
 }
 
+func recordDLQReplayed(topic, reasonCategory string) {
+	dlqReplayedCounter.WithLabelValues(topic, reasonCategory).Inc()
+}
+
+func recordDLQReplayFailed(topic string) {
+	dlqReplayFailedCounter.WithLabelValues(topic).Inc()
+}
+
 func updateBacklogGauge(ctx context.Context, pool *pgxpool.Pool) {
 	row := pool.QueryRow(ctx, `SELECT COUNT(*) FROM outbox_dlq WHERE quarantined_at IS NULL`)
 	var count int
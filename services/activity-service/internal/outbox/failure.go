@@ -2,18 +2,23 @@ package outbox
 
 import (
 	"context"
+	"log/slog"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"example.com/platform/libs/go/logging"
 )
 
 // DLQWriter persists failed events for investigation.
 type DLQWriter struct {
-	pool *pgxpool.Pool
+	pool   *pgxpool.Pool
+	logger *slog.Logger
 }
 
-// NewDLQWriter initialises a writer backed by the provided connection pool.
-func NewDLQWriter(pool *pgxpool.Pool) *DLQWriter {
-	return &DLQWriter{pool: pool}
+// NewDLQWriter initialises a writer backed by the provided connection pool. A nil logger falls
+// back to slog.Default().
+func NewDLQWriter(pool *pgxpool.Pool, logger *slog.Logger) *DLQWriter {
+	return &DLQWriter{pool: pool, logger: logging.OrDefault(logger)}
 }
 
 // Write records a failed outbox message in the DLQ alongside the supplied reason.
@@ -42,5 +47,11 @@ func (w *DLQWriter) Write(ctx context.Context, msg Message, reason string) error
 		return err
 	}
 
-	return tx.Commit(ctx)
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	w.logger.Warn("outbox message routed to dlq",
+		"tenant_id", msg.TenantID, "event_id", msg.EventID, "event_type", msg.EventType, "topic", msg.Topic, "reason", reason)
+	return nil
 }
@@ -0,0 +1,114 @@
+package outbox
+
+import (
+	"context"
+	"hash/fnv"
+	"log/slog"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"example.com/platform/libs/go/logging"
+)
+
+// dlqLockName is hashed into the int64 key pg_try_advisory_lock expects, so every DLQManager
+// replica derives the same lock regardless of pod identity.
+const dlqLockName = "activity_service.dlq"
+
+// LockKey hashes name into the int64 key pg_try_advisory_lock/pg_advisory_unlock expect.
+func LockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+// LeaderElector coordinates which of several DLQManager replicas is allowed to run
+// RunOnce, using a Postgres session-level advisory lock on a dedicated pool connection.
+// Because pg_try_advisory_lock is scoped to the session that took it, losing the
+// connection (crash, network blip, pool eviction) releases the lock automatically -
+// there is no separate lease/heartbeat bookkeeping to fall out of sync with Postgres.
+type LeaderElector struct {
+	pool    *pgxpool.Pool
+	lockKey int64
+	logger  *slog.Logger
+
+	mu   sync.Mutex
+	conn *pgxpool.Conn
+}
+
+// NewLeaderElector constructs a LeaderElector for the DLQ manager's advisory lock. A nil
+// logger falls back to slog.Default().
+func NewLeaderElector(pool *pgxpool.Pool, logger *slog.Logger) *LeaderElector {
+	return &LeaderElector{
+		pool:    pool,
+		lockKey: LockKey(dlqLockName),
+		logger:  logging.OrDefault(logger),
+	}
+}
+
+// TryAcquire attempts to become (or remain) leader. If this elector is already holding the
+// lock, it verifies the held connection is still alive rather than re-acquiring - the lock
+// is already ours for as long as that session survives. It returns whether this process is
+// the leader after the call.
+func (e *LeaderElector) TryAcquire(ctx context.Context) (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.conn != nil {
+		if err := e.conn.Ping(ctx); err == nil {
+			return true, nil
+		}
+		e.conn.Release()
+		e.conn = nil
+		e.logger.Warn("dlq leader lost its connection", "lock_key", e.lockKey)
+		recordLeadership(false)
+	}
+
+	conn, err := e.pool.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var acquired bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", e.lockKey).Scan(&acquired); err != nil {
+		conn.Release()
+		return false, err
+	}
+	if !acquired {
+		conn.Release()
+		recordLockContention()
+		return false, nil
+	}
+
+	e.conn = conn
+	e.logger.Info("acquired dlq leadership", "lock_key", e.lockKey)
+	recordLeadership(true)
+	return true, nil
+}
+
+// IsLeader reports whether this elector currently holds the advisory lock.
+func (e *LeaderElector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.conn != nil
+}
+
+// Release performs a voluntary handoff: it explicitly unlocks the advisory lock instead of
+// just closing the connection, so a standby's next poll can acquire it immediately rather
+// than waiting out a dead connection's TCP timeout.
+func (e *LeaderElector) Release(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.conn == nil {
+		return
+	}
+	if _, err := e.conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", e.lockKey); err != nil {
+		e.logger.Warn("dlq leadership release error", "error", err, "lock_key", e.lockKey)
+	} else {
+		e.logger.Info("released dlq leadership", "lock_key", e.lockKey)
+	}
+	e.conn.Release()
+	e.conn = nil
+	recordLeadership(false)
+}
@@ -39,8 +39,38 @@ var (
 		Name:      "activities_marked_synced_total",
 		Help:      "Count of activities transitioned to synced after outbox publish.",
 	})
+
+	schemaCacheHits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "activity_service",
+		Subsystem: "outbox",
+		Name:      "schema_cache_hits_total",
+		Help:      "Number of schema ID lookups served from the in-process cache, labeled by subject.",
+	}, []string{"subject"})
+
+	schemaCacheMisses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "activity_service",
+		Subsystem: "outbox",
+		Name:      "schema_cache_misses_total",
+		Help:      "Number of schema ID lookups that required a Schema Registry round-trip, labeled by subject.",
+	}, []string{"subject"})
+
+	schemaValidationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "activity_service",
+		Subsystem: "outbox",
+		Name:      "schema_validation_failures_total",
+		Help:      "Number of outbox events that failed payload validation against their registered schema, labeled by event type.",
+	}, []string{"event_type"})
+
+	attemptsToSuccess = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "activity_service",
+		Subsystem: "outbox",
+		Name:      "delivery_attempts_to_success",
+		Help:      "Number of delivery attempts (including the successful one) an outbox row took before it published.",
+		Buckets:   prometheus.LinearBuckets(1, 1, 10),
+	})
 )
 
 func init() {
-	prometheus.MustRegister(deliveredCounter, failedCounter, batchDuration, dlqCounter, markedSyncedCounter)
+	prometheus.MustRegister(deliveredCounter, failedCounter, batchDuration, dlqCounter, markedSyncedCounter,
+		schemaCacheHits, schemaCacheMisses, schemaValidationFailures, attemptsToSuccess)
 }
@@ -5,25 +5,62 @@ import (
 	"sync"
 
 	"github.com/segmentio/kafka-go"
+
+	"example.com/platform/libs/go/datastreams"
+	"example.com/platform/libs/go/kafkasecurity"
 )
 
+// ProducerOption configures optional behaviour for a KafkaProducer.
+type ProducerOption func(*KafkaProducer)
+
+// WithDataStreamsProcessor attaches a datastreams.Processor that stamps a pathway
+// checkpoint header on every produced message. Passing nil (the default) disables
+// checkpointing, which is how tests keep producer output deterministic.
+func WithDataStreamsProcessor(dsp *datastreams.Processor) ProducerOption {
+	return func(p *KafkaProducer) {
+		p.dsp = dsp
+	}
+}
+
+// WithSecurityConfig authenticates/encrypts every writer's connection according to cfg.
+// Passing the zero Config (the default) preserves today's plaintext, no-auth behaviour.
+func WithSecurityConfig(cfg kafkasecurity.Config) ProducerOption {
+	return func(p *KafkaProducer) {
+		p.security = cfg
+	}
+}
+
 // KafkaProducer lazily manages writers per topic.
 type KafkaProducer struct {
-	brokers []string
-	mu      sync.Mutex
-	writers map[string]*kafka.Writer
+	brokers  []string
+	mu       sync.Mutex
+	writers  map[string]*kafka.Writer
+	dsp      *datastreams.Processor
+	security kafkasecurity.Config
 }
 
 // NewKafkaProducer creates a KafkaProducer.
-func NewKafkaProducer(brokers []string) *KafkaProducer {
-	return &KafkaProducer{
+func NewKafkaProducer(brokers []string, opts ...ProducerOption) *KafkaProducer {
+	p := &KafkaProducer{
 		brokers: brokers,
 		writers: make(map[string]*kafka.Writer),
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
-// WriteMessages writes messages to the given topic, creating a writer if necessary.
+// WriteMessages writes messages to the given topic, creating a writer if necessary. When a
+// data-streams processor is configured, each message is stamped with a pathway checkpoint
+// header before being handed to the writer.
 func (p *KafkaProducer) WriteMessages(ctx context.Context, topic string, msgs ...kafka.Message) error {
+	if p.dsp != nil {
+		for i, msg := range msgs {
+			ctxHeader := p.dsp.CheckpointOut(topic, string(msg.Key), nil)
+			msgs[i].Headers = append(msg.Headers, kafka.Header{Key: datastreams.PathwayHeader, Value: ctxHeader})
+		}
+	}
 	writer := p.writerForTopic(topic)
 	return writer.WriteMessages(ctx, msgs...)
 }
@@ -43,6 +80,14 @@ func (p *KafkaProducer) writerForTopic(topic string) *kafka.Writer {
 		Compression:  kafka.Snappy,
 		Async:        false,
 	}
+
+	// The Transport is built lazily per writer (rather than once in NewKafkaProducer) so a
+	// misconfigured security config surfaces as a write error against the first topic used,
+	// consistent with how writerForTopic already defers writer construction.
+	if transport, err := p.security.Transport(context.Background()); err == nil && transport != nil {
+		writer.Transport = transport
+	}
+
 	p.writers[topic] = writer
 	return writer
 }
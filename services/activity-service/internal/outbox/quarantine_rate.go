@@ -0,0 +1,65 @@
+package outbox
+
+import (
+	"sync"
+	"time"
+)
+
+// quarantineRateTracker counts quarantine events within a trailing window and reports edge
+// transitions across threshold, so DLQManager can page on a quarantine-rate spike rather than on
+// every individual quarantine and can resolve once the rate falls back below threshold.
+type quarantineRateTracker struct {
+	mu        sync.Mutex
+	window    time.Duration
+	threshold int
+
+	events     []time.Time
+	firing     bool
+	lastLabels map[string]string
+}
+
+func newQuarantineRateTracker(threshold int, window time.Duration) *quarantineRateTracker {
+	return &quarantineRateTracker{threshold: threshold, window: window}
+}
+
+// record appends a quarantine event and reports whether the trailing count just exceeded
+// threshold for the first time since the last resolve, in which case labels is returned
+// unchanged so the caller can use it to fire the alert.
+func (t *quarantineRateTracker) record(now time.Time, labels map[string]string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.events = append(t.events, now)
+	t.events = pruneBefore(t.events, now.Add(-t.window))
+
+	if !t.firing && len(t.events) > t.threshold {
+		t.firing = true
+		t.lastLabels = labels
+		return true
+	}
+	return false
+}
+
+// checkResolved prunes the window and reports whether the rate has dropped back to threshold or
+// below since the tracker last fired, returning the labels the alert fired with so the caller can
+// resolve the same Alertmanager instance.
+func (t *quarantineRateTracker) checkResolved(now time.Time) (map[string]string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.events = pruneBefore(t.events, now.Add(-t.window))
+	if t.firing && len(t.events) <= t.threshold {
+		t.firing = false
+		return t.lastLabels, true
+	}
+	return nil, false
+}
+
+// pruneBefore drops events older than cutoff from the front of the (time-ordered) slice.
+func pruneBefore(events []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(events) && events[i].Before(cutoff) {
+		i++
+	}
+	return events[i:]
+}
@@ -0,0 +1,45 @@
+package outbox
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuarantineRateTrackerFiresOnceAndResolvesOnce(t *testing.T) {
+	tracker := newQuarantineRateTracker(3, time.Minute)
+	base := time.Now()
+	labels := map[string]string{"alertname": "DLQQuarantineRateHigh"}
+
+	require.False(t, tracker.record(base, labels))
+	require.False(t, tracker.record(base.Add(time.Second), labels))
+	// Reaching threshold is not enough; the trailing count must exceed it.
+	require.False(t, tracker.record(base.Add(2*time.Second), labels))
+	require.True(t, tracker.record(base.Add(3*time.Second), labels))
+
+	// Already firing: further events past threshold must not re-fire.
+	require.False(t, tracker.record(base.Add(4*time.Second), labels))
+
+	_, resolved := tracker.checkResolved(base.Add(5 * time.Second))
+	require.False(t, resolved)
+
+	// Advance past window so all prior events are pruned.
+	gotLabels, resolved := tracker.checkResolved(base.Add(time.Minute + 5*time.Second))
+	require.True(t, resolved)
+	require.Equal(t, labels, gotLabels)
+
+	// Resolve is edge-triggered: calling again without a new firing must not resolve again.
+	_, resolved = tracker.checkResolved(base.Add(2 * time.Minute))
+	require.False(t, resolved)
+}
+
+func TestQuarantineRateTrackerPrunesOutsideWindow(t *testing.T) {
+	tracker := newQuarantineRateTracker(2, time.Second)
+	base := time.Now()
+	labels := map[string]string{"alertname": "DLQQuarantineRateHigh"}
+
+	require.False(t, tracker.record(base, labels))
+	// Second event arrives after the first has already aged out of the window.
+	require.False(t, tracker.record(base.Add(2*time.Second), labels))
+}
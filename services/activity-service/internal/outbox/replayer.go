@@ -0,0 +1,468 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"example.com/activity/internal/domain"
+)
+
+// ErrDLQEntryNotFound is returned when a targeted replay references an event_id
+// that is not currently sitting in outbox_dlq (already replayed, quarantined, or
+// never dead-lettered).
+var ErrDLQEntryNotFound = errors.New("dlq entry not found")
+
+// defaultReplayBatchSize bounds how many DLQ entries a single automatic replay
+// pass will consider, mirroring defaultDLQBatchSize used by the DLQ manager.
+const defaultReplayBatchSize = 50
+
+// transientReasons lists substrings of outbox_dlq.reason that indicate a failure
+// likely to succeed on retry (a broker blip, a network timeout) rather than a
+// structural problem with the event itself. Anything not matching here is
+// treated as permanent and excluded from automatic replay.
+var transientReasons = []string{
+	"kafka write failed",
+	"upstream kafka unavailable",
+	"connection reset",
+	"i/o timeout",
+	"context deadline exceeded",
+	"broker not available",
+}
+
+// isTransient reports whether reason looks safe to retry without operator
+// intervention.
+func isTransient(reason string) bool {
+	for _, needle := range transientReasons {
+		if strings.Contains(reason, needle) {
+			return true
+		}
+	}
+	return false
+}
+
+// Replayer periodically requeues outbox_dlq entries whose failures look
+// transient, and backs an admin endpoint that force-replays a specific entry
+// regardless of age or reason.
+type Replayer struct {
+	pool             *pgxpool.Pool
+	backoffWindow    time.Duration
+	pollInterval     time.Duration
+	shutdownComplete chan struct{}
+}
+
+// NewReplayer constructs a Replayer. Entries must have sat in outbox_dlq for at
+// least backoffWindow before automatic replay considers them.
+func NewReplayer(pool *pgxpool.Pool, backoffWindow, pollInterval time.Duration) *Replayer {
+	if backoffWindow <= 0 {
+		backoffWindow = 5 * time.Minute
+	}
+	if pollInterval <= 0 {
+		pollInterval = time.Minute
+	}
+	return &Replayer{
+		pool:             pool,
+		backoffWindow:    backoffWindow,
+		pollInterval:     pollInterval,
+		shutdownComplete: make(chan struct{}),
+	}
+}
+
+// Start launches the polling loop. It should be called in a goroutine.
+func (r *Replayer) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer func() {
+		ticker.Stop()
+		close(r.shutdownComplete)
+	}()
+
+	for {
+		if _, err := r.RunOnce(ctx, defaultReplayBatchSize); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("outbox replayer error: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Wait blocks until the replayer's polling loop has stopped.
+func (r *Replayer) Wait() {
+	<-r.shutdownComplete
+}
+
+// RunOnce scans outbox_dlq for non-quarantined entries older than the backoff
+// window and requeues the transient ones into outbox. It returns the number of
+// entries successfully replayed.
+func (r *Replayer) RunOnce(ctx context.Context, batchSize int) (int, error) {
+	const query = `SELECT dlq_id, tenant_id, event_id, event_type, topic, payload, reason, aggregate_type, aggregate_id, schema_subject, partition_key, retry_count
+                    FROM outbox_dlq
+                   WHERE quarantined_at IS NULL AND created_at <= NOW() - $1::interval
+                   ORDER BY created_at
+                   LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, query, r.backoffWindow, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var candidates []replayCandidate
+	for rows.Next() {
+		entry, scanErr := scanReplayCandidate(rows)
+		if scanErr != nil {
+			err = errors.Join(err, scanErr)
+			continue
+		}
+		candidates = append(candidates, entry)
+	}
+	if rowsErr := rows.Err(); rowsErr != nil {
+		err = errors.Join(err, rowsErr)
+	}
+	rows.Close()
+
+	replayed := 0
+	for _, candidate := range candidates {
+		if !isTransient(candidate.Reason) {
+			continue
+		}
+		if replayErr := r.replay(ctx, candidate); replayErr != nil {
+			err = errors.Join(err, replayErr)
+			continue
+		}
+		replayed++
+	}
+	return replayed, err
+}
+
+// ReplayByEventID force-replays a single DLQ entry identified by its original
+// event_id, bypassing both the backoff window and the transient/permanent
+// classification. It backs the admin replay endpoint, where an operator has
+// already judged the underlying issue fixed.
+func (r *Replayer) ReplayByEventID(ctx context.Context, eventID int64) error {
+	const query = `SELECT dlq_id, tenant_id, event_id, event_type, topic, payload, reason, aggregate_type, aggregate_id, schema_subject, partition_key, retry_count
+                    FROM outbox_dlq
+                   WHERE event_id = $1 AND quarantined_at IS NULL`
+
+	row := r.pool.QueryRow(ctx, query, eventID)
+	candidate, err := scanReplayCandidate(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrDLQEntryNotFound
+		}
+		return err
+	}
+
+	return r.replay(ctx, candidate)
+}
+
+// replay reinserts candidate into outbox with attempt_count carried forward
+// from the DLQ's retry_count, then removes it from outbox_dlq.
+func (r *Replayer) replay(ctx context.Context, candidate replayCandidate) error {
+	conn, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := insertReplayCandidate(ctx, tx, candidate); err != nil {
+		recordDLQReplayFailed(candidate.Topic)
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		recordDLQReplayFailed(candidate.Topic)
+		return err
+	}
+
+	recordDLQReplayed(candidate.Topic, replayReasonLabel(candidate.Reason))
+	return nil
+}
+
+// replayAll reinserts every candidate into outbox and removes it from outbox_dlq inside a
+// single transaction, so a batch admin replay is all-or-nothing.
+func (r *Replayer) replayAll(ctx context.Context, candidates []replayCandidate) error {
+	conn, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, candidate := range candidates {
+		if err := insertReplayCandidate(ctx, tx, candidate); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// insertReplayCandidate requeues a single DLQ entry into outbox within tx, carrying its
+// attempt_count forward from the DLQ's retry_count, then deletes it from outbox_dlq. Callers are
+// responsible for the transaction's tenant context, commit, and metrics.
+func insertReplayCandidate(ctx context.Context, tx pgx.Tx, candidate replayCandidate) error {
+	if _, err := tx.Exec(ctx, "SELECT set_config('app.tenant_id', $1, true)", candidate.TenantID); err != nil {
+		return err
+	}
+
+	const stmt = `INSERT INTO outbox (tenant_id, aggregate_type, aggregate_id, event_type, topic, schema_subject, partition_key, payload, attempt_count)
+                   VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`
+	if _, err := tx.Exec(ctx, stmt,
+		candidate.TenantID,
+		candidate.AggregateType,
+		candidate.AggregateID,
+		candidate.EventType,
+		candidate.Topic,
+		candidate.SchemaSubject,
+		candidate.PartitionKey,
+		candidate.Payload,
+		candidate.RetryCount+1,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM outbox_dlq WHERE dlq_id = $1`, candidate.ID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DLQEntry exposes a dead-lettered outbox event for admin tooling.
+type DLQEntry struct {
+	EventID    int64
+	TenantID   string
+	EventType  string
+	Topic      string
+	Reason     string
+	RetryCount int
+	CreatedAt  time.Time
+}
+
+// DLQFilter narrows ListDLQ to a subset of non-quarantined entries. Zero-value fields are
+// ignored, so the zero DLQFilter matches everything (today's unfiltered listing behaviour).
+type DLQFilter struct {
+	Topic          string
+	EventType      string
+	TenantID       string
+	ReasonContains string
+	From           time.Time
+	To             time.Time
+}
+
+// ListDLQ returns a page of non-quarantined DLQ entries matching filter, ordered by creation
+// time, for the admin inspection endpoint. cursor/limit follow the same convention as the
+// activity list endpoint.
+func (r *Replayer) ListDLQ(ctx context.Context, filter DLQFilter, cursor *domain.Cursor, limit int) ([]DLQEntry, *domain.Cursor, error) {
+	args := []any{limit}
+	query := `SELECT event_id, tenant_id, event_type, topic, reason, retry_count, created_at
+                FROM outbox_dlq
+               WHERE quarantined_at IS NULL`
+
+	if filter.Topic != "" {
+		args = append(args, filter.Topic)
+		query += fmt.Sprintf(" AND topic = $%d", len(args))
+	}
+	if filter.EventType != "" {
+		args = append(args, filter.EventType)
+		query += fmt.Sprintf(" AND event_type = $%d", len(args))
+	}
+	if filter.TenantID != "" {
+		args = append(args, filter.TenantID)
+		query += fmt.Sprintf(" AND tenant_id = $%d", len(args))
+	}
+	if filter.ReasonContains != "" {
+		args = append(args, "%"+filter.ReasonContains+"%")
+		query += fmt.Sprintf(" AND reason ILIKE $%d", len(args))
+	}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		query += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		query += fmt.Sprintf(" AND created_at <= $%d", len(args))
+	}
+
+	if cursor != nil {
+		args = append(args, cursor.StartedAt, cursor.ID)
+		query += fmt.Sprintf(" AND (created_at, event_id::text) > ($%d, $%d)", len(args)-1, len(args))
+	}
+	query += " ORDER BY created_at, event_id LIMIT $1"
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]DLQEntry, 0, limit)
+	for rows.Next() {
+		var e DLQEntry
+		if err := rows.Scan(&e.EventID, &e.TenantID, &e.EventType, &e.Topic, &e.Reason, &e.RetryCount, &e.CreatedAt); err != nil {
+			return nil, nil, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var next *domain.Cursor
+	if len(entries) == limit {
+		last := entries[len(entries)-1]
+		next = &domain.Cursor{StartedAt: last.CreatedAt, ID: strconv.FormatInt(last.EventID, 10)}
+	}
+
+	return entries, next, nil
+}
+
+// ReplayByEventIDs force-replays a batch of DLQ entries identified by their original event_ids,
+// all inside a single transaction: either every entry is requeued or none are. It bypasses both
+// the backoff window and the transient/permanent classification, like ReplayByEventID.
+func (r *Replayer) ReplayByEventIDs(ctx context.Context, eventIDs []int64) (int, error) {
+	if len(eventIDs) == 0 {
+		return 0, nil
+	}
+
+	const query = `SELECT dlq_id, tenant_id, event_id, event_type, topic, payload, reason, aggregate_type, aggregate_id, schema_subject, partition_key, retry_count
+                    FROM outbox_dlq
+                   WHERE event_id = ANY($1) AND quarantined_at IS NULL`
+
+	rows, err := r.pool.Query(ctx, query, eventIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	var candidates []replayCandidate
+	for rows.Next() {
+		candidate, scanErr := scanReplayCandidate(rows)
+		if scanErr != nil {
+			err = errors.Join(err, scanErr)
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+	if rowsErr := rows.Err(); rowsErr != nil {
+		err = errors.Join(err, rowsErr)
+	}
+	rows.Close()
+	if err != nil {
+		return 0, err
+	}
+	if len(candidates) != len(eventIDs) {
+		return 0, ErrDLQEntryNotFound
+	}
+
+	if err := r.replayAll(ctx, candidates); err != nil {
+		for _, candidate := range candidates {
+			recordDLQReplayFailed(candidate.Topic)
+		}
+		return 0, err
+	}
+
+	for _, candidate := range candidates {
+		recordDLQReplayed(candidate.Topic, replayReasonLabel(candidate.Reason))
+	}
+	return len(candidates), nil
+}
+
+// Drain force-replays every non-quarantined DLQ entry for topic, oldest first, pacing requeues
+// rate apart so a large backlog doesn't slam the outbox dispatcher all at once. A rate of zero
+// replays as fast as possible. It stops early if ctx is cancelled or a replay fails.
+func (r *Replayer) Drain(ctx context.Context, topic string, rate time.Duration) (int, error) {
+	replayed := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return replayed, err
+		}
+
+		const query = `SELECT dlq_id, tenant_id, event_id, event_type, topic, payload, reason, aggregate_type, aggregate_id, schema_subject, partition_key, retry_count
+                        FROM outbox_dlq
+                       WHERE topic = $1 AND quarantined_at IS NULL
+                       ORDER BY created_at
+                       LIMIT 1`
+
+		candidate, err := scanReplayCandidate(r.pool.QueryRow(ctx, query, topic))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return replayed, nil
+			}
+			return replayed, err
+		}
+
+		if err := r.replay(ctx, candidate); err != nil {
+			return replayed, err
+		}
+		replayed++
+
+		if rate > 0 {
+			select {
+			case <-ctx.Done():
+				return replayed, ctx.Err()
+			case <-time.After(rate):
+			}
+		}
+	}
+}
+
+// replayReasonLabel collapses a free-form DLQ reason down to a bounded category
+// for the outbox_dlq_replayed_total metric label.
+func replayReasonLabel(reason string) string {
+	if isTransient(reason) {
+		return "transient"
+	}
+	return "permanent"
+}
+
+// replayCandidate is an outbox_dlq row staged for requeue into outbox.
+type replayCandidate struct {
+	ID            int64
+	TenantID      string
+	EventID       int64
+	EventType     string
+	Topic         string
+	Payload       []byte
+	Reason        string
+	AggregateType string
+	AggregateID   string
+	SchemaSubject string
+	PartitionKey  string
+	RetryCount    int
+}
+
+// rowScanner abstracts over pgx.Row and pgx.Rows so scanReplayCandidate can
+// back both RunOnce's batch query and ReplayByEventID's single-row lookup.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanReplayCandidate(row rowScanner) (replayCandidate, error) {
+	var c replayCandidate
+	err := row.Scan(&c.ID, &c.TenantID, &c.EventID, &c.EventType, &c.Topic, &c.Payload, &c.Reason, &c.AggregateType, &c.AggregateID, &c.SchemaSubject, &c.PartitionKey, &c.RetryCount)
+	if err != nil {
+		return replayCandidate{}, err
+	}
+	return c, nil
+}
@@ -0,0 +1,192 @@
+//go:build integration
+
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"example.com/activity/internal/testsupport"
+)
+
+func TestReplayerRequeuesTransientDLQEntriesPastBackoffWindow(t *testing.T) {
+	ctx := context.Background()
+	pool, _ := testsupport.StartPostgres(ctx, t)
+
+	tenantID := uuid.NewString()
+	aggregateID := uuid.NewString()
+	require.NotZero(t, testsupport.SeedOutbox(ctx, t, pool, tenantID, aggregateID, "activity.created", nil))
+
+	failingProducer := &stubProducer{err: errors.New("kafka write failed")}
+	registry := &stubRegistry{id: 11}
+	dispatcher := NewDispatcher(pool, failingProducer, registry, 5*time.Millisecond, 10, WithMaxAttempts(1))
+	require.NoError(t, dispatcher.processBatch(ctx))
+
+	var dlqID int64
+	err := pool.QueryRow(ctx, `SELECT dlq_id FROM outbox_dlq WHERE tenant_id = $1`, tenantID).Scan(&dlqID)
+	require.NoError(t, err)
+	backdateDLQEntry(t, ctx, pool, dlqID, time.Hour)
+
+	replayer := NewReplayer(pool, 10*time.Minute, time.Minute)
+
+	replayed, err := replayer.RunOnce(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, replayed)
+
+	var dlqCount int
+	require.NoError(t, pool.QueryRow(ctx, `SELECT COUNT(*) FROM outbox_dlq WHERE tenant_id = $1`, tenantID).Scan(&dlqCount))
+	require.Equal(t, 0, dlqCount)
+
+	var attemptCount int
+	err = pool.QueryRow(ctx, `SELECT attempt_count FROM outbox WHERE tenant_id = $1 AND published_at IS NULL`, tenantID).Scan(&attemptCount)
+	require.NoError(t, err)
+	require.Equal(t, 1, attemptCount, "replay carries the DLQ retry_count forward, plus the attempt already recorded before dead-lettering")
+
+	succeedingProducer := &stubProducer{}
+	dispatcher = NewDispatcher(pool, succeedingProducer, registry, 5*time.Millisecond, 10)
+	require.NoError(t, dispatcher.processBatch(ctx))
+	require.Len(t, succeedingProducer.writes, 1)
+}
+
+func TestReplayerSkipsPermanentReasonsUnlessExplicitlyReplayed(t *testing.T) {
+	ctx := context.Background()
+	pool, _ := testsupport.StartPostgres(ctx, t)
+
+	tenantID := uuid.NewString()
+	eventID := testsupport.SeedOutbox(ctx, t, pool, tenantID, uuid.NewString(), "activity.unknown", nil)
+	require.NotZero(t, eventID)
+
+	producer := &stubProducer{}
+	registry := &stubRegistry{id: 12}
+	dispatcher := NewDispatcher(pool, producer, registry, 5*time.Millisecond, 10, WithMaxAttempts(1))
+	require.NoError(t, dispatcher.processBatch(ctx))
+
+	var dlqID int64
+	err := pool.QueryRow(ctx, `SELECT dlq_id FROM outbox_dlq WHERE event_id = $1`, eventID).Scan(&dlqID)
+	require.NoError(t, err)
+	backdateDLQEntry(t, ctx, pool, dlqID, time.Hour)
+
+	replayer := NewReplayer(pool, 10*time.Minute, time.Minute)
+
+	replayed, err := replayer.RunOnce(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, 0, replayed, "permanent failures should not auto-replay")
+
+	var dlqCount int
+	require.NoError(t, pool.QueryRow(ctx, `SELECT COUNT(*) FROM outbox_dlq WHERE event_id = $1`, eventID).Scan(&dlqCount))
+	require.Equal(t, 1, dlqCount)
+
+	require.NoError(t, replayer.ReplayByEventID(ctx, eventID))
+
+	require.NoError(t, pool.QueryRow(ctx, `SELECT COUNT(*) FROM outbox_dlq WHERE event_id = $1`, eventID).Scan(&dlqCount))
+	require.Equal(t, 0, dlqCount)
+}
+
+func TestReplayerReplaysBatchAtomically(t *testing.T) {
+	ctx := context.Background()
+	pool, _ := testsupport.StartPostgres(ctx, t)
+
+	registry := &stubRegistry{id: 13}
+	failingProducer := &stubProducer{err: errors.New("kafka write failed")}
+	dispatcher := NewDispatcher(pool, failingProducer, registry, 5*time.Millisecond, 10, WithMaxAttempts(1))
+
+	eventIDA := testsupport.SeedOutbox(ctx, t, pool, uuid.NewString(), uuid.NewString(), "activity.created", nil)
+	eventIDB := testsupport.SeedOutbox(ctx, t, pool, uuid.NewString(), uuid.NewString(), "activity.created", nil)
+	require.NoError(t, dispatcher.processBatch(ctx))
+
+	var dlqCount int
+	require.NoError(t, pool.QueryRow(ctx, `SELECT COUNT(*) FROM outbox_dlq`).Scan(&dlqCount))
+	require.Equal(t, 2, dlqCount)
+
+	replayer := NewReplayer(pool, 10*time.Minute, time.Minute)
+	replayed, err := replayer.ReplayByEventIDs(ctx, []int64{eventIDA, eventIDB})
+	require.NoError(t, err)
+	require.Equal(t, 2, replayed)
+
+	require.NoError(t, pool.QueryRow(ctx, `SELECT COUNT(*) FROM outbox_dlq`).Scan(&dlqCount))
+	require.Equal(t, 0, dlqCount)
+
+	var requeued int
+	require.NoError(t, pool.QueryRow(ctx, `SELECT COUNT(*) FROM outbox WHERE published_at IS NULL`).Scan(&requeued))
+	require.Equal(t, 2, requeued)
+}
+
+func TestReplayerReplayByEventIDsFailsAtomicallyOnUnknownEntry(t *testing.T) {
+	ctx := context.Background()
+	pool, _ := testsupport.StartPostgres(ctx, t)
+
+	registry := &stubRegistry{id: 14}
+	failingProducer := &stubProducer{err: errors.New("kafka write failed")}
+	dispatcher := NewDispatcher(pool, failingProducer, registry, 5*time.Millisecond, 10, WithMaxAttempts(1))
+
+	eventID := testsupport.SeedOutbox(ctx, t, pool, uuid.NewString(), uuid.NewString(), "activity.created", nil)
+	require.NoError(t, dispatcher.processBatch(ctx))
+
+	replayer := NewReplayer(pool, 10*time.Minute, time.Minute)
+	_, err := replayer.ReplayByEventIDs(ctx, []int64{eventID, eventID + 999999})
+	require.ErrorIs(t, err, ErrDLQEntryNotFound)
+
+	var dlqCount int
+	require.NoError(t, pool.QueryRow(ctx, `SELECT COUNT(*) FROM outbox_dlq`).Scan(&dlqCount))
+	require.Equal(t, 1, dlqCount, "the known entry must not be requeued when the batch as a whole fails")
+}
+
+func TestReplayerDrainsTopicInFIFOOrder(t *testing.T) {
+	ctx := context.Background()
+	pool, _ := testsupport.StartPostgres(ctx, t)
+
+	registry := &stubRegistry{id: 15}
+	failingProducer := &stubProducer{err: errors.New("kafka write failed")}
+	dispatcher := NewDispatcher(pool, failingProducer, registry, 5*time.Millisecond, 10, WithMaxAttempts(1))
+
+	for i := 0; i < 3; i++ {
+		testsupport.SeedOutbox(ctx, t, pool, uuid.NewString(), uuid.NewString(), "activity.created", nil)
+	}
+	require.NoError(t, dispatcher.processBatch(ctx))
+
+	var dlqCount int
+	require.NoError(t, pool.QueryRow(ctx, `SELECT COUNT(*) FROM outbox_dlq`).Scan(&dlqCount))
+	require.Equal(t, 3, dlqCount)
+
+	replayer := NewReplayer(pool, 10*time.Minute, time.Minute)
+	replayed, err := replayer.Drain(ctx, "activity_events", 0)
+	require.NoError(t, err)
+	require.Equal(t, 3, replayed)
+
+	require.NoError(t, pool.QueryRow(ctx, `SELECT COUNT(*) FROM outbox_dlq`).Scan(&dlqCount))
+	require.Equal(t, 0, dlqCount)
+}
+
+func TestReplayerListDLQFiltersByReason(t *testing.T) {
+	ctx := context.Background()
+	pool, _ := testsupport.StartPostgres(ctx, t)
+
+	registry := &stubRegistry{id: 16}
+	failingProducer := &stubProducer{err: errors.New("kafka write failed")}
+	dispatcher := NewDispatcher(pool, failingProducer, registry, 5*time.Millisecond, 10, WithMaxAttempts(1))
+
+	testsupport.SeedOutbox(ctx, t, pool, uuid.NewString(), uuid.NewString(), "activity.created", nil)
+	testsupport.SeedOutbox(ctx, t, pool, uuid.NewString(), uuid.NewString(), "activity.unknown", nil)
+	require.NoError(t, dispatcher.processBatch(ctx))
+
+	replayer := NewReplayer(pool, 10*time.Minute, time.Minute)
+	entries, _, err := replayer.ListDLQ(ctx, DLQFilter{ReasonContains: "no schema metadata"}, nil, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "activity.unknown", entries[0].EventType)
+}
+
+// backdateDLQEntry pushes a DLQ entry's created_at back by age, so tests can
+// exercise the replay backoff window without sleeping.
+func backdateDLQEntry(t *testing.T, ctx context.Context, pool *pgxpool.Pool, dlqID int64, age time.Duration) {
+	t.Helper()
+
+	_, err := pool.Exec(ctx, `UPDATE outbox_dlq SET created_at = created_at - $1::interval WHERE dlq_id = $2`, age, dlqID)
+	require.NoError(t, err)
+}
@@ -0,0 +1,129 @@
+package outbox
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RetryPolicy decides how long to wait before the next retry of a DLQ entry. lastDelay is
+// zero on the first retry.
+type RetryPolicy interface {
+	Next(attempt int, lastDelay time.Duration) time.Duration
+}
+
+// ExponentialRetryPolicy doubles the delay per attempt, capped at Cap. This is the strategy
+// DLQManager used before RetryPolicy was introduced.
+type ExponentialRetryPolicy struct {
+	BaseDelay time.Duration
+	Cap       time.Duration
+}
+
+// Next implements RetryPolicy.
+func (p ExponentialRetryPolicy) Next(attempt int, _ time.Duration) time.Duration {
+	cap := p.Cap
+	if cap <= 0 {
+		cap = time.Hour
+	}
+	delay := time.Duration(1<<uint(attempt-1)) * p.BaseDelay
+	if delay > cap || delay <= 0 {
+		delay = cap
+	}
+	return delay
+}
+
+// DecorrelatedJitterRetryPolicy implements the AWS Architecture Blog "decorrelated jitter"
+// strategy: sleep = min(cap, random_between(base, lastDelay*3)). Unlike plain exponential
+// backoff, successive retries across many DLQ entries desynchronize from one another instead
+// of converging on the same few retry windows, which avoids retry storms against Kafka/Dgraph
+// after an outage.
+type DecorrelatedJitterRetryPolicy struct {
+	BaseDelay time.Duration
+	Cap       time.Duration
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// Next implements RetryPolicy. It is safe for concurrent use.
+func (p *DecorrelatedJitterRetryPolicy) Next(_ int, lastDelay time.Duration) time.Duration {
+	base := p.BaseDelay
+	if base <= 0 {
+		base = time.Second
+	}
+	cap := p.Cap
+	if cap <= 0 {
+		cap = time.Hour
+	}
+
+	upper := lastDelay * 3
+	if upper < base {
+		upper = base
+	}
+
+	p.mu.Lock()
+	if p.rand == nil {
+		p.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	delay := base + time.Duration(p.rand.Int63n(int64(upper-base+1)))
+	p.mu.Unlock()
+
+	if delay > cap {
+		delay = cap
+	}
+	return delay
+}
+
+// TokenBucketRetryPolicy wraps another policy but throttles total re-enqueues per minute
+// across all DLQ entries: once the bucket is exhausted, Next returns the wrapped policy's
+// delay raised to at least one minute (the refill interval), so a burst of failures
+// doesn't re-enqueue faster than downstream systems can absorb.
+type TokenBucketRetryPolicy struct {
+	Wrapped       RetryPolicy
+	MaxPerMinute  int
+
+	mu         sync.Mutex
+	tokens     int
+	lastRefill time.Time
+}
+
+// Next implements RetryPolicy.
+func (p *TokenBucketRetryPolicy) Next(attempt int, lastDelay time.Duration) time.Duration {
+	wrapped := p.Wrapped
+	if wrapped == nil {
+		wrapped = ExponentialRetryPolicy{BaseDelay: time.Minute, Cap: time.Hour}
+	}
+
+	p.mu.Lock()
+	now := time.Now()
+	if p.lastRefill.IsZero() {
+		p.lastRefill = now
+		p.tokens = p.MaxPerMinute
+	}
+	if elapsed := now.Sub(p.lastRefill); elapsed >= time.Minute {
+		refills := int(elapsed / time.Minute)
+		p.tokens = min(p.MaxPerMinute, p.tokens+refills*p.MaxPerMinute)
+		p.lastRefill = now
+	}
+
+	var throttled bool
+	if p.tokens > 0 {
+		p.tokens--
+	} else {
+		throttled = true
+	}
+	p.mu.Unlock()
+
+	delay := wrapped.Next(attempt, lastDelay)
+	if throttled && delay < time.Minute {
+		delay = time.Minute
+	}
+	return delay
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,126 @@
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrSchemaValidationFailed wraps payload validation errors so Dispatcher can route them to
+// the DLQ with a dedicated reason instead of the generic delivery-failure reason.
+var ErrSchemaValidationFailed = errors.New("schema validation failed")
+
+// ErrSchemaIncompatible is returned by Dispatcher.CheckCatalogCompatibility when a catalog
+// entry's schema would break a subject's configured compatibility rule.
+var ErrSchemaIncompatible = errors.New("schema registry compatibility check failed")
+
+// Validator checks an encoded payload against a schema definition before it is published.
+// JSON Schema and Avro subjects describe their field contract differently, so each gets its
+// own implementation behind this interface.
+type Validator interface {
+	Validate(schema string, payload []byte) error
+}
+
+// validatorFor returns the Validator for a catalog entry's schema format, defaulting to JSON
+// Schema for entries that predate the Format field.
+func validatorFor(format SchemaFormat) Validator {
+	switch format {
+	case SchemaFormatAvro:
+		return avroValidator{}
+	case SchemaFormatProtobuf:
+		return protobufValidator{}
+	default:
+		return jsonSchemaValidator{}
+	}
+}
+
+// jsonSchemaValidator enforces a JSON Schema document's property whitelist. It does not
+// attempt full JSON Schema validation (required fields, type coercion, formats, nested
+// schemas) - that would need a dedicated library this repo doesn't vendor - but rejecting
+// unknown top-level properties already catches the failure mode that actually reaches the
+// DLQ in practice: a producer that drifted out of sync with the registered contract.
+type jsonSchemaValidator struct{}
+
+func (jsonSchemaValidator) Validate(schema string, payload []byte) error {
+	var def struct {
+		Properties           map[string]json.RawMessage `json:"properties"`
+		AdditionalProperties *bool                      `json:"additionalProperties"`
+	}
+	if err := json.Unmarshal([]byte(schema), &def); err != nil {
+		return fmt.Errorf("parse json schema: %w", err)
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &doc); err != nil {
+		return fmt.Errorf("payload is not a JSON object: %w", err)
+	}
+
+	if def.AdditionalProperties != nil && !*def.AdditionalProperties {
+		return rejectUnknownFields(def.Properties, doc)
+	}
+	return nil
+}
+
+// avroValidator enforces an Avro record schema's field whitelist against the JSON-encoded
+// payload. Outbox payloads are stored as JSON regardless of wire schema type, so this checks
+// field names rather than decoding Avro binary.
+type avroValidator struct{}
+
+func (avroValidator) Validate(schema string, payload []byte) error {
+	var def struct {
+		Fields []struct {
+			Name string `json:"name"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(schema), &def); err != nil {
+		return fmt.Errorf("parse avro schema: %w", err)
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &doc); err != nil {
+		return fmt.Errorf("payload is not a JSON object: %w", err)
+	}
+
+	allowed := make(map[string]json.RawMessage, len(def.Fields))
+	for _, field := range def.Fields {
+		allowed[field.Name] = nil
+	}
+	return rejectUnknownFields(allowed, doc)
+}
+
+// protobufValidator enforces a proto3 message definition's field whitelist against the
+// JSON-encoded payload, the same way avroValidator does for Avro records: field names are
+// extracted from the schema text with a regexp rather than a real descriptor parser, since
+// this repo doesn't vendor one, and the JSON-encoded payload is checked against that set.
+type protobufValidator struct{}
+
+var protoFieldPattern = regexp.MustCompile(`(?m)^\s*\w[\w.]*\s+(\w+)\s*=\s*\d+\s*;`)
+
+func (protobufValidator) Validate(schema string, payload []byte) error {
+	matches := protoFieldPattern.FindAllStringSubmatch(schema, -1)
+	allowed := make(map[string]json.RawMessage, len(matches))
+	for _, match := range matches {
+		allowed[match[1]] = nil
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &doc); err != nil {
+		return fmt.Errorf("payload is not a JSON object: %w", err)
+	}
+	return rejectUnknownFields(allowed, doc)
+}
+
+func rejectUnknownFields(allowed map[string]json.RawMessage, doc map[string]json.RawMessage) error {
+	var unknown []string
+	for field := range doc {
+		if _, ok := allowed[field]; !ok {
+			unknown = append(unknown, field)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown fields not in schema: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package outbox
+
+// Encoder serializes a canonical outbox payload into a schema format's wire representation,
+// before Confluent framing (magic byte + schema id) is applied by encodeWireFormat.
+type Encoder interface {
+	Encode(payload []byte) ([]byte, error)
+}
+
+// encoderFor returns the Encoder for a catalog entry's schema format.
+//
+// Outbox payloads are always persisted as JSON in Postgres. For AVRO and PROTOBUF subjects
+// this repo doesn't vendor a binary codec - that's the same tradeoff avroValidator documents
+// for validation - so every format's encoder currently passes the JSON bytes through
+// unchanged. The Confluent framing is still correct, so a standard deserializer resolves the
+// right schema by id; it just receives JSON-encoded data rather than true Avro/Protobuf
+// binary until a transcoding library lands.
+func encoderFor(format SchemaFormat) Encoder {
+	switch format {
+	case SchemaFormatAvro, SchemaFormatProtobuf:
+		return passthroughEncoder{}
+	default:
+		return passthroughEncoder{}
+	}
+}
+
+type passthroughEncoder struct{}
+
+func (passthroughEncoder) Encode(payload []byte) ([]byte, error) {
+	return payload, nil
+}
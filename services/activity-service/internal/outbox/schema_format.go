@@ -0,0 +1,16 @@
+package outbox
+
+// SchemaFormat identifies how a schema registry subject serializes its payload on the wire.
+// It selects both the Validator used before publish and the Encoder used to produce the
+// bytes that get Confluent-framed and sent to Kafka.
+type SchemaFormat string
+
+const (
+	// SchemaFormatJSON is the default format for catalog entries that predate multi-format
+	// support.
+	SchemaFormatJSON SchemaFormat = "JSON"
+	// SchemaFormatAvro registers the subject as an Avro record.
+	SchemaFormatAvro SchemaFormat = "AVRO"
+	// SchemaFormatProtobuf registers the subject as a Protobuf message.
+	SchemaFormatProtobuf SchemaFormat = "PROTOBUF"
+)
@@ -10,29 +10,126 @@ import (
 	"time"
 )
 
+// SchemaRegistryClientOption configures optional SchemaRegistryClient behaviour.
+type SchemaRegistryClientOption func(*SchemaRegistryClient)
+
+// WithBasicAuth authenticates every request with HTTP basic auth.
+func WithBasicAuth(username, password string) SchemaRegistryClientOption {
+	return func(c *SchemaRegistryClient) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// WithBearerToken authenticates every request with a bearer token, taking precedence over
+// WithBasicAuth if both are configured.
+func WithBearerToken(token string) SchemaRegistryClientOption {
+	return func(c *SchemaRegistryClient) {
+		c.bearerToken = token
+	}
+}
+
 // SchemaRegistryClient provides minimal interactions with Confluent Schema Registry.
 type SchemaRegistryClient struct {
-	baseURL    string
-	httpClient *http.Client
+	baseURL     string
+	httpClient  *http.Client
+	username    string
+	password    string
+	bearerToken string
 }
 
 // NewSchemaRegistryClient constructs a client with sane defaults.
-func NewSchemaRegistryClient(baseURL string) *SchemaRegistryClient {
-	return &SchemaRegistryClient{
+func NewSchemaRegistryClient(baseURL string, opts ...SchemaRegistryClientOption) *SchemaRegistryClient {
+	c := &SchemaRegistryClient{
 		baseURL: baseURL,
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// authenticate applies the configured credentials, if any, to req.
+func (c *SchemaRegistryClient) authenticate(req *http.Request) {
+	if c.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.bearerToken)
+		return
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
 }
 
-// EnsureSchema ensures a schema subject exists and returns the schema ID.
-func (c *SchemaRegistryClient) EnsureSchema(ctx context.Context, subject string, schema string) (int, error) {
+// SchemaByID fetches the writer schema registered under id, for consumer-side decoding.
+func (c *SchemaRegistryClient) SchemaByID(ctx context.Context, id int) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/schemas/ids/%d", c.baseURL, id), nil)
+	if err != nil {
+		return "", err
+	}
+	c.authenticate(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("schema registry error: %s", body)
+	}
+
+	var payload struct {
+		Schema string `json:"schema"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", err
+	}
+	return payload.Schema, nil
+}
+
+// Ping verifies the registry is reachable by listing its registered subjects.
+func (c *SchemaRegistryClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/subjects", nil)
+	if err != nil {
+		return err
+	}
+	c.authenticate(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("schema registry error: %s", body)
+	}
+	return nil
+}
+
+// EnsureSchema ensures a schema subject exists under format and returns its schema ID. A
+// Schema Registry subject is single-format, so format is simply echoed back on success: it
+// is whatever format the subject was (or is being) registered with.
+func (c *SchemaRegistryClient) EnsureSchema(ctx context.Context, subject, schema string, format SchemaFormat) (int, SchemaFormat, error) {
 	if id, err := c.fetchLatest(ctx, subject); err == nil {
-		return id, nil
+		return id, format, nil
 	}
 
-	return c.register(ctx, subject, schema)
+	id, err := c.register(ctx, subject, schema, format)
+	return id, format, err
+}
+
+// LatestSchemaID resolves subject to the schema ID of its latest registered version. Unlike
+// EnsureSchema, it never registers a new version on a miss - it's for producer-side callers
+// (persistence/postgres.Repository) that expect the subject to already be registered rather
+// than owning its registration.
+func (c *SchemaRegistryClient) LatestSchemaID(ctx context.Context, subject string) (int, error) {
+	return c.fetchLatest(ctx, subject)
 }
 
 func (c *SchemaRegistryClient) fetchLatest(ctx context.Context, subject string) (int, error) {
@@ -40,6 +137,7 @@ func (c *SchemaRegistryClient) fetchLatest(ctx context.Context, subject string)
 	if err != nil {
 		return 0, err
 	}
+	c.authenticate(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -64,9 +162,93 @@ func (c *SchemaRegistryClient) fetchLatest(ctx context.Context, subject string)
 	return payload.ID, nil
 }
 
-func (c *SchemaRegistryClient) register(ctx context.Context, subject string, schema string) (int, error) {
+// CompatibilityLevel is one of Schema Registry's per-subject compatibility modes.
+type CompatibilityLevel string
+
+const (
+	CompatibilityBackward           CompatibilityLevel = "BACKWARD"
+	CompatibilityBackwardTransitive CompatibilityLevel = "BACKWARD_TRANSITIVE"
+	CompatibilityForward            CompatibilityLevel = "FORWARD"
+	CompatibilityForwardTransitive  CompatibilityLevel = "FORWARD_TRANSITIVE"
+	CompatibilityFull               CompatibilityLevel = "FULL"
+	CompatibilityFullTransitive     CompatibilityLevel = "FULL_TRANSITIVE"
+	CompatibilityNone               CompatibilityLevel = "NONE"
+)
+
+// CheckCompatibility reports whether schema is compatible with subject's latest registered
+// version under its configured compatibility level, without registering it. Callers should fail
+// closed on a transport error: an unreachable registry means compatibility is unknown, not
+// granted.
+func (c *SchemaRegistryClient) CheckCompatibility(ctx context.Context, subject, schema string) (bool, error) {
+	body, err := json.Marshal(map[string]any{"schema": schema})
+	if err != nil {
+		return false, err
+	}
+
+	url := fmt.Sprintf("%s/compatibility/subjects/%s/versions/latest", c.baseURL, subject)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	c.authenticate(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		// No prior version to compare against, so there's nothing to be incompatible with.
+		return true, nil
+	}
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(resp.Body)
+		return false, fmt.Errorf("schema registry compatibility check error: %s", data)
+	}
+
+	var payload struct {
+		IsCompatible bool `json:"is_compatible"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return false, err
+	}
+	return payload.IsCompatible, nil
+}
+
+// SetCompatibilityLevel pins subject's compatibility mode, overriding the registry's global
+// default for that subject alone.
+func (c *SchemaRegistryClient) SetCompatibilityLevel(ctx context.Context, subject string, level CompatibilityLevel) error {
+	body, err := json.Marshal(map[string]any{"compatibility": string(level)})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/config/%s", c.baseURL, subject)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	c.authenticate(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("schema registry set compatibility error: %s", data)
+	}
+	return nil
+}
+
+func (c *SchemaRegistryClient) register(ctx context.Context, subject, schema string, format SchemaFormat) (int, error) {
 	body, err := json.Marshal(map[string]any{
-		"schemaType": "JSON",
+		"schemaType": string(format),
 		"schema":     schema,
 	})
 	if err != nil {
@@ -78,6 +260,7 @@ func (c *SchemaRegistryClient) register(ctx context.Context, subject string, sch
 		return 0, err
 	}
 	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	c.authenticate(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
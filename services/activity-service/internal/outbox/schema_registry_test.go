@@ -0,0 +1,78 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCompatibilityReturnsRegistryVerdict(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/compatibility/subjects/activity-events/versions/latest", r.URL.Path)
+		_ = json.NewEncoder(w).Encode(map[string]bool{"is_compatible": false})
+	}))
+	defer srv.Close()
+
+	client := NewSchemaRegistryClient(srv.URL)
+	compatible, err := client.CheckCompatibility(context.Background(), "activity-events", `{"type":"record"}`)
+	require.NoError(t, err)
+	require.False(t, compatible)
+}
+
+func TestCheckCompatibilityTreatsMissingSubjectAsCompatible(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	client := NewSchemaRegistryClient(srv.URL)
+	compatible, err := client.CheckCompatibility(context.Background(), "new-subject", `{"type":"record"}`)
+	require.NoError(t, err)
+	require.True(t, compatible)
+}
+
+func TestSetCompatibilityLevelSendsConfiguredLevel(t *testing.T) {
+	var body map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		require.Equal(t, "/config/activity-events", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+	}))
+	defer srv.Close()
+
+	client := NewSchemaRegistryClient(srv.URL)
+	err := client.SetCompatibilityLevel(context.Background(), "activity-events", CompatibilityFull)
+	require.NoError(t, err)
+	require.Equal(t, "FULL", body["compatibility"])
+}
+
+func TestPingSucceedsWhenSubjectsListable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/subjects", r.URL.Path)
+		_ = json.NewEncoder(w).Encode([]string{"activity-events-value"})
+	}))
+	defer srv.Close()
+
+	client := NewSchemaRegistryClient(srv.URL)
+	require.NoError(t, client.Ping(context.Background()))
+}
+
+func TestPingReturnsErrorOnRegistryFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	client := NewSchemaRegistryClient(srv.URL)
+	require.Error(t, client.Ping(context.Background()))
+}
+
+func TestSubjectNameStrategies(t *testing.T) {
+	require.Equal(t, "activity_events-value", TopicNameStrategy{}.Subject("activity_events", "ActivityCreated"))
+	require.Equal(t, "ActivityCreated", RecordNameStrategy{}.Subject("activity_events", "ActivityCreated"))
+	require.Equal(t, "activity_events-ActivityCreated", TopicRecordNameStrategy{}.Subject("activity_events", "ActivityCreated"))
+}
@@ -17,17 +17,38 @@ const activityCreatedSchema = `{
   "additionalProperties": false
 }`
 
-const activityStateChangedSchema = `{
-  "type": "object",
-  "title": "ActivityStateChanged",
-  "properties": {
-    "activity_id": {"type": "string"},
-    "tenant_id": {"type": "string"},
-    "user_id": {"type": "string"},
-    "state": {"type": "string"},
-    "occurred_at": {"type": "string", "format": "date-time"},
-    "reason": {"type": "string"}
-  },
-  "required": ["activity_id", "tenant_id", "user_id", "state", "occurred_at"],
-  "additionalProperties": false
+// activityCreatedAvroSchema is the Avro record definition for the ActivityCreated contract,
+// kept alongside activityCreatedSchema for consumers migrating off JSON Schema. It isn't
+// wired into schemaCatalog yet - activity.state_changed is the first subject cut over to
+// AVRO below - but ships now so the subject can be registered ahead of that migration.
+const activityCreatedAvroSchema = `{
+  "type": "record",
+  "name": "ActivityCreated",
+  "namespace": "com.example.activity",
+  "fields": [
+    {"name": "activity_id", "type": "string"},
+    {"name": "tenant_id", "type": "string"},
+    {"name": "user_id", "type": "string"},
+    {"name": "activity_type", "type": "string"},
+    {"name": "started_at", "type": "string"},
+    {"name": "duration_min", "type": "int"},
+    {"name": "source", "type": "string"},
+    {"name": "version", "type": "string"}
+  ]
+}`
+
+// activityStateChangedAvroSchema is the Avro record definition registered for the
+// activity.state_changed subject, the first catalog entry to use AVRO instead of JSON Schema.
+const activityStateChangedAvroSchema = `{
+  "type": "record",
+  "name": "ActivityStateChanged",
+  "namespace": "com.example.activity",
+  "fields": [
+    {"name": "activity_id", "type": "string"},
+    {"name": "tenant_id", "type": "string"},
+    {"name": "user_id", "type": "string"},
+    {"name": "state", "type": "string"},
+    {"name": "occurred_at", "type": "string"},
+    {"name": "reason", "type": ["null", "string"], "default": null}
+  ]
 }`
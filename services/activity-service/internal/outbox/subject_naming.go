@@ -0,0 +1,42 @@
+package outbox
+
+import "fmt"
+
+// SubjectNameStrategy computes the Schema Registry subject a schema is registered and looked up
+// under, given the Kafka topic a message is published to and the fully-qualified record name of
+// its schema (the Avro/Protobuf message name; empty for JSON schemas, which have none). This
+// mirrors Confluent's subject naming strategies, letting the outbox register one subject per
+// event type instead of being locked into "subject == topic".
+type SubjectNameStrategy interface {
+	Subject(topic, recordName string) string
+}
+
+// TopicNameStrategy is Schema Registry's default: one subject per topic, shared by every event
+// type published to it. This is the convention the outbox used before SubjectNameStrategy
+// existed, so it remains the zero-value-compatible default.
+type TopicNameStrategy struct{}
+
+// Subject returns "{topic}-value", ignoring recordName.
+func (TopicNameStrategy) Subject(topic, recordName string) string {
+	return topic + "-value"
+}
+
+// RecordNameStrategy registers one subject per record name, regardless of which topic (or
+// topics) carry it. Use this when multiple event types sharing a topic must evolve their
+// schemas independently.
+type RecordNameStrategy struct{}
+
+// Subject returns recordName, ignoring topic.
+func (RecordNameStrategy) Subject(topic, recordName string) string {
+	return recordName
+}
+
+// TopicRecordNameStrategy registers one subject per (topic, record name) pair, so the same
+// record name can evolve independently on different topics while still letting multiple event
+// types share a topic without colliding on a single subject.
+type TopicRecordNameStrategy struct{}
+
+// Subject returns "{topic}-{recordName}".
+func (TopicRecordNameStrategy) Subject(topic, recordName string) string {
+	return fmt.Sprintf("%s-%s", topic, recordName)
+}
@@ -0,0 +1,81 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// TransactionalWriter delivers outbox batches to Kafka using an idempotent, transactional
+// producer. WriteMessages calls made between BeginTransaction and CommitTransaction are only
+// visible to read_committed consumers once the transaction commits, which is what lets
+// Dispatcher flip published_at only after Kafka has durably committed the whole batch.
+//
+// Unlike KafkaProducer, a TransactionalWriter is not safe for concurrent use: only one
+// transaction (and therefore one in-flight batch) may be open on a given instance at a time,
+// which matches how Dispatcher drives it — one batch fully resolved before the next begins.
+type TransactionalWriter struct {
+	client *kgo.Client
+}
+
+// NewTransactionalWriter constructs a TransactionalWriter. transactional.id is derived
+// deterministically from instanceID (rather than randomly generated) so that if the process
+// restarts under the same instance ID, the broker fences out any zombie producer left over
+// from the previous run instead of letting both commit.
+func NewTransactionalWriter(brokers []string, instanceID string) (*TransactionalWriter, error) {
+	client, err := kgo.NewClient(
+		kgo.SeedBrokers(brokers...),
+		kgo.TransactionalID(fmt.Sprintf("activity-outbox-dispatcher-%s", instanceID)),
+		kgo.TransactionTimeout(time.Minute),
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+		kgo.ProducerBatchCompression(kgo.SnappyCompression()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: construct transactional kafka client: %w", err)
+	}
+	return &TransactionalWriter{client: client}, nil
+}
+
+// BeginTransaction opens a new Kafka transaction covering the WriteMessages calls that follow.
+func (w *TransactionalWriter) BeginTransaction(ctx context.Context) error {
+	return w.client.BeginTransaction()
+}
+
+// WriteMessages produces msgs to topic within the currently open transaction.
+func (w *TransactionalWriter) WriteMessages(ctx context.Context, topic string, msgs ...kafka.Message) error {
+	records := make([]*kgo.Record, len(msgs))
+	for i, msg := range msgs {
+		headers := make([]kgo.RecordHeader, len(msg.Headers))
+		for j, h := range msg.Headers {
+			headers[j] = kgo.RecordHeader{Key: h.Key, Value: h.Value}
+		}
+		records[i] = &kgo.Record{
+			Topic:     topic,
+			Key:       msg.Key,
+			Value:     msg.Value,
+			Headers:   headers,
+			Timestamp: msg.Time,
+		}
+	}
+	return w.client.ProduceSync(ctx, records...).FirstErr()
+}
+
+// CommitTransaction commits the open transaction, making every message written since
+// BeginTransaction visible to read_committed consumers.
+func (w *TransactionalWriter) CommitTransaction(ctx context.Context) error {
+	return w.client.EndTransaction(ctx, kgo.TryCommit)
+}
+
+// AbortTransaction discards the open transaction; none of its writes become visible.
+func (w *TransactionalWriter) AbortTransaction(ctx context.Context) error {
+	return w.client.EndTransaction(ctx, kgo.TryAbort)
+}
+
+// Close releases the underlying Kafka client.
+func (w *TransactionalWriter) Close() error {
+	w.client.Close()
+	return nil
+}
@@ -2,7 +2,10 @@
 package persistence
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -10,31 +13,100 @@ import (
 	"example.com/activity/internal/domain"
 )
 
-// EncodeCursor serialises the cursor to a string token.
-func EncodeCursor(c *domain.Cursor) string {
-	if c == nil {
+// cursorVersion is the prefix on every token this codec issues. Decode rejects
+// any other prefix outright, so a future encoding change can roll out as v2
+// without colliding with v1 tokens still in flight.
+const cursorVersion = "v1"
+
+// ErrInvalidCursor is returned for tokens that are malformed, carry an unknown
+// version, or fail MAC verification.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorCodec encodes and verifies HMAC-signed pagination cursors. Plain
+// base64 cursors let a client forge started_at|id pairs to skip past other
+// tenants' records or force a full table scan, so every token carries a
+// keyed HMAC-SHA256 tag over its payload.
+//
+// The first key is used for signing; any additional keys are accepted for
+// verification only, so a key can be rotated by prepending the new key and
+// keeping the old one around until outstanding cursors expire.
+type CursorCodec struct {
+	keys [][]byte
+}
+
+// NewCursorCodec constructs a CursorCodec. signingKey signs new tokens;
+// verificationKeys (if any) are additionally accepted when verifying, to
+// support rotation.
+func NewCursorCodec(signingKey []byte, verificationKeys ...[]byte) *CursorCodec {
+	keys := make([][]byte, 0, 1+len(verificationKeys))
+	keys = append(keys, signingKey)
+	keys = append(keys, verificationKeys...)
+	return &CursorCodec{keys: keys}
+}
+
+// Encode serialises and signs c, producing a token of the form
+// v1.<base64(payload)>.<base64(mac)>. A nil cursor encodes to "".
+func (c *CursorCodec) Encode(cur *domain.Cursor) string {
+	if cur == nil {
 		return ""
 	}
-	raw := fmt.Sprintf("%s|%s", c.StartedAt.UTC().Format(time.RFC3339Nano), c.ID)
-	return base64.StdEncoding.EncodeToString([]byte(raw))
+	payload := fmt.Sprintf("%s|%s", cur.StartedAt.UTC().Format(time.RFC3339Nano), cur.ID)
+	mac := c.sign([]byte(payload))
+	return fmt.Sprintf("%s.%s.%s", cursorVersion,
+		base64.StdEncoding.EncodeToString([]byte(payload)),
+		base64.StdEncoding.EncodeToString(mac))
 }
 
-// DecodeCursor parses the encoded cursor token.
-func DecodeCursor(token string) (*domain.Cursor, error) {
+// Decode parses and verifies an encoded cursor token. An empty token decodes
+// to (nil, nil). Any malformed token, unknown version prefix, or MAC mismatch
+// returns ErrInvalidCursor rather than a lower-level parse error, so callers
+// don't need to distinguish tampering from a bad request.
+func (c *CursorCodec) Decode(token string) (*domain.Cursor, error) {
 	if strings.TrimSpace(token) == "" {
 		return nil, nil
 	}
-	decoded, err := base64.StdEncoding.DecodeString(token)
+
+	parts := strings.SplitN(token, ".", 3)
+	if len(parts) != 3 || parts[0] != cursorVersion {
+		return nil, ErrInvalidCursor
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	mac, err := base64.StdEncoding.DecodeString(parts[2])
 	if err != nil {
-		return nil, err
+		return nil, ErrInvalidCursor
 	}
-	parts := strings.SplitN(string(decoded), "|", 2)
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid cursor format")
+	if !c.verify(payload, mac) {
+		return nil, ErrInvalidCursor
 	}
-	ts, err := time.Parse(time.RFC3339Nano, parts[0])
+
+	fields := strings.SplitN(string(payload), "|", 2)
+	if len(fields) != 2 {
+		return nil, ErrInvalidCursor
+	}
+	ts, err := time.Parse(time.RFC3339Nano, fields[0])
 	if err != nil {
-		return nil, err
+		return nil, ErrInvalidCursor
+	}
+	return &domain.Cursor{StartedAt: ts, ID: fields[1]}, nil
+}
+
+func (c *CursorCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.keys[0])
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func (c *CursorCodec) verify(payload, mac []byte) bool {
+	for _, key := range c.keys {
+		candidate := hmac.New(sha256.New, key)
+		candidate.Write(payload)
+		if hmac.Equal(candidate.Sum(nil), mac) {
+			return true
+		}
 	}
-	return &domain.Cursor{StartedAt: ts, ID: parts[1]}, nil
+	return false
 }
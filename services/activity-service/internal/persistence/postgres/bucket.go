@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// sharedSchema is the Postgres schema every tenant lives in until it's assigned a dedicated
+// bucket, and remains the home of the tenant_buckets registry table itself.
+const sharedSchema = "public"
+
+// BucketResolver maps a tenant ID to the Postgres schema holding that tenant's activities,
+// outbox, and outbox_dlq tables. Most tenants share sharedSchema and rely on row-level
+// security (withTenant's set_config call) for isolation; a tenant with its own bucket gets
+// physical isolation instead, per the registry BucketManager.EnsureBucket populates.
+type BucketResolver interface {
+	SchemaFor(ctx context.Context, tenantID string) (string, error)
+}
+
+// SharedSchemaResolver is the default BucketResolver: every tenant resolves to sharedSchema.
+type SharedSchemaResolver struct{}
+
+// SchemaFor always returns sharedSchema.
+func (SharedSchemaResolver) SchemaFor(ctx context.Context, tenantID string) (string, error) {
+	return sharedSchema, nil
+}
+
+// rowQueryer is satisfied by *pgxpool.Pool and pgx.Tx, so RegistryBucketResolver can be backed
+// by either a standalone pool or a transaction a caller already holds.
+type rowQueryer interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// RegistryBucketResolver looks tenants up in the tenant_buckets registry table, caching hits
+// for the lifetime of the resolver since a tenant's bucket assignment never changes once made.
+// Tenants with no row stay on sharedSchema.
+type RegistryBucketResolver struct {
+	db    rowQueryer
+	cache sync.Map // tenantID (string) -> schema name (string)
+}
+
+// NewRegistryBucketResolver returns a BucketResolver backed by the tenant_buckets table in db.
+func NewRegistryBucketResolver(db rowQueryer) *RegistryBucketResolver {
+	return &RegistryBucketResolver{db: db}
+}
+
+// SchemaFor resolves tenantID's schema, defaulting to sharedSchema when no bucket is assigned.
+func (r *RegistryBucketResolver) SchemaFor(ctx context.Context, tenantID string) (string, error) {
+	if cached, ok := r.cache.Load(tenantID); ok {
+		return cached.(string), nil
+	}
+
+	var schema string
+	err := r.db.QueryRow(ctx, `SELECT schema_name FROM tenant_buckets WHERE tenant_id = $1::uuid`, tenantID).Scan(&schema)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sharedSchema, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	r.cache.Store(tenantID, schema)
+	return schema, nil
+}
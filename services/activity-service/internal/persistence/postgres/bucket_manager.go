@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// registryMigrationFile is applied once, centrally, against the shared "public" schema (see
+// testsupport.applyMigrations for the equivalent runner used in integration tests) and is
+// never replayed into a tenant's bucket schema - a bucket only needs the tenant-data tables
+// (activities, outbox, outbox_dlq), not a second copy of the registry itself.
+const registryMigrationFile = "0001_tenant_buckets.up.sql"
+
+// BucketManager provisions dedicated Postgres schemas ("buckets") for tenants that need
+// physical isolation from the shared schema, and keeps the tenant_buckets registry table in
+// sync with what's been provisioned.
+type BucketManager struct {
+	pool          *pgxpool.Pool
+	migrationsDir string
+}
+
+// NewBucketManager returns a BucketManager that provisions schemas in pool, replaying
+// migrations found in migrationsDir against each new bucket.
+func NewBucketManager(pool *pgxpool.Pool, migrationsDir string) *BucketManager {
+	return &BucketManager{pool: pool, migrationsDir: migrationsDir}
+}
+
+// schemaNameForTenant derives a bucket's schema name from a tenant ID.
+func schemaNameForTenant(tenantID string) string {
+	return "tenant_" + strings.ReplaceAll(tenantID, "-", "_")
+}
+
+// EnsureBucket creates tenantID's dedicated schema if it doesn't already exist, replays the
+// tenant-data migration set against it (so it ends up with the same activities/outbox/
+// outbox_dlq DDL as the shared schema), and records the assignment in tenant_buckets. It is
+// idempotent: calling it again for a tenant that already has a bucket re-applies any
+// migrations added since and refreshes migration_version.
+func (m *BucketManager) EnsureBucket(ctx context.Context, tenantID string) (string, error) {
+	schema := schemaNameForTenant(tenantID)
+
+	files, err := bucketMigrationFiles(m.migrationsDir)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", pgx.Identifier{schema}.Sanitize())); err != nil {
+		return "", fmt.Errorf("create schema %s: %w", schema, err)
+	}
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL search_path TO %s, public", pgx.Identifier{schema}.Sanitize())); err != nil {
+		return "", err
+	}
+
+	for _, file := range files {
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("read migration %s: %w", file, err)
+		}
+		if _, err := tx.Exec(ctx, string(contents)); err != nil {
+			return "", fmt.Errorf("apply migration %s to schema %s: %w", filepath.Base(file), schema, err)
+		}
+	}
+
+	const upsert = `INSERT INTO tenant_buckets (tenant_id, schema_name, migration_version, updated_at)
+        VALUES ($1::uuid, $2, $3, NOW())
+        ON CONFLICT (tenant_id) DO UPDATE SET
+            schema_name = EXCLUDED.schema_name,
+            migration_version = EXCLUDED.migration_version,
+            updated_at = NOW()`
+	if _, err := tx.Exec(ctx, upsert, tenantID, schema, len(files)); err != nil {
+		return "", fmt.Errorf("record tenant_buckets row: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return schema, nil
+}
+
+// bucketMigrationFiles returns the sorted *.up.sql files in dir that define tenant-data
+// tables, excluding registryMigrationFile.
+func bucketMigrationFiles(dir string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+
+	files := make([]string, 0, len(matches))
+	for _, file := range matches {
+		if filepath.Base(file) == registryMigrationFile {
+			continue
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
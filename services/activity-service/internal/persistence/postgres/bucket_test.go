@@ -0,0 +1,18 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaNameForTenantReplacesDashes(t *testing.T) {
+	require.Equal(t, "tenant_9f8c6b2e_1111_4a2b_8c3d_abcdefabcdef", schemaNameForTenant("9f8c6b2e-1111-4a2b-8c3d-abcdefabcdef"))
+}
+
+func TestSharedSchemaResolverAlwaysReturnsPublic(t *testing.T) {
+	schema, err := SharedSchemaResolver{}.SchemaFor(context.Background(), "any-tenant")
+	require.NoError(t, err)
+	require.Equal(t, "public", schema)
+}
@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"example.com/activity/internal/domain"
+)
+
+// cloudEventsSpecVersion is the only CloudEvents spec version this package produces.
+const cloudEventsSpecVersion = "1.0"
+
+// cloudEventsSource identifies this service as the CloudEvents "source" attribute, per the
+// spec's recommendation of a URI-reference unique to the producing service.
+const cloudEventsSource = "example.com/activity-service"
+
+// CloudEvent is the CloudEvents 1.0 JSON envelope Repository wraps every outbox payload in, so
+// consumers get occurredAt, trace correlation, and a versioned schema reference without
+// memorizing per-topic conventions. TenantID and UserID are carried as CloudEvents extension
+// attributes (lowercase alphanumeric, per spec); TraceParent is the registered "distributed
+// tracing" extension. Data holds the event body verbatim; DataBase64 is used instead once the
+// body has been Confluent-framed and is no longer valid JSON (the CloudEvents JSON format
+// reserves data_base64 for exactly this case).
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	DataSchema      string          `json:"dataschema,omitempty"`
+	TraceParent     string          `json:"traceparent,omitempty"`
+	TenantID        string          `json:"tenantid"`
+	UserID          string          `json:"userid"`
+	Data            json.RawMessage `json:"data,omitempty"`
+	DataBase64      string          `json:"data_base64,omitempty"`
+}
+
+// Codec selects how an EventMetadata's PayloadVersion schema is encoded inside a CloudEvents
+// envelope's data field.
+type Codec string
+
+const (
+	// CodecJSON stores the event body as plain JSON in the envelope's data field when no
+	// SchemaRegistry is configured; with one, the body is Confluent-framed into data_base64
+	// just as for CodecAvro, and only datacontenttype differs.
+	CodecJSON Codec = "JSON"
+	// CodecAvro stores the event body Confluent-framed (once a SchemaRegistry is configured)
+	// in the envelope's data_base64 field, matching the wire format internal/outbox's
+	// Dispatcher expects for Avro subjects.
+	CodecAvro Codec = "AVRO"
+)
+
+// contentType returns the datacontenttype attribute for an envelope encoded with c.
+func (c Codec) contentType() string {
+	if c == CodecAvro {
+		return "application/avro"
+	}
+	return "application/json"
+}
+
+// SchemaRegistry resolves a schema subject to its currently registered schema ID. It's narrower
+// than internal/outbox's Registry interface on purpose: this package never registers a schema
+// itself (that's the relay's or an out-of-band CI job's responsibility), it only needs to stamp
+// the right ID onto the envelope it writes. *outbox.SchemaRegistryClient's LatestSchemaID method
+// satisfies this without persistence importing the outbox package.
+type SchemaRegistry interface {
+	LatestSchemaID(ctx context.Context, subject string) (int, error)
+}
+
+// buildCloudEvent wraps payload in a CloudEvent envelope for aggregate and eventType, resolving
+// and caching meta's schema ID against r.registry when one is configured, and framing the data
+// field with the Confluent 5-byte magic-byte+ID header expected by registry-aware consumers.
+func (r *Repository) buildCloudEvent(ctx context.Context, aggregate domain.ActivityAggregate, meta EventMetadata, eventType string, payload interface{}) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := CloudEvent{
+		SpecVersion: cloudEventsSpecVersion,
+		ID:          uuid.NewString(),
+		Source:      cloudEventsSource,
+		Type:        eventType,
+		Time:        time.Now().UTC(),
+		DataSchema:  fmt.Sprintf("%s/%s", meta.SchemaSubject, meta.PayloadVersion),
+		TraceParent: traceParentFromContext(ctx),
+		TenantID:    aggregate.TenantID,
+		UserID:      aggregate.UserID,
+	}
+
+	if r.registry == nil {
+		envelope.DataContentType = meta.Codec.contentType()
+		envelope.Data = data
+		return json.Marshal(envelope)
+	}
+
+	schemaID, err := r.schemaIDFor(ctx, meta.SchemaSubject)
+	if err != nil {
+		return nil, err
+	}
+	envelope.DataContentType = meta.Codec.contentType()
+	envelope.DataBase64 = base64.StdEncoding.EncodeToString(encodeConfluentFrame(schemaID, data))
+	return json.Marshal(envelope)
+}
+
+// schemaIDFor resolves subject's schema ID through r.registry, caching the result in
+// r.schemaIDCache so repeated Create calls for the same event type don't round-trip to the
+// registry. WarmSchemaCache populates this cache eagerly at startup; this is the lazy fallback
+// for any subject that wasn't (or couldn't be) warmed.
+func (r *Repository) schemaIDFor(ctx context.Context, subject string) (int, error) {
+	if id, ok := r.schemaIDCache.Load(subject); ok {
+		return id.(int), nil
+	}
+
+	id, err := r.registry.LatestSchemaID(ctx, subject)
+	if err != nil {
+		return 0, err
+	}
+	r.schemaIDCache.Store(subject, id)
+	return id, nil
+}
+
+// WarmSchemaCache resolves every catalog entry's schema subject through r.registry up front, so
+// the first Create call for each event type doesn't pay a registry round-trip. Intended to be
+// called once at service startup when a SchemaRegistry is configured; it's a no-op otherwise.
+func (r *Repository) WarmSchemaCache(ctx context.Context) error {
+	if r.registry == nil {
+		return nil
+	}
+	for _, meta := range eventCatalog {
+		if _, err := r.schemaIDFor(ctx, meta.SchemaSubject); err != nil {
+			return fmt.Errorf("warm schema cache for subject %s: %w", meta.SchemaSubject, err)
+		}
+	}
+	return nil
+}
+
+// encodeConfluentFrame applies the Confluent wire format's 5-byte magic-byte+schema-ID header,
+// mirroring internal/outbox's encodeWireFormat so both the producer and relay sides of the
+// outbox frame registry-aware payloads identically.
+func encodeConfluentFrame(schemaID int, payload []byte) []byte {
+	frame := make([]byte, 5+len(payload))
+	frame[0] = 0
+	binary.BigEndian.PutUint32(frame[1:5], uint32(schemaID))
+	copy(frame[5:], payload)
+	return frame
+}
+
+// traceParentFromContext extracts the W3C traceparent value for the span active on ctx (if
+// any), so a CloudEvent created inside a traced request carries its trace correlation into the
+// outbox and on to whatever consumes it downstream.
+func traceParentFromContext(ctx context.Context) string {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ""
+	}
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return carrier.Get("traceparent")
+}
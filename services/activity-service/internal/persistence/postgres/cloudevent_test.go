@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"example.com/activity/internal/domain"
+)
+
+func TestBuildCloudEventWithoutRegistryStoresPlainJSONData(t *testing.T) {
+	r := &Repository{}
+	aggregate := domain.ActivityAggregate{TenantID: "tenant-1", UserID: "user-1"}
+	meta := eventCatalog["activity.created"]
+
+	body, err := r.buildCloudEvent(context.Background(), aggregate, meta, "activity.created", map[string]string{"foo": "bar"})
+	require.NoError(t, err)
+
+	var envelope CloudEvent
+	require.NoError(t, json.Unmarshal(body, &envelope))
+	require.Equal(t, cloudEventsSpecVersion, envelope.SpecVersion)
+	require.Equal(t, "activity.created", envelope.Type)
+	require.Equal(t, "tenant-1", envelope.TenantID)
+	require.Equal(t, "user-1", envelope.UserID)
+	require.Equal(t, "application/json", envelope.DataContentType)
+	require.JSONEq(t, `{"foo":"bar"}`, string(envelope.Data))
+	require.Empty(t, envelope.DataBase64)
+}
+
+type fakeSchemaRegistry struct {
+	id int
+}
+
+func (f fakeSchemaRegistry) LatestSchemaID(ctx context.Context, subject string) (int, error) {
+	return f.id, nil
+}
+
+func TestBuildCloudEventWithRegistryFramesDataBase64(t *testing.T) {
+	r := &Repository{registry: fakeSchemaRegistry{id: 7}}
+	aggregate := domain.ActivityAggregate{TenantID: "tenant-1", UserID: "user-1"}
+	meta := eventCatalog["activity.state_changed"]
+
+	body, err := r.buildCloudEvent(context.Background(), aggregate, meta, "activity.state_changed", map[string]string{"state": "synced"})
+	require.NoError(t, err)
+
+	var envelope CloudEvent
+	require.NoError(t, json.Unmarshal(body, &envelope))
+	require.Empty(t, envelope.Data)
+	require.NotEmpty(t, envelope.DataBase64)
+	require.Equal(t, "application/avro", envelope.DataContentType)
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Dialect abstracts the handful of places Repository's SQL and session setup diverge between
+// Postgres and CockroachDB: tenant scoping (RLS via set_config on Postgres, since CRDB doesn't
+// honor session GUCs inside RLS policies the same way and every generated query already carries
+// an explicit tenant_id predicate anyway), the DLQ lookup shape (LATERAL joins CRDB's
+// distributed planner handles poorly, vs. a correlated subquery), and which errors represent a
+// transient serialization conflict worth retrying.
+type Dialect interface {
+	// ScopeTenant prepares tx for queries scoped to tenantID before fn runs. On Postgres this
+	// sets app.tenant_id so RLS policies apply; on Cockroach it's a no-op.
+	ScopeTenant(ctx context.Context, tx pgx.Tx, tenantID string) error
+	// DLQJoin returns the join clause correlating an "activities AS a" row with its most recent
+	// outbox_dlq entry, and the expression (referencing the join's "dlq" alias) that computes
+	// replay_available from it.
+	DLQJoin() (join, replayExpr string)
+	// IsRetryableSerializationError reports whether err is a transient serialization conflict
+	// worth retrying with the same parameters.
+	IsRetryableSerializationError(err error) bool
+}
+
+// serializationFailureCode is the SQLSTATE both Postgres and CockroachDB use for a transaction
+// aborted by a serialization conflict.
+const serializationFailureCode = "40001"
+
+// PostgresDialect is the default Dialect, matching the schema and RLS policies the baseline
+// migrations define.
+type PostgresDialect struct{}
+
+// ScopeTenant sets app.tenant_id so the activities/outbox/outbox_dlq RLS policies apply for the
+// rest of tx.
+func (PostgresDialect) ScopeTenant(ctx context.Context, tx pgx.Tx, tenantID string) error {
+	_, err := tx.Exec(ctx, "SELECT set_config('app.tenant_id', $1, true)", tenantID)
+	return err
+}
+
+// DLQJoin correlates via a LATERAL subquery, which Postgres's planner handles well for
+// one-row-per-activity lookups.
+func (PostgresDialect) DLQJoin() (string, string) {
+	join := `LEFT JOIN LATERAL (
+    SELECT reason, next_retry_at, quarantined_at, (quarantined_at IS NULL) AS replay_available
+    FROM outbox_dlq
+    WHERE aggregate_type = 'activity' AND aggregate_id = a.activity_id::text
+    ORDER BY created_at DESC
+    LIMIT 1
+) AS dlq ON TRUE`
+	return join, "COALESCE(dlq.replay_available, FALSE)::boolean"
+}
+
+// IsRetryableSerializationError always returns false: under this service's default READ
+// COMMITTED isolation, Postgres never returns a serialization failure for Create.
+func (PostgresDialect) IsRetryableSerializationError(err error) bool {
+	return false
+}
+
+// CockroachDialect targets CockroachDB, which runs every transaction at SERIALIZABLE isolation
+// and expects retries on 40001, doesn't apply RLS policies driven by session GUCs, and
+// optimizes correlated subqueries better than LATERAL joins under its distributed planner.
+type CockroachDialect struct{}
+
+// ScopeTenant is a no-op: CRDB doesn't enforce RLS policies via set_config the way Postgres
+// does, and every generated query already filters by tenant_id explicitly.
+func (CockroachDialect) ScopeTenant(ctx context.Context, tx pgx.Tx, tenantID string) error {
+	return nil
+}
+
+// DLQJoin correlates via a scalar subquery instead of LATERAL, which CRDB's optimizer plans as
+// a single lookup join rather than a per-row apply.
+func (CockroachDialect) DLQJoin() (string, string) {
+	join := `LEFT JOIN outbox_dlq AS dlq ON dlq.dlq_id = (
+    SELECT dlq2.dlq_id
+    FROM outbox_dlq AS dlq2
+    WHERE dlq2.aggregate_type = 'activity' AND dlq2.aggregate_id = a.activity_id::text
+    ORDER BY dlq2.created_at DESC
+    LIMIT 1
+)`
+	return join, "COALESCE(dlq.quarantined_at IS NULL, FALSE)::boolean"
+}
+
+// IsRetryableSerializationError reports whether err is a Postgres-wire-protocol error carrying
+// CRDB's serialization_failure SQLSTATE, which every CRDB transaction must be prepared to retry.
+func (CockroachDialect) IsRetryableSerializationError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == serializationFailureCode
+	}
+	return false
+}
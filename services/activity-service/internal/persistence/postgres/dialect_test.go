@@ -0,0 +1,20 @@
+package postgres
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDialectNeverRetriesSerializationErrors(t *testing.T) {
+	err := &pgconn.PgError{Code: serializationFailureCode}
+	require.False(t, PostgresDialect{}.IsRetryableSerializationError(err))
+}
+
+func TestCockroachDialectRetriesOnlySerializationFailureCode(t *testing.T) {
+	require.True(t, CockroachDialect{}.IsRetryableSerializationError(&pgconn.PgError{Code: serializationFailureCode}))
+	require.False(t, CockroachDialect{}.IsRetryableSerializationError(&pgconn.PgError{Code: "23505"}))
+	require.False(t, CockroachDialect{}.IsRetryableSerializationError(errors.New("not a pg error")))
+}
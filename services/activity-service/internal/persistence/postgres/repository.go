@@ -2,132 +2,174 @@ package postgres
 
 import (
 	"context"
-	"database/sql"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	"example.com/activity/internal/domain"
 	"example.com/activity/internal/observability"
 	platformevents "example.com/platform/libs/go/events"
 )
 
-// Repository provides Postgres-backed persistence for activities and outbox events.
+// DataStore is satisfied by both *pgxpool.Pool and pgx.Tx, so Repository's methods can run
+// against a fresh connection from the pool or compose into a transaction the caller already
+// holds (e.g. an HTTP handler that creates an activity and updates a projection table
+// atomically, or a saga step). Both types already implement Begin(ctx) (pgx.Tx, error): on the
+// pool it opens a new transaction; on a Tx it opens a nested transaction backed by a SAVEPOINT.
+type DataStore interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Repository provides Postgres-backed persistence for activities and outbox events, delegating
+// row scanning and parameter binding to the sqlc-generated Queries.
 type Repository struct {
-	pool *pgxpool.Pool
+	ds       DataStore
+	resolver BucketResolver
+	dialect  Dialect
+	registry SchemaRegistry
+
+	schemaIDCache sync.Map
 }
 
-// NewRepository constructs a Repository.
-func NewRepository(pool *pgxpool.Pool) *Repository {
-	return &Repository{pool: pool}
+// Option configures optional Repository behavior.
+type Option func(*Repository)
+
+// WithBucketResolver overrides the default SharedSchemaResolver, so tenants assigned a
+// dedicated bucket via the tenant_buckets registry get their activities, outbox, and
+// outbox_dlq tables resolved to their own schema instead of sharedSchema.
+func WithBucketResolver(resolver BucketResolver) Option {
+	return func(r *Repository) { r.resolver = resolver }
 }
 
-// FindByIdempotency checks if an activity already exists for the supplied idempotency key.
-func (r *Repository) FindByIdempotency(ctx context.Context, tenantID, userID, idempotencyKey string) (*domain.ActivityAggregate, error) {
-	if idempotencyKey == "" {
-		return nil, nil
-	}
+// WithDialect overrides the default PostgresDialect, so Repository can target CockroachDB
+// instead.
+func WithDialect(dialect Dialect) Option {
+	return func(r *Repository) { r.dialect = dialect }
+}
 
-	const query = `SELECT activity_id, tenant_id, user_id, activity_type, started_at, duration_min, source, version, processing_state, created_at, updated_at
-        FROM activities WHERE tenant_id=$1::uuid AND user_id=$2::uuid AND idempotency_key=$3`
+// WithSchemaRegistry configures a Confluent-compatible SchemaRegistry for Repository to resolve
+// each EventMetadata.SchemaSubject's schema ID against. Without one, Create still wraps outbox
+// payloads in a CloudEvents envelope, just without a Confluent-framed data field - the
+// CloudEvents datacontenttype is enough for a consumer to fall back to unframed JSON.
+func WithSchemaRegistry(registry SchemaRegistry) Option {
+	return func(r *Repository) { r.registry = registry }
+}
 
-	conn, err := r.pool.Acquire(ctx)
-	if err != nil {
-		return nil, err
+// NewRepository constructs a Repository backed by ds, typically a *pgxpool.Pool.
+func NewRepository(ds DataStore, opts ...Option) *Repository {
+	r := &Repository{ds: ds, resolver: SharedSchemaResolver{}, dialect: PostgresDialect{}}
+	for _, opt := range opts {
+		opt(r)
 	}
-	defer conn.Release()
+	return r
+}
 
-	tx, err := conn.Begin(ctx)
+// WithTx returns a Repository whose methods run inside tx instead of opening their own
+// transaction against the pool, so a caller already holding tx can compose a Repository call
+// with its own writes atomically.
+func (r *Repository) WithTx(tx pgx.Tx) *Repository {
+	return &Repository{ds: tx, resolver: r.resolver, dialect: r.dialect, registry: r.registry}
+}
+
+// withTenant begins a transaction (or, when r.ds is already a pgx.Tx, a nested transaction via
+// SAVEPOINT), applies dialect's tenant scoping, and points search_path at schema so the queries
+// sqlc generated - which reference tables by their bare name - resolve against the tenant's
+// bucket instead of always hitting sharedSchema. Both only need to run once per outer
+// transaction, so they're skipped when r.ds is itself a Tx - the caller's outer Begin already
+// applied them for the whole transaction. Commits on success, rolls back on any error
+// (including one returned by fn).
+func withTenant[T any](ctx context.Context, ds DataStore, dialect Dialect, tenantID, schema string, fn func(q *Queries) (T, error)) (T, error) {
+	var zero T
+
+	tx, err := ds.Begin(ctx)
 	if err != nil {
-		return nil, err
+		return zero, err
 	}
 	defer tx.Rollback(ctx)
 
-	if _, err := tx.Exec(ctx, "SELECT set_config('app.tenant_id', $1, true)", tenantID); err != nil {
-		return nil, err
-	}
-
-	row := tx.QueryRow(ctx, query, tenantID, userID, idempotencyKey)
-	var agg domain.ActivityAggregate
-	if err := row.Scan(&agg.ID, &agg.TenantID, &agg.UserID, &agg.ActivityType, &agg.StartedAt, &agg.DurationMin, &agg.Source, &agg.Version, &agg.State, &agg.CreatedAt, &agg.UpdatedAt); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, tx.Commit(ctx)
+	if _, alreadyInTx := ds.(pgx.Tx); !alreadyInTx {
+		if err := dialect.ScopeTenant(ctx, tx, tenantID); err != nil {
+			return zero, err
+		}
+		if _, err := tx.Exec(ctx, "SELECT set_config('search_path', $1, true)", schema+", public"); err != nil {
+			return zero, err
 		}
-		return nil, err
 	}
 
+	result, err := fn(New(tx))
+	if err != nil {
+		return zero, err
+	}
 	if err := tx.Commit(ctx); err != nil {
-		return nil, err
+		return zero, err
 	}
-	return &agg, nil
+	return result, nil
 }
 
-// Create persists the aggregate and records outbox events inside a single transaction.
-func (r *Repository) Create(ctx context.Context, aggregate domain.ActivityAggregate, idempotencyKey string) error {
-	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+// FindByIdempotency checks if an activity already exists for the supplied idempotency key.
+func (r *Repository) FindByIdempotency(ctx context.Context, tenantID, userID, idempotencyKey string) (*domain.ActivityAggregate, error) {
+	if idempotencyKey == "" {
+		return nil, nil
+	}
+
+	schema, err := r.resolver.SchemaFor(ctx, tenantID)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer func() {
+
+	return withTenant(ctx, r.ds, r.dialect, tenantID, schema, func(q *Queries) (*domain.ActivityAggregate, error) {
+		row, err := q.FindByIdempotency(ctx, FindByIdempotencyParams{
+			TenantID:       tenantID,
+			UserID:         userID,
+			IdempotencyKey: idempotencyKey,
+		})
 		if err != nil {
-			tx.Rollback(ctx)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, nil
+			}
+			return nil, err
 		}
-	}()
+		agg := domain.ActivityAggregate{
+			ID:              row.ActivityID,
+			TenantID:        row.TenantID,
+			UserID:          row.UserID,
+			ActivityType:    row.ActivityType,
+			StartedAt:       row.StartedAt.Time,
+			DurationMin:     int(row.DurationMin),
+			Source:          row.Source,
+			Version:         row.Version,
+			State:           domain.ActivityState(row.ProcessingState),
+			CreatedAt:       row.CreatedAt.Time,
+			UpdatedAt:       row.UpdatedAt.Time,
+		}
+		return &agg, nil
+	})
+}
 
-	if _, err = tx.Exec(ctx, "SELECT set_config('app.tenant_id', $1, true)", aggregate.TenantID); err != nil {
-		return err
-	}
+// maxSerializationRetries bounds how many times Create retries after a dialect-reported
+// serialization conflict before giving up and returning the error to the caller. CockroachDB
+// runs every transaction at SERIALIZABLE isolation and expects callers to retry 40001s; this
+// keeps that retry local to Create instead of pushing it onto every caller.
+const maxSerializationRetries = 3
 
-	insertActivity := `INSERT INTO activities (activity_id, tenant_id, user_id, activity_type, started_at, duration_min, source, idempotency_key, version, processing_state, created_at, updated_at)
-        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)`
-
-	_, err = tx.Exec(ctx, insertActivity,
-		aggregate.ID,
-		aggregate.TenantID,
-		aggregate.UserID,
-		aggregate.ActivityType,
-		aggregate.StartedAt,
-		aggregate.DurationMin,
-		aggregate.Source,
-		nullIfEmpty(idempotencyKey),
-		aggregate.Version,
-		aggregate.State,
-		aggregate.CreatedAt,
-		aggregate.UpdatedAt,
-	)
+// Create persists the aggregate and records outbox events inside a single transaction,
+// retrying on a dialect-reported serialization conflict.
+func (r *Repository) Create(ctx context.Context, aggregate domain.ActivityAggregate, idempotencyKey string) error {
+	schema, err := r.resolver.SchemaFor(ctx, aggregate.TenantID)
 	if err != nil {
 		return err
 	}
 
-	if err = r.insertOutbox(ctx, tx, aggregate, "activity.created", platformevents.ActivityCreated{
-		ActivityID:   aggregate.ID,
-		TenantID:     aggregate.TenantID,
-		UserID:       aggregate.UserID,
-		ActivityType: aggregate.ActivityType,
-		StartedAt:    aggregate.StartedAt,
-		DurationMin:  aggregate.DurationMin,
-		Source:       aggregate.Source,
-		Version:      aggregate.Version,
-	}); err != nil {
-		return err
-	}
-
-	if err = r.insertOutbox(ctx, tx, aggregate, "activity.state_changed", platformevents.ActivityStateChanged{
-		ActivityID: aggregate.ID,
-		TenantID:   aggregate.TenantID,
-		UserID:     aggregate.UserID,
-		State:      string(aggregate.State),
-		OccurredAt: aggregate.UpdatedAt,
-	}); err != nil {
-		return err
+	for attempt := 0; ; attempt++ {
+		err = r.createOnce(ctx, aggregate, idempotencyKey, schema)
+		if err == nil || attempt >= maxSerializationRetries || !r.dialect.IsRetryableSerializationError(err) {
+			break
+		}
 	}
-
-	err = tx.Commit(ctx)
 	if err != nil {
 		return err
 	}
@@ -135,138 +177,182 @@ func (r *Repository) Create(ctx context.Context, aggregate domain.ActivityAggreg
 	return nil
 }
 
-func (r *Repository) insertOutbox(ctx context.Context, tx pgx.Tx, aggregate domain.ActivityAggregate, eventType string, payload interface{}) error {
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
+func (r *Repository) createOnce(ctx context.Context, aggregate domain.ActivityAggregate, idempotencyKey, schema string) error {
+	_, err := withTenant(ctx, r.ds, r.dialect, aggregate.TenantID, schema, func(q *Queries) (struct{}, error) {
+		if err := q.InsertActivity(ctx, InsertActivityParams{
+			ActivityID:      aggregate.ID,
+			TenantID:        aggregate.TenantID,
+			UserID:          aggregate.UserID,
+			ActivityType:    aggregate.ActivityType,
+			StartedAt:       pgtype.Timestamptz{Time: aggregate.StartedAt, Valid: true},
+			DurationMin:     int32(aggregate.DurationMin),
+			Source:          aggregate.Source,
+			IdempotencyKey:  textOrNull(idempotencyKey),
+			Version:         aggregate.Version,
+			ProcessingState: string(aggregate.State),
+			CreatedAt:       pgtype.Timestamptz{Time: aggregate.CreatedAt, Valid: true},
+			UpdatedAt:       pgtype.Timestamptz{Time: aggregate.UpdatedAt, Valid: true},
+		}); err != nil {
+			return struct{}{}, err
+		}
+
+		if err := r.insertOutboxEvent(ctx, q, aggregate, "activity.created", platformevents.ActivityCreated{
+			ActivityID:   aggregate.ID,
+			TenantID:     aggregate.TenantID,
+			UserID:       aggregate.UserID,
+			ActivityType: aggregate.ActivityType,
+			StartedAt:    aggregate.StartedAt,
+			DurationMin:  aggregate.DurationMin,
+			Source:       aggregate.Source,
+			Version:      aggregate.Version,
+		}); err != nil {
+			return struct{}{}, err
+		}
 
+		if err := r.insertOutboxEvent(ctx, q, aggregate, "activity.state_changed", platformevents.ActivityStateChanged{
+			ActivityID: aggregate.ID,
+			TenantID:   aggregate.TenantID,
+			UserID:     aggregate.UserID,
+			State:      string(aggregate.State),
+			OccurredAt: aggregate.UpdatedAt,
+		}); err != nil {
+			return struct{}{}, err
+		}
+
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// insertOutboxEvent records an outbox event for aggregate via q, routed by eventCatalog and
+// wrapped in a CloudEvents envelope by buildCloudEvent. The set_config tenant helper and
+// event-catalog routing stay hand-written in Go; only the row insert itself is delegated to the
+// generated query.
+func (r *Repository) insertOutboxEvent(ctx context.Context, q *Queries, aggregate domain.ActivityAggregate, eventType string, payload interface{}) error {
 	meta := eventCatalog[eventType]
 	if meta.Topic == "" {
 		return fmt.Errorf("unknown event type: %s", eventType)
 	}
 
+	body, err := r.buildCloudEvent(ctx, aggregate, meta, eventType, payload)
+	if err != nil {
+		return err
+	}
+
 	partitionKey := meta.PartitionKeyFn(aggregate)
 	dedupeKey := fmt.Sprintf("%s:%s", aggregate.ID, eventType)
 
-	const stmt = `INSERT INTO outbox (tenant_id, aggregate_type, aggregate_id, event_type, topic, schema_subject, partition_key, payload, dedupe_key)
-        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`
-
-	_, err = tx.Exec(ctx, stmt,
-		aggregate.TenantID,
-		"activity",
-		aggregate.ID,
-		eventType,
-		meta.Topic,
-		meta.SchemaSubject,
-		partitionKey,
-		body,
-		dedupeKey,
-	)
-	return err
+	return q.InsertOutbox(ctx, InsertOutboxParams{
+		TenantID:      aggregate.TenantID,
+		AggregateType: "activity",
+		AggregateID:   aggregate.ID,
+		EventType:     eventType,
+		Topic:         meta.Topic,
+		SchemaSubject: meta.SchemaSubject,
+		PartitionKey:  partitionKey,
+		Payload:       body,
+		DedupeKey:     textOrNull(dedupeKey),
+	})
+}
+
+// dlqSelectColumns is the column list shared by the Get and ListByUser queries, which both join
+// an activity row to its most recent outbox_dlq entry.
+const dlqSelectColumns = `a.activity_id, a.tenant_id, a.user_id, a.activity_type, a.started_at, a.duration_min, a.source, a.version, a.processing_state, a.created_at, a.updated_at,
+       dlq.reason, dlq.next_retry_at, dlq.quarantined_at`
+
+// dlqGetQuery builds the Get query around dialect's DLQ join shape, so Postgres keeps its
+// LATERAL join and Cockroach gets the correlated subquery its planner prefers.
+func dlqGetQuery(dialect Dialect) string {
+	join, replayExpr := dialect.DLQJoin()
+	return `SELECT ` + dlqSelectColumns + `,
+       ` + replayExpr + ` AS replay_available
+FROM activities AS a
+` + join + `
+WHERE a.tenant_id = $1 AND a.activity_id = $2`
 }
 
 // Get retrieves an activity by ID.
 func (r *Repository) Get(ctx context.Context, tenantID, activityID string) (*domain.ActivityAggregate, error) {
-	const query = `SELECT a.activity_id,
-	                     a.tenant_id,
-	                     a.user_id,
-	                     a.activity_type,
-	                     a.started_at,
-	                     a.duration_min,
-	                     a.source,
-	                     a.version,
-	                     a.processing_state,
-	                     a.created_at,
-	                     a.updated_at,
-	                     dlq.reason,
-	                     dlq.next_retry_at,
-	                     dlq.quarantined_at,
-	                     COALESCE(dlq.replay_available, FALSE)
-	                FROM activities AS a
-	                LEFT JOIN LATERAL (
-	                    SELECT reason,
-	                           next_retry_at,
-	                           quarantined_at,
-	                           (quarantined_at IS NULL) AS replay_available
-	                      FROM outbox_dlq
-	                     WHERE aggregate_type = 'activity'
-	                       AND aggregate_id = a.activity_id::text
-	                     ORDER BY created_at DESC
-	                     LIMIT 1
-	                ) AS dlq ON TRUE
-	               WHERE a.tenant_id = $1::uuid AND a.activity_id = $2::uuid`
-
-	conn, err := r.pool.Acquire(ctx)
+	schema, err := r.resolver.SchemaFor(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Release()
 
-	tx, err := conn.Begin(ctx)
-	if err != nil {
-		return nil, err
-	}
-	defer tx.Rollback(ctx)
+	query := dlqGetQuery(r.dialect)
 
-	if _, err := tx.Exec(ctx, "SELECT set_config('app.tenant_id', $1, true)", tenantID); err != nil {
-		return nil, err
-	}
-
-	row := tx.QueryRow(ctx, query, tenantID, activityID)
-	var (
-		agg             domain.ActivityAggregate
-		reason          sql.NullString
-		nextRetryRaw    pgtype.Timestamptz
-		quarantinedRaw  pgtype.Timestamptz
-		replayAvailable bool
-	)
-	if err := row.Scan(&agg.ID, &agg.TenantID, &agg.UserID, &agg.ActivityType, &agg.StartedAt, &agg.DurationMin, &agg.Source, &agg.Version, &agg.State, &agg.CreatedAt, &agg.UpdatedAt, &reason, &nextRetryRaw, &quarantinedRaw, &replayAvailable); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			if err := tx.Commit(ctx); err != nil {
-				return nil, err
+	return withTenant(ctx, r.ds, r.dialect, tenantID, schema, func(q *Queries) (*domain.ActivityAggregate, error) {
+		var row GetActivityWithDlqRow
+		err := q.db.QueryRow(ctx, query, tenantID, activityID).Scan(
+			&row.ActivityID, &row.TenantID, &row.UserID, &row.ActivityType, &row.StartedAt, &row.DurationMin, &row.Source, &row.Version, &row.ProcessingState, &row.CreatedAt, &row.UpdatedAt,
+			&row.Reason, &row.NextRetryAt, &row.QuarantinedAt, &row.ReplayAvailable,
+		)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, nil
 			}
-			return nil, nil
+			return nil, err
 		}
-		return nil, err
-	}
-	if reason.Valid {
-		value := reason.String
-		agg.FailureReason = &value
-	}
-	if nextRetryRaw.Valid {
-		t := nextRetryRaw.Time
-		agg.NextRetryAt = &t
-	}
-	if quarantinedRaw.Valid {
-		t := quarantinedRaw.Time
-		agg.QuarantinedAt = &t
-	}
-	agg.ReplayAvailable = replayAvailable
-	if err := tx.Commit(ctx); err != nil {
-		return nil, err
-	}
-	return &agg, nil
+		return activityWithDLQFromRow(row.ActivityID, row.TenantID, row.UserID, row.ActivityType, row.StartedAt, row.DurationMin, row.Source, row.Version, row.ProcessingState, row.CreatedAt, row.UpdatedAt, row.Reason, row.NextRetryAt, row.QuarantinedAt, row.ReplayAvailable), nil
+	})
 }
 
 // SummaryByUser aggregates activity statistics for the specified user within the optional window.
 func (r *Repository) SummaryByUser(ctx context.Context, tenantID, userID string, window time.Duration) (domain.ActivitySummary, error) {
-	var summary domain.ActivitySummary
-
-	conn, err := r.pool.Acquire(ctx)
-	if err != nil {
-		return summary, err
+	windowSeconds := int64(window / time.Second)
+	if windowSeconds < 0 {
+		windowSeconds = 0
 	}
-	defer conn.Release()
 
-	tx, err := conn.Begin(ctx)
+	schema, err := r.resolver.SchemaFor(ctx, tenantID)
 	if err != nil {
-		return summary, err
+		return domain.ActivitySummary{}, err
 	}
-	defer tx.Rollback(ctx)
 
-	if _, err := tx.Exec(ctx, "SELECT set_config('app.tenant_id', $1, true)", tenantID); err != nil {
-		return summary, err
+	return withTenant(ctx, r.ds, r.dialect, tenantID, schema, func(q *Queries) (domain.ActivitySummary, error) {
+		row, err := q.SummaryByUser(ctx, SummaryByUserParams{TenantID: tenantID, UserID: userID, WindowSeconds: windowSeconds})
+		if err != nil {
+			return domain.ActivitySummary{}, err
+		}
+
+		summary := domain.ActivitySummary{
+			Total:   int(row.Total),
+			Pending: int(row.Pending),
+			Synced:  int(row.Synced),
+			Failed:  int(row.Failed),
+		}
+		if row.AvgDurationMinutes.Valid {
+			summary.AverageDurationMinutes = row.AvgDurationMinutes.Float64
+		}
+		if row.AvgProcessingSeconds.Valid {
+			summary.AverageProcessingSeconds = row.AvgProcessingSeconds.Float64
+		}
+		if row.OldestPendingSeconds.Valid {
+			summary.OldestPendingAgeSeconds = row.OldestPendingSeconds.Float64
+		}
+		if row.LastActivityAt.Valid {
+			t := row.LastActivityAt.Time.UTC()
+			summary.LastActivityAt = &t
+		}
+		return summary, nil
+	})
+}
+
+// bucketIntervals maps the API's bucket granularity to the Postgres interval literal used to
+// step generate_series when filling in empty buckets.
+var bucketIntervals = map[string]string{
+	"hour": "1 hour",
+	"day":  "1 day",
+	"week": "1 week",
+}
+
+// BucketsByUser aggregates activity counts into date_trunc'd buckets (hour, day, or week) in
+// the supplied IANA timezone, covering the full window so callers see empty buckets (count=0)
+// rather than gaps. It isn't part of the sqlc migration: its generate_series CTE doesn't map
+// cleanly onto a single reusable Row/Params shape, so it keeps querying through the raw tx.
+func (r *Repository) BucketsByUser(ctx context.Context, tenantID, userID string, window time.Duration, bucket, tz string) ([]domain.ActivityBucket, error) {
+	step, ok := bucketIntervals[bucket]
+	if !ok {
+		return nil, fmt.Errorf("unknown bucket granularity: %s", bucket)
 	}
 
 	windowSeconds := int64(window / time.Second)
@@ -274,214 +360,235 @@ func (r *Repository) SummaryByUser(ctx context.Context, tenantID, userID string,
 		windowSeconds = 0
 	}
 
-	const summaryQuery = `SELECT
-	    COUNT(*) AS total,
-	    COUNT(*) FILTER (WHERE processing_state = 'pending') AS pending,
-	    COUNT(*) FILTER (WHERE processing_state = 'synced') AS synced,
-	    COUNT(*) FILTER (WHERE processing_state = 'failed') AS failed,
-	    AVG(duration_min)::float AS avg_duration_minutes,
-	    AVG(EXTRACT(EPOCH FROM (a.updated_at - a.started_at))) FILTER (WHERE processing_state = 'synced') AS avg_processing_seconds,
-	    MAX(EXTRACT(EPOCH FROM (NOW() - a.started_at))) FILTER (WHERE processing_state = 'pending') AS oldest_pending_seconds,
-	    MAX(a.started_at) AS last_activity_at
-	  FROM activities AS a
-	  WHERE a.tenant_id = $1::uuid
-	    AND a.user_id = $2::uuid
-	    AND ($3 = 0 OR a.started_at >= NOW() - ($3::double precision * INTERVAL '1 second'))`
-
-	var (
-		total, pending, synced, failed int64
-		avgDuration                    sql.NullFloat64
-		avgProcessing                  sql.NullFloat64
-		oldestPending                  sql.NullFloat64
-		lastActivity                   pgtype.Timestamptz
-	)
-
-	if err := tx.QueryRow(ctx, summaryQuery, tenantID, userID, windowSeconds).Scan(
-		&total,
-		&pending,
-		&synced,
-		&failed,
-		&avgDuration,
-		&avgProcessing,
-		&oldestPending,
-		&lastActivity,
-	); err != nil {
-		return summary, err
+	schema, err := r.resolver.SchemaFor(ctx, tenantID)
+	if err != nil {
+		return nil, err
 	}
 
-	summary.Total = int(total)
-	summary.Pending = int(pending)
-	summary.Synced = int(synced)
-	summary.Failed = int(failed)
-	if avgDuration.Valid {
-		summary.AverageDurationMinutes = avgDuration.Float64
-	}
-	if avgProcessing.Valid {
-		summary.AverageProcessingSeconds = avgProcessing.Float64
-	}
-	if oldestPending.Valid {
-		summary.OldestPendingAgeSeconds = oldestPending.Float64
-	}
-	if lastActivity.Valid {
-		t := lastActivity.Time.UTC()
-		summary.LastActivityAt = &t
-	}
+	const bucketQuery = `WITH bucket_series AS (
+	    SELECT generate_series(
+	        date_trunc($4, (NOW() - ($3::double precision * INTERVAL '1 second')) AT TIME ZONE $5),
+	        date_trunc($4, NOW() AT TIME ZONE $5),
+	        $6::interval
+	    ) AS bucket_start
+	)
+	SELECT
+	    bs.bucket_start,
+	    COUNT(a.activity_id) AS total,
+	    COUNT(a.activity_id) FILTER (WHERE a.processing_state = 'synced') AS synced,
+	    COUNT(a.activity_id) FILTER (WHERE a.processing_state = 'failed') AS failed,
+	    AVG(a.duration_min)::float AS avg_duration_minutes,
+	    AVG(EXTRACT(EPOCH FROM (a.updated_at - a.started_at))) FILTER (WHERE a.processing_state = 'synced') AS avg_processing_seconds
+	  FROM bucket_series AS bs
+	  LEFT JOIN activities AS a
+	    ON a.tenant_id = $1::uuid
+	   AND a.user_id = $2::uuid
+	   AND date_trunc($4, a.started_at AT TIME ZONE $5) = bs.bucket_start
+	  GROUP BY bs.bucket_start
+	  ORDER BY bs.bucket_start`
+
+	return withTenant(ctx, r.ds, r.dialect, tenantID, schema, func(q *Queries) ([]domain.ActivityBucket, error) {
+		rows, err := q.db.Query(ctx, bucketQuery, tenantID, userID, windowSeconds, bucket, tz, step)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		var buckets []domain.ActivityBucket
+		for rows.Next() {
+			var (
+				bucketStart                time.Time
+				total, synced, failed      int64
+				avgDuration, avgProcessing pgtype.Float8
+			)
+			if err := rows.Scan(&bucketStart, &total, &synced, &failed, &avgDuration, &avgProcessing); err != nil {
+				return nil, err
+			}
 
-	if err := tx.Commit(ctx); err != nil {
-		return summary, err
-	}
-	return summary, nil
+			b := domain.ActivityBucket{
+				BucketStart: bucketStart.UTC(),
+				Count:       int(total),
+				SyncedCount: int(synced),
+				FailedCount: int(failed),
+			}
+			if avgDuration.Valid {
+				b.AverageDurationMinutes = avgDuration.Float64
+			}
+			if avgProcessing.Valid {
+				b.AverageProcessingSeconds = avgProcessing.Float64
+			}
+			buckets = append(buckets, b)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return buckets, nil
+	})
+}
+
+// dlqListQuery builds the ListByUser query around dialect's DLQ join shape, mirroring
+// dlqGetQuery.
+func dlqListQuery(dialect Dialect) string {
+	join, replayExpr := dialect.DLQJoin()
+	return `SELECT ` + dlqSelectColumns + `,
+       ` + replayExpr + ` AS replay_available
+FROM activities AS a
+` + join + `
+WHERE a.tenant_id = $1 AND a.user_id = $2
+  AND ($4::timestamptz IS NULL
+       OR (a.started_at, a.activity_id) < ($4::timestamptz, $5::uuid))
+ORDER BY a.started_at DESC, a.activity_id DESC
+LIMIT $3`
 }
 
 // ListByUser returns activities for a user ordered by time.
 func (r *Repository) ListByUser(ctx context.Context, tenantID, userID string, cursor *domain.Cursor, limit int) ([]domain.ActivityAggregate, *domain.Cursor, error) {
-	args := []interface{}{tenantID, userID, limit}
-	query := `SELECT a.activity_id,
-	                 a.tenant_id,
-	                 a.user_id,
-	                 a.activity_type,
-	                 a.started_at,
-	                 a.duration_min,
-	                 a.source,
-	                 a.version,
-	                 a.processing_state,
-	                 a.created_at,
-	                 a.updated_at,
-	                 dlq.reason,
-	                 dlq.next_retry_at,
-	                 dlq.quarantined_at,
-	                 COALESCE(dlq.replay_available, FALSE)
-	          FROM activities AS a
-	          LEFT JOIN LATERAL (
-	              SELECT reason,
-	                     next_retry_at,
-	                     quarantined_at,
-	                     (quarantined_at IS NULL) AS replay_available
-	                FROM outbox_dlq
-	               WHERE aggregate_type = 'activity'
-	                 AND aggregate_id = a.activity_id::text
-	               ORDER BY created_at DESC
-	               LIMIT 1
-	          ) AS dlq ON TRUE
-	         WHERE a.tenant_id = $1 AND a.user_id = $2`
-
-	if cursor != nil {
-		query += ` AND (a.started_at, a.activity_id) < ($4, $5)`
-		args = append(args, cursor.StartedAt, cursor.ID)
-	}
-
-	query += ` ORDER BY a.started_at DESC, a.activity_id DESC LIMIT $3`
-
-	conn, err := r.pool.Acquire(ctx)
+	schema, err := r.resolver.SchemaFor(ctx, tenantID)
 	if err != nil {
 		return nil, nil, err
 	}
-	defer conn.Release()
 
-	tx, err := conn.Begin(ctx)
-	if err != nil {
-		return nil, nil, err
-	}
-	defer tx.Rollback(ctx)
-
-	if _, err := tx.Exec(ctx, "SELECT set_config('app.tenant_id', $1, true)", tenantID); err != nil {
-		return nil, nil, err
+	query := dlqListQuery(r.dialect)
+	var cursorStartedAt pgtype.Timestamptz
+	var cursorID *string
+	if cursor != nil {
+		cursorStartedAt = pgtype.Timestamptz{Time: cursor.StartedAt, Valid: true}
+		id := cursor.ID
+		cursorID = &id
 	}
 
-	rows, err := tx.Query(ctx, query, args...)
-	if err != nil {
-		return nil, nil, err
-	}
-	defer rows.Close()
-
-	results := make([]domain.ActivityAggregate, 0, limit)
-	for rows.Next() {
-		var (
-			agg             domain.ActivityAggregate
-			reason          sql.NullString
-			nextRetryRaw    pgtype.Timestamptz
-			quarantinedRaw  pgtype.Timestamptz
-			replayAvailable bool
-		)
-		if err := rows.Scan(
-			&agg.ID,
-			&agg.TenantID,
-			&agg.UserID,
-			&agg.ActivityType,
-			&agg.StartedAt,
-			&agg.DurationMin,
-			&agg.Source,
-			&agg.Version,
-			&agg.State,
-			&agg.CreatedAt,
-			&agg.UpdatedAt,
-			&reason,
-			&nextRetryRaw,
-			&quarantinedRaw,
-			&replayAvailable,
-		); err != nil {
-			return nil, nil, err
-		}
-		if reason.Valid {
-			value := reason.String
-			agg.FailureReason = &value
+	page, err := withTenant(ctx, r.ds, r.dialect, tenantID, schema, func(q *Queries) (listByUserPage, error) {
+		rows, err := q.db.Query(ctx, query, tenantID, userID, int32(limit), cursorStartedAt, cursorID)
+		if err != nil {
+			return listByUserPage{}, err
 		}
-		if nextRetryRaw.Valid {
-			t := nextRetryRaw.Time
-			agg.NextRetryAt = &t
+		defer rows.Close()
+
+		results := make([]domain.ActivityAggregate, 0, limit)
+		for rows.Next() {
+			var row ListActivitiesWithDlqRow
+			if err := rows.Scan(
+				&row.ActivityID, &row.TenantID, &row.UserID, &row.ActivityType, &row.StartedAt, &row.DurationMin, &row.Source, &row.Version, &row.ProcessingState, &row.CreatedAt, &row.UpdatedAt,
+				&row.Reason, &row.NextRetryAt, &row.QuarantinedAt, &row.ReplayAvailable,
+			); err != nil {
+				return listByUserPage{}, err
+			}
+			agg := activityWithDLQFromRow(row.ActivityID, row.TenantID, row.UserID, row.ActivityType, row.StartedAt, row.DurationMin, row.Source, row.Version, row.ProcessingState, row.CreatedAt, row.UpdatedAt, row.Reason, row.NextRetryAt, row.QuarantinedAt, row.ReplayAvailable)
+			results = append(results, *agg)
 		}
-		if quarantinedRaw.Valid {
-			t := quarantinedRaw.Time
-			agg.QuarantinedAt = &t
+		if err := rows.Err(); err != nil {
+			return listByUserPage{}, err
 		}
-		agg.ReplayAvailable = replayAvailable
-		results = append(results, agg)
-	}
 
-	if err := rows.Err(); err != nil {
+		var nextCursor *domain.Cursor
+		if len(results) == limit {
+			last := results[len(results)-1]
+			nextCursor = &domain.Cursor{StartedAt: last.StartedAt, ID: last.ID}
+		}
+		return listByUserPage{activities: results, cursor: nextCursor}, nil
+	})
+	if err != nil {
 		return nil, nil, err
 	}
+	return page.activities, page.cursor, nil
+}
 
-	if err := tx.Commit(ctx); err != nil {
-		return nil, nil, err
-	}
+// listByUserPage bundles ListByUser's two return values so they can flow through withTenant's
+// single-value generic result.
+type listByUserPage struct {
+	activities []domain.ActivityAggregate
+	cursor     *domain.Cursor
+}
 
-	var nextCursor *domain.Cursor
-	if len(results) == limit {
-		last := results[len(results)-1]
-		nextCursor = &domain.Cursor{StartedAt: last.StartedAt, ID: last.ID}
+// activityWithDLQFromRow maps the columns shared by GetActivityWithDlq and
+// ListActivitiesWithDlq into a domain.ActivityAggregate, since both queries join the same
+// DLQ-status columns onto the same activity columns.
+func activityWithDLQFromRow(
+	activityID, tenantID, userID, activityType string,
+	startedAt pgtype.Timestamptz,
+	durationMin int32,
+	source, version, processingState string,
+	createdAt, updatedAt pgtype.Timestamptz,
+	reason pgtype.Text,
+	nextRetryAt, quarantinedAt pgtype.Timestamptz,
+	replayAvailable bool,
+) *domain.ActivityAggregate {
+	agg := domain.ActivityAggregate{
+		ID:              activityID,
+		TenantID:        tenantID,
+		UserID:          userID,
+		ActivityType:    activityType,
+		StartedAt:       startedAt.Time,
+		DurationMin:     int(durationMin),
+		Source:          source,
+		Version:         version,
+		State:           domain.ActivityState(processingState),
+		CreatedAt:       createdAt.Time,
+		UpdatedAt:       updatedAt.Time,
+		ReplayAvailable: replayAvailable,
 	}
-
-	return results, nextCursor, nil
+	if reason.Valid {
+		value := reason.String
+		agg.FailureReason = &value
+	}
+	if nextRetryAt.Valid {
+		t := nextRetryAt.Time
+		agg.NextRetryAt = &t
+	}
+	if quarantinedAt.Valid {
+		t := quarantinedAt.Time
+		agg.QuarantinedAt = &t
+	}
+	return &agg
 }
 
-func nullIfEmpty(value string) interface{} {
+func textOrNull(value string) pgtype.Text {
 	if value == "" {
-		return nil
+		return pgtype.Text{}
 	}
-	return value
+	return pgtype.Text{String: value, Valid: true}
 }
 
-// EventMetadata describes how to route an outbox event.
+// EventMetadata describes how to route an outbox event. PayloadVersion and Codec describe the
+// CloudEvents envelope buildCloudEvent wraps the payload in: PayloadVersion becomes part of the
+// envelope's dataschema attribute, and Codec selects the envelope's datacontenttype and whether
+// its data field is plain JSON or Confluent-framed bytes.
 type EventMetadata struct {
 	Topic          string
 	SchemaSubject  string
+	PayloadVersion string
+	Codec          Codec
 	PartitionKeyFn func(domain.ActivityAggregate) string
 }
 
 var eventCatalog = map[string]EventMetadata{
 	"activity.created": {
-		Topic:         "activity_events",
-		SchemaSubject: "activity_events-value",
+		Topic:          "activity_events",
+		SchemaSubject:  "activity_events-value",
+		PayloadVersion: "v1",
+		Codec:          CodecJSON,
 		PartitionKeyFn: func(a domain.ActivityAggregate) string {
 			return fmt.Sprintf("%s:%s", a.TenantID, a.UserID)
 		},
 	},
 	"activity.state_changed": {
-		Topic:         "activity_state_changed",
-		SchemaSubject: "activity_state_changed-value",
+		Topic:          "activity_state_changed",
+		SchemaSubject:  "activity_state_changed-value",
+		PayloadVersion: "v1",
+		Codec:          CodecAvro,
 		PartitionKeyFn: func(a domain.ActivityAggregate) string {
 			return a.ID
 		},
 	},
 }
+
+// EventSchemaSubjects returns the event-type-to-subject mapping eventCatalog routes outbox rows
+// through, for callers outside this package (the outbox relay's startup compatibility check)
+// that need to know which subject an event type resolves to without duplicating eventCatalog.
+func EventSchemaSubjects() map[string]string {
+	subjects := make(map[string]string, len(eventCatalog))
+	for eventType, meta := range eventCatalog {
+		subjects[eventType] = meta.SchemaSubject
+	}
+	return subjects
+}
@@ -0,0 +1,106 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"example.com/activity/internal/testsupport"
+)
+
+// crdbAutoFKIndexPattern matches the auto-generated indexes CockroachDB creates to back foreign
+// keys when no existing index already covers the referencing columns - Postgres never creates
+// these, so a naive column-for-column index diff would flag every FK as a CRDB-only gap.
+// Storj's schema-parity tooling ignores these same indexes for the same reason.
+var crdbAutoFKIndexPattern = regexp.MustCompile(`(?i)_auto_index_`)
+
+// TestSchemaParityAcrossDialects applies the shared migrations to both a Postgres and a
+// CockroachDB container and confirms they produce the same tables, columns, and
+// (non-FK-auto-generated) indexes, so a change to db/postgres/migrations can't silently drift
+// the two engines' schemas apart.
+func TestSchemaParityAcrossDialects(t *testing.T) {
+	ctx := context.Background()
+	pgPool, _ := testsupport.StartPostgres(ctx, t)
+	crdbPool, _ := testsupport.StartCockroach(ctx, t)
+
+	pgSchema := introspectSchema(ctx, t, pgPool)
+	crdbSchema := introspectSchema(ctx, t, crdbPool)
+
+	require.Equal(t, pgSchema.tables, crdbSchema.tables)
+	require.Equal(t, pgSchema.columns, crdbSchema.columns)
+	require.Equal(t, pgSchema.indexes, crdbSchema.indexes)
+}
+
+type introspectedSchema struct {
+	tables  []string
+	columns map[string][]string
+	indexes map[string][]string
+}
+
+func introspectSchema(ctx context.Context, t *testing.T, pool *pgxpool.Pool) introspectedSchema {
+	t.Helper()
+
+	schema := introspectedSchema{columns: map[string][]string{}, indexes: map[string][]string{}}
+
+	tableRows, err := pool.Query(ctx, `SELECT table_name FROM information_schema.tables
+		WHERE table_schema = 'public' AND table_type = 'BASE TABLE' ORDER BY table_name`)
+	require.NoError(t, err)
+	for tableRows.Next() {
+		var name string
+		require.NoError(t, tableRows.Scan(&name))
+		schema.tables = append(schema.tables, name)
+	}
+	require.NoError(t, tableRows.Err())
+	tableRows.Close()
+
+	for _, table := range schema.tables {
+		schema.columns[table] = introspectColumns(ctx, t, pool, table)
+		schema.indexes[table] = introspectIndexes(ctx, t, pool, table)
+	}
+
+	return schema
+}
+
+func introspectColumns(ctx context.Context, t *testing.T, pool *pgxpool.Pool, table string) []string {
+	t.Helper()
+
+	rows, err := pool.Query(ctx, `SELECT column_name FROM information_schema.columns
+		WHERE table_schema = 'public' AND table_name = $1 ORDER BY column_name`, table)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		columns = append(columns, name)
+	}
+	require.NoError(t, rows.Err())
+	return columns
+}
+
+func introspectIndexes(ctx context.Context, t *testing.T, pool *pgxpool.Pool, table string) []string {
+	t.Helper()
+
+	rows, err := pool.Query(ctx, `SELECT indexname FROM pg_indexes
+		WHERE schemaname = 'public' AND tablename = $1 ORDER BY indexname`, table)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var indexes []string
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		if crdbAutoFKIndexPattern.MatchString(name) {
+			continue
+		}
+		indexes = append(indexes, name)
+	}
+	require.NoError(t, rows.Err())
+	return indexes
+}
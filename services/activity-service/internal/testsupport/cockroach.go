@@ -0,0 +1,50 @@
+//go:build integration
+
+package testsupport
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+	cockroachcontainer "github.com/testcontainers/testcontainers-go/modules/cockroachdb"
+)
+
+// reuseCockroachEnvVar, when set to an already-running CockroachDB instance's connection
+// string, makes StartCockroach connect to it instead of launching a fresh testcontainers
+// container, mirroring reusePostgresEnvVar.
+const reuseCockroachEnvVar = "TESTSUPPORT_COCKROACH_URL"
+
+// cockroachMigrationOnces mirrors migrationOnces for CockroachDB connection strings.
+var cockroachMigrationOnces sync.Map
+
+// StartCockroach returns a ready CockroachDB pool and its Endpoint, with the same migrations
+// from db/postgres/migrations applied as StartPostgres uses - the two engines share one
+// migration set so schema-parity tests can confirm they stay compatible.
+func StartCockroach(ctx context.Context, t *testing.T) (*pgxpool.Pool, Endpoint) {
+	t.Helper()
+
+	connStr := os.Getenv(reuseCockroachEnvVar)
+	if connStr == "" {
+		crdb, err := cockroachcontainer.RunContainer(ctx, cockroachcontainer.WithDatabase("fitness"))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = crdb.Terminate(context.Background()) })
+
+		connStr, err = crdb.ConnectionString(ctx, "sslmode=disable")
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, waitForDatabase(ctx, connStr))
+
+	once, _ := cockroachMigrationOnces.LoadOrStore(connStr, &sync.Once{})
+	once.(*sync.Once).Do(func() { applyMigrations(ctx, t, connStr) })
+
+	pool, err := pgxpool.New(ctx, connStr)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	return pool, Endpoint{ConnString: connStr}
+}
@@ -0,0 +1,48 @@
+//go:build integration
+
+package testsupport
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+)
+
+// SeedOutbox inserts a row into outbox for tenantID and returns its event_id. A nil payload
+// defaults to the minimal {activity_id, tenant_id} body most dispatcher/replayer tests only
+// care to have present for schema validation.
+func SeedOutbox(ctx context.Context, t *testing.T, pool *pgxpool.Pool, tenantID, aggregateID, eventType string, payload map[string]any) int64 {
+	t.Helper()
+
+	if payload == nil {
+		payload = map[string]any{
+			"activity_id": aggregateID,
+			"tenant_id":   tenantID,
+		}
+	}
+	payloadBytes, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	var eventID int64
+	WithTenant(ctx, t, pool, tenantID, func(tx pgx.Tx) error {
+		row := tx.QueryRow(ctx,
+			`INSERT INTO outbox (tenant_id, aggregate_type, aggregate_id, event_type, topic, schema_subject, partition_key, payload)
+             VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+             RETURNING event_id`,
+			tenantID,
+			"activity",
+			aggregateID,
+			eventType,
+			"activity_events",
+			"activity_events-value",
+			tenantID+":"+aggregateID,
+			payloadBytes,
+		)
+		return row.Scan(&eventID)
+	})
+	return eventID
+}
@@ -0,0 +1,114 @@
+//go:build integration
+
+// Package testsupport provides shared Postgres test-container bootstrapping for
+// activity-service's integration test suites, replacing the setupPostgres/runMigrations pairs
+// that used to be copy-pasted into each package's _integration_test.go file.
+package testsupport
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+	postgrescontainer "github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+// reusePostgresEnvVar, when set to an already-running Postgres instance's connection string,
+// makes StartPostgres connect to it instead of launching a fresh testcontainers container.
+// CI can point every integration test binary at one long-lived instance to cut container
+// startup off the critical path; local runs leave it unset and get an isolated container.
+const reusePostgresEnvVar = "TESTSUPPORT_POSTGRES_URL"
+
+// Endpoint describes a Postgres instance ready for integration tests.
+type Endpoint struct {
+	ConnString string
+}
+
+// migrationOnces ensures migrations for a given connection string run exactly once per
+// process, keyed on the container's connection string, even if StartPostgres is called many
+// times against the same (possibly reused) instance.
+var migrationOnces sync.Map
+
+// StartPostgres returns a ready Postgres pool and its Endpoint. Migrations from
+// db/postgres/migrations are applied before the pool is returned.
+func StartPostgres(ctx context.Context, t *testing.T) (*pgxpool.Pool, Endpoint) {
+	t.Helper()
+
+	connStr := os.Getenv(reusePostgresEnvVar)
+	if connStr == "" {
+		pg, err := postgrescontainer.RunContainer(ctx,
+			postgrescontainer.WithDatabase("fitness"),
+			postgrescontainer.WithUsername("platform"),
+			postgrescontainer.WithPassword("platform"),
+		)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = pg.Terminate(context.Background()) })
+
+		connStr, err = pg.ConnectionString(ctx, "sslmode=disable")
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, waitForDatabase(ctx, connStr))
+
+	once, _ := migrationOnces.LoadOrStore(connStr, &sync.Once{})
+	once.(*sync.Once).Do(func() { applyMigrations(ctx, t, connStr) })
+
+	pool, err := pgxpool.New(ctx, connStr)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	return pool, Endpoint{ConnString: connStr}
+}
+
+func applyMigrations(ctx context.Context, t *testing.T, connStr string) {
+	t.Helper()
+
+	pool, err := pgxpool.New(ctx, connStr)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	migrationsDir := resolvePath(t, "../../../../db/postgres/migrations")
+	files, err := filepath.Glob(filepath.Join(migrationsDir, "*.up.sql"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files, "expected at least one migration .up.sql file")
+	sort.Strings(files)
+
+	for _, file := range files {
+		contents, readErr := os.ReadFile(file)
+		require.NoErrorf(t, readErr, "read migration %s", file)
+		_, execErr := pool.Exec(ctx, string(contents))
+		require.NoErrorf(t, execErr, "execute migration %s", file)
+	}
+}
+
+func resolvePath(t *testing.T, rel string) string {
+	t.Helper()
+	_, file, _, ok := runtime.Caller(0)
+	require.True(t, ok)
+	return filepath.Join(filepath.Dir(file), rel)
+}
+
+func waitForDatabase(ctx context.Context, connStr string) error {
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		pool, err := pgxpool.New(ctx, connStr)
+		if err == nil {
+			err = pool.Ping(ctx)
+			pool.Close()
+			if err == nil {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(time.Second)
+	}
+}
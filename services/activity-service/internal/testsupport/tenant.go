@@ -0,0 +1,33 @@
+//go:build integration
+
+package testsupport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+)
+
+// WithTenant runs fn inside a transaction with app.tenant_id set to tenantID, the same
+// acquire-begin-set_config sequence RLS-aware writes need. It commits on success and fails
+// the test on any error, including fn's.
+func WithTenant(ctx context.Context, t *testing.T, pool *pgxpool.Pool, tenantID string, fn func(tx pgx.Tx) error) {
+	t.Helper()
+
+	conn, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, "SELECT set_config('app.tenant_id', $1, true)", tenantID)
+	require.NoError(t, err)
+
+	require.NoError(t, fn(tx))
+	require.NoError(t, tx.Commit(ctx))
+}
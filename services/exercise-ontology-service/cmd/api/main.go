@@ -2,7 +2,8 @@ package main
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -17,26 +18,54 @@ import (
 	"example.com/exerciseontology/internal/config"
 	"example.com/exerciseontology/internal/domain"
 	"example.com/exerciseontology/internal/knowledge"
+	"example.com/exerciseontology/internal/storage/etcd"
+	"example.com/exerciseontology/internal/subscription"
 	httptransport "example.com/exerciseontology/internal/transport/http"
+	"example.com/platform/libs/go/health"
+	"example.com/platform/libs/go/httplog"
+	"example.com/platform/libs/go/logging"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/pkg/v3/transport"
 )
 
 func main() {
 	cfg := config.Load()
+	logger := logging.New(cfg.Logging)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := buildRepository(cfg, logger)
+	if err := knowledge.RunHooks(context.Background(), repo, knowledge.StageStartup); err != nil {
+		logger.Error("startup hooks failed", "error", err)
+	}
+	if err := knowledge.RunHooks(context.Background(), repo, knowledge.StagePostMigrate); err != nil {
+		logger.Error("post-migrate hooks failed", "error", err)
+	}
 
-	repo := buildRepository(cfg)
 	var invalidator cache.Invalidator = cache.NoopInvalidator{}
 	if cfg.CacheInvalidationURL != "" {
 		invalidator = cache.NewHTTPInvalidator(cfg.CacheInvalidationURL, cfg.CacheInvalidationToken, cfg.HTTPTimeout)
-		log.Printf("cache invalidator enabled -> %s", cfg.CacheInvalidationURL)
+		logger.Info("cache invalidator enabled", "url", cfg.CacheInvalidationURL)
 	}
 
 	service := domain.NewService(repo, invalidator)
 
+	subscriptionService := subscription.NewService(buildSubscriptionRepository(cfg), subscription.NewHTTPNotifier(cfg.HTTPTimeout, nil))
+
 	handler := api.NewHandler(service)
+	subscriptionHandler := api.NewSubscriptionHandler(subscriptionService)
+
+	healthRegistry := buildHealthRegistry(repo)
+	go healthRegistry.Run(ctx, 15*time.Second)
 
 	mux := http.NewServeMux()
 	handler.RegisterRoutes(mux)
+	subscriptionHandler.RegisterRoutes(mux)
 	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/healthz", healthRegistry)
+	mux.Handle("/readyz", healthRegistry)
 
 	// Simple CORS middleware for local dev
 	cors := func(next http.Handler) http.Handler {
@@ -53,47 +82,150 @@ func main() {
 		})
 	}
 
-	middleware := auth.NewMiddleware(auth.Config{Secret: cfg.JWTSecret, Issuer: cfg.JWTIssuer})
-
-	// Basic request logger
-	logger := func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			log.Printf("%s %s", r.Method, r.URL.Path)
-			next.ServeHTTP(w, r)
-		})
+	authCfg := auth.Config{Secret: cfg.JWTSecret, Issuer: cfg.JWTIssuer}
+	if cfg.OIDCIssuer != "" {
+		authCfg = auth.Config{
+			Issuer:               cfg.OIDCIssuer,
+			Audiences:            cfg.OIDCAudiences,
+			JWKSRefreshInterval:  cfg.JWKSRefreshInterval,
+			JWKSNegativeCacheTTL: cfg.JWKSNegativeCacheTTL,
+		}
 	}
+	middleware := auth.NewMiddleware(authCfg)
+
+	requestLogger := httplog.Middleware(logger)
 
 	server := httptransport.NewServer(httptransport.ServerConfig{
 		Address:      cfg.HTTPAddress,
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  60 * time.Second,
-	}, cors(logger(middleware.Wrap(mux))))
+	}, cors(middleware.Wrap(requestLogger(mux))))
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
-		log.Printf("exercise-ontology-service listening on %s", cfg.HTTPAddress)
+		logger.Info("exercise-ontology-service listening", "address", cfg.HTTPAddress)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("server error: %v", err)
+			logger.Error("server error", "error", err)
+			os.Exit(1)
 		}
 	}()
 
 	<-stop
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	cancel()
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+
+	if err := knowledge.RunHooks(shutdownCtx, repo, knowledge.StagePreShutdown); err != nil {
+		logger.Error("pre-shutdown hooks failed", "error", err)
+	}
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("graceful shutdown failed", "error", err)
+	}
+}
+
+// pinger is implemented by repositories that can verify their backing store is reachable.
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// sizer is implemented by repositories that can cheaply report how many exercises they hold.
+type sizer interface {
+	Len() int
+}
 
-	if err := server.Shutdown(ctx); err != nil {
-		log.Printf("graceful shutdown failed: %v", err)
+// buildHealthRegistry wires up the checks available for repo's concrete type. Repositories that
+// don't implement pinger/sizer (etcd's, Dgraph over gRPC) simply contribute no check for that
+// dependency, rather than failing to start.
+func buildHealthRegistry(repo domain.Repository) *health.Registry {
+	registry := health.NewRegistry()
+	if p, ok := repo.(pinger); ok {
+		registry.Register(health.Check{
+			Name:     "dgraph",
+			Critical: true,
+			Func: func(ctx context.Context) (string, error) {
+				return "", p.Ping(ctx)
+			},
+		})
+	}
+	if s, ok := repo.(sizer); ok {
+		registry.Register(health.Check{
+			Name: "repositorySize",
+			Func: func(ctx context.Context) (string, error) {
+				return fmt.Sprintf("exercises=%d", s.Len()), nil
+			},
+		})
 	}
+	return registry
 }
 
-func buildRepository(cfg config.Config) domain.Repository {
+func buildRepository(cfg config.Config, logger *slog.Logger) domain.Repository {
+	if len(cfg.EtcdEndpoints) > 0 {
+		logger.Info("using etcd repository", "endpoints", cfg.EtcdEndpoints)
+		client, err := newEtcdClient(cfg)
+		if err != nil {
+			logger.Error("failed to construct etcd client", "error", err)
+			os.Exit(1)
+		}
+		return etcd.NewRepository(client, etcd.WithTimeout(cfg.HTTPTimeout))
+	}
+	if cfg.DgraphGRPCAddr != "" {
+		logger.Info("using Dgraph gRPC repository", "addr", cfg.DgraphGRPCAddr)
+		repo, err := knowledge.NewDgraphGRPCRepository(cfg.DgraphGRPCAddr)
+		if err != nil {
+			logger.Error("failed to construct Dgraph gRPC repository", "error", err)
+			os.Exit(1)
+		}
+		return repo
+	}
 	if cfg.DgraphURL != "" {
-		log.Printf("using Dgraph repository at %s", cfg.DgraphURL)
-		return knowledge.NewDgraphRepository(cfg.DgraphURL, cfg.HTTPTimeout)
+		var opts []knowledge.Option
+		if cfg.DgraphACLUserid != "" {
+			logger.Info("Dgraph ACL enabled", "namespaces", len(cfg.DgraphACLNamespaces))
+			opts = append(opts, knowledge.WithACL(knowledge.DgraphAuth{
+				Userid:       cfg.DgraphACLUserid,
+				Password:     cfg.DgraphACLPassword,
+				Namespaces:   cfg.DgraphACLNamespaces,
+				SharedSecret: cfg.DgraphACLSharedSecret,
+			}))
+		}
+		logger.Info("using Dgraph repository", "url", cfg.DgraphURL)
+		return knowledge.NewDgraphRepository(cfg.DgraphURL, cfg.HTTPTimeout, opts...)
 	}
-	log.Printf("DGRAPH_URL not set, using in-memory repository")
+	logger.Info("DGRAPH_URL not set, using in-memory repository")
 	return knowledge.NewInMemoryRepository()
 }
+
+// newEtcdClient dials the configured etcd cluster, enabling mutual TLS only when all three
+// cert/key/CA files are set.
+func newEtcdClient(cfg config.Config) (*clientv3.Client, error) {
+	clientCfg := clientv3.Config{
+		Endpoints:   cfg.EtcdEndpoints,
+		DialTimeout: cfg.HTTPTimeout,
+		Username:    cfg.EtcdUsername,
+		Password:    cfg.EtcdPassword,
+	}
+	if cfg.EtcdTLSCertFile != "" && cfg.EtcdTLSKeyFile != "" && cfg.EtcdTLSCAFile != "" {
+		tlsInfo := transport.TLSInfo{
+			CertFile:      cfg.EtcdTLSCertFile,
+			KeyFile:       cfg.EtcdTLSKeyFile,
+			TrustedCAFile: cfg.EtcdTLSCAFile,
+		}
+		tlsConfig, err := tlsInfo.ClientConfig()
+		if err != nil {
+			return nil, err
+		}
+		clientCfg.TLS = tlsConfig
+	}
+	return clientv3.New(clientCfg)
+}
+
+func buildSubscriptionRepository(cfg config.Config) subscription.Repository {
+	if cfg.DgraphURL != "" {
+		return knowledge.NewDgraphSubscriptionRepository(cfg.DgraphURL, cfg.HTTPTimeout)
+	}
+	return knowledge.NewInMemorySubscriptionRepository()
+}
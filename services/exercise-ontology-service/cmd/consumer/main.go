@@ -16,39 +16,122 @@ import (
 	"example.com/exerciseontology/internal/cache"
 	"example.com/exerciseontology/internal/config"
 	"example.com/exerciseontology/internal/consumer"
+	"example.com/exerciseontology/internal/diagnostics"
 	"example.com/exerciseontology/internal/domain"
 	"example.com/exerciseontology/internal/knowledge"
+	"example.com/exerciseontology/internal/observability"
+	"example.com/exerciseontology/internal/subscription"
+	"example.com/platform/libs/go/datastreams"
+	"example.com/platform/libs/go/health"
+	"example.com/platform/libs/go/kafkasecurity"
+	"example.com/platform/libs/go/logging"
+	"example.com/platform/libs/go/schemaregistry"
+	"example.com/platform/libs/go/token"
 )
 
 func main() {
 	cfg := config.Load()
+	logger := logging.New(cfg.Logging)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	metricsSrv := &http.Server{Addr: cfg.MetricsAddress, Handler: promhttp.Handler()}
-	go func() {
-		log.Printf("ontology consumer metrics listening on %s", cfg.MetricsAddress)
-		if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Printf("metrics server error: %v", err)
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", promhttp.Handler())
+	metricsSrv := &http.Server{Addr: cfg.MetricsAddress, Handler: metricsMux}
+
+	// A single token source is shared across Kafka SASL/OAUTHBEARER and the Dgraph/cache HTTP
+	// clients below, so all three authenticate as the same IdP session.
+	var tokenSource *token.Source
+	if cfg.OAuth2.ClientID != "" && cfg.OAuth2.TokenURL != "" {
+		tokenSource = token.NewSource(cfg.OAuth2, token.WithOnRefreshError(func(err error) {
+			consumer.RecordTokenRefreshFailure()
+			log.Printf("oauth2 token refresh failed: %v", err)
+		}))
+		cfg.KafkaSecurity.SASLMechanism = kafkasecurity.MechanismOAuthBearer
+		cfg.KafkaSecurity.TokenSource = tokenSource
+	}
+
+	var dgraphOpts []knowledge.Option
+	if tokenSource != nil {
+		dgraphOpts = append(dgraphOpts, knowledge.WithRoundTripper(tokenSource.RoundTripper(nil)))
+	}
+	if cfg.DgraphACLUserid != "" {
+		dgraphOpts = append(dgraphOpts, knowledge.WithACL(knowledge.DgraphAuth{
+			Userid:       cfg.DgraphACLUserid,
+			Password:     cfg.DgraphACLPassword,
+			Namespaces:   cfg.DgraphACLNamespaces,
+			SharedSecret: cfg.DgraphACLSharedSecret,
+		}))
+	}
+	repo := knowledge.NewDgraphRepository(cfg.DgraphURL, cfg.HTTPTimeout, dgraphOpts...)
+	if err := knowledge.RunHooks(ctx, repo, knowledge.StageStartup); err != nil {
+		log.Printf("startup hooks failed: %v", err)
+	}
+	if err := knowledge.RunHooks(ctx, repo, knowledge.StagePostMigrate); err != nil {
+		log.Printf("post-migrate hooks failed: %v", err)
+	}
+
+	healthRegistry := health.NewRegistry()
+	healthRegistry.Register(health.Check{
+		Name:     "dgraph",
+		Critical: true,
+		Func: func(checkCtx context.Context) (string, error) {
+			return "", repo.Ping(checkCtx)
+		},
+	})
+	go healthRegistry.Run(ctx, 15*time.Second)
+	metricsMux.Handle("/healthz", healthRegistry)
+	metricsMux.Handle("/readyz", healthRegistry)
+
+	var invalidator cache.Invalidator = cache.NoopInvalidator{}
+	if cfg.CacheInvalidationURL != "" {
+		var invalidatorOpts []cache.Option
+		if tokenSource != nil {
+			invalidatorOpts = append(invalidatorOpts, cache.WithRoundTripper(tokenSource.RoundTripper(nil)))
 		}
-	}()
+		invalidator = cache.NewHTTPInvalidator(cfg.CacheInvalidationURL, cfg.CacheInvalidationToken, cfg.HTTPTimeout, invalidatorOpts...)
+	}
+	service := domain.NewService(repo, invalidator)
+	registry := schemaregistry.NewClient(cfg.SchemaRegistry)
+	decoded := consumer.NewSchemaDecoder(consumer.NewEnrichmentHandler(service, logger), registry)
+	enrichment := consumer.NewDecompressingHandler(decoded, cfg.MaxDecompressedBytes)
 
-	repo := knowledge.NewDgraphRepository(cfg.DgraphURL, cfg.HTTPTimeout)
-	service := domain.NewService(repo, cache.NoopInvalidator{})
-	handler := consumer.NewEnrichmentHandler(service)
+	var subscriptionOpts []knowledge.SubscriptionOption
+	if tokenSource != nil {
+		subscriptionOpts = append(subscriptionOpts, knowledge.WithSubscriptionRoundTripper(tokenSource.RoundTripper(nil)))
+	}
+	subscriptionRepo := knowledge.NewDgraphSubscriptionRepository(cfg.DgraphURL, cfg.HTTPTimeout, subscriptionOpts...)
+	subscriptionService := subscription.NewService(subscriptionRepo, subscription.NewHTTPNotifier(cfg.HTTPTimeout, logger))
+
+	// FanOutHandler turns this consumer into a general enrichment/fan-out plane: every message
+	// still runs through the hard-coded EnrichmentHandler pipeline above, then is additionally
+	// matched against operator-registered subscriptions and republished to their delivery
+	// topic or webhook.
+	var handler consumer.Handler = consumer.NewFanOutHandler(enrichment, subscriptionService, consumer.NewKafkaTopicPublisher(cfg.KafkaBrokers), cfg.HTTPTimeout, logger)
+
+	dsp := datastreams.NewProcessor("exercise-ontology-service", observability.DataStreamsRecorder{}, 10*time.Second)
+	defer dsp.Close()
 	var wg sync.WaitGroup
+	var diagnosticSources []diagnostics.Source
 
 	for _, topic := range cfg.ConsumerTopics {
-		reader := kafka.NewReader(kafka.ReaderConfig{
-			Brokers:        cfg.KafkaBrokers,
-			GroupID:        cfg.ConsumerGroup,
-			Topic:          topic,
-			MinBytes:       1e3,
-			MaxBytes:       10e6,
-			CommitInterval: time.Second,
+		reader, err := consumer.NewReader(ctx, consumer.ReaderConfig{
+			Brokers:  cfg.KafkaBrokers,
+			GroupID:  cfg.ConsumerGroup,
+			Topic:    topic,
+			Security: cfg.KafkaSecurity,
 		})
-		proc := consumer.NewProcessor(reader, handler)
+		if err != nil {
+			log.Fatalf("failed to construct kafka reader (topic=%s): %v", topic, err)
+		}
+		proc := consumer.NewProcessor(reader, handler,
+			consumer.WithDataStreamsProcessor(dsp, cfg.ConsumerGroup),
+			consumer.WithRetry(cfg.ConsumerMaxAttempts, exponentialBackoff),
+			consumer.WithHandleTimeout(cfg.ConsumerHandleTimeout),
+			consumer.WithDeadLetterPublisher(consumer.NewKafkaDeadLetterPublisher(cfg.KafkaBrokers, cfg.ConsumerDLQSuffix)),
+		)
+		diagnosticSources = append(diagnosticSources, diagnostics.Source{Topic: topic, Processor: proc})
 
 		wg.Add(1)
 		go func(tp string, r *kafka.Reader) {
@@ -60,6 +143,14 @@ func main() {
 		}(topic, reader)
 	}
 
+	diagnostics.RegisterRoutes(metricsMux, cfg.MetricsDebugToken, diagnosticSources)
+	go func() {
+		log.Printf("ontology consumer metrics listening on %s", cfg.MetricsAddress)
+		if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("metrics server error: %v", err)
+		}
+	}()
+
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
 	<-signals
@@ -68,9 +159,24 @@ func main() {
 
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer shutdownCancel()
+	if err := knowledge.RunHooks(shutdownCtx, repo, knowledge.StagePreShutdown); err != nil {
+		log.Printf("pre-shutdown hooks failed: %v", err)
+	}
 	if err := metricsSrv.Shutdown(shutdownCtx); err != nil {
 		log.Printf("metrics shutdown error: %v", err)
 	}
 
 	wg.Wait()
 }
+
+// exponentialBackoff doubles from 200ms starting at attempt 1, capping at 10s.
+func exponentialBackoff(attempt int) time.Duration {
+	delay := 200 * time.Millisecond
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= 10*time.Second {
+			return 10 * time.Second
+		}
+	}
+	return delay
+}
@@ -2,8 +2,12 @@
 package api
 
 import (
+	"bufio"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
@@ -26,13 +30,8 @@ func NewHandler(service *domain.Service) *Handler {
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/v1/exercises", h.exercises)
 	mux.HandleFunc("/v1/exercises/", h.exerciseByID)
-	mux.HandleFunc("/healthz", healthz)
-}
-
-// healthz returns an OK response for readiness probes.
-func healthz(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte("ok"))
+	mux.HandleFunc("/v1/exercises:bulk", h.bulkExercises)
+	mux.HandleFunc("/v1/exercises:export", h.exportExercises)
 }
 
 func (h *Handler) exercises(w http.ResponseWriter, r *http.Request) {
@@ -47,6 +46,16 @@ func (h *Handler) exercises(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) exerciseByID(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/v1/exercises/")
+	if path == "relationships/audit" {
+		h.auditRelationships(w, r)
+		return
+	}
+	if id, ok := strings.CutSuffix(path, "/relationships"); ok {
+		h.updateRelationships(w, r, id)
+		return
+	}
+
 	claims, ok := auth.FromContext(r.Context())
 	if !ok {
 		writeError(w, http.StatusUnauthorized, "unauthorized", "missing bearer token")
@@ -61,7 +70,7 @@ func (h *Handler) exerciseByID(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "unsupported method")
 		return
 	}
-	id := strings.TrimPrefix(r.URL.Path, "/v1/exercises/")
+	id := path
 	if strings.TrimSpace(id) == "" {
 		writeError(w, http.StatusBadRequest, "invalid_request", "missing exercise id")
 		return
@@ -79,6 +88,93 @@ func (h *Handler) exerciseByID(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, exercise)
 }
 
+// updateRelationships handles PUT /v1/exercises/{id}/relationships.
+func (h *Handler) updateRelationships(w http.ResponseWriter, r *http.Request, id string) {
+	claims, ok := auth.FromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "missing bearer token")
+		return
+	}
+	if !claims.HasScope(auth.ScopeOntologyWrite) {
+		writeError(w, http.StatusForbidden, "forbidden", "scope ontology:write required")
+		return
+	}
+	if r.Method != http.MethodPut {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "unsupported method")
+		return
+	}
+	if strings.TrimSpace(id) == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "missing exercise id")
+		return
+	}
+
+	var req UpdateRelationshipsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "unable to parse body")
+		return
+	}
+
+	updated, err := h.service.UpdateRelationships(r.Context(), id, domain.ExerciseRelationships{
+		Targets:           req.Targets,
+		ComplementaryTo:   req.ComplementaryTo,
+		Contraindications: req.Contraindications,
+	})
+	if err != nil {
+		var conflict *domain.RelationshipConflictError
+		if errors.As(err, &conflict) {
+			h.writeRelationshipConflict(w, conflict)
+			return
+		}
+		// A reference in the request body (or the exercise itself) doesn't exist - that's the
+		// caller's mistake, not a 404 on some resource the caller is trying to look up.
+		if errors.Is(err, domain.ErrExerciseNotFound) {
+			writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"exercise": updated})
+}
+
+// auditRelationships handles GET /v1/exercises/relationships/audit. It's gated on
+// ontology:write, same as other relationship-mutating routes, since the audit is meant for the
+// same operators who'd act on its findings via RepairSymmetry rather than general read access.
+func (h *Handler) auditRelationships(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.FromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "missing bearer token")
+		return
+	}
+	if !claims.HasScope(auth.ScopeOntologyWrite) {
+		writeError(w, http.StatusForbidden, "forbidden", "scope ontology:write required")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "unsupported method")
+		return
+	}
+
+	report, err := h.service.AuditRelationships(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"report": report})
+}
+
+// writeRelationshipConflict responds 422: the request was well-formed, but committing it would
+// leave the relationship graph in an impossible state (the conflicting path and edge types say
+// why), unlike the 409 writeConflict sends for a lost optimistic-concurrency race.
+func (h *Handler) writeRelationshipConflict(w http.ResponseWriter, conflict *domain.RelationshipConflictError) {
+	writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
+		"type":       "relationship_conflict",
+		"detail":     conflict.Error(),
+		"path":       conflict.Path,
+		"edge_types": conflict.EdgeTypes,
+	})
+}
+
 func (h *Handler) searchExercises(w http.ResponseWriter, r *http.Request) {
 	claims, ok := auth.FromContext(r.Context())
 	if !ok {
@@ -127,23 +223,268 @@ func (h *Handler) upsertExercise(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	exercise := domain.Exercise{
-		ID:                req.ID,
-		Name:              req.Name,
-		Difficulty:        req.Difficulty,
-		Targets:           req.Targets,
-		Requires:          req.Requires,
-		Contraindications: req.Contraindications,
-		ComplementaryTo:   req.ComplementaryTo,
+	exercise := req.toExercise()
+
+	updated, err := h.service.UpsertExercise(r.Context(), exercise)
+	if err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			h.writeConflict(w, r, req.ID)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
 	}
 
-    updated, err := h.service.UpsertExercise(r.Context(), exercise)
-    if err != nil {
-        writeError(w, http.StatusInternalServerError, "server_error", err.Error())
-        return
-    }
+	writeJSON(w, http.StatusOK, map[string]any{"exercise": updated})
+}
 
-    writeJSON(w, http.StatusOK, map[string]any{"exercise": updated})
+// bulkExercises handles POST /v1/exercises:bulk. The body is streamed line-by-line (NDJSON) or
+// row-by-row (CSV) rather than buffered whole, decoded records are handed to
+// Service.BulkUpsert, and its results are written back as NDJSON, flushed as each one arrives, so
+// a caller seeding a large ontology sees progress instead of waiting for the whole import to
+// finish.
+func (h *Handler) bulkExercises(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.FromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "missing bearer token")
+		return
+	}
+	if !claims.HasScope(auth.ScopeOntologyWrite) {
+		writeError(w, http.StatusForbidden, "forbidden", "scope ontology:write required")
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "unsupported method")
+		return
+	}
+
+	items := make(chan domain.BulkUpsertItem)
+	failures := make(chan domain.BulkResult)
+	go func() {
+		defer close(items)
+		defer close(failures)
+		if strings.Contains(r.Header.Get("Content-Type"), "text/csv") {
+			decodeBulkCSV(r.Body, items, failures)
+			return
+		}
+		decodeBulkNDJSON(r.Body, items, failures)
+	}()
+
+	results := h.service.BulkUpsert(r.Context(), items)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+
+	for results != nil || failures != nil {
+		select {
+		case result, ok := <-results:
+			if !ok {
+				results = nil
+				continue
+			}
+			if err := encoder.Encode(result); err != nil {
+				log.Printf("api: bulk result encode failed: %v", err)
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		case failure, ok := <-failures:
+			if !ok {
+				failures = nil
+				continue
+			}
+			if err := encoder.Encode(failure); err != nil {
+				log.Printf("api: bulk result encode failed: %v", err)
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// decodeBulkNDJSON streams body one line at a time, decoding each as an UpsertExerciseRequest and
+// sending it to items. A line that fails to parse is reported on failures rather than items,
+// since there's no Exercise to hand BulkUpsert.
+func decodeBulkNDJSON(body io.Reader, items chan<- domain.BulkUpsertItem, failures chan<- domain.BulkResult) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var req UpsertExerciseRequest
+		if err := json.Unmarshal([]byte(text), &req); err != nil {
+			failures <- domain.BulkResult{Line: line, Status: "error", Error: "invalid json: " + err.Error()}
+			continue
+		}
+		items <- domain.BulkUpsertItem{Line: line, Exercise: req.toExercise()}
+	}
+	if err := scanner.Err(); err != nil {
+		failures <- domain.BulkResult{Line: line + 1, Status: "error", Error: "read failed: " + err.Error()}
+	}
+}
+
+// bulkCSVMultiValueSep separates the members of a multi-value CSV column (targets, requires,
+// contraindicated_with, complementary_to), since a CSV cell is otherwise a single flat string.
+const bulkCSVMultiValueSep = ";"
+
+// decodeBulkCSV streams body as CSV, using its header row to look up columns by name so the
+// column order in the file doesn't matter. Recognized headers: id, name, difficulty, targets,
+// requires, contraindicated_with, complementary_to - the last four are
+// semicolon-separated lists. A row that fails to parse is reported on failures rather than items.
+func decodeBulkCSV(body io.Reader, items chan<- domain.BulkUpsertItem, failures chan<- domain.BulkResult) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		failures <- domain.BulkResult{Line: 0, Status: "error", Error: "invalid csv header: " + err.Error()}
+		return
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	field := func(record []string, name string) string {
+		idx, ok := columns[name]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return record[idx]
+	}
+	list := func(record []string, name string) []string {
+		raw := field(record, name)
+		if raw == "" {
+			return nil
+		}
+		parts := strings.Split(raw, bulkCSVMultiValueSep)
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		return parts
+	}
+
+	line := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return
+		}
+		line++
+		if err != nil {
+			failures <- domain.BulkResult{Line: line, Status: "error", Error: "invalid csv row: " + err.Error()}
+			continue
+		}
+		items <- domain.BulkUpsertItem{Line: line, Exercise: domain.Exercise{
+			ID:                field(record, "id"),
+			Name:              field(record, "name"),
+			Difficulty:        field(record, "difficulty"),
+			Targets:           list(record, "targets"),
+			Requires:          list(record, "requires"),
+			Contraindications: list(record, "contraindicated_with"),
+			ComplementaryTo:   list(record, "complementary_to"),
+		}}
+	}
+}
+
+// exportExercises handles GET /v1/exercises:export. format selects the response encoding
+// (ndjson, the default, or csv); query filters by the same case-insensitive name substring match
+// as GET /v1/exercises. Both encodings stream one record at a time via Service.ExportExercises'
+// keyset pagination, so exporting a large ontology never holds it all in memory at once.
+func (h *Handler) exportExercises(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.FromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "missing bearer token")
+		return
+	}
+	if !claims.HasScope(auth.ScopeOntologyRead) && !claims.HasScope(auth.ScopeOntologyWrite) {
+		writeError(w, http.StatusForbidden, "forbidden", "scope ontology:read required")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "unsupported method")
+		return
+	}
+
+	query := r.URL.Query().Get("query")
+	flusher, _ := w.(http.Flusher)
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "ndjson":
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		encoder := json.NewEncoder(w)
+		err := h.service.ExportExercises(r.Context(), query, 0, func(exercise domain.Exercise) error {
+			if err := encoder.Encode(exercise); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("api: ndjson export failed: %v", err)
+		}
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.WriteHeader(http.StatusOK)
+		writer := csv.NewWriter(w)
+		_ = writer.Write(exportCSVHeader)
+		err := h.service.ExportExercises(r.Context(), query, 0, func(exercise domain.Exercise) error {
+			if err := writer.Write(exportCSVRow(exercise)); err != nil {
+				return err
+			}
+			writer.Flush()
+			if flusher != nil {
+				flusher.Flush()
+			}
+			return writer.Error()
+		})
+		if err != nil {
+			log.Printf("api: csv export failed: %v", err)
+		}
+	default:
+		writeError(w, http.StatusBadRequest, "invalid_request", "unsupported format: "+format)
+	}
+}
+
+// exportCSVHeader names the columns exportExercises writes when format=csv, matching the header
+// row decodeBulkCSV expects on import.
+var exportCSVHeader = []string{"id", "name", "difficulty", "targets", "requires", "contraindicated_with", "complementary_to"}
+
+func exportCSVRow(exercise domain.Exercise) []string {
+	return []string{
+		exercise.ID,
+		exercise.Name,
+		exercise.Difficulty,
+		strings.Join(exercise.Targets, bulkCSVMultiValueSep),
+		strings.Join(exercise.Requires, bulkCSVMultiValueSep),
+		strings.Join(exercise.Contraindications, bulkCSVMultiValueSep),
+		strings.Join(exercise.ComplementaryTo, bulkCSVMultiValueSep),
+	}
+}
+
+// writeConflict responds 409 with the exercise's current ResourceVersion, so a caller that lost
+// an optimistic-concurrency race can re-read it and retry with an If-Match-style resubmit.
+func (h *Handler) writeConflict(w http.ResponseWriter, r *http.Request, id string) {
+	current, err := h.service.GetExercise(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusConflict, "conflict", "exercise was concurrently modified")
+		return
+	}
+	writeJSON(w, http.StatusConflict, map[string]any{
+		"type":             "conflict",
+		"detail":           "exercise was concurrently modified",
+		"resource_version": current.ResourceVersion,
+	})
 }
 
 // UpsertExerciseRequest represents the request payload.
@@ -155,6 +496,7 @@ type UpsertExerciseRequest struct {
 	Requires          []string `json:"requires"`
 	Contraindications []string `json:"contraindicated_with"`
 	ComplementaryTo   []string `json:"complementary_to"`
+	ResourceVersion   uint64   `json:"resource_version"`
 }
 
 // Validate ensures request integrity.
@@ -165,6 +507,28 @@ func (r UpsertExerciseRequest) Validate() error {
 	return nil
 }
 
+// toExercise converts the wire request into a domain.Exercise, the same field mapping
+// upsertExercise applies inline.
+func (r UpsertExerciseRequest) toExercise() domain.Exercise {
+	return domain.Exercise{
+		ID:                r.ID,
+		Name:              r.Name,
+		Difficulty:        r.Difficulty,
+		Targets:           r.Targets,
+		Requires:          r.Requires,
+		Contraindications: r.Contraindications,
+		ComplementaryTo:   r.ComplementaryTo,
+		ResourceVersion:   r.ResourceVersion,
+	}
+}
+
+// UpdateRelationshipsRequest represents the PUT /v1/exercises/{id}/relationships payload.
+type UpdateRelationshipsRequest struct {
+	Targets           []string `json:"targets"`
+	ComplementaryTo   []string `json:"complementary_to"`
+	Contraindications []string `json:"contraindicated_with"`
+}
+
 func writeError(w http.ResponseWriter, status int, code, detail string) {
 	writeJSON(w, status, map[string]string{"type": code, "detail": detail})
 }
@@ -1,11 +1,13 @@
 package api
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -326,6 +328,204 @@ func TestUpdateRelationshipsInvalidReference(t *testing.T) {
 	}
 }
 
+func TestUpdateRelationshipsDetectsConflict(t *testing.T) {
+	repo := knowledge.NewInMemoryRepository()
+	service := domain.NewService(repo, cache.NoopInvalidator{})
+	handler := NewHandler(service)
+
+	base, err := service.UpsertExercise(context.Background(), domain.Exercise{
+		ID:   "exercise-base",
+		Name: "Base",
+	})
+	if err != nil {
+		t.Fatalf("seed failed: %v", err)
+	}
+	_, err = service.UpsertExercise(context.Background(), domain.Exercise{
+		ID:   "exercise-other",
+		Name: "Other",
+	})
+	if err != nil {
+		t.Fatalf("seed failed: %v", err)
+	}
+
+	payload := map[string]interface{}{
+		"complementary_to":     []string{"exercise-other"},
+		"contraindicated_with": []string{"exercise-other"},
+	}
+	buf, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest(http.MethodPut, "/v1/exercises/"+base.ID+"/relationships", bytes.NewReader(buf))
+	req.Header.Set("Content-Type", "application/json")
+	claims := &auth.Claims{
+		Subject:   "coach",
+		TenantID:  "tenant",
+		Scopes:    scopesWith(auth.ScopeOntologyWrite),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	req = req.WithContext(auth.WithClaims(req.Context(), claims))
+
+	rr := httptest.NewRecorder()
+	handler.exerciseByID(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rr.Code)
+	}
+
+	var body struct {
+		Type string `json:"type"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if body.Type != "relationship_conflict" {
+		t.Fatalf("expected relationship_conflict type, got %s", body.Type)
+	}
+}
+
+func TestAuditRelationshipsReportsDanglingEdges(t *testing.T) {
+	repo := knowledge.NewInMemoryRepository()
+	service := domain.NewService(repo, cache.NoopInvalidator{})
+	handler := NewHandler(service)
+
+	_, err := service.UpsertExercise(context.Background(), domain.Exercise{
+		ID:              "exercise-base",
+		Name:            "Base",
+		ComplementaryTo: []string{"exercise-other"},
+	})
+	if err != nil {
+		t.Fatalf("seed failed: %v", err)
+	}
+	_, err = service.UpsertExercise(context.Background(), domain.Exercise{
+		ID:   "exercise-other",
+		Name: "Other",
+	})
+	if err != nil {
+		t.Fatalf("seed failed: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/exercises/relationships/audit", nil)
+	claims := &auth.Claims{
+		Subject:   "coach",
+		TenantID:  "tenant",
+		Scopes:    scopesWith(auth.ScopeOntologyWrite),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	req = req.WithContext(auth.WithClaims(req.Context(), claims))
+
+	rr := httptest.NewRecorder()
+	handler.exerciseByID(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	var body struct {
+		Report domain.RelationshipAuditReport `json:"report"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if len(body.Report.DanglingComplementary) != 1 {
+		t.Fatalf("expected one dangling complementary edge, got %d", len(body.Report.DanglingComplementary))
+	}
+	if body.Report.DanglingComplementary[0].From != "exercise-base" || body.Report.DanglingComplementary[0].To != "exercise-other" {
+		t.Fatalf("unexpected dangling edge: %+v", body.Report.DanglingComplementary[0])
+	}
+}
+
+func TestBulkExercisesImportsNDJSON(t *testing.T) {
+	repo := knowledge.NewInMemoryRepository()
+	service := domain.NewService(repo, cache.NoopInvalidator{})
+	handler := NewHandler(service)
+
+	body := strings.Join([]string{
+		`{"name":"Box Jump","difficulty":"advanced"}`,
+		`{"name":"Wall Sit","difficulty":"beginner"}`,
+	}, "\n")
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/exercises:bulk", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	claims := &auth.Claims{
+		Subject:   "coach",
+		TenantID:  "tenant",
+		Scopes:    scopesWith(auth.ScopeOntologyWrite),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	req = req.WithContext(auth.WithClaims(req.Context(), claims))
+
+	rr := httptest.NewRecorder()
+	handler.bulkExercises(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	scanner := bufio.NewScanner(rr.Body)
+	var results []domain.BulkResult
+	for scanner.Scan() {
+		var result domain.BulkResult
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			t.Fatalf("decode result failed: %v", err)
+		}
+		results = append(results, result)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	for _, result := range results {
+		if result.Status != "ok" {
+			t.Fatalf("expected ok status, got %+v", result)
+		}
+	}
+
+	all, err := service.SearchExercises(context.Background(), "", 10)
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected 2 persisted exercises, got %d", len(all))
+	}
+}
+
+func TestExportExercisesStreamsNDJSON(t *testing.T) {
+	repo := knowledge.NewInMemoryRepository()
+	service := domain.NewService(repo, cache.NoopInvalidator{})
+	handler := NewHandler(service)
+
+	if _, err := service.UpsertExercise(context.Background(), domain.Exercise{ID: "exercise-export", Name: "Plank"}); err != nil {
+		t.Fatalf("seed failed: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/exercises:export?format=ndjson", nil)
+	claims := &auth.Claims{
+		Subject:   "coach",
+		TenantID:  "tenant",
+		Scopes:    scopesWith(auth.ScopeOntologyRead),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	req = req.WithContext(auth.WithClaims(req.Context(), claims))
+
+	rr := httptest.NewRecorder()
+	handler.exportExercises(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	scanner := bufio.NewScanner(rr.Body)
+	var exported []domain.Exercise
+	for scanner.Scan() {
+		var exercise domain.Exercise
+		if err := json.Unmarshal(scanner.Bytes(), &exercise); err != nil {
+			t.Fatalf("decode exercise failed: %v", err)
+		}
+		exported = append(exported, exercise)
+	}
+	if len(exported) != 1 || exported[0].ID != "exercise-export" {
+		t.Fatalf("expected exported exercise-export, got %+v", exported)
+	}
+}
+
 func scopesWith(values ...string) map[string]struct{} {
 	m := make(map[string]struct{}, len(values))
 	for _, v := range values {
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"example.com/exerciseontology/internal/auth"
+	"example.com/exerciseontology/internal/subscription"
+)
+
+// SubscriptionHandler exposes the /v1/subscriptions management API backing the consumer's
+// fan-out plane.
+type SubscriptionHandler struct {
+	service *subscription.Service
+}
+
+// NewSubscriptionHandler constructs a SubscriptionHandler.
+func NewSubscriptionHandler(service *subscription.Service) *SubscriptionHandler {
+	return &SubscriptionHandler{service: service}
+}
+
+// RegisterRoutes registers the subscription management endpoints on mux.
+func (h *SubscriptionHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/v1/subscriptions", h.subscriptions)
+	mux.HandleFunc("/v1/subscriptions/", h.subscriptionByID)
+}
+
+func (h *SubscriptionHandler) subscriptions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.listSubscriptions(w, r)
+	case http.MethodPost:
+		h.registerSubscription(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "unsupported method")
+	}
+}
+
+func (h *SubscriptionHandler) listSubscriptions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.FromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "missing bearer token")
+		return
+	}
+	if !claims.HasScope(auth.ScopeOntologyRead) && !claims.HasScope(auth.ScopeOntologyWrite) {
+		writeError(w, http.StatusForbidden, "forbidden", "scope ontology:read required")
+		return
+	}
+
+	subs, err := h.service.List(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": subs})
+}
+
+func (h *SubscriptionHandler) registerSubscription(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.FromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "missing bearer token")
+		return
+	}
+	if !claims.HasScope(auth.ScopeOntologyWrite) {
+		writeError(w, http.StatusForbidden, "forbidden", "scope ontology:write required")
+		return
+	}
+
+	var req SubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "unable to parse body")
+		return
+	}
+	if err := req.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_failed", err.Error())
+		return
+	}
+
+	sub, err := h.service.Register(r.Context(), subscription.Subscription{
+		InfoTypeID:    req.InfoTypeID,
+		EventTypeGlob: req.EventTypeGlob,
+		TenantID:      req.TenantID,
+		ActivityType:  req.ActivityType,
+		Source:        req.Source,
+		Delivery: subscription.Delivery{
+			Topic:      req.Delivery.Topic,
+			WebhookURL: req.Delivery.WebhookURL,
+		},
+		StatusNotificationURI: req.StatusNotificationURI,
+	})
+	if err != nil {
+		if errors.Is(err, subscription.ErrInvalidDelivery) {
+			writeError(w, http.StatusBadRequest, "validation_failed", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"subscription": sub})
+}
+
+func (h *SubscriptionHandler) subscriptionByID(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.FromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "missing bearer token")
+		return
+	}
+	if !claims.HasScope(auth.ScopeOntologyWrite) {
+		writeError(w, http.StatusForbidden, "forbidden", "scope ontology:write required")
+		return
+	}
+	if r.Method != http.MethodDelete {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "unsupported method")
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/v1/subscriptions/")
+	if strings.TrimSpace(id) == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "missing subscription id")
+		return
+	}
+
+	if err := h.service.Unregister(r.Context(), id); err != nil {
+		if errors.Is(err, subscription.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "not_found", "subscription not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SubscriptionRequest is the POST /v1/subscriptions request payload.
+type SubscriptionRequest struct {
+	InfoTypeID            string          `json:"info_type_id"`
+	EventTypeGlob         string          `json:"event_type_glob"`
+	TenantID              string          `json:"tenant_id"`
+	ActivityType          string          `json:"activity_type"`
+	Source                string          `json:"source"`
+	Delivery              DeliveryRequest `json:"delivery"`
+	StatusNotificationURI string          `json:"status_notification_uri"`
+}
+
+// DeliveryRequest captures a subscription's chosen fan-out destination. Exactly one of Topic
+// or WebhookURL must be set.
+type DeliveryRequest struct {
+	Topic      string `json:"topic"`
+	WebhookURL string `json:"webhook_url"`
+}
+
+// Validate ensures request integrity.
+func (r SubscriptionRequest) Validate() error {
+	if strings.TrimSpace(r.EventTypeGlob) == "" {
+		return errors.New("event_type_glob is required")
+	}
+	if (r.Delivery.Topic == "") == (r.Delivery.WebhookURL == "") {
+		return errors.New("delivery must set exactly one of topic or webhook_url")
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"example.com/exerciseontology/internal/auth"
+	"example.com/exerciseontology/internal/knowledge"
+	"example.com/exerciseontology/internal/subscription"
+)
+
+func TestRegisterSubscriptionPersistsAndLists(t *testing.T) {
+	service := subscription.NewService(knowledge.NewInMemorySubscriptionRepository(), nil)
+	handler := NewSubscriptionHandler(service)
+
+	body, err := json.Marshal(SubscriptionRequest{
+		EventTypeGlob: "activity.*",
+		Delivery:      DeliveryRequest{Topic: "ontology.fanout"},
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/subscriptions", bytes.NewReader(body))
+	claims := &auth.Claims{
+		Subject:   "user",
+		TenantID:  "tenant",
+		Scopes:    scopesWith(auth.ScopeOntologyWrite),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	req = req.WithContext(auth.WithClaims(req.Context(), claims))
+
+	rr := httptest.NewRecorder()
+	handler.subscriptions(rr, req)
+	require.Equal(t, http.StatusCreated, rr.Code)
+
+	listReq := httptest.NewRequest(http.MethodGet, "/v1/subscriptions", nil)
+	listReq = listReq.WithContext(auth.WithClaims(listReq.Context(), claims))
+	listRR := httptest.NewRecorder()
+	handler.subscriptions(listRR, listReq)
+	require.Equal(t, http.StatusOK, listRR.Code)
+
+	var listed struct {
+		Items []subscription.Subscription `json:"items"`
+	}
+	require.NoError(t, json.NewDecoder(listRR.Body).Decode(&listed))
+	require.Len(t, listed.Items, 1)
+	require.Equal(t, "activity.*", listed.Items[0].EventTypeGlob)
+}
+
+func TestRegisterSubscriptionRejectsAmbiguousDelivery(t *testing.T) {
+	service := subscription.NewService(knowledge.NewInMemorySubscriptionRepository(), nil)
+	handler := NewSubscriptionHandler(service)
+
+	body, err := json.Marshal(SubscriptionRequest{EventTypeGlob: "activity.*"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/subscriptions", bytes.NewReader(body))
+	claims := &auth.Claims{
+		Subject:   "user",
+		TenantID:  "tenant",
+		Scopes:    scopesWith(auth.ScopeOntologyWrite),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	req = req.WithContext(auth.WithClaims(req.Context(), claims))
+
+	rr := httptest.NewRecorder()
+	handler.subscriptions(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestDeleteSubscriptionRequiresWriteScope(t *testing.T) {
+	service := subscription.NewService(knowledge.NewInMemorySubscriptionRepository(), nil)
+	handler := NewSubscriptionHandler(service)
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/subscriptions/some-id", nil)
+	claims := &auth.Claims{
+		Subject:   "user",
+		TenantID:  "tenant",
+		Scopes:    scopesWith(auth.ScopeOntologyRead),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	req = req.WithContext(auth.WithClaims(req.Context(), claims))
+
+	rr := httptest.NewRecorder()
+	handler.subscriptionByID(rr, req)
+	require.Equal(t, http.StatusForbidden, rr.Code)
+}
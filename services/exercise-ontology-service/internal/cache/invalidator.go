@@ -10,6 +10,9 @@ import (
 // Invalidator defines a cache invalidation contract.
 type Invalidator interface {
 	Invalidate(ctx context.Context, exerciseID string) error
+	// InvalidateBatch invalidates many exercises in one call, for callers (like a bulk import)
+	// that want a single end-of-run invalidation rather than one round trip per row.
+	InvalidateBatch(ctx context.Context, exerciseIDs []string) error
 }
 
 // NoopInvalidator is a no-op implementation.
@@ -18,6 +21,9 @@ type NoopInvalidator struct{}
 // Invalidate performs no action.
 func (NoopInvalidator) Invalidate(context.Context, string) error { return nil }
 
+// InvalidateBatch performs no action.
+func (NoopInvalidator) InvalidateBatch(context.Context, []string) error { return nil }
+
 // HTTPInvalidator calls an upstream edge cache invalidation endpoint.
 type HTTPInvalidator struct {
 	client *http.Client
@@ -25,13 +31,28 @@ type HTTPInvalidator struct {
 	token  string
 }
 
+// Option configures optional HTTPInvalidator behaviour.
+type Option func(*HTTPInvalidator)
+
+// WithRoundTripper overrides the HTTP client's transport, e.g. to inject OAuth2 bearer tokens
+// via a token.Source's RoundTripper.
+func WithRoundTripper(rt http.RoundTripper) Option {
+	return func(h *HTTPInvalidator) {
+		h.client.Transport = rt
+	}
+}
+
 // NewHTTPInvalidator constructs an HTTPInvalidator.
-func NewHTTPInvalidator(endpoint, token string, timeout time.Duration) *HTTPInvalidator {
-	return &HTTPInvalidator{
+func NewHTTPInvalidator(endpoint, token string, timeout time.Duration, opts ...Option) *HTTPInvalidator {
+	h := &HTTPInvalidator{
 		client: &http.Client{Timeout: timeout},
 		url:    strings.TrimRight(endpoint, "/"),
 		token:  token,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // Invalidate triggers an HTTP POST containing the exercise identifier.
@@ -57,6 +78,18 @@ func (h *HTTPInvalidator) Invalidate(ctx context.Context, exerciseID string) err
 	return nil
 }
 
+// InvalidateBatch invalidates each ID in turn, stopping at the first failure. The upstream edge
+// cache endpoint only takes one identifier per request, so there's no single batched call to make
+// here - this just spares the caller from looping itself.
+func (h *HTTPInvalidator) InvalidateBatch(ctx context.Context, exerciseIDs []string) error {
+	for _, id := range exerciseIDs {
+		if err := h.Invalidate(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // InvalidationError represents a non-successful invalidation response.
 type InvalidationError struct {
 	Status int
@@ -2,23 +2,53 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"strings"
 	"time"
+
+	"example.com/platform/libs/go/kafkasecurity"
+	"example.com/platform/libs/go/logging"
+	"example.com/platform/libs/go/schemaregistry"
+	"example.com/platform/libs/go/token"
 )
 
 // Config captures runtime configuration values for the ontology service.
 type Config struct {
 	HTTPAddress            string
 	DgraphURL              string
+	DgraphGRPCAddr         string            // Dgraph Alpha gRPC endpoint (e.g. "dgraph-alpha:9080"); when set, takes precedence over DgraphURL.
+	DgraphACLUserid        string            // Dgraph ACL user; when set, DgraphRepository logs in per tenant namespace.
+	DgraphACLPassword      string            // Dgraph ACL password.
+	DgraphACLNamespaces    map[string]uint64 // tenant ID -> Dgraph namespace, parsed from "tenant:ns,tenant:ns".
+	DgraphACLSharedSecret  string            // Sent as Dgraph-AuthToken alongside the per-namespace ACL access JWT.
+	EtcdEndpoints          []string          // etcd v3 endpoints; when set, takes precedence over Dgraph/in-memory.
+	EtcdUsername           string
+	EtcdPassword           string
+	EtcdTLSCertFile        string
+	EtcdTLSKeyFile         string
+	EtcdTLSCAFile          string
 	JWTSecret              string
 	JWTIssuer              string
 	HTTPTimeout            time.Duration
 	CacheInvalidationURL   string
 	CacheInvalidationToken string
 	KafkaBrokers           []string
+	KafkaSecurity          kafkasecurity.Config
 	ConsumerGroup          string
 	ConsumerTopics         []string
 	MetricsAddress         string
+	SchemaRegistry         schemaregistry.Config
+	OIDCIssuer             string // OIDC issuer URL; when set, auth verifies tokens against its JWKS instead of JWTSecret.
+	OIDCAudiences          []string
+	JWKSRefreshInterval    time.Duration
+	JWKSNegativeCacheTTL   time.Duration
+	OAuth2                 token.Config // Client-credentials config; empty ClientID/TokenURL means OAuth2 is disabled.
+	Logging                logging.Config
+	MaxDecompressedBytes   int64         // Upper bound on a message payload's decompressed size; guards DecompressingHandler against zip bombs.
+	MetricsDebugToken      string        // Bearer token gating the metrics listener's /debug/ tree; empty disables it.
+	ConsumerMaxAttempts    int           // Handler retries (including the first attempt) before a message is dead-lettered. <=1 disables retry.
+	ConsumerHandleTimeout  time.Duration // Per-attempt bound on handler.Handle; zero leaves it bounded only by ctx.
+	ConsumerDLQSuffix      string        // Dead-letter topic suffix, e.g. "<topic>.dlq"; empty uses the DeadLetterPublisher's own default.
 }
 
 // Load reads environment variables and applies defaults.
@@ -26,15 +56,39 @@ func Load() Config {
 	return Config{
 		HTTPAddress:            getEnv("HTTP_ADDRESS", ":8090"),
 		DgraphURL:              getEnv("DGRAPH_URL", "http://dgraph-alpha:8080"),
+		DgraphGRPCAddr:         getEnv("DGRAPH_GRPC_ADDR", ""),
+		DgraphACLUserid:        getEnv("DGRAPH_ACL_USERID", ""),
+		DgraphACLPassword:      getEnv("DGRAPH_ACL_PASSWORD", ""),
+		DgraphACLNamespaces:    splitNamespaceMap(getEnv("DGRAPH_ACL_NAMESPACES", "")),
+		DgraphACLSharedSecret:  getEnv("DGRAPH_ACL_SHARED_SECRET", ""),
+		EtcdEndpoints:          splitAndTrim(getEnv("ETCD_ENDPOINTS", "")),
+		EtcdUsername:           getEnv("ETCD_USERNAME", ""),
+		EtcdPassword:           getEnv("ETCD_PASSWORD", ""),
+		EtcdTLSCertFile:        getEnv("ETCD_TLS_CERT_FILE", ""),
+		EtcdTLSKeyFile:         getEnv("ETCD_TLS_KEY_FILE", ""),
+		EtcdTLSCAFile:          getEnv("ETCD_TLS_CA_FILE", ""),
 		JWTSecret:              getEnv("JWT_SECRET", "dev-secret-change-me"),
 		JWTIssuer:              getEnv("JWT_ISSUER", "i5e.identity"),
 		HTTPTimeout:            getDurationEnv("HTTP_TIMEOUT", 5*time.Second),
 		CacheInvalidationURL:   getEnv("CACHE_INVALIDATION_URL", ""),
 		CacheInvalidationToken: getEnv("CACHE_INVALIDATION_TOKEN", ""),
 		KafkaBrokers:           splitAndTrim(getEnv("KAFKA_BROKERS", "kafka:9092")),
+		KafkaSecurity:          kafkasecurity.FromEnv(),
 		ConsumerGroup:          getEnv("CONSUMER_GROUP_ID", "exercise-ontology-consumer"),
 		ConsumerTopics:         splitAndTrim(getEnv("CONSUMER_TOPICS", "activity_events")),
 		MetricsAddress:         getEnv("METRICS_ADDRESS", ":9195"),
+		SchemaRegistry:         schemaregistry.FromEnv(),
+		OIDCIssuer:             getEnv("OIDC_ISSUER", ""),
+		OIDCAudiences:          splitAndTrim(getEnv("OIDC_AUDIENCES", "")),
+		JWKSRefreshInterval:    getDurationEnv("JWKS_REFRESH_INTERVAL", 15*time.Minute),
+		JWKSNegativeCacheTTL:   getDurationEnv("JWKS_NEGATIVE_CACHE_TTL", 30*time.Second),
+		OAuth2:                 token.FromEnv(),
+		Logging:                logging.FromEnv(),
+		MaxDecompressedBytes:   getInt64Env("MAX_DECOMPRESSED_BYTES", 16<<20),
+		MetricsDebugToken:      getEnv("METRICS_DEBUG_TOKEN", ""),
+		ConsumerMaxAttempts:    getIntEnv("CONSUMER_MAX_ATTEMPTS", 1),
+		ConsumerHandleTimeout:  getDurationEnv("CONSUMER_HANDLE_TIMEOUT", 0),
+		ConsumerDLQSuffix:      getEnv("CONSUMER_DLQ_SUFFIX", ""),
 	}
 }
 
@@ -64,3 +118,39 @@ func getDurationEnv(key string, fallback time.Duration) time.Duration {
 	}
 	return fallback
 }
+
+// splitNamespaceMap parses a "tenant:ns,tenant:ns" string into a tenant-ID-to-Dgraph-namespace
+// map. Malformed or non-numeric entries are skipped.
+func splitNamespaceMap(value string) map[string]uint64 {
+	result := make(map[string]uint64)
+	for _, pair := range splitAndTrim(value) {
+		tenant, ns, ok := strings.Cut(pair, ":")
+		if !ok {
+			continue
+		}
+		parsed, err := strconv.ParseUint(strings.TrimSpace(ns), 10, 64)
+		if err != nil {
+			continue
+		}
+		result[strings.TrimSpace(tenant)] = parsed
+	}
+	return result
+}
+
+func getInt64Env(key string, fallback int64) int64 {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		if parsed, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+func getIntEnv(key string, fallback int) int {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,36 @@
+package consumer
+
+import (
+	"context"
+	"strings"
+
+	"example.com/exerciseontology/internal/domain"
+	"example.com/exerciseontology/internal/knowledge"
+)
+
+func init() {
+	knowledge.RegisterHook(knowledge.AllKinds, complementaryBackfillHook{})
+}
+
+// complementaryBackfillHook fills in ComplementaryTo links for exercises whose Name matches an
+// activityCatalog entry but were created without going through Handle - seeded directly, bulk
+// imported, or migrated from a backend that predates enrichment - so the ontology graph converges
+// to the same links a freshly enriched exercise would get.
+type complementaryBackfillHook struct{}
+
+func (complementaryBackfillHook) Stage() knowledge.Stage { return knowledge.StageStartup }
+
+func (complementaryBackfillHook) Apply(ctx context.Context, exercise domain.Exercise) (domain.Exercise, error) {
+	tenantID, _, ok := strings.Cut(exercise.ID, ":")
+	if !ok {
+		return exercise, nil
+	}
+
+	meta, ok := activityCatalog[strings.ToLower(strings.TrimSpace(exercise.Name))]
+	if !ok || len(meta.ComplementaryTo) == 0 {
+		return exercise, nil
+	}
+
+	exercise.ComplementaryTo = mergeSlices(exercise.ComplementaryTo, namesToIDs(tenantID, meta.ComplementaryTo))
+	return exercise, nil
+}
@@ -0,0 +1,35 @@
+package consumer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"example.com/exerciseontology/internal/domain"
+)
+
+func TestComplementaryBackfillHookFillsLinksFromCatalog(t *testing.T) {
+	hook := complementaryBackfillHook{}
+	require.Equal(t, "startup", string(hook.Stage()))
+
+	updated, err := hook.Apply(context.Background(), domain.Exercise{
+		ID:   ActivityExerciseID("tenant", "Tempo Ride"),
+		Name: "Tempo Ride",
+	})
+	require.NoError(t, err)
+	require.Contains(t, updated.ComplementaryTo, ActivityExerciseID("tenant", "Recovery Ride"))
+	require.Contains(t, updated.ComplementaryTo, ActivityExerciseID("tenant", "Strength Session"))
+}
+
+func TestComplementaryBackfillHookLeavesUnknownExercisesUnchanged(t *testing.T) {
+	hook := complementaryBackfillHook{}
+
+	exercise := domain.Exercise{
+		ID:   ActivityExerciseID("tenant", "Bodyweight Squat"),
+		Name: "Bodyweight Squat",
+	}
+	updated, err := hook.Apply(context.Background(), exercise)
+	require.NoError(t, err)
+	require.Equal(t, exercise, updated)
+}
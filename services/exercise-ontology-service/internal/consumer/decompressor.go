@@ -0,0 +1,109 @@
+package consumer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// maxDecompressedSizeDefault bounds decompression when a DecompressingHandler is constructed
+// without an explicit limit, guarding against zip-bomb style payloads absent operator config.
+const maxDecompressedSizeDefault = 16 << 20 // 16MiB
+
+// DecompressingHandler is a Handler middleware that inspects a message's content-encoding
+// header and, when set, decompresses the payload before forwarding it to the wrapped Handler.
+// It runs ahead of SchemaDecoder in the chain so Confluent wire-format framing is parsed from
+// the decompressed bytes. Messages without a content-encoding header pass through unchanged.
+type DecompressingHandler struct {
+	next    Handler
+	maxSize int64
+	zstdDec *zstd.Decoder
+}
+
+// NewDecompressingHandler constructs a DecompressingHandler wrapping next. maxDecompressedSize
+// caps the number of bytes read out of a compressed payload; passing 0 selects
+// maxDecompressedSizeDefault.
+func NewDecompressingHandler(next Handler, maxDecompressedSize int64) *DecompressingHandler {
+	if maxDecompressedSize <= 0 {
+		maxDecompressedSize = maxDecompressedSizeDefault
+	}
+	zstdDec, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(uint64(maxDecompressedSize)))
+	if err != nil {
+		// zstd.NewReader(nil) only fails on invalid options, never for a nil reader, so this
+		// would indicate a programming error rather than a runtime condition.
+		panic(fmt.Sprintf("consumer: construct zstd decoder: %v", err))
+	}
+	return &DecompressingHandler{next: next, maxSize: maxDecompressedSize, zstdDec: zstdDec}
+}
+
+// Handle implements Handler.
+func (d *DecompressingHandler) Handle(ctx context.Context, msg Message) error {
+	encoding := msg.Headers["content-encoding"]
+	if encoding == "" {
+		return d.next.Handle(ctx, msg)
+	}
+
+	body, err := d.decompress(encoding, msg.Payload)
+	if err != nil {
+		decompressionErrors.WithLabelValues(msg.Topic, encoding).Inc()
+		return fmt.Errorf("decompress payload (encoding=%s): %w", encoding, err)
+	}
+
+	msg.Payload = body
+	return d.next.Handle(ctx, msg)
+}
+
+func (d *DecompressingHandler) decompress(encoding string, payload []byte) ([]byte, error) {
+	switch encoding {
+	case "gzip":
+		gz, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("open gzip reader: %w", err)
+		}
+		defer gz.Close()
+		return readLimited(gz, d.maxSize)
+	case "snappy":
+		decoded, err := snappy.Decode(nil, payload)
+		if err != nil {
+			return nil, fmt.Errorf("snappy decode: %w", err)
+		}
+		if int64(len(decoded)) > d.maxSize {
+			return nil, fmt.Errorf("decompressed payload exceeds limit of %d bytes", d.maxSize)
+		}
+		return decoded, nil
+	case "zstd":
+		// DecodeAll is the only concurrency-safe entry point on a shared *zstd.Decoder -
+		// IOReadCloser/Read stream state across the decoder and would corrupt output if two
+		// goroutines (one per partition/topic reader) decoded through it at once.
+		decoded, err := d.zstdDec.DecodeAll(payload, nil)
+		if err != nil {
+			return nil, fmt.Errorf("zstd decode: %w", err)
+		}
+		if int64(len(decoded)) > d.maxSize {
+			return nil, fmt.Errorf("decompressed payload exceeds limit of %d bytes", d.maxSize)
+		}
+		return decoded, nil
+	default:
+		return nil, fmt.Errorf("unsupported content-encoding %q", encoding)
+	}
+}
+
+// readLimited reads r to completion, failing once more than maxSize bytes have been read so a
+// malicious or corrupt payload can't exhaust memory during decompression.
+func readLimited(r io.ReadCloser, maxSize int64) ([]byte, error) {
+	defer r.Close()
+	limited := io.LimitReader(r, maxSize+1)
+	body, err := io.ReadAll(limited)
+	if err != nil {
+		return nil, fmt.Errorf("read decompressed payload: %w", err)
+	}
+	if int64(len(body)) > maxSize {
+		return nil, fmt.Errorf("decompressed payload exceeds limit of %d bytes", maxSize)
+	}
+	return body, nil
+}
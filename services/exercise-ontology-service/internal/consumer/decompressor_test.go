@@ -0,0 +1,212 @@
+package consumer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipPayload(t *testing.T, raw []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write(raw)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func zstdPayload(t *testing.T, raw []byte) []byte {
+	t.Helper()
+	enc, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	defer enc.Close()
+	return enc.EncodeAll(raw, nil)
+}
+
+func TestDecompressingHandlerGzip(t *testing.T) {
+	next := &RecordingHandler{}
+	handler := NewDecompressingHandler(next, 0)
+
+	raw := []byte(`{"example":true}`)
+	msg := Message{
+		Topic:   "activity_events",
+		Payload: gzipPayload(t, raw),
+		Headers: map[string]string{"content-encoding": "gzip"},
+	}
+
+	err := handler.Handle(context.Background(), msg)
+	require.NoError(t, err)
+	require.Equal(t, 1, next.count)
+	require.JSONEq(t, string(raw), string(next.last.Payload))
+}
+
+func TestDecompressingHandlerSnappy(t *testing.T) {
+	next := &RecordingHandler{}
+	handler := NewDecompressingHandler(next, 0)
+
+	raw := []byte(`{"example":true}`)
+	msg := Message{
+		Topic:   "activity_events",
+		Payload: snappy.Encode(nil, raw),
+		Headers: map[string]string{"content-encoding": "snappy"},
+	}
+
+	err := handler.Handle(context.Background(), msg)
+	require.NoError(t, err)
+	require.Equal(t, 1, next.count)
+	require.JSONEq(t, string(raw), string(next.last.Payload))
+}
+
+func TestDecompressingHandlerZstd(t *testing.T) {
+	next := &RecordingHandler{}
+	handler := NewDecompressingHandler(next, 0)
+
+	raw := []byte(`{"example":true}`)
+	msg := Message{
+		Topic:   "activity_events",
+		Payload: zstdPayload(t, raw),
+		Headers: map[string]string{"content-encoding": "zstd"},
+	}
+
+	err := handler.Handle(context.Background(), msg)
+	require.NoError(t, err)
+	require.Equal(t, 1, next.count)
+	require.JSONEq(t, string(raw), string(next.last.Payload))
+}
+
+// TestDecompressingHandlerZstdDecodesConcurrently guards against reintroducing a shared
+// *zstd.Decoder stream (e.g. via IOReadCloser), which klauspost/compress/zstd documents as
+// unsafe for concurrent reuse: two goroutines decoding through the same streaming reader at
+// once can corrupt each other's output. Every goroutine here decodes a distinct payload through
+// the one DecompressingHandler shared across Kafka partitions/topic readers and must see back
+// exactly its own content.
+func TestDecompressingHandlerZstdDecodesConcurrently(t *testing.T) {
+	next := &RecordingHandler{}
+	handler := NewDecompressingHandler(next, 0)
+
+	const workers = 32
+	raws := make([][]byte, workers)
+	payloads := make([][]byte, workers)
+	for i := range raws {
+		raws[i] = []byte(fmt.Sprintf(`{"worker":%d}`, i))
+		payloads[i] = zstdPayload(t, raws[i])
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			decoded, err := handler.decompress("zstd", payloads[i])
+			if err != nil {
+				errs <- err
+				return
+			}
+			if !bytes.Equal(decoded, raws[i]) {
+				errs <- fmt.Errorf("worker %d: got %q, want %q", i, decoded, raws[i])
+				return
+			}
+			errs <- nil
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+}
+
+func TestDecompressingHandlerNoEncodingPassesThrough(t *testing.T) {
+	next := &RecordingHandler{}
+	handler := NewDecompressingHandler(next, 0)
+
+	msg := Message{Topic: "activity_events", Payload: json.RawMessage(`{"example":true}`)}
+
+	err := handler.Handle(context.Background(), msg)
+	require.NoError(t, err)
+	require.Equal(t, 1, next.count)
+}
+
+func TestDecompressingHandlerRejectsOversizedPayload(t *testing.T) {
+	next := &RecordingHandler{}
+	handler := NewDecompressingHandler(next, 8)
+
+	raw := bytes.Repeat([]byte("a"), 1024)
+	msg := Message{
+		Topic:   "activity_events",
+		Payload: gzipPayload(t, raw),
+		Headers: map[string]string{"content-encoding": "gzip"},
+	}
+
+	err := handler.Handle(context.Background(), msg)
+	require.Error(t, err)
+	require.Equal(t, 0, next.count)
+}
+
+func TestDecompressingHandlerRejectsOversizedZstdPayload(t *testing.T) {
+	next := &RecordingHandler{}
+	handler := NewDecompressingHandler(next, 8)
+
+	raw := bytes.Repeat([]byte("a"), 1024)
+	msg := Message{
+		Topic:   "activity_events",
+		Payload: zstdPayload(t, raw),
+		Headers: map[string]string{"content-encoding": "zstd"},
+	}
+
+	err := handler.Handle(context.Background(), msg)
+	require.Error(t, err)
+	require.Equal(t, 0, next.count)
+}
+
+func TestDecompressingHandlerRejectsUnknownEncoding(t *testing.T) {
+	next := &RecordingHandler{}
+	handler := NewDecompressingHandler(next, 0)
+
+	msg := Message{
+		Topic:   "activity_events",
+		Payload: []byte("opaque"),
+		Headers: map[string]string{"content-encoding": "brotli"},
+	}
+
+	err := handler.Handle(context.Background(), msg)
+	require.Error(t, err)
+	require.Equal(t, 0, next.count)
+}
+
+func TestProcessorCommitsOversizedPayloadAfterDecompressionError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	raw := bytes.Repeat([]byte("a"), 1024)
+	msg := kafka.Message{
+		Topic: "activity_events",
+		Value: gzipPayload(t, raw),
+		Time:  time.Now().UTC(),
+		Headers: []kafka.Header{
+			{Key: "content-encoding", Value: []byte("gzip")},
+		},
+	}
+
+	reader := &stubReader{msgs: []kafka.Message{msg}, errAfter: context.Canceled}
+	next := &RecordingHandler{}
+	handler := NewDecompressingHandler(next, 8)
+	proc := NewProcessor(reader, handler)
+
+	err := proc.Run(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 0, next.count)
+	require.Equal(t, 1, reader.commitCount)
+}
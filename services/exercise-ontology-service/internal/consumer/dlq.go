@@ -0,0 +1,73 @@
+package consumer
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// DeadLetterPublisher routes a message that a Processor gave up on - either because it failed
+// decode validation or exhausted its retry policy - to a dead-letter topic.
+type DeadLetterPublisher interface {
+	Publish(ctx context.Context, msg Message, stage string, cause error, attempts int) error
+}
+
+// dlqStageDecode and dlqStageHandle are the two x-error-stage values Publish writes: a message
+// is routed to the DLQ for a decode failure before Handler ever sees it, or for a handle failure
+// after RetryPolicy's attempts are exhausted.
+const (
+	dlqStageDecode = "decode"
+	dlqStageHandle = "handle"
+)
+
+// kafkaDeadLetterPublisher publishes failed messages to "<topic><suffix>" via a kafka.Writer.
+type kafkaDeadLetterPublisher struct {
+	writer *kafka.Writer
+	suffix string
+}
+
+// NewKafkaDeadLetterPublisher builds a DeadLetterPublisher that writes to brokers. suffix
+// defaults to ".dlq" when empty, so a topic "activity_events" is dead-lettered to
+// "activity_events.dlq".
+func NewKafkaDeadLetterPublisher(brokers []string, suffix string) DeadLetterPublisher {
+	if suffix == "" {
+		suffix = ".dlq"
+	}
+	return &kafkaDeadLetterPublisher{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Balancer:               &kafka.LeastBytes{},
+			AllowAutoTopicCreation: true,
+		},
+		suffix: suffix,
+	}
+}
+
+// Publish implements DeadLetterPublisher, preserving msg's original headers and appending the
+// x-original-*/x-error-*/x-attempts headers a consumer of the DLQ topic needs to triage or
+// replay it.
+func (p *kafkaDeadLetterPublisher) Publish(ctx context.Context, msg Message, stage string, cause error, attempts int) error {
+	headers := make([]kafka.Header, 0, len(msg.Headers)+6)
+	for key, value := range msg.Headers {
+		headers = append(headers, kafka.Header{Key: key, Value: []byte(value)})
+	}
+	headers = append(headers,
+		kafka.Header{Key: "x-original-topic", Value: []byte(msg.Topic)},
+		kafka.Header{Key: "x-original-partition", Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: "x-original-offset", Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: "x-error-stage", Value: []byte(stage)},
+		kafka.Header{Key: "x-error-message", Value: []byte(cause.Error())},
+		kafka.Header{Key: "x-attempts", Value: []byte(strconv.Itoa(attempts))},
+	)
+
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Topic:   msg.Topic + p.suffix,
+		Key:     msg.Key,
+		Value:   msg.Payload,
+		Headers: headers,
+	})
+}
+
+// Close releases the underlying kafka.Writer.
+func (p *kafkaDeadLetterPublisher) Close() error { return p.writer.Close() }
@@ -5,13 +5,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"regexp"
 	"sort"
 	"strings"
 	"time"
 
+	"example.com/exerciseontology/internal/auth"
 	"example.com/exerciseontology/internal/domain"
 	"example.com/platform/libs/go/events"
+	"example.com/platform/libs/go/logging"
 )
 
 var nonAlphaNumeric = regexp.MustCompile(`[^a-z0-9]+`)
@@ -19,11 +22,13 @@ var nonAlphaNumeric = regexp.MustCompile(`[^a-z0-9]+`)
 // EnrichmentHandler maps activity events to ontology exercises.
 type EnrichmentHandler struct {
 	service *domain.Service
+	logger  *slog.Logger
 }
 
-// NewEnrichmentHandler constructs an enrichment handler backed by the provided service.
-func NewEnrichmentHandler(service *domain.Service) Handler {
-	return &EnrichmentHandler{service: service}
+// NewEnrichmentHandler constructs an enrichment handler backed by the provided service. A nil
+// logger falls back to slog.Default().
+func NewEnrichmentHandler(service *domain.Service, logger *slog.Logger) Handler {
+	return &EnrichmentHandler{service: service, logger: logging.OrDefault(logger)}
 }
 
 // Handle projects activity.created events into the ontology repository.
@@ -32,21 +37,20 @@ func (h *EnrichmentHandler) Handle(ctx context.Context, msg Message) error {
 		return nil
 	}
 
+	// SchemaDecoder strips the Confluent wire format upstream, so msg.Payload is already
+	// plain JSON by the time it reaches Handle.
 	var evt events.ActivityCreated
-	payload := msg.Payload
-	// Handle Confluent Schema Registry wire format (magic byte + 4-byte schema id)
-	if len(payload) >= 5 && payload[0] == 0x00 {
-		payload = payload[5:]
-	}
-	if err := json.Unmarshal(payload, &evt); err != nil {
+	if err := json.Unmarshal(msg.Payload, &evt); err != nil {
 		return err
 	}
 
+	// Route repository calls to this event's Dgraph namespace (see knowledge.DgraphAuth), so
+	// tenants stay isolated at the graph layer rather than by exercise_id prefix alone.
+	ctx = auth.WithClaims(ctx, &auth.Claims{TenantID: evt.TenantID})
+
 	exerciseID := ActivityExerciseID(evt.TenantID, evt.ActivityType)
-	existing, err := h.service.GetExercise(ctx, exerciseID)
-	if err != nil && !errors.Is(err, domain.ErrExerciseNotFound) {
-		return err
-	}
+	h.logger.Debug("enriching activity event",
+		"tenant_id", evt.TenantID, "event_id", evt.ActivityID, "event_type", evt.ActivityType, "exercise_id", exerciseID)
 
 	meta := lookupMetadata(evt.ActivityType)
 	complementaryIDs, contraindicatedIDs := deriveRelationshipIDs(evt.TenantID, evt.ActivityType, meta)
@@ -59,39 +63,42 @@ func (h *EnrichmentHandler) Handle(ctx context.Context, msg Message) error {
 		eventTime = time.Now().UTC()
 	}
 
-	exercise := domain.Exercise{
-		ID:                exerciseID,
-		Name:              evt.ActivityType,
-		Difficulty:        meta.Difficulty,
-		Targets:           copyIfNotEmpty(meta.Targets),
-		Requires:          copyIfNotEmpty(meta.Requires),
-		ComplementaryTo:   complementaryIDs,
-		Contraindications: contraindicatedIDs,
-		LastUpdated:       eventTime,
-		LastSeenAt:        eventTime,
-	}
-
-	if existing != nil {
-		exercise.SessionCount = existing.SessionCount + 1
-		exercise.Targets = mergeSlices(exercise.Targets, existing.Targets)
-		exercise.Requires = mergeSlices(exercise.Requires, existing.Requires)
-		exercise.ComplementaryTo = mergeSlices(exercise.ComplementaryTo, existing.ComplementaryTo)
-		exercise.Contraindications = mergeSlices(exercise.Contraindications, existing.Contraindications)
-		exercise.Difficulty = coalesce(existing.Difficulty, exercise.Difficulty)
-		if existing.LastUpdated.After(exercise.LastUpdated) {
-			exercise.LastUpdated = existing.LastUpdated
+	mutate := func(current *domain.Exercise) (*domain.Exercise, error) {
+		exercise := domain.Exercise{
+			ID:                exerciseID,
+			Name:              evt.ActivityType,
+			Difficulty:        meta.Difficulty,
+			Targets:           copyIfNotEmpty(meta.Targets),
+			Requires:          copyIfNotEmpty(meta.Requires),
+			ComplementaryTo:   complementaryIDs,
+			Contraindications: contraindicatedIDs,
+			LastUpdated:       eventTime,
+			LastSeenAt:        eventTime,
 		}
-		if existing.LastSeenAt.After(exercise.LastSeenAt) {
-			exercise.LastSeenAt = existing.LastSeenAt
+
+		if current != nil {
+			exercise.SessionCount = current.SessionCount + 1
+			exercise.Targets = mergeSlices(exercise.Targets, current.Targets)
+			exercise.Requires = mergeSlices(exercise.Requires, current.Requires)
+			exercise.ComplementaryTo = mergeSlices(exercise.ComplementaryTo, current.ComplementaryTo)
+			exercise.Contraindications = mergeSlices(exercise.Contraindications, current.Contraindications)
+			exercise.Difficulty = coalesce(current.Difficulty, exercise.Difficulty)
+			if current.LastUpdated.After(exercise.LastUpdated) {
+				exercise.LastUpdated = current.LastUpdated
+			}
+			if current.LastSeenAt.After(exercise.LastSeenAt) {
+				exercise.LastSeenAt = current.LastSeenAt
+			}
+		} else {
+			exercise.SessionCount = 1
 		}
-	} else {
-		exercise.SessionCount = 1
+		return &exercise, nil
 	}
 
 	sessionID := fmt.Sprintf("%s:%s", evt.TenantID, evt.ActivityID)
 	session := domain.ActivitySession{
 		ID:          sessionID,
-		ExerciseID:  exercise.ID,
+		ExerciseID:  exerciseID,
 		ActivityID:  evt.ActivityID,
 		TenantID:    evt.TenantID,
 		UserID:      evt.UserID,
@@ -102,9 +109,16 @@ func (h *EnrichmentHandler) Handle(ctx context.Context, msg Message) error {
 		RecordedAt:  msg.Timestamp,
 	}
 
-	updated, err := h.service.UpsertExerciseWithSession(ctx, exercise, session)
+	// GuaranteedUpdateWithSession writes the merge conditionally on the version it read, so
+	// when two enrichment handlers process events for the same exercise concurrently the losing
+	// write fails with domain.ErrConflict (returned below) instead of clobbering the other's
+	// SessionCount/relationship merge - the session edge is committed under the same version
+	// check as the merge, not in a separate unguarded write.
+	updated, err := h.service.GuaranteedUpdateWithSession(ctx, exerciseID, session, mutate)
 	if err == nil {
 		RecordProcessed(msg)
+		h.logger.Info("activity event enriched",
+			"tenant_id", evt.TenantID, "event_id", evt.ActivityID, "event_type", evt.ActivityType, "exercise_id", updated.ID)
 	} else {
 		return err
 	}
@@ -3,6 +3,9 @@ package consumer
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -28,7 +31,7 @@ func TestLookupMetadataFallsBack(t *testing.T) {
 func TestEnrichmentHandlerCreatesExercise(t *testing.T) {
 	repo := knowledge.NewInMemoryRepository()
 	service := domain.NewService(repo, cache.NoopInvalidator{})
-	handler := NewEnrichmentHandler(service)
+	handler := NewEnrichmentHandler(service, nil)
 
 	// Seed an existing exercise to ensure the handler increments session counts.
 	_, err := service.UpsertExercise(context.Background(), domain.Exercise{
@@ -79,3 +82,77 @@ func TestEnrichmentHandlerCreatesExercise(t *testing.T) {
 	require.Equal(t, "act-123", sessions[0].ActivityID)
 	require.Equal(t, stored.ID, sessions[0].ExerciseID)
 }
+
+// TestEnrichmentHandlerAccumulatesSessionCountConcurrently guards against two enrichment
+// handlers racing on the same exercise. GuaranteedUpdate surfaces a losing write as ErrConflict
+// rather than silently clobbering the winner's SessionCount, so - exactly as a redelivered Kafka
+// message would - each goroutine here retries Handle until it observes its own event recorded.
+func TestEnrichmentHandlerAccumulatesSessionCountConcurrently(t *testing.T) {
+	repo := knowledge.NewInMemoryRepository()
+	service := domain.NewService(repo, cache.NoopInvalidator{})
+	handler := NewEnrichmentHandler(service, nil)
+
+	const concurrentEvents = 20
+	startedAt := time.Date(2025, time.October, 27, 12, 0, 0, 0, time.UTC)
+
+	// Seed the exercise first so every goroutine below races on a versioned update rather than
+	// the initial unconditional create, which (like the Dgraph/etcd repositories) always
+	// succeeds regardless of ResourceVersion and so isn't CAS-protected.
+	_, err := service.UpsertExercise(context.Background(), domain.Exercise{
+		ID:          ActivityExerciseID("tenant", "Tempo Ride"),
+		Name:        "Tempo Ride",
+		LastUpdated: startedAt.Add(-time.Hour),
+		LastSeenAt:  startedAt.Add(-time.Hour),
+	})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, concurrentEvents)
+	for i := 0; i < concurrentEvents; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			evt := events.ActivityCreated{
+				ActivityID:   fmt.Sprintf("act-%d", i),
+				TenantID:     "tenant",
+				UserID:       "user",
+				ActivityType: "Tempo Ride",
+				StartedAt:    startedAt,
+				DurationMin:  45,
+			}
+			payload, err := json.Marshal(evt)
+			if err != nil {
+				errs <- err
+				return
+			}
+			msg := Message{
+				Headers:   map[string]string{"event_type": "activity.created"},
+				Payload:   payload,
+				Timestamp: startedAt,
+			}
+
+			var err2 error
+			for attempt := 0; attempt < concurrentEvents; attempt++ {
+				err2 = handler.Handle(context.Background(), msg)
+				if err2 == nil || !errors.Is(err2, domain.ErrConflict) {
+					break
+				}
+			}
+			errs <- err2
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	stored, err := repo.Get(context.Background(), ActivityExerciseID("tenant", "Tempo Ride"))
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	require.Equal(t, concurrentEvents, stored.SessionCount)
+
+	sessions, err := repo.ListSessions(context.Background(), stored.ID, concurrentEvents+1)
+	require.NoError(t, err)
+	require.Len(t, sessions, concurrentEvents)
+}
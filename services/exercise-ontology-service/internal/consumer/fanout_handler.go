@@ -0,0 +1,141 @@
+package consumer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"example.com/exerciseontology/internal/subscription"
+	"example.com/platform/libs/go/logging"
+)
+
+// SubscriptionLister supplies the active subscription set FanOutHandler matches messages
+// against. subscription.Service satisfies it.
+type SubscriptionLister interface {
+	List(ctx context.Context) ([]subscription.Subscription, error)
+}
+
+// TopicPublisher republishes a matched message to a subscription's delivery topic.
+type TopicPublisher interface {
+	Publish(ctx context.Context, topic string, msg Message) error
+}
+
+// kafkaTopicPublisher publishes fan-out matches via a single kafka.Writer; the destination
+// topic is chosen per message since different subscriptions deliver to different topics.
+type kafkaTopicPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaTopicPublisher constructs a TopicPublisher writing to brokers.
+func NewKafkaTopicPublisher(brokers []string) TopicPublisher {
+	return &kafkaTopicPublisher{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Balancer:               &kafka.LeastBytes{},
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+// Publish implements TopicPublisher.
+func (p *kafkaTopicPublisher) Publish(ctx context.Context, topic string, msg Message) error {
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Topic: topic,
+		Key:   msg.Key,
+		Value: msg.Payload,
+	})
+}
+
+// fanOutFields extracts the subset of an event's body FanOutHandler matches subscriptions
+// against, beyond what's already carried in Message headers.
+type fanOutFields struct {
+	ActivityType string `json:"activity_type"`
+	Source       string `json:"source"`
+}
+
+// FanOutHandler wraps next, calling it first, then evaluates every active subscription's
+// predicate against the message and republishes matches to their delivery topic or POSTs them
+// to their webhook. Fan-out failures are logged, not returned, so a misconfigured subscription
+// can't stall or dead-letter the primary enrichment pipeline.
+type FanOutHandler struct {
+	next      Handler
+	lister    SubscriptionLister
+	publisher TopicPublisher
+	webhook   *http.Client
+	logger    *slog.Logger
+}
+
+// NewFanOutHandler constructs a FanOutHandler wrapping next. A nil logger falls back to
+// slog.Default().
+func NewFanOutHandler(next Handler, lister SubscriptionLister, publisher TopicPublisher, webhookTimeout time.Duration, logger *slog.Logger) *FanOutHandler {
+	return &FanOutHandler{
+		next:      next,
+		lister:    lister,
+		publisher: publisher,
+		webhook:   &http.Client{Timeout: webhookTimeout},
+		logger:    logging.OrDefault(logger),
+	}
+}
+
+// Handle implements Handler.
+func (h *FanOutHandler) Handle(ctx context.Context, msg Message) error {
+	if err := h.next.Handle(ctx, msg); err != nil {
+		return err
+	}
+
+	subs, err := h.lister.List(ctx)
+	if err != nil {
+		h.logger.Warn("subscription list failed, skipping fan-out", "error", err)
+		return nil
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	var fields fanOutFields
+	_ = json.Unmarshal(msg.Payload, &fields)
+	eventType := msg.Headers["event_type"]
+	tenantID := msg.Headers["tenant_id"]
+
+	for _, sub := range subs {
+		if !sub.Matches(eventType, tenantID, fields.ActivityType, fields.Source) {
+			continue
+		}
+		h.dispatch(ctx, sub, msg)
+	}
+	return nil
+}
+
+func (h *FanOutHandler) dispatch(ctx context.Context, sub subscription.Subscription, msg Message) {
+	if sub.Delivery.Topic != "" {
+		if err := h.publisher.Publish(ctx, sub.Delivery.Topic, msg); err != nil {
+			h.logger.Warn("fan-out publish failed", "subscription_id", sub.ID, "topic", sub.Delivery.Topic, "error", err)
+		}
+		return
+	}
+	h.postWebhook(ctx, sub, msg)
+}
+
+func (h *FanOutHandler) postWebhook(ctx context.Context, sub subscription.Subscription, msg Message) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Delivery.WebhookURL, bytes.NewReader(msg.Payload))
+	if err != nil {
+		h.logger.Warn("build webhook request failed", "subscription_id", sub.ID, "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.webhook.Do(req)
+	if err != nil {
+		h.logger.Warn("webhook delivery failed", "subscription_id", sub.ID, "webhook_url", sub.Delivery.WebhookURL, "error", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		h.logger.Warn("webhook rejected", "subscription_id", sub.ID, "status", resp.StatusCode)
+	}
+}
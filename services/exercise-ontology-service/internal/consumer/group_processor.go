@@ -0,0 +1,255 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// RebalanceListener observes consumer group partition assignment changes. Implementations
+// must not block for long: Assigned/Revoked/Lost run inline with generation handling.
+type RebalanceListener interface {
+	// Assigned is called with the partitions newly claimed for the given topic.
+	Assigned(topic string, partitions []int)
+	// Revoked is called with partitions the generation is giving up cleanly; in-flight
+	// handlers for these partitions have already drained and offsets committed.
+	Revoked(topic string, partitions []int)
+	// Lost is called when partitions were taken away without a clean handoff (e.g. the
+	// generation expired). No further commits should be attempted for these partitions.
+	Lost(topic string, partitions []int)
+}
+
+// NoopRebalanceListener implements RebalanceListener with no-op callbacks.
+type NoopRebalanceListener struct{}
+
+func (NoopRebalanceListener) Assigned(string, []int) {}
+func (NoopRebalanceListener) Revoked(string, []int)  {}
+func (NoopRebalanceListener) Lost(string, []int)     {}
+
+// GroupConfig configures a rebalance-aware consumer group.
+type GroupConfig struct {
+	Brokers  []string
+	Topics   []string
+	GroupID  string
+	Dialer   *kafka.Dialer
+	Listener RebalanceListener
+	// Copartition, when true, routes every topic's partition N to the same per-partition
+	// worker goroutine, so a tenant's events across co-subscribed topics (e.g.
+	// activity_events and a future activity_state_changed) are handled in order relative
+	// to one another rather than by independent per-topic workers.
+	Copartition   bool
+	ReaderMinByte int
+	ReaderMaxByte int
+}
+
+// GroupProcessor coordinates a rebalance-aware Kafka consumer group, running one worker
+// goroutine per assigned partition (or, with Copartition enabled, per partition number
+// across all subscribed topics) so a slow handler on one partition cannot head-of-line
+// block the others. It wraps kafka-go's low-level ConsumerGroup so Assigned/Revoked/Lost
+// callbacks fire around generation changes.
+type GroupProcessor struct {
+	cfg      GroupConfig
+	handler  Handler
+	logger   *log.Logger
+	listener RebalanceListener
+}
+
+// NewGroupProcessor constructs a GroupProcessor. If cfg.Listener is nil a NoopRebalanceListener
+// is used.
+func NewGroupProcessor(cfg GroupConfig, handler Handler, opts ...Option) *GroupProcessor {
+	listener := cfg.Listener
+	if listener == nil {
+		listener = NoopRebalanceListener{}
+	}
+
+	p := &Processor{logger: log.Default()}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return &GroupProcessor{
+		cfg:      cfg,
+		handler:  handler,
+		logger:   p.logger,
+		listener: listener,
+	}
+}
+
+// Run drives the consumer group until ctx is cancelled, reconnecting between generations.
+func (g *GroupProcessor) Run(ctx context.Context) error {
+	group, err := kafka.NewConsumerGroup(kafka.ConsumerGroupConfig{
+		ID:      g.cfg.GroupID,
+		Brokers: g.cfg.Brokers,
+		Topics:  g.cfg.Topics,
+		Dialer:  g.cfg.Dialer,
+	})
+	if err != nil {
+		return err
+	}
+	defer group.Close()
+
+	for {
+		gen, err := group.Next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			g.logger.Printf("consumer group rebalance error: %v", err)
+			continue
+		}
+
+		g.runGeneration(ctx, gen)
+	}
+}
+
+// runGeneration spins up per-partition workers for the generation's assignments, waits for
+// it to end (clean revoke or context cancellation), and blocks until every worker has
+// drained in-flight handlers and committed or abandoned its offsets.
+func (g *GroupProcessor) runGeneration(ctx context.Context, gen *kafka.Generation) {
+	var wg sync.WaitGroup
+
+	if g.cfg.Copartition {
+		for partition, byTopic := range assignmentsByPartition(gen.Assignments) {
+			for topic := range byTopic {
+				g.listener.Assigned(topic, []int{partition})
+			}
+			wg.Add(1)
+			go func(partition int, byTopic map[string]kafka.PartitionAssignment) {
+				defer wg.Done()
+				g.runCopartitionedWorker(ctx, gen, partition, byTopic)
+			}(partition, byTopic)
+		}
+		wg.Wait()
+		return
+	}
+
+	for topic, assignments := range gen.Assignments {
+		partitions := make([]int, 0, len(assignments))
+		for _, a := range assignments {
+			partitions = append(partitions, a.ID)
+		}
+		g.listener.Assigned(topic, partitions)
+
+		for _, assignment := range assignments {
+			wg.Add(1)
+			go func(topic string, assignment kafka.PartitionAssignment) {
+				defer wg.Done()
+				g.runPartitionWorker(ctx, gen, topic, assignment.ID, []string{topic}, map[string]kafka.PartitionAssignment{topic: assignment})
+			}(topic, assignment)
+		}
+	}
+	wg.Wait()
+}
+
+func assignmentsByPartition(assignments map[string][]kafka.PartitionAssignment) map[int]map[string]kafka.PartitionAssignment {
+	byPartition := make(map[int]map[string]kafka.PartitionAssignment)
+	for topic, list := range assignments {
+		for _, a := range list {
+			if byPartition[a.ID] == nil {
+				byPartition[a.ID] = make(map[string]kafka.PartitionAssignment)
+			}
+			byPartition[a.ID][topic] = a
+		}
+	}
+	return byPartition
+}
+
+// runCopartitionedWorker consumes the same partition number across every co-subscribed
+// topic from a single goroutine, polling each topic's reader in turn.
+func (g *GroupProcessor) runCopartitionedWorker(ctx context.Context, gen *kafka.Generation, partition int, byTopic map[string]kafka.PartitionAssignment) {
+	topics := make([]string, 0, len(byTopic))
+	for topic := range byTopic {
+		topics = append(topics, topic)
+	}
+	g.runPartitionWorker(ctx, gen, "", partition, topics, byTopic)
+}
+
+// runPartitionWorker round-robins FetchMessage across the supplied topics' readers for a
+// single partition number, committing offsets on that partition after every successfully
+// handled message, until the generation ends.
+func (g *GroupProcessor) runPartitionWorker(ctx context.Context, gen *kafka.Generation, _ string, partition int, topics []string, byTopic map[string]kafka.PartitionAssignment) {
+	readers := make(map[string]*kafka.Reader, len(topics))
+	for _, topic := range topics {
+		reader := kafka.NewReader(kafka.ReaderConfig{
+			Brokers:   g.cfg.Brokers,
+			Topic:     topic,
+			Partition: partition,
+			MinBytes:  nonZero(g.cfg.ReaderMinByte, 1e3),
+			MaxBytes:  nonZero(g.cfg.ReaderMaxByte, 10e6),
+		})
+		if err := reader.SetOffset(byTopic[topic].Offset); err != nil {
+			g.logger.Printf("set offset error (topic=%s partition=%d): %v", topic, partition, err)
+			g.listener.Lost(topic, []int{partition})
+			reader.Close()
+			continue
+		}
+		readers[topic] = reader
+	}
+	defer func() {
+		for _, reader := range readers {
+			reader.Close()
+		}
+	}()
+
+	for {
+		select {
+		case <-gen.Context().Done():
+			for topic := range readers {
+				g.listener.Revoked(topic, []int{partition})
+			}
+			return
+		default:
+		}
+
+		for topic, reader := range readers {
+			msg, err := reader.ReadMessage(gen.Context())
+			if err != nil {
+				select {
+				case <-gen.Context().Done():
+					g.listener.Revoked(topic, []int{partition})
+				default:
+					g.logger.Printf("fetch error (topic=%s partition=%d): %v", topic, partition, err)
+					g.listener.Lost(topic, []int{partition})
+				}
+				delete(readers, topic)
+				continue
+			}
+
+			decoded := decodeGroupMessage(msg)
+			if err := g.handler.Handle(ctx, decoded); err != nil {
+				g.logger.Printf("handler error (topic=%s partition=%d offset=%d): %v", topic, msg.Partition, msg.Offset, err)
+				continue
+			}
+
+			if err := gen.CommitOffsets(map[string]map[int]int64{topic: {partition: msg.Offset + 1}}); err != nil {
+				g.logger.Printf("commit error (topic=%s partition=%d): %v", topic, msg.Partition, err)
+			}
+		}
+	}
+}
+
+func decodeGroupMessage(msg kafka.Message) Message {
+	decoded := Message{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Key:       msg.Key,
+		Payload:   append(json.RawMessage{}, msg.Value...),
+		Timestamp: msg.Time,
+		Headers:   make(map[string]string, len(msg.Headers)),
+	}
+	for _, header := range msg.Headers {
+		decoded.Headers[header.Key] = string(header.Value)
+	}
+	return decoded
+}
+
+func nonZero(v, fallback int) int {
+	if v <= 0 {
+		return fallback
+	}
+	return v
+}
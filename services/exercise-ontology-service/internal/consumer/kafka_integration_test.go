@@ -10,8 +10,6 @@ import (
 
 	"github.com/segmentio/kafka-go"
 	"github.com/stretchr/testify/require"
-	"github.com/testcontainers/testcontainers-go"
-	kafkaContainer "github.com/testcontainers/testcontainers-go/modules/kafka"
 
 	"example.com/exerciseontology/internal/cache"
 	"example.com/exerciseontology/internal/domain"
@@ -25,47 +23,15 @@ func TestKafkaActivityEventCreatesExercise(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Minute)
 	defer cancel()
 
-	kafkaC, err := kafkaContainer.RunContainer(ctx, testcontainers.WithEnv(map[string]string{
-		"KAFKA_AUTO_CREATE_TOPICS_ENABLE": "true",
-	}))
-	require.NoError(t, err)
-	t.Cleanup(func() { _ = kafkaC.Terminate(context.Background()) })
-
-	brokers, err := kafkaC.Brokers(ctx)
-	require.NoError(t, err)
-	require.NotEmpty(t, brokers)
-	broker := brokers[0]
+	stack := testsupport.StartStack(ctx, t, testsupport.Options{Dgraph: true, Kafka: true})
+	require.NoError(t, testsupport.SeedOntology(ctx, stack.DgraphEndpoint))
+	broker := stack.KafkaBootstrap
 
 	topic := "activity_events"
 
-	dgraphContainer, endpoint := testsupport.StartDgraph(ctx, t)
-	t.Cleanup(func() { _ = dgraphContainer.Terminate(context.Background()) })
-
-	repo := knowledge.NewDgraphRepository(endpoint, 10*time.Second)
+	repo := knowledge.NewDgraphRepository(stack.DgraphEndpoint, 10*time.Second)
 	service := domain.NewService(repo, cache.NoopInvalidator{})
-	handler := NewEnrichmentHandler(service)
-
-	// Seed complementary exercise so relationship updates can be evaluated after enrichment.
-	seed := domain.Exercise{
-		ID:          ActivityExerciseID("tenant", "Tempo Ride"),
-		Name:        "Tempo Ride",
-		Difficulty:  "intermediate",
-		Targets:     []string{"cardio"},
-		Requires:    []string{"bike"},
-		LastUpdated: time.Now().UTC(),
-	}
-	_, err = service.UpsertExercise(ctx, seed)
-	require.NoError(t, err)
-
-	_, err = service.UpsertExercise(ctx, domain.Exercise{
-		ID:          ActivityExerciseID("tenant", "Yoga Flow"),
-		Name:        "Yoga Flow",
-		Difficulty:  "beginner",
-		Targets:     []string{"flexibility"},
-		Requires:    []string{"mat"},
-		LastUpdated: time.Now().UTC(),
-	})
-	require.NoError(t, err)
+	handler := NewEnrichmentHandler(service, nil)
 
 	conn, err := kafka.Dial("tcp", broker)
 	require.NoError(t, err)
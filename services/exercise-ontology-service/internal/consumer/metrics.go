@@ -1,6 +1,9 @@
 package consumer
 
 import (
+	"sync"
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -18,10 +21,79 @@ var (
 		Name:      "last_message_timestamp_seconds",
 		Help:      "Timestamp of the most recent Kafka message processed.",
 	}, []string{"topic"})
+
+	schemaCacheHits = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "exercise_ontology",
+		Subsystem: "consumer",
+		Name:      "schema_cache_hits_total",
+		Help:      "Number of writer schema lookups served from SchemaDecoder's in-process cache.",
+	})
+
+	schemaCacheMisses = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "exercise_ontology",
+		Subsystem: "consumer",
+		Name:      "schema_cache_misses_total",
+		Help:      "Number of writer schema lookups that required a Schema Registry round-trip.",
+	})
+
+	schemaDecodeFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "exercise_ontology",
+		Subsystem: "consumer",
+		Name:      "schema_decode_failures_total",
+		Help:      "Number of messages SchemaDecoder failed to decode, labeled by topic.",
+	}, []string{"topic"})
+
+	tokenRefreshFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "exercise_ontology",
+		Subsystem: "consumer",
+		Name:      "oauth2_token_refresh_failures_total",
+		Help:      "Number of failed OAuth2 client-credentials token refreshes.",
+	})
+
+	decompressionErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "exercise_ontology",
+		Subsystem: "consumer",
+		Name:      "decompression_errors_total",
+		Help:      "Number of messages DecompressingHandler failed to decompress, labeled by topic and content-encoding.",
+	}, []string{"topic", "encoding"})
+
+	consumerRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "exercise_ontology",
+		Subsystem: "consumer",
+		Name:      "retries_total",
+		Help:      "Number of in-place Handler retries Processor attempted before giving up or succeeding.",
+	}, []string{"topic"})
+
+	consumerDLQ = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "exercise_ontology",
+		Subsystem: "consumer",
+		Name:      "dlq_total",
+		Help:      "Number of messages Processor routed to a dead-letter topic, labeled by the stage (decode/handle) that failed.",
+	}, []string{"stage"})
 )
 
 func init() {
-	prometheus.MustRegister(processedCounter, lastMessageGauge)
+	prometheus.MustRegister(processedCounter, lastMessageGauge, schemaCacheHits, schemaCacheMisses, schemaDecodeFailures, tokenRefreshFailures, decompressionErrors, consumerRetries, consumerDLQ)
+}
+
+// recordRetry increments the retry counter for topic.
+func recordRetry(topic string) {
+	consumerRetries.WithLabelValues(topic).Inc()
+}
+
+// recordDLQ increments the dead-letter counter for stage ("decode" or "handle").
+func recordDLQ(stage string) {
+	consumerDLQ.WithLabelValues(stage).Inc()
+}
+
+var (
+	lastMessageMu         sync.RWMutex
+	lastMessageTimestamps = make(map[string]time.Time)
+)
+
+// RecordTokenRefreshFailure increments the OAuth2 token refresh failure counter.
+func RecordTokenRefreshFailure() {
+	tokenRefreshFailures.Inc()
 }
 
 // RecordProcessed updates counters for successfully handled messages.
@@ -30,5 +102,21 @@ func RecordProcessed(msg Message) {
 	processedCounter.WithLabelValues(msg.Topic, eventType).Inc()
 	if !msg.Timestamp.IsZero() {
 		lastMessageGauge.WithLabelValues(msg.Topic).Set(float64(msg.Timestamp.Unix()))
+		lastMessageMu.Lock()
+		lastMessageTimestamps[msg.Topic] = msg.Timestamp
+		lastMessageMu.Unlock()
+	}
+}
+
+// LastMessageTimestamps returns a snapshot of the most recent message timestamp processed per
+// topic, mirroring lastMessageGauge in a form diagnostics code can read back without scraping
+// Prometheus.
+func LastMessageTimestamps() map[string]time.Time {
+	lastMessageMu.RLock()
+	defer lastMessageMu.RUnlock()
+	out := make(map[string]time.Time, len(lastMessageTimestamps))
+	for topic, ts := range lastMessageTimestamps {
+		out[topic] = ts
 	}
+	return out
 }
@@ -5,10 +5,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/segmentio/kafka-go"
+
+	"example.com/platform/libs/go/datastreams"
 )
 
 // Reader describes the kafka.Reader functions the processor interacts with.
@@ -16,6 +20,24 @@ type Reader interface {
 	FetchMessage(context.Context) (kafka.Message, error)
 	CommitMessages(context.Context, ...kafka.Message) error
 	Close() error
+	Stats() kafka.ReaderStats
+}
+
+// ReaderStats is a snapshot of a Kafka reader's consumer-group lag and throughput counters,
+// decoupled from kafka-go's own stats type so callers outside this package (e.g. the debug
+// HTTP tree) don't need to import kafka-go just to report diagnostics.
+type ReaderStats struct {
+	Topic     string
+	Partition string
+	Offset    int64
+	Lag       int64
+	Messages  int64
+	Bytes     int64
+}
+
+// StatsProvider is implemented by Processor to expose its reader's current stats.
+type StatsProvider interface {
+	Stats() ReaderStats
 }
 
 // Handler processes decoded Kafka messages.
@@ -42,22 +64,96 @@ func WithLogger(l *log.Logger) Option {
 	return func(p *Processor) { p.logger = l }
 }
 
+// WithDataStreamsProcessor attaches a datastreams.Processor that extracts the pathway
+// checkpoint header from each consumed message and records edge/pathway latency. Passing
+// nil (the default) disables checkpointing, which is how tests avoid non-deterministic
+// metric output.
+func WithDataStreamsProcessor(dsp *datastreams.Processor, consumerGroup string) Option {
+	return func(p *Processor) {
+		p.dsp = dsp
+		p.consumerGroup = consumerGroup
+	}
+}
+
+// WithRetry bounds handler errors to maxAttempts in-place retries (including the first attempt)
+// before the message is routed to the DeadLetterPublisher, sleeping backoff(attempt) between
+// each. Malformed messages - those that fail decode validation - skip retries entirely. The
+// default, maxAttempts <= 1, retries nothing, matching the processor's legacy behaviour.
+func WithRetry(maxAttempts int, backoff func(attempt int) time.Duration) Option {
+	return func(p *Processor) {
+		p.maxAttempts = maxAttempts
+		p.backoff = backoff
+	}
+}
+
+// WithDeadLetterPublisher configures where messages go once retries are exhausted, or a message
+// fails decode validation. Without one, such messages are logged and committed as before.
+func WithDeadLetterPublisher(publisher DeadLetterPublisher) Option {
+	return func(p *Processor) {
+		p.deadLetter = publisher
+	}
+}
+
+// WithHandleTimeout bounds each individual handler.Handle call with a context derived from the
+// Run loop's ctx, so a stuck handler fails that attempt (and is retried or dead-lettered per
+// WithRetry) rather than blocking partition progress indefinitely. Zero (the default) leaves
+// Handle bounded only by ctx itself.
+func WithHandleTimeout(d time.Duration) Option {
+	return func(p *Processor) {
+		p.handleTimeout = d
+	}
+}
+
 // Processor coordinates the consumer loop.
 type Processor struct {
-	reader  Reader
-	handler Handler
-	logger  *log.Logger
+	reader        Reader
+	handler       Handler
+	logger        *log.Logger
+	dsp           *datastreams.Processor
+	consumerGroup string
+
+	maxAttempts   int
+	backoff       func(attempt int) time.Duration
+	deadLetter    DeadLetterPublisher
+	handleTimeout time.Duration
+
+	attemptsMu sync.Mutex
+	attempts   map[string]int
+}
+
+// Stats implements StatsProvider, reporting the current state of the underlying Kafka reader.
+func (p *Processor) Stats() ReaderStats {
+	s := p.reader.Stats()
+	return ReaderStats{
+		Topic:     s.Topic,
+		Partition: s.Partition,
+		Offset:    s.Offset,
+		Lag:       s.Lag,
+		Messages:  s.Messages,
+		Bytes:     s.Bytes,
+	}
 }
 
 // NewProcessor constructs a processor from a reader/handler pair.
 func NewProcessor(reader Reader, handler Handler, opts ...Option) *Processor {
-	p := &Processor{reader: reader, handler: handler, logger: log.Default()}
+	p := &Processor{
+		reader:      reader,
+		handler:     handler,
+		logger:      log.Default(),
+		maxAttempts: 1,
+		attempts:    make(map[string]int),
+	}
 	for _, opt := range opts {
 		opt(p)
 	}
 	return p
 }
 
+// attemptKey identifies a message's uncommitted position within the attempts map.
+func attemptKey(partition int, offset int64) string {
+	return fmt.Sprintf("%d:%d", partition, offset)
+}
+
 // Run consumes messages until ctx cancellation.
 func (p *Processor) Run(ctx context.Context) error {
 	for {
@@ -87,14 +183,110 @@ func (p *Processor) Run(ctx context.Context) error {
 			decoded.Headers[header.Key] = string(header.Value)
 		}
 
-		if err := p.handler.Handle(ctx, decoded); err != nil {
-			p.logger.Printf("handler error (topic=%s offset=%d): %v", msg.Topic, msg.Offset, err)
+		if p.dsp != nil {
+			p.dsp.CheckpointIn(msg.Topic, msg.Partition, p.consumerGroup, []byte(decoded.Headers[datastreams.PathwayHeader]))
+		}
+
+		// Compressed and Confluent-framed payloads only become JSON once DecompressingHandler and
+		// SchemaDecoder unwrap them, so the up-front JSON check applies to plain payloads only.
+		_, _, framed := splitWireFormat(msg.Value)
+		plain := decoded.Headers["content-encoding"] == "" && !framed
+		if plain && !json.Valid(msg.Value) {
+			err := fmt.Errorf("invalid JSON payload")
+			p.logger.Printf("decode error (topic=%s offset=%d): %v", msg.Topic, msg.Offset, err)
+			p.routeToDeadLetter(ctx, decoded, dlqStageDecode, err, 1)
+		} else if err := p.handleWithRetry(ctx, decoded); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return err
+			}
+			p.routeToDeadLetter(ctx, decoded, dlqStageHandle, err, p.attemptsFor(msg.Partition, msg.Offset))
 		} else {
 			p.logger.Printf("processed (topic=%s offset=%d)", msg.Topic, msg.Offset)
 		}
 
+		p.clearAttempts(msg.Partition, msg.Offset)
 		if err := p.reader.CommitMessages(ctx, msg); err != nil {
 			p.logger.Printf("commit error: %v", err)
 		}
 	}
 }
+
+// handleWithRetry calls handler.Handle, retrying up to maxAttempts times (including the first
+// attempt) with backoff(attempt) sleeps in between, recording each attempt against decoded's
+// (partition, offset) in the attempts map. It returns the last error once attempts are
+// exhausted, or nil on success.
+func (p *Processor) handleWithRetry(ctx context.Context, decoded Message) error {
+	var lastErr error
+	for attempt := 1; attempt <= p.maxAttempts; attempt++ {
+		p.setAttempts(decoded.Partition, decoded.Offset, attempt)
+
+		handleCtx, cancel := p.withHandleTimeout(ctx)
+		lastErr = p.handler.Handle(handleCtx, decoded)
+		cancel()
+		if lastErr == nil {
+			return nil
+		}
+
+		if attempt == p.maxAttempts {
+			p.logger.Printf("handler error (topic=%s offset=%d) after %d attempts: %v", decoded.Topic, decoded.Offset, attempt, lastErr)
+			break
+		}
+
+		p.logger.Printf("retrying handler error (topic=%s offset=%d, attempt=%d): %v", decoded.Topic, decoded.Offset, attempt, lastErr)
+		recordRetry(decoded.Topic)
+
+		select {
+		case <-time.After(p.backoffDelay(attempt)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return lastErr
+}
+
+func (p *Processor) backoffDelay(attempt int) time.Duration {
+	if p.backoff == nil {
+		return 0
+	}
+	return p.backoff(attempt)
+}
+
+func (p *Processor) withHandleTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.handleTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, p.handleTimeout)
+}
+
+// routeToDeadLetter publishes decoded to the configured DeadLetterPublisher. Without one it just
+// logs, matching the processor's behaviour before this existed - either way the caller commits
+// the original offset afterward.
+func (p *Processor) routeToDeadLetter(ctx context.Context, decoded Message, stage string, cause error, attempts int) {
+	if p.deadLetter == nil {
+		p.logger.Printf("no dead-letter publisher configured, dropping message (topic=%s offset=%d stage=%s): %v", decoded.Topic, decoded.Offset, stage, cause)
+		return
+	}
+	if err := p.deadLetter.Publish(ctx, decoded, stage, cause, attempts); err != nil {
+		p.logger.Printf("dlq publish error (topic=%s offset=%d stage=%s): %v", decoded.Topic, decoded.Offset, stage, err)
+		return
+	}
+	recordDLQ(stage)
+}
+
+func (p *Processor) setAttempts(partition int, offset int64, attempt int) {
+	p.attemptsMu.Lock()
+	defer p.attemptsMu.Unlock()
+	p.attempts[attemptKey(partition, offset)] = attempt
+}
+
+func (p *Processor) attemptsFor(partition int, offset int64) int {
+	p.attemptsMu.Lock()
+	defer p.attemptsMu.Unlock()
+	return p.attempts[attemptKey(partition, offset)]
+}
+
+func (p *Processor) clearAttempts(partition int, offset int64) {
+	p.attemptsMu.Lock()
+	defer p.attemptsMu.Unlock()
+	delete(p.attempts, attemptKey(partition, offset))
+}
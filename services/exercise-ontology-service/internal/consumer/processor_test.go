@@ -3,6 +3,7 @@ package consumer
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -59,6 +60,8 @@ func (r *stubReader) CommitMessages(_ context.Context, _ ...kafka.Message) error
 
 func (r *stubReader) Close() error { return nil }
 
+func (r *stubReader) Stats() kafka.ReaderStats { return kafka.ReaderStats{} }
+
 type RecordingHandler struct {
 	count int
 	last  Message
@@ -71,3 +74,83 @@ func (h *RecordingHandler) Handle(_ context.Context, msg Message) error {
 	h.last = msg
 	return nil
 }
+
+type failingHandler struct {
+	failures int
+	calls    int
+}
+
+func (h *failingHandler) Handle(_ context.Context, _ Message) error {
+	h.calls++
+	if h.calls <= h.failures {
+		return errors.New("boom")
+	}
+	return nil
+}
+
+type recordingDLQ struct {
+	stage    string
+	attempts int
+	calls    int
+}
+
+func (d *recordingDLQ) Publish(_ context.Context, _ Message, stage string, _ error, attempts int) error {
+	d.calls++
+	d.stage = stage
+	d.attempts = attempts
+	return nil
+}
+
+func TestProcessorRetriesThenSucceeds(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msg := kafka.Message{Topic: "activity_events", Value: json.RawMessage(`{"ok":true}`)}
+	reader := &stubReader{msgs: []kafka.Message{msg}, errAfter: context.Canceled}
+	handler := &failingHandler{failures: 2}
+	dlq := &recordingDLQ{}
+	proc := NewProcessor(reader, handler, WithRetry(3, func(int) time.Duration { return 0 }), WithDeadLetterPublisher(dlq))
+
+	err := proc.Run(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 3, handler.calls)
+	require.Equal(t, 0, dlq.calls)
+	require.Equal(t, 1, reader.commitCount)
+}
+
+func TestProcessorRoutesExhaustedRetriesToDLQ(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msg := kafka.Message{Topic: "activity_events", Value: json.RawMessage(`{"ok":true}`)}
+	reader := &stubReader{msgs: []kafka.Message{msg}, errAfter: context.Canceled}
+	handler := &failingHandler{failures: 5}
+	dlq := &recordingDLQ{}
+	proc := NewProcessor(reader, handler, WithRetry(2, func(int) time.Duration { return 0 }), WithDeadLetterPublisher(dlq))
+
+	err := proc.Run(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 2, handler.calls)
+	require.Equal(t, 1, dlq.calls)
+	require.Equal(t, dlqStageHandle, dlq.stage)
+	require.Equal(t, 2, dlq.attempts)
+	require.Equal(t, 1, reader.commitCount)
+}
+
+func TestProcessorSendsMalformedMessagesToDLQWithoutRetry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msg := kafka.Message{Topic: "activity_events", Value: []byte("not json")}
+	reader := &stubReader{msgs: []kafka.Message{msg}, errAfter: context.Canceled}
+	handler := &RecordingHandler{}
+	dlq := &recordingDLQ{}
+	proc := NewProcessor(reader, handler, WithRetry(3, func(int) time.Duration { return 0 }), WithDeadLetterPublisher(dlq))
+
+	err := proc.Run(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 0, handler.count)
+	require.Equal(t, 1, dlq.calls)
+	require.Equal(t, dlqStageDecode, dlq.stage)
+	require.Equal(t, 1, reader.commitCount)
+}
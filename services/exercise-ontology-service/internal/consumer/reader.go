@@ -0,0 +1,39 @@
+package consumer
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"example.com/platform/libs/go/kafkasecurity"
+)
+
+// ReaderConfig captures the parameters needed to construct an authenticated kafka.Reader.
+type ReaderConfig struct {
+	Brokers  []string
+	GroupID  string
+	Topic    string
+	MinBytes int
+	MaxBytes int
+	Security kafkasecurity.Config
+}
+
+// NewReader builds a kafka.Reader authenticated according to cfg.Security. Passing the zero
+// kafkasecurity.Config preserves today's plaintext, no-auth behaviour.
+func NewReader(ctx context.Context, cfg ReaderConfig) (*kafka.Reader, error) {
+	dialer, err := cfg.Security.Dialer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:        cfg.Brokers,
+		GroupID:        cfg.GroupID,
+		Topic:          cfg.Topic,
+		MinBytes:       nonZero(cfg.MinBytes, 1e3),
+		MaxBytes:       nonZero(cfg.MaxBytes, 10e6),
+		CommitInterval: time.Second,
+		Dialer:         dialer,
+	}), nil
+}
@@ -0,0 +1,81 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// SchemaRegistryReader resolves a Confluent Schema Registry schema ID to its writer schema.
+type SchemaRegistryReader interface {
+	SchemaByID(ctx context.Context, id int) (string, error)
+}
+
+// SchemaDecoder is a Handler middleware that strips the Confluent wire format (magic byte +
+// 4-byte schema ID) from a message payload, resolves the writer schema so callers downstream
+// can reason about it, and forwards the decoded JSON body to the wrapped Handler. Messages
+// without the wire format prefix pass through unchanged, so topics that don't go through
+// Schema Registry keep working.
+type SchemaDecoder struct {
+	next     Handler
+	registry SchemaRegistryReader
+
+	mu    sync.RWMutex
+	cache map[int]string
+}
+
+// NewSchemaDecoder constructs a SchemaDecoder wrapping next.
+func NewSchemaDecoder(next Handler, registry SchemaRegistryReader) *SchemaDecoder {
+	return &SchemaDecoder{
+		next:     next,
+		registry: registry,
+		cache:    make(map[int]string),
+	}
+}
+
+// Handle implements Handler.
+func (d *SchemaDecoder) Handle(ctx context.Context, msg Message) error {
+	schemaID, body, ok := splitWireFormat(msg.Payload)
+	if !ok {
+		return d.next.Handle(ctx, msg)
+	}
+
+	if _, err := d.schemaFor(ctx, schemaID); err != nil {
+		schemaDecodeFailures.WithLabelValues(msg.Topic).Inc()
+		return fmt.Errorf("resolve writer schema id=%d: %w", schemaID, err)
+	}
+
+	msg.Payload = body
+	return d.next.Handle(ctx, msg)
+}
+
+func (d *SchemaDecoder) schemaFor(ctx context.Context, id int) (string, error) {
+	d.mu.RLock()
+	schema, ok := d.cache[id]
+	d.mu.RUnlock()
+	if ok {
+		schemaCacheHits.Inc()
+		return schema, nil
+	}
+
+	schema, err := d.registry.SchemaByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	schemaCacheMisses.Inc()
+
+	d.mu.Lock()
+	d.cache[id] = schema
+	d.mu.Unlock()
+	return schema, nil
+}
+
+// splitWireFormat extracts the schema ID and body from a Confluent wire-format payload
+// (0x00 || schemaID(BE32) || body). ok is false if payload doesn't carry the magic byte.
+func splitWireFormat(payload []byte) (id int, body []byte, ok bool) {
+	if len(payload) < 5 || payload[0] != 0x00 {
+		return 0, nil, false
+	}
+	id = int(payload[1])<<24 | int(payload[2])<<16 | int(payload[3])<<8 | int(payload[4])
+	return id, payload[5:], true
+}
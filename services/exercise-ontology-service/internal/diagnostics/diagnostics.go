@@ -0,0 +1,79 @@
+// Package diagnostics exposes a token-gated debug HTTP tree (pprof, expvar, and per-topic
+// Kafka reader stats) intended for mounting on the metrics listener, which otherwise only
+// serves promhttp.Handler and is safe to reach from inside the cluster.
+package diagnostics
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"example.com/exerciseontology/internal/consumer"
+)
+
+// Source pairs a topic name with the Processor reporting its Kafka reader stats.
+type Source struct {
+	Topic     string
+	Processor consumer.StatsProvider
+}
+
+// RegisterRoutes mounts the debug tree under /debug/ on mux, gated behind a bearer token
+// check against token. An empty token disables the tree entirely (every route 404s), so
+// it's off unless an operator explicitly sets METRICS_DEBUG_TOKEN.
+func RegisterRoutes(mux *http.ServeMux, token string, sources []Source) {
+	publishVars(sources)
+
+	debug := http.NewServeMux()
+	debug.HandleFunc("/debug/pprof/", pprof.Index)
+	debug.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	debug.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	debug.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	debug.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	debug.Handle("/debug/vars", expvar.Handler())
+
+	mux.Handle("/debug/", requireToken(token, debug))
+}
+
+// publishVars registers the expvars /debug/vars serves: goroutine count, per-topic consumer
+// lag derived from consumer.LastMessageTimestamps, and per-topic Kafka reader stats.
+func publishVars(sources []Source) {
+	expvar.Publish("goroutines", expvar.Func(func() any {
+		return runtime.NumGoroutine()
+	}))
+
+	expvar.Publish("consumer_lag_seconds", expvar.Func(func() any {
+		lag := make(map[string]float64)
+		now := time.Now()
+		for topic, ts := range consumer.LastMessageTimestamps() {
+			lag[topic] = now.Sub(ts).Seconds()
+		}
+		return lag
+	}))
+
+	expvar.Publish("kafka_reader_stats", expvar.Func(func() any {
+		stats := make(map[string]consumer.ReaderStats, len(sources))
+		for _, src := range sources {
+			stats[src.Topic] = src.Processor.Stats()
+		}
+		return stats
+	}))
+}
+
+// requireToken gates next behind an "Authorization: Bearer <token>" header match. An empty
+// token means the debug tree is disabled, so every request 404s instead of being served
+// unauthenticated.
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
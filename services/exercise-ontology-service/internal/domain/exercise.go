@@ -4,14 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"math/rand"
+	"runtime"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 
 	"example.com/exerciseontology/internal/cache"
 	"example.com/exerciseontology/internal/observability"
+	"example.com/platform/libs/go/events"
 )
 
 // Exercise represents an ontology node.
@@ -26,6 +31,10 @@ type Exercise struct {
 	LastUpdated       time.Time `json:"last_updated"`
 	SessionCount      int       `json:"session_count"`
 	LastSeenAt        time.Time `json:"last_seen_at"`
+	// ResourceVersion is an optimistic-concurrency token: callers read it off a fetched
+	// Exercise and echo it back on write so Upsert can reject the write (ErrConflict) if
+	// another writer updated the node in between, rather than silently clobbering it.
+	ResourceVersion uint64 `json:"resource_version"`
 }
 
 // ActivitySession represents an individual performed session tied to an exercise.
@@ -51,31 +60,171 @@ type ExerciseRelationships struct {
 
 // Repository exposes persistence behaviour.
 type Repository interface {
+	// Upsert creates or updates exercise. If exercise.ResourceVersion is non-zero, the write
+	// is conditional on the stored node's current version still matching it; a mismatch (or
+	// the node having disappeared) returns ErrConflict without writing anything.
 	Upsert(ctx context.Context, exercise Exercise) error
+	// UpsertBatch writes every exercise as a single transaction/mutation - all-or-nothing, same
+	// as a Dgraph mutation already is. A failure reports one error for the whole call; callers
+	// that need per-row attribution (like BulkUpsert) keep their own batch boundaries small
+	// enough that "which batch" is informative on its own.
+	UpsertBatch(ctx context.Context, exercises []Exercise) error
 	UpsertWithSession(ctx context.Context, exercise Exercise, session ActivitySession) error
 	Get(ctx context.Context, id string) (*Exercise, error)
 	Search(ctx context.Context, query string, limit int) ([]Exercise, error)
+	// ListAll returns every exercise in the ontology, bounded by limit (<=0 means unbounded).
+	// Unlike Search it performs no name matching, so AuditRelationships can enumerate the whole
+	// graph rather than whatever a query term happens to hit.
+	ListAll(ctx context.Context, limit int) ([]Exercise, error)
+	// ListPage returns up to limit exercises ordered by ID, starting after cursor (exclusive).
+	// An empty cursor starts from the beginning; nextCursor is empty once there are no further
+	// pages. Unlike ListAll it never materializes more than one page at a time, so
+	// Service.ExportExercises can stream an arbitrarily large ontology.
+	ListPage(ctx context.Context, cursor string, limit int) (exercises []Exercise, nextCursor string, err error)
 	ListSessions(ctx context.Context, exerciseID string, limit int) ([]ActivitySession, error)
 	Delete(ctx context.Context, id string) error
+	// RepairSymmetry treats id's own stored ComplementaryTo and Contraindications as
+	// authoritative and pushes any missing back-link onto each referenced neighbor, so a graph
+	// left asymmetric by an interrupted UpdateRelationships write converges back to symmetric.
+	RepairSymmetry(ctx context.Context, id string) error
+}
+
+// eventQueueSize bounds the upstream queue Service.publish feeds the broadcaster from. It is
+// only drained once something subscribes; publishing before that (or faster than it drains)
+// fills the buffer and starts dropping, same as a slow subscriber would.
+const eventQueueSize = 100
+
+// ExerciseEvent is the sum type Service broadcasts after a successful repository commit.
+// Exactly one field is set.
+type ExerciseEvent struct {
+	Upserted *events.ExerciseUpserted
+	Deleted  *events.ExerciseDeleted
 }
 
 // Service contains business logic.
 type Service struct {
-	repo  Repository
-	cache cache.Invalidator
+	repo        Repository
+	cache       cache.Invalidator
+	broadcaster *events.Broadcaster[ExerciseEvent]
+	upstream    chan ExerciseEvent
 }
 
 var (
 	// ErrExerciseNotFound indicates the entity does not exist.
 	ErrExerciseNotFound = errors.New("exercise not found")
+	// ErrConflict indicates a versioned write lost a race with another writer updating the
+	// same exercise; callers should re-read and re-apply their mutation.
+	ErrConflict = errors.New("exercise was concurrently modified")
+	// ErrGuaranteedUpdateRetriesExhausted is returned by guaranteedUpdateFrom if
+	// guaranteedUpdateMaxRetries attempts all hit ErrConflict. Since a conflict on a freshly
+	// read exercise is returned as ErrConflict straight away, its loop makes at most two
+	// attempts and this error is not returned in practice.
+	ErrGuaranteedUpdateRetriesExhausted = errors.New("exhausted retries reconciling concurrent update")
+	// ErrRelationshipConflict is the sentinel every RelationshipConflictError wraps, so callers
+	// that only care whether the update was rejected as graph-inconsistent can check
+	// errors.Is(err, ErrRelationshipConflict) without handling the structured detail.
+	ErrRelationshipConflict = errors.New("relationship conflict detected")
 )
 
-// NewService constructs a new Service.
+// relationshipConflictMaxDepth bounds how many complementary hops UpdateRelationships walks
+// outward from the exercise being updated while looking for a contradictory contraindication
+// closure. Ontologies are shallow and densely cross-linked in practice, so 4 hops catches
+// realistic chains without risking an unbounded walk over a large graph.
+const relationshipConflictMaxDepth = 4
+
+// RelationshipConflictError reports a pairwise-disjointness violation or a contradictory
+// complementary/contraindication cycle found by UpdateRelationships. Path lists the exercise IDs
+// involved, starting with the exercise being updated. EdgeTypes has the same length as Path: entry
+// i is the edge from Path[i] to Path[(i+1)%len(Path)], so the last entry closes the cycle back to
+// Path[0].
+type RelationshipConflictError struct {
+	Path      []string
+	EdgeTypes []string
+}
+
+func (e *RelationshipConflictError) Error() string {
+	return fmt.Sprintf("relationship conflict along %s (%s)", strings.Join(e.Path, " -> "), strings.Join(e.EdgeTypes, ", "))
+}
+
+// Unwrap lets errors.Is(err, ErrRelationshipConflict) succeed without callers needing to know
+// about the concrete type.
+func (e *RelationshipConflictError) Unwrap() error { return ErrRelationshipConflict }
+
+// guaranteedUpdateMaxRetries bounds GuaranteedUpdate's read-mutate-write retry loop.
+const guaranteedUpdateMaxRetries = 5
+
+// NewService constructs a new Service. Exercise events published by UpsertExercise are
+// fanned out through Events(); Service itself subscribes to drive cache invalidation, so an
+// invalidator failure no longer fails the upsert call that triggered it.
 func NewService(repo Repository, invalidator cache.Invalidator) *Service {
 	if invalidator == nil {
 		invalidator = cache.NoopInvalidator{}
 	}
-	return &Service{repo: repo, cache: invalidator}
+	s := &Service{
+		repo:        repo,
+		cache:       invalidator,
+		broadcaster: events.NewBroadcaster[ExerciseEvent](),
+		upstream:    make(chan ExerciseEvent, eventQueueSize),
+	}
+	s.subscribeInvalidation()
+	return s
+}
+
+// Events returns the broadcaster driving exercise-change fan-out, for subscribers like an
+// SSE endpoint or outbox publisher that want the same stream Service invalidates its cache
+// from.
+func (s *Service) Events() *events.Broadcaster[ExerciseEvent] {
+	return s.broadcaster
+}
+
+// connect implements events.ConnectFunc by handing the broadcaster Service's own upstream
+// queue; Service is the sole producer, so there's nothing further to "connect" to.
+func (s *Service) connect(context.Context) (<-chan ExerciseEvent, error) {
+	return s.upstream, nil
+}
+
+// publish enqueues evt for delivery to subscribers without blocking the caller; a full queue
+// (no subscribers yet, or subscribers falling behind) drops the event and logs, same as a
+// slow per-subscriber channel would.
+func (s *Service) publish(evt ExerciseEvent) {
+	select {
+	case s.upstream <- evt:
+	default:
+		log.Printf("domain: dropping exercise event, broadcaster queue full")
+	}
+}
+
+// subscribeInvalidation registers Service's own cache-invalidation consumer on its
+// broadcaster, replacing what used to be a direct, synchronous cache.Invalidator call inline
+// in UpsertExercise.
+func (s *Service) subscribeInvalidation() {
+	sub, err := s.broadcaster.Subscribe(context.Background(), s.connect)
+	if err != nil {
+		log.Printf("domain: failed to subscribe cache invalidator: %v", err)
+		return
+	}
+	go func() {
+		for evt := range sub {
+			id := evt.exerciseID()
+			if id == "" {
+				continue
+			}
+			if err := s.cache.Invalidate(context.Background(), id); err != nil {
+				log.Printf("domain: cache invalidation failed for exercise %s: %v", id, err)
+			}
+		}
+	}()
+}
+
+// exerciseID returns the ID carried by whichever event variant is set.
+func (e ExerciseEvent) exerciseID() string {
+	if e.Upserted != nil {
+		return e.Upserted.ExerciseID
+	}
+	if e.Deleted != nil {
+		return e.Deleted.ExerciseID
+	}
+	return ""
 }
 
 // UpsertExercise creates or updates entries.
@@ -92,13 +241,207 @@ func (s *Service) UpsertExercise(ctx context.Context, exercise Exercise) (Exerci
 	if err := s.repo.Upsert(ctx, exercise); err != nil {
 		return Exercise{}, err
 	}
-	if err := s.cache.Invalidate(ctx, exercise.ID); err != nil {
-		return Exercise{}, fmt.Errorf("cache invalidation: %w", err)
-	}
 	observability.RecordOntologyUpsert(exercise.LastUpdated)
+	s.publish(ExerciseEvent{Upserted: &events.ExerciseUpserted{
+		ExerciseID:        exercise.ID,
+		Name:              exercise.Name,
+		Difficulty:        exercise.Difficulty,
+		Targets:           exercise.Targets,
+		Requires:          exercise.Requires,
+		Contraindications: exercise.Contraindications,
+		ComplementaryTo:   exercise.ComplementaryTo,
+		UpdatedAt:         exercise.LastUpdated,
+	}})
 	return exercise, nil
 }
 
+// BulkUpsertItem pairs an Exercise with the 1-based input line it was decoded from, so BulkUpsert
+// can attribute each BulkResult back to its source line even though its worker pool completes
+// batches out of input order.
+type BulkUpsertItem struct {
+	Line     int
+	Exercise Exercise
+}
+
+// BulkResult reports the outcome of one BulkUpsertItem.
+type BulkResult struct {
+	Line   int    `json:"line"`
+	ID     string `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// bulkDefaultBatchSize is how many records BulkUpsert groups into a single Repository.UpsertBatch
+// call by default.
+const bulkDefaultBatchSize = 100
+
+// BulkOption configures optional BulkUpsert behaviour.
+type BulkOption func(*bulkConfig)
+
+type bulkConfig struct {
+	concurrency int
+	batchSize   int
+}
+
+// WithBulkConcurrency overrides how many workers concurrently drain BulkUpsert's input channel.
+// Defaults to runtime.GOMAXPROCS(0).
+func WithBulkConcurrency(n int) BulkOption {
+	return func(c *bulkConfig) { c.concurrency = n }
+}
+
+// WithBulkBatchSize overrides how many records each worker groups into a single
+// Repository.UpsertBatch call. Defaults to bulkDefaultBatchSize.
+func WithBulkBatchSize(n int) BulkOption {
+	return func(c *bulkConfig) { c.batchSize = n }
+}
+
+// BulkUpsert fans in over a worker pool (concurrency workers, default runtime.GOMAXPROCS(0)),
+// each grouping its share of in into batches of batchSize (default bulkDefaultBatchSize) and
+// writing every batch with a single Repository.UpsertBatch call. Results land on the returned
+// channel as each batch completes - in whatever order workers finish, not input order - and the
+// caller must drain it until it closes. Unlike UpsertExercise, BulkUpsert does not publish
+// per-row ExerciseEvents or invalidate the cache per row: it invalidates once, after every worker
+// has finished, covering every exercise ID written across the whole call.
+func (s *Service) BulkUpsert(ctx context.Context, in <-chan BulkUpsertItem, opts ...BulkOption) <-chan BulkResult {
+	cfg := bulkConfig{concurrency: runtime.GOMAXPROCS(0), batchSize: bulkDefaultBatchSize}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.concurrency <= 0 {
+		cfg.concurrency = 1
+	}
+	if cfg.batchSize <= 0 {
+		cfg.batchSize = bulkDefaultBatchSize
+	}
+
+	out := make(chan BulkResult)
+
+	var (
+		mu          sync.Mutex
+		upsertedIDs []string
+	)
+
+	var wg sync.WaitGroup
+	wg.Add(cfg.concurrency)
+	for i := 0; i < cfg.concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			batch := make([]BulkUpsertItem, 0, cfg.batchSize)
+			flush := func() {
+				if len(batch) == 0 {
+					return
+				}
+				ids := s.writeBulkBatch(ctx, batch, out)
+				if len(ids) > 0 {
+					mu.Lock()
+					upsertedIDs = append(upsertedIDs, ids...)
+					mu.Unlock()
+				}
+				batch = batch[:0]
+			}
+			for item := range in {
+				batch = append(batch, item)
+				if len(batch) >= cfg.batchSize {
+					flush()
+				}
+			}
+			flush()
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		if len(upsertedIDs) > 0 {
+			if err := s.cache.InvalidateBatch(context.Background(), upsertedIDs); err != nil {
+				log.Printf("domain: bulk cache invalidation failed for %d exercises: %v", len(upsertedIDs), err)
+			}
+		}
+		close(out)
+	}()
+
+	return out
+}
+
+// writeBulkBatch backfills ID/LastUpdated the same way UpsertExercise does, writes the
+// name-validated items in one Repository.UpsertBatch call, and sends a BulkResult per item -
+// every item in the batch shares the same outcome, since UpsertBatch is all-or-nothing. It
+// returns the IDs that were successfully written.
+func (s *Service) writeBulkBatch(ctx context.Context, batch []BulkUpsertItem, out chan<- BulkResult) []string {
+	valid := make([]BulkUpsertItem, 0, len(batch))
+	for _, item := range batch {
+		exercise := item.Exercise
+		if strings.TrimSpace(exercise.Name) == "" {
+			out <- BulkResult{Line: item.Line, Status: "error", Error: "name is required"}
+			continue
+		}
+		if strings.TrimSpace(exercise.ID) == "" {
+			exercise.ID = uuid.NewString()
+		}
+		if exercise.LastUpdated.IsZero() {
+			exercise.LastUpdated = time.Now().UTC()
+		}
+		item.Exercise = exercise
+		valid = append(valid, item)
+	}
+	if len(valid) == 0 {
+		return nil
+	}
+
+	exercises := make([]Exercise, len(valid))
+	for i, item := range valid {
+		exercises[i] = item.Exercise
+	}
+
+	if err := s.repo.UpsertBatch(ctx, exercises); err != nil {
+		for _, item := range valid {
+			out <- BulkResult{Line: item.Line, Status: "error", Error: err.Error()}
+		}
+		return nil
+	}
+
+	ids := make([]string, len(valid))
+	for i, item := range valid {
+		out <- BulkResult{Line: item.Line, ID: item.Exercise.ID, Status: "ok"}
+		ids[i] = item.Exercise.ID
+	}
+	return ids
+}
+
+// exportDefaultPageSize is how many exercises ExportExercises pulls from Repository.ListPage per
+// round trip.
+const exportDefaultPageSize = 100
+
+// ExportExercises streams every exercise matching query (a case-insensitive substring of Name;
+// empty matches everything) to emit, page by page via Repository.ListPage, so a caller streaming
+// an HTTP response never holds the whole ontology in memory. It stops and returns emit's error as
+// soon as emit returns one.
+func (s *Service) ExportExercises(ctx context.Context, query string, pageSize int, emit func(Exercise) error) error {
+	if pageSize <= 0 {
+		pageSize = exportDefaultPageSize
+	}
+	normalized := strings.ToLower(strings.TrimSpace(query))
+
+	cursor := ""
+	for {
+		page, next, err := s.repo.ListPage(ctx, cursor, pageSize)
+		if err != nil {
+			return err
+		}
+		for _, exercise := range page {
+			if normalized != "" && !strings.Contains(strings.ToLower(exercise.Name), normalized) {
+				continue
+			}
+			if err := emit(exercise); err != nil {
+				return err
+			}
+		}
+		if next == "" {
+			return nil
+		}
+		cursor = next
+	}
+}
+
 // UpsertExerciseWithSession persists exercise metadata and records a linked activity session.
 func (s *Service) UpsertExerciseWithSession(ctx context.Context, exercise Exercise, session ActivitySession) (Exercise, error) {
 	if strings.TrimSpace(exercise.Name) == "" {
@@ -139,6 +482,106 @@ func (s *Service) UpsertExerciseWithSession(ctx context.Context, exercise Exerci
 	return exercise, nil
 }
 
+// GuaranteedUpdate reads the current state of id and delegates to guaranteedUpdateFrom with
+// origStateIsCurrent true, since that read is a fresh Get. current is nil if the exercise does
+// not yet exist; mutate is responsible for populating ID and any required fields on creation.
+func (s *Service) GuaranteedUpdate(ctx context.Context, id string, mutate func(current *Exercise) (*Exercise, error)) (Exercise, error) {
+	current, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return Exercise{}, err
+	}
+	return s.guaranteedUpdateFrom(ctx, id, current, true, mutate, s.repo.Upsert)
+}
+
+// GuaranteedUpdateWithSession behaves exactly like GuaranteedUpdate, except each attempt writes
+// through Repository.UpsertWithSession instead of Upsert, so the merged exercise and session are
+// committed together under the same version check. Without this, a caller that read via
+// GuaranteedUpdate and then wrote the merged result through a separate, unversioned
+// UpsertWithSession call could have that second write silently reverted by a concurrent writer
+// that raced it to UpsertWithSession - the version check that protects the merge wouldn't protect the
+// write that actually lands.
+func (s *Service) GuaranteedUpdateWithSession(ctx context.Context, id string, session ActivitySession, mutate func(current *Exercise) (*Exercise, error)) (Exercise, error) {
+	current, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return Exercise{}, err
+	}
+	write := func(ctx context.Context, exercise Exercise) error {
+		if strings.TrimSpace(exercise.Name) == "" {
+			return errors.New("name is required")
+		}
+		return s.repo.UpsertWithSession(ctx, exercise, session)
+	}
+	return s.guaranteedUpdateFrom(ctx, id, current, true, mutate, write)
+}
+
+// guaranteedUpdateFrom attempts a versioned write of mutate(origState)'s result via write,
+// retrying on ErrConflict up to guaranteedUpdateMaxRetries attempts with exponential backoff
+// between tries. This mirrors the retry loop around etcd3's GuaranteedUpdate in Kubernetes'
+// apiserver storage layer, adapted to Dgraph's compare-and-swap-by-predicate mutations:
+// origStateIsCurrent is true only immediately after a fresh Get. If a write loses the race while
+// origStateIsCurrent is true, the state we just re-read is already stale in a way no further
+// retry within this call can fix, so the conflict is returned to the caller as-is. Otherwise
+// origState may simply be older than the latest fresh read would be (e.g. ensureExercise cached
+// it earlier in a multi-entity operation), so guaranteedUpdateFrom re-reads, marks
+// origStateIsCurrent true, and retries.
+//
+// origState is nil if the exercise does not yet exist; mutate is responsible for populating ID
+// and any required fields on creation. write performs the actual versioned persistence - Upsert
+// for GuaranteedUpdate, UpsertWithSession (closed over a fixed session) for
+// GuaranteedUpdateWithSession - and must honor next.ResourceVersion as a CAS guard exactly like
+// Upsert does.
+func (s *Service) guaranteedUpdateFrom(ctx context.Context, id string, origState *Exercise, origStateIsCurrent bool, mutate func(current *Exercise) (*Exercise, error), write func(ctx context.Context, exercise Exercise) error) (Exercise, error) {
+	current := origState
+
+	backoff := 10 * time.Millisecond
+	for attempt := 0; attempt < guaranteedUpdateMaxRetries; attempt++ {
+		next, err := mutate(current)
+		if err != nil {
+			return Exercise{}, err
+		}
+		if strings.TrimSpace(next.ID) == "" {
+			next.ID = id
+		}
+		if current != nil {
+			next.ResourceVersion = current.ResourceVersion
+		} else {
+			next.ResourceVersion = 0
+		}
+
+		err = write(ctx, *next)
+		if err == nil {
+			if err := s.cache.Invalidate(ctx, next.ID); err != nil {
+				return Exercise{}, fmt.Errorf("cache invalidation: %w", err)
+			}
+			observability.RecordOntologyUpsert(next.LastUpdated)
+			next.ResourceVersion++
+			return *next, nil
+		}
+		if !errors.Is(err, ErrConflict) {
+			return Exercise{}, err
+		}
+		if origStateIsCurrent {
+			return Exercise{}, err
+		}
+
+		jittered := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2+1)))
+		select {
+		case <-ctx.Done():
+			return Exercise{}, ctx.Err()
+		case <-time.After(jittered):
+		}
+		backoff *= 2
+
+		current, err = s.repo.Get(ctx, id)
+		if err != nil {
+			return Exercise{}, err
+		}
+		origStateIsCurrent = true
+	}
+
+	return Exercise{}, ErrGuaranteedUpdateRetriesExhausted
+}
+
 // GetExercise retrieves by ID.
 func (s *Service) GetExercise(ctx context.Context, id string) (*Exercise, error) {
 	exercise, err := s.repo.Get(ctx, id)
@@ -203,89 +646,281 @@ func (s *Service) UpdateRelationships(ctx context.Context, id string, rel Exerci
 		return Exercise{}, errors.New("exercise_id is required")
 	}
 
-	base, err := s.repo.Get(ctx, id)
-	if err != nil {
-		return Exercise{}, err
-	}
-	if base == nil {
-		return Exercise{}, ErrExerciseNotFound
-	}
-
-	now := time.Now().UTC()
-	base.LastUpdated = now
-	base.Targets = normalizeStrings(rel.Targets)
-
+	newTargets := normalizeStrings(rel.Targets)
 	newComplementary := normalizeStrings(rel.ComplementaryTo)
 	newContra := normalizeStrings(rel.Contraindications)
-
 	if err := ensureNotSelf(id, newComplementary); err != nil {
 		return Exercise{}, err
 	}
 	if err := ensureNotSelf(id, newContra); err != nil {
 		return Exercise{}, err
 	}
+	if err := validateRelationshipSets(newTargets, newComplementary, newContra); err != nil {
+		return Exercise{}, err
+	}
+
+	// states caches every exercise ensureExercise fetches across this call, so the neighbor
+	// existence check below and the neighbor upserts further down read the same snapshot
+	// instead of each paying their own repository round trip.
+	states := make(map[string]*Exercise)
+
+	existing, err := s.ensureExercise(ctx, id, states)
+	if err != nil {
+		if errors.Is(err, ErrExerciseNotFound) {
+			return Exercise{}, ErrExerciseNotFound
+		}
+		return Exercise{}, err
+	}
 
 	complementarySet := toSet(newComplementary)
 	contraSet := toSet(newContra)
 
-	oldComplementary := toSet(base.ComplementaryTo)
-	oldContra := toSet(base.Contraindications)
-
-	// Validate references exist and build cache of retrieved exercises.
-	related := make(map[string]*Exercise)
-	for ref := range complementarySet {
-		ex, err := s.ensureExercise(ctx, ref, related)
-		if err != nil {
+	// affected is every exercise whose back-link either needs to be added or removed: the new
+	// complementary/contraindicated sets, plus whatever was linked before that isn't in them
+	// any more.
+	affected := unionSets(toSet(existing.ComplementaryTo), complementarySet, toSet(existing.Contraindications), contraSet)
+	for ref := range affected {
+		if _, err := s.ensureExercise(ctx, ref, states); err != nil {
 			return Exercise{}, err
 		}
-		ex.ComplementaryTo = addToSet(ex.ComplementaryTo, id)
 	}
-	for ref := range contraSet {
-		ex, err := s.ensureExercise(ctx, ref, related)
-		if err != nil {
-			return Exercise{}, err
-		}
-		ex.Contraindications = addToSet(ex.Contraindications, id)
+
+	// validateNeighborConsistency and detectComplementaryConflict both read states, which is
+	// already fully populated by the ensureExercise loop above - so a conflict is detected and
+	// returned before anything is written, rather than after the base exercise's write commits.
+	if err := validateNeighborConsistency(id, newComplementary, newContra, states); err != nil {
+		return Exercise{}, err
+	}
+	conflict, err := s.detectComplementaryConflict(ctx, id, newComplementary, contraSet, states)
+	if err != nil {
+		return Exercise{}, err
+	}
+	if conflict != nil {
+		return Exercise{}, conflict
 	}
 
-	// Remove stale symmetric links.
-	for ref := range difference(oldComplementary, complementarySet) {
-		ex, err := s.ensureExercise(ctx, ref, related)
-		if err != nil {
-			return Exercise{}, err
+	// GuaranteedUpdate makes the write conditional on the version it reads, so a replayed DLQ
+	// message or a racing writer that lands a newer Targets/ComplementaryTo set in between
+	// makes this call fail with ErrConflict instead of being clobbered by it.
+	base, err := s.GuaranteedUpdate(ctx, id, func(current *Exercise) (*Exercise, error) {
+		if current == nil {
+			return nil, ErrExerciseNotFound
 		}
-		ex.ComplementaryTo = removeFromSet(ex.ComplementaryTo, id)
+		next := *current
+		next.LastUpdated = time.Now().UTC()
+		next.Targets = newTargets
+		next.ComplementaryTo = setToSlice(complementarySet)
+		next.Contraindications = setToSlice(contraSet)
+		return &next, nil
+	})
+	if err != nil {
+		return Exercise{}, err
 	}
-	for ref := range difference(oldContra, contraSet) {
-		ex, err := s.ensureExercise(ctx, ref, related)
+
+	for ref := range affected {
+		_, shouldComplement := complementarySet[ref]
+		_, shouldContra := contraSet[ref]
+		// guaranteedUpdateFrom reuses states[ref] rather than letting GuaranteedUpdate pay for
+		// another fresh Get: that snapshot was read above, before the base exercise's write, so
+		// it may already be stale by now (origStateIsCurrent=false) - a conflict here re-reads
+		// and retries rather than failing fast.
+		_, err := s.guaranteedUpdateFrom(ctx, ref, states[ref], false, func(current *Exercise) (*Exercise, error) {
+			if current == nil {
+				return nil, fmt.Errorf("exercise %s not found", ref)
+			}
+			next := *current
+			next.LastUpdated = base.LastUpdated
+			if shouldComplement {
+				next.ComplementaryTo = addToSet(next.ComplementaryTo, id)
+			} else {
+				next.ComplementaryTo = removeFromSet(next.ComplementaryTo, id)
+			}
+			if shouldContra {
+				next.Contraindications = addToSet(next.Contraindications, id)
+			} else {
+				next.Contraindications = removeFromSet(next.Contraindications, id)
+			}
+			return &next, nil
+		}, s.repo.Upsert)
 		if err != nil {
 			return Exercise{}, err
 		}
-		ex.Contraindications = removeFromSet(ex.Contraindications, id)
 	}
 
-	base.ComplementaryTo = setToSlice(complementarySet)
-	base.Contraindications = setToSlice(contraSet)
+	return base, nil
+}
 
-	if err := s.repo.Upsert(ctx, *base); err != nil {
-		return Exercise{}, err
+// ensureExercise fetches id, returning ErrExerciseNotFound-wrapped detail if it doesn't exist,
+// and caches a clone of it in states so a later guaranteedUpdateFrom call for the same id can
+// reuse this read instead of fetching it again.
+func (s *Service) ensureExercise(ctx context.Context, id string, states map[string]*Exercise) (*Exercise, error) {
+	if cached, ok := states[id]; ok {
+		return cached, nil
 	}
-	if err := s.cache.Invalidate(ctx, base.ID); err != nil {
-		return Exercise{}, fmt.Errorf("cache invalidation: %w", err)
+
+	ex, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ex == nil {
+		return nil, fmt.Errorf("exercise %s not found: %w", id, ErrExerciseNotFound)
 	}
 
-	for _, ex := range related {
-		ex.LastUpdated = now
-		if err := s.repo.Upsert(ctx, *ex); err != nil {
-			return Exercise{}, err
+	clone := *ex
+	states[id] = &clone
+	return &clone, nil
+}
+
+// validateRelationshipSets rejects a request where Targets, ComplementaryTo, and
+// Contraindications aren't pairwise disjoint - an exercise can't simultaneously target,
+// complement, and contraindicate the same ref.
+func validateRelationshipSets(targets, complementary, contra []string) error {
+	targetSet := toSet(targets)
+	complementarySet := toSet(complementary)
+	contraSet := toSet(contra)
+
+	if ref, ok := firstOverlap(complementarySet, contraSet); ok {
+		return &RelationshipConflictError{Path: []string{ref}, EdgeTypes: []string{"complementary", "contraindicated"}}
+	}
+	if ref, ok := firstOverlap(targetSet, complementarySet); ok {
+		return &RelationshipConflictError{Path: []string{ref}, EdgeTypes: []string{"target", "complementary"}}
+	}
+	if ref, ok := firstOverlap(targetSet, contraSet); ok {
+		return &RelationshipConflictError{Path: []string{ref}, EdgeTypes: []string{"target", "contraindicated"}}
+	}
+	return nil
+}
+
+// firstOverlap returns the lowest member (by sorted order) present in both a and b, if any.
+func firstOverlap(a, b map[string]struct{}) (string, bool) {
+	for _, ref := range setToSlice(a) {
+		if _, ok := b[ref]; ok {
+			return ref, true
+		}
+	}
+	return "", false
+}
+
+// validateNeighborConsistency rejects this update if a neighbor's currently-stored relationship
+// to id contradicts what this request is about to set: ref is being recorded as complementary
+// here while ref's own stored edge already says id is contraindicated, or vice versa. This is
+// the asymmetry a partially-failed prior write can leave behind - the pairwise-disjoint check
+// above can't see it, since it only looks at this request's own three lists.
+func validateNeighborConsistency(id string, newComplementary, newContra []string, states map[string]*Exercise) error {
+	for _, ref := range newComplementary {
+		if neighbor, ok := states[ref]; ok && slices.Contains(neighbor.Contraindications, id) {
+			return &RelationshipConflictError{Path: []string{id, ref}, EdgeTypes: []string{"complementary", "contraindicated"}}
 		}
-		if err := s.cache.Invalidate(ctx, ex.ID); err != nil {
-			return Exercise{}, fmt.Errorf("cache invalidation: %w", err)
+	}
+	for _, ref := range newContra {
+		if neighbor, ok := states[ref]; ok && slices.Contains(neighbor.ComplementaryTo, id) {
+			return &RelationshipConflictError{Path: []string{id, ref}, EdgeTypes: []string{"contraindicated", "complementary"}}
+		}
+	}
+	return nil
+}
+
+// detectComplementaryConflict walks the complementary graph outward from id - starting with
+// newComplementary, id's own pending edges, then each visited neighbor's persisted
+// ComplementaryTo beyond that - up to relationshipConflictMaxDepth hops, looking for any node
+// newContra marks as contraindicated. Finding one means this update would commit a state where
+// id is transitively complementary to, and directly contraindicated with, the same exercise: the
+// "A complementary -> B, B contraindicated -> A" case, generalized to a chain A -> ... -> B.
+func (s *Service) detectComplementaryConflict(ctx context.Context, id string, newComplementary []string, contraSet map[string]struct{}, states map[string]*Exercise) (*RelationshipConflictError, error) {
+	type frontierNode struct {
+		id   string
+		path []string
+	}
+
+	visited := map[string]struct{}{id: {}}
+	frontier := make([]frontierNode, 0, len(newComplementary))
+	for _, ref := range newComplementary {
+		frontier = append(frontier, frontierNode{id: ref, path: []string{id, ref}})
+	}
+
+	for depth := 1; len(frontier) > 0 && depth <= relationshipConflictMaxDepth; depth++ {
+		var next []frontierNode
+		for _, n := range frontier {
+			if _, ok := visited[n.id]; ok {
+				continue
+			}
+			visited[n.id] = struct{}{}
+
+			if _, contraindicated := contraSet[n.id]; contraindicated {
+				edgeTypes := make([]string, len(n.path))
+				for i := range edgeTypes[:len(edgeTypes)-1] {
+					edgeTypes[i] = "complementary"
+				}
+				edgeTypes[len(edgeTypes)-1] = "contraindicated"
+				return &RelationshipConflictError{Path: n.path, EdgeTypes: edgeTypes}, nil
+			}
+
+			neighbor, err := s.ensureExercise(ctx, n.id, states)
+			if err != nil {
+				return nil, err
+			}
+			for _, ref := range neighbor.ComplementaryTo {
+				if _, ok := visited[ref]; ok {
+					continue
+				}
+				path := append(append([]string{}, n.path...), ref)
+				next = append(next, frontierNode{id: ref, path: path})
+			}
 		}
+		frontier = next
+	}
+	return nil, nil
+}
+
+// RelationshipAuditReport summarizes one-sided (dangling) complementary/contraindication edges
+// found across the ontology: entries where some exercise lists a neighbor that doesn't list it
+// back, which RepairSymmetry on that neighbor would fix.
+type RelationshipAuditReport struct {
+	DanglingComplementary     []DanglingEdge `json:"dangling_complementary"`
+	DanglingContraindications []DanglingEdge `json:"dangling_contraindications"`
+}
+
+// DanglingEdge names one side of a one-sided relationship: From lists To, but To doesn't list
+// From back.
+type DanglingEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// auditScanLimit bounds a single AuditRelationships pass. Ontologies larger than this scan only
+// the first auditScanLimit exercises ListAll returns.
+const auditScanLimit = 10000
+
+// AuditRelationships scans every exercise in the ontology and reports any one-sided
+// complementary/contraindication edge. It only reports - repairing a given exercise's edges is
+// Repository.RepairSymmetry's job.
+func (s *Service) AuditRelationships(ctx context.Context) (RelationshipAuditReport, error) {
+	exercises, err := s.repo.ListAll(ctx, auditScanLimit)
+	if err != nil {
+		return RelationshipAuditReport{}, err
 	}
 
-	observability.RecordOntologyUpsert(now)
-	return *base, nil
+	byID := make(map[string]Exercise, len(exercises))
+	for _, ex := range exercises {
+		byID[ex.ID] = ex
+	}
+
+	var report RelationshipAuditReport
+	for _, ex := range exercises {
+		for _, ref := range ex.ComplementaryTo {
+			neighbor, ok := byID[ref]
+			if !ok || !slices.Contains(neighbor.ComplementaryTo, ex.ID) {
+				report.DanglingComplementary = append(report.DanglingComplementary, DanglingEdge{From: ex.ID, To: ref})
+			}
+		}
+		for _, ref := range ex.Contraindications {
+			neighbor, ok := byID[ref]
+			if !ok || !slices.Contains(neighbor.Contraindications, ex.ID) {
+				report.DanglingContraindications = append(report.DanglingContraindications, DanglingEdge{From: ex.ID, To: ref})
+			}
+		}
+	}
+	return report, nil
 }
 
 func ensureNotSelf(id string, refs []string) error {
@@ -322,10 +957,10 @@ func toSet(values []string) map[string]struct{} {
 	return set
 }
 
-func difference(a, b map[string]struct{}) map[string]struct{} {
+func unionSets(sets ...map[string]struct{}) map[string]struct{} {
 	result := make(map[string]struct{})
-	for k := range a {
-		if _, ok := b[k]; !ok {
+	for _, set := range sets {
+		for k := range set {
 			result[k] = struct{}{}
 		}
 	}
@@ -352,19 +987,3 @@ func removeFromSet(values []string, item string) []string {
 	delete(set, item)
 	return setToSlice(set)
 }
-
-func (s *Service) ensureExercise(ctx context.Context, id string, cache map[string]*Exercise) (*Exercise, error) {
-	if ex, ok := cache[id]; ok {
-		return ex, nil
-	}
-	ex, err := s.repo.Get(ctx, id)
-	if err != nil {
-		return nil, err
-	}
-	if ex == nil {
-		return nil, fmt.Errorf("exercise %s not found", id)
-	}
-	clone := *ex
-	cache[id] = &clone
-	return &clone, nil
-}
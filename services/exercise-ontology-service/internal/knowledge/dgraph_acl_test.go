@@ -0,0 +1,110 @@
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"example.com/exerciseontology/internal/auth"
+	"example.com/exerciseontology/internal/domain"
+)
+
+func fakeAccessJWT(t *testing.T, expiresIn time.Duration) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"exp": time.Now().Add(expiresIn).Unix(),
+	})
+	signed, err := token.SignedString([]byte("test-secret"))
+	require.NoError(t, err)
+	return signed
+}
+
+func TestDgraphRepositoryACLRoutesByTenantNamespace(t *testing.T) {
+	var loginNamespaces []float64
+	var mutateAccessTokens []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			loginNamespaces = append(loginNamespaces, body["namespace"].(float64))
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]string{
+					// Offset the expiry by namespace so each tenant's token differs even when both
+					// logins fall in the same second.
+					"accessJWT":  fakeAccessJWT(t, time.Hour+time.Duration(body["namespace"].(float64))*time.Second),
+					"refreshJwt": "refresh-token",
+				},
+			})
+		case "/mutate":
+			mutateAccessTokens = append(mutateAccessTokens, r.Header.Get("X-Dgraph-AccessToken"))
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	repo := NewDgraphRepository(server.URL, time.Second, WithACL(DgraphAuth{
+		Userid:     "groot",
+		Password:   "password",
+		Namespaces: map[string]uint64{"tenant-a": 1, "tenant-b": 2},
+	}))
+	defer repo.Close()
+
+	ctxA := auth.WithClaims(context.Background(), &auth.Claims{TenantID: "tenant-a"})
+	require.NoError(t, repo.Upsert(ctxA, domain.Exercise{ID: "tenant-a:tempo-ride"}))
+
+	ctxB := auth.WithClaims(context.Background(), &auth.Claims{TenantID: "tenant-b"})
+	require.NoError(t, repo.Upsert(ctxB, domain.Exercise{ID: "tenant-b:tempo-ride"}))
+
+	// A second request for the same tenant reuses the cached token rather than logging in
+	// again.
+	require.NoError(t, repo.Upsert(ctxA, domain.Exercise{ID: "tenant-a:tempo-ride-2"}))
+
+	require.ElementsMatch(t, []float64{1, 2}, loginNamespaces)
+	require.Len(t, mutateAccessTokens, 3)
+	require.NotEmpty(t, mutateAccessTokens[0])
+	require.Equal(t, mutateAccessTokens[0], mutateAccessTokens[2])
+	require.NotEqual(t, mutateAccessTokens[0], mutateAccessTokens[1])
+}
+
+func TestDgraphRepositoryACLDefaultsToNamespaceZeroWithoutTenant(t *testing.T) {
+	var loginNamespaces []float64
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			loginNamespaces = append(loginNamespaces, body["namespace"].(float64))
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]string{"accessJWT": fakeAccessJWT(t, time.Hour)},
+			})
+		case "/mutate":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	repo := NewDgraphRepository(server.URL, time.Second, WithACL(DgraphAuth{
+		Userid:     "groot",
+		Password:   "password",
+		Namespaces: map[string]uint64{"tenant-a": 1},
+	}))
+	defer repo.Close()
+
+	require.NoError(t, repo.Upsert(context.Background(), domain.Exercise{ID: "untenanted"}))
+	require.Equal(t, []float64{0}, loginNamespaces)
+}
@@ -0,0 +1,227 @@
+package knowledge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"example.com/exerciseontology/internal/auth"
+)
+
+// DgraphAuth configures ACL login against a multi-tenant Dgraph Enterprise cluster. Each tenant
+// ID (as carried on the request context by the auth package) maps to its own Dgraph namespace,
+// so DgraphRepository can isolate tenants at the graph layer instead of relying on exercise_id
+// prefixes.
+type DgraphAuth struct {
+	// Userid and Password authenticate against Dgraph ACL.
+	Userid   string
+	Password string
+	// Namespaces maps tenant ID to Dgraph namespace. A tenant with no entry, and requests made
+	// with no tenant on context, use the default namespace (0).
+	Namespaces map[string]uint64
+	// SharedSecret, when set, is sent as Dgraph-AuthToken on every request in addition to the
+	// per-namespace ACL access JWT, for clusters that also gate access behind Dgraph's
+	// pre-ACL auth-token feature.
+	SharedSecret string
+	// RefreshBefore controls how far ahead of expiry an access JWT is refreshed. Defaults to
+	// 30 seconds.
+	RefreshBefore time.Duration
+}
+
+// aclManager logs into Dgraph namespaces on demand and keeps each one's access JWT refreshed in
+// the background once it has been used.
+type aclManager struct {
+	endpoint      string
+	httpClient    *http.Client
+	userid        string
+	password      string
+	sharedSecret  string
+	refreshBefore time.Duration
+	namespaces    map[string]uint64
+
+	mu     sync.RWMutex
+	tokens map[uint64]string
+	loops  map[uint64]struct{}
+
+	stop chan struct{}
+}
+
+func newACLManager(endpoint string, httpClient *http.Client, cfg DgraphAuth) *aclManager {
+	refreshBefore := cfg.RefreshBefore
+	if refreshBefore <= 0 {
+		refreshBefore = 30 * time.Second
+	}
+	return &aclManager{
+		endpoint:      endpoint,
+		httpClient:    httpClient,
+		userid:        cfg.Userid,
+		password:      cfg.Password,
+		sharedSecret:  cfg.SharedSecret,
+		refreshBefore: refreshBefore,
+		namespaces:    cfg.Namespaces,
+		tokens:        make(map[uint64]string),
+		loops:         make(map[uint64]struct{}),
+		stop:          make(chan struct{}),
+	}
+}
+
+// namespaceFor resolves the Dgraph namespace a request should run against, based on the tenant
+// ID carried on ctx by the auth package. Requests with no tenant on context, or a tenant with no
+// configured mapping, run against the default namespace (0).
+func (m *aclManager) namespaceFor(ctx context.Context) uint64 {
+	claims, ok := auth.FromContext(ctx)
+	if !ok || claims.TenantID == "" {
+		return 0
+	}
+	ns, ok := m.namespaces[claims.TenantID]
+	if !ok {
+		return 0
+	}
+	return ns
+}
+
+// applyHeaders resolves the namespace for ctx, logging in on first use, and sets the resulting
+// access JWT plus any configured shared secret on req.
+func (m *aclManager) applyHeaders(ctx context.Context, req *http.Request) error {
+	ns := m.namespaceFor(ctx)
+
+	m.mu.RLock()
+	token, ok := m.tokens[ns]
+	m.mu.RUnlock()
+
+	if !ok {
+		var expiry time.Time
+		var err error
+		token, expiry, err = m.login(ctx, ns)
+		if err != nil {
+			return fmt.Errorf("dgraph acl login namespace %d: %w", ns, err)
+		}
+		m.ensureRefreshLoop(ns, expiry)
+	}
+
+	req.Header.Set("X-Dgraph-AccessToken", token)
+	if m.sharedSecret != "" {
+		req.Header.Set("Dgraph-AuthToken", m.sharedSecret)
+	}
+	return nil
+}
+
+// ensureRefreshLoop starts the background refresh goroutine for ns the first time it is used;
+// later calls are no-ops since the existing loop keeps the namespace's token fresh from here on.
+func (m *aclManager) ensureRefreshLoop(ns uint64, expiry time.Time) {
+	m.mu.Lock()
+	if _, started := m.loops[ns]; started {
+		m.mu.Unlock()
+		return
+	}
+	m.loops[ns] = struct{}{}
+	m.mu.Unlock()
+
+	go m.refreshLoop(ns, expiry)
+}
+
+func (m *aclManager) close() {
+	close(m.stop)
+}
+
+func (m *aclManager) refreshLoop(ns uint64, expiry time.Time) {
+	for {
+		wait := time.Until(expiry) - m.refreshBefore
+		if wait < 0 {
+			wait = 0
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-m.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		_, next, err := m.login(context.Background(), ns)
+		if err != nil {
+			// Retry shortly rather than leaving the namespace permanently unauthenticated; the
+			// stale token, if any, keeps serving requests in the meantime.
+			expiry = time.Now().Add(m.refreshBefore)
+			continue
+		}
+		expiry = next
+	}
+}
+
+// login authenticates against Dgraph's /login endpoint for the given namespace, stores the
+// resulting access JWT, and returns it along with its expiry.
+func (m *aclManager) login(ctx context.Context, ns uint64) (string, time.Time, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"userid":    m.userid,
+		"password":  m.password,
+		"namespace": ns,
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.endpoint+"/login", bytes.NewReader(body))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return "", time.Time{}, fmt.Errorf("dgraph login failed: %s", resp.Status)
+	}
+
+	var result struct {
+		Data struct {
+			AccessJWT  string `json:"accessJWT"`
+			RefreshJWT string `json:"refreshJwt"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", time.Time{}, err
+	}
+	if result.Data.AccessJWT == "" {
+		return "", time.Time{}, fmt.Errorf("dgraph login response missing accessJWT")
+	}
+
+	expiry, err := jwtExpiry(result.Data.AccessJWT)
+	if err != nil {
+		expiry = time.Now().Add(6 * time.Hour)
+	}
+
+	m.mu.Lock()
+	m.tokens[ns] = result.Data.AccessJWT
+	m.mu.Unlock()
+
+	return result.Data.AccessJWT, expiry, nil
+}
+
+// jwtExpiry reads the exp claim off an access JWT without verifying its signature; Dgraph, not
+// this client, is the party that validates the token, so this is purely for scheduling refresh.
+func jwtExpiry(token string) (time.Time, error) {
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		return time.Time{}, err
+	}
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unexpected claims type")
+	}
+	exp, err := claims.GetExpirationTime()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return exp.Time, nil
+}
@@ -0,0 +1,227 @@
+package knowledge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"example.com/exerciseontology/internal/domain"
+)
+
+// defaultMaxBatchBytes keeps a single bulk mutation well under Dgraph's 32 MiB request limit.
+const defaultMaxBatchBytes = 8 << 20
+
+// BulkRowError is the failure for a single exercise or session row passed to BulkUpsert.
+type BulkRowError struct {
+	Kind string // "exercise" or "session"
+	ID   string
+	Err  error
+}
+
+func (e BulkRowError) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Kind, e.ID, e.Err)
+}
+
+// BulkResult summarizes a BulkUpsert call: how many exercise and session rows were written, and
+// which rows failed, so the caller (e.g. the outbox dispatcher replaying a DLQ) can mark only
+// the failing rows rather than the whole batch.
+type BulkResult struct {
+	ExercisesUpserted int
+	SessionsUpserted  int
+	Failed            []BulkRowError
+}
+
+// BulkUpsert writes many exercises and sessions in as few Dgraph mutations as possible,
+// following the live-loader pattern of grouping nodes into one set mutation with
+// uid(exercise_N)/uid(session_N) blank-node aliases so a session points at its parent exercise
+// within the same transaction. A session's ExerciseID must belong to the exercises slice of the
+// same call, or to an exercise already stored in Dgraph, or its "exercise" edge is left unset.
+//
+// The batch is split (never exceeding WithMaxBatchBytes) to stay under Dgraph's mutation size
+// limit. If a sub-batch's mutation fails outright - Dgraph mutations are all-or-nothing, so a
+// single bad row fails the whole sub-batch - BulkUpsert falls back to upserting that sub-batch
+// one row at a time via Upsert/UpsertWithSession so only the genuinely bad rows end up in
+// BulkResult.Failed.
+func (r *DgraphRepository) BulkUpsert(ctx context.Context, exercises []domain.Exercise, sessions []domain.ActivitySession) (BulkResult, error) {
+	var result BulkResult
+
+	validExercises := make([]domain.Exercise, 0, len(exercises))
+	for _, ex := range exercises {
+		if err := validateID(ex.ID); err != nil {
+			result.Failed = append(result.Failed, BulkRowError{Kind: "exercise", ID: ex.ID, Err: err})
+			continue
+		}
+		validExercises = append(validExercises, ex)
+	}
+
+	validSessions := make([]domain.ActivitySession, 0, len(sessions))
+	for _, session := range sessions {
+		if err := validateID(session.ID); err != nil {
+			result.Failed = append(result.Failed, BulkRowError{Kind: "session", ID: session.ID, Err: err})
+			continue
+		}
+		if err := validateID(session.ExerciseID); err != nil {
+			result.Failed = append(result.Failed, BulkRowError{Kind: "session", ID: session.ID, Err: err})
+			continue
+		}
+		validSessions = append(validSessions, session)
+	}
+
+	if err := r.bulkUpsertBatch(ctx, validExercises, validSessions, &result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func (r *DgraphRepository) bulkUpsertBatch(ctx context.Context, exercises []domain.Exercise, sessions []domain.ActivitySession, result *BulkResult) error {
+	if len(exercises) == 0 && len(sessions) == 0 {
+		return nil
+	}
+
+	payload := buildBulkPayload(exercises, sessions)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	if len(body) > r.maxBatchBytes && len(exercises)+len(sessions) > 1 {
+		exA, sessA, exB, sessB := splitBatch(exercises, sessions)
+		if err := r.bulkUpsertBatch(ctx, exA, sessA, result); err != nil {
+			return err
+		}
+		return r.bulkUpsertBatch(ctx, exB, sessB, result)
+	}
+
+	if err := r.sendBulkMutation(ctx, body); err != nil {
+		return r.bulkUpsertRowByRow(ctx, exercises, sessions, result)
+	}
+
+	result.ExercisesUpserted += len(exercises)
+	result.SessionsUpserted += len(sessions)
+	return nil
+}
+
+func (r *DgraphRepository) bulkUpsertRowByRow(ctx context.Context, exercises []domain.Exercise, sessions []domain.ActivitySession, result *BulkResult) error {
+	exerciseByID := make(map[string]domain.Exercise, len(exercises))
+	for _, ex := range exercises {
+		exerciseByID[ex.ID] = ex
+		if err := r.Upsert(ctx, ex); err != nil {
+			result.Failed = append(result.Failed, BulkRowError{Kind: "exercise", ID: ex.ID, Err: err})
+			continue
+		}
+		result.ExercisesUpserted++
+	}
+
+	for _, session := range sessions {
+		ex, ok := exerciseByID[session.ExerciseID]
+		if !ok {
+			ex = domain.Exercise{ID: session.ExerciseID}
+		}
+		if err := r.UpsertWithSession(ctx, ex, session); err != nil {
+			result.Failed = append(result.Failed, BulkRowError{Kind: "session", ID: session.ID, Err: err})
+			continue
+		}
+		result.SessionsUpserted++
+	}
+	return nil
+}
+
+// buildBulkPayload assembles one upsert-block mutation covering every exercise and session in
+// the batch: a var block per distinct exercise_id resolving its (possibly not-yet-existing) uid,
+// and a set mutation referencing those vars so repeated exercise_ids across sessions collapse
+// onto the same node instead of creating duplicates.
+func buildBulkPayload(exercises []domain.Exercise, sessions []domain.ActivitySession) map[string]interface{} {
+	variables := make(map[string]string)
+	var varDecls, varBlocks []string
+	exerciseAlias := make(map[string]string)
+
+	declareVar := func(predicate, alias, id string) {
+		varName := fmt.Sprintf("$v%d", len(variables))
+		variables[varName] = id
+		varDecls = append(varDecls, varName+": string")
+		varBlocks = append(varBlocks, fmt.Sprintf("  %s as var(func: eq(%s, %s))", alias, predicate, varName))
+	}
+
+	aliasForExercise := func(id string) string {
+		if alias, ok := exerciseAlias[id]; ok {
+			return alias
+		}
+		alias := fmt.Sprintf("exercise_%d", len(exerciseAlias))
+		exerciseAlias[id] = alias
+		declareVar("exercise_id", alias, id)
+		return alias
+	}
+
+	set := make([]map[string]interface{}, 0, len(exercises)+len(sessions))
+	for _, ex := range exercises {
+		alias := aliasForExercise(ex.ID)
+		node := buildExerciseMutation(ex)
+		node["uid"] = fmt.Sprintf("uid(%s)", alias)
+		set = append(set, node)
+	}
+	for i, session := range sessions {
+		exerciseAliasName := aliasForExercise(session.ExerciseID)
+		sessionAlias := fmt.Sprintf("session_%d", i)
+		declareVar("session_id", sessionAlias, session.ID)
+
+		node := buildSessionMutation(session)
+		node["uid"] = fmt.Sprintf("uid(%s)", sessionAlias)
+		node["exercise"] = fmt.Sprintf("uid(%s)", exerciseAliasName)
+		set = append(set, node)
+	}
+
+	query := fmt.Sprintf("query q(%s) {\n%s\n}", strings.Join(varDecls, ", "), strings.Join(varBlocks, "\n"))
+	return map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+		"set":       set,
+	}
+}
+
+func (r *DgraphRepository) sendBulkMutation(ctx context.Context, body []byte) error {
+	ctx, cancel := WithOperationDeadline(ctx, r.getMutationDeadline())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint+"/mutate?commitNow=true", chunkedBody(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := r.applyACL(ctx, req); err != nil {
+		return err
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("dgraph bulk mutate failed: %s", resp.Status)
+	}
+	return nil
+}
+
+// chunkedBody wraps body in an io.Reader that hides its length from net/http, so large bulk
+// payloads are sent with Transfer-Encoding: chunked instead of requiring a pre-computed
+// Content-Length.
+func chunkedBody(body []byte) io.Reader {
+	return struct{ io.Reader }{bytes.NewReader(body)}
+}
+
+// splitBatch divides exercises and sessions roughly in half by total row count, treating the
+// two slices as one logical sequence (all exercises, then all sessions) so the split always
+// shrinks both halves even when one slice has only a single element.
+func splitBatch(exercises []domain.Exercise, sessions []domain.ActivitySession) ([]domain.Exercise, []domain.ActivitySession, []domain.Exercise, []domain.ActivitySession) {
+	mid := (len(exercises) + len(sessions)) / 2
+	if mid <= len(exercises) {
+		return exercises[:mid], nil, exercises[mid:], sessions
+	}
+	sessionMid := mid - len(exercises)
+	return exercises, sessions[:sessionMid], nil, sessions[sessionMid:]
+}
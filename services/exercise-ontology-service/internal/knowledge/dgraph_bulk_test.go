@@ -0,0 +1,99 @@
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"example.com/exerciseontology/internal/domain"
+)
+
+func TestDgraphRepositoryBulkUpsertSendsOneMutation(t *testing.T) {
+	var mutateCalls int
+	var lastBody map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/mutate":
+			mutateCalls++
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(body, &lastBody))
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	repo := NewDgraphRepository(server.URL, 5*time.Second)
+
+	exercises := []domain.Exercise{
+		{ID: "tenant:tempo-ride", Name: "Tempo Ride"},
+		{ID: "tenant:recovery-ride", Name: "Recovery Ride"},
+	}
+	sessions := []domain.ActivitySession{
+		{ID: "tenant:activity-1", ExerciseID: "tenant:tempo-ride"},
+		{ID: "tenant:activity-2", ExerciseID: "tenant:recovery-ride"},
+	}
+
+	result, err := repo.BulkUpsert(context.Background(), exercises, sessions)
+	require.NoError(t, err)
+	require.Equal(t, 1, mutateCalls)
+	require.Equal(t, 2, result.ExercisesUpserted)
+	require.Equal(t, 2, result.SessionsUpserted)
+	require.Empty(t, result.Failed)
+
+	set, ok := lastBody["set"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, set, 4)
+}
+
+func TestDgraphRepositoryBulkUpsertRejectsInvalidIDsWithoutCallingServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("server should not be contacted for a fully-invalid batch")
+	}))
+	defer server.Close()
+
+	repo := NewDgraphRepository(server.URL, 5*time.Second)
+
+	result, err := repo.BulkUpsert(context.Background(), []domain.Exercise{{ID: ""}}, []domain.ActivitySession{{ID: "bad\nid", ExerciseID: "tenant:tempo-ride"}})
+	require.NoError(t, err)
+	require.Len(t, result.Failed, 2)
+	require.Equal(t, 0, result.ExercisesUpserted)
+	require.Equal(t, 0, result.SessionsUpserted)
+}
+
+func TestDgraphRepositoryBulkUpsertFallsBackToRowByRowOnMutationFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var decoded map[string]interface{}
+		_ = json.Unmarshal(body, &decoded)
+
+		if set, ok := decoded["set"].([]interface{}); ok && len(set) > 1 {
+			// Reject the batched mutation to force the row-by-row fallback.
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	repo := NewDgraphRepository(server.URL, 5*time.Second)
+
+	exercises := []domain.Exercise{
+		{ID: "tenant:tempo-ride", Name: "Tempo Ride"},
+		{ID: "tenant:recovery-ride", Name: "Recovery Ride"},
+	}
+
+	result, err := repo.BulkUpsert(context.Background(), exercises, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, result.ExercisesUpserted)
+	require.Empty(t, result.Failed)
+}
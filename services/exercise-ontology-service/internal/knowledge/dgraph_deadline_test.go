@@ -0,0 +1,67 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"example.com/exerciseontology/internal/domain"
+)
+
+func TestDgraphRepositoryMutationDeadlineExceeded(t *testing.T) {
+	unblock := make(chan struct{})
+	handlerDone := make(chan struct{}, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+		w.WriteHeader(http.StatusOK)
+		handlerDone <- struct{}{}
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	repo := NewDgraphRepository(server.URL, 10*time.Second)
+	repo.SetMutationDeadline(20 * time.Millisecond)
+
+	start := time.Now()
+	err := repo.Upsert(context.Background(), domain.Exercise{ID: "tenant:tempo-ride"})
+	elapsed := time.Since(start)
+
+	require.Error(t, err)
+	require.True(t, errors.Is(err, context.DeadlineExceeded), "expected context.DeadlineExceeded, got %v", err)
+	require.Less(t, elapsed, time.Second, "mutation should have aborted on the deadline, not the slow handler")
+}
+
+func TestDgraphRepositoryQueryDeadlineExceeded(t *testing.T) {
+	unblock := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	repo := NewDgraphRepository(server.URL, 10*time.Second)
+	repo.SetQueryDeadline(20 * time.Millisecond)
+
+	start := time.Now()
+	_, err := repo.Get(context.Background(), "tenant:tempo-ride")
+	elapsed := time.Since(start)
+
+	require.Error(t, err)
+	require.True(t, errors.Is(err, context.DeadlineExceeded), "expected context.DeadlineExceeded, got %v", err)
+	require.Less(t, elapsed, time.Second, "query should have aborted on the deadline, not the slow handler")
+}
+
+func TestWithOperationDeadlineNoopForNonPositive(t *testing.T) {
+	ctx := context.Background()
+	derived, cancel := WithOperationDeadline(ctx, 0)
+	defer cancel()
+	require.Equal(t, ctx, derived)
+}
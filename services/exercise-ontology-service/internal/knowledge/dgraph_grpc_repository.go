@@ -0,0 +1,645 @@
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync/atomic"
+
+	"github.com/dgraph-io/dgo/v210"
+	"github.com/dgraph-io/dgo/v210/protos/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"example.com/exerciseontology/internal/domain"
+)
+
+// DgraphGRPCRepository persists exercises via Dgraph's native gRPC protocol. Unlike
+// DgraphRepository it keeps a pool of long-lived *grpc.ClientConn connections and exposes
+// explicit transaction control, so callers that need to batch several mutations into one ACID
+// commit (rather than one HTTP round trip per mutation) can do so via NewTxn.
+type DgraphGRPCRepository struct {
+	conns []*grpc.ClientConn
+	next  atomic.Uint64
+}
+
+// GRPCOption configures optional DgraphGRPCRepository behaviour.
+type GRPCOption func(*grpcConfig)
+
+type grpcConfig struct {
+	poolSize    int
+	dialOptions []grpc.DialOption
+}
+
+// WithPoolSize overrides the number of pooled gRPC connections dialed against addr. Defaults
+// to 4; Dgraph clients are expected to round-robin across a handful of connections rather than
+// share a single one, since each *grpc.ClientConn multiplexes over one HTTP/2 transport.
+func WithPoolSize(n int) GRPCOption {
+	return func(c *grpcConfig) { c.poolSize = n }
+}
+
+// WithDialOptions appends additional grpc.DialOptions, e.g. transport credentials for a
+// TLS-terminated Dgraph cluster.
+func WithDialOptions(opts ...grpc.DialOption) GRPCOption {
+	return func(c *grpcConfig) { c.dialOptions = append(c.dialOptions, opts...) }
+}
+
+// NewDgraphGRPCRepository dials poolSize connections against addr (a single Dgraph Alpha's
+// gRPC endpoint, typically :9080) and returns a repository that round-robins requests across
+// them.
+func NewDgraphGRPCRepository(addr string, opts ...GRPCOption) (*DgraphGRPCRepository, error) {
+	cfg := &grpcConfig{poolSize: 4}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.poolSize <= 0 {
+		cfg.poolSize = 1
+	}
+	if len(cfg.dialOptions) == 0 {
+		cfg.dialOptions = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+
+	conns := make([]*grpc.ClientConn, 0, cfg.poolSize)
+	for i := 0; i < cfg.poolSize; i++ {
+		conn, err := grpc.NewClient(addr, cfg.dialOptions...)
+		if err != nil {
+			for _, c := range conns {
+				c.Close()
+			}
+			return nil, fmt.Errorf("dial dgraph grpc endpoint %s: %w", addr, err)
+		}
+		conns = append(conns, conn)
+	}
+
+	return &DgraphGRPCRepository{conns: conns}, nil
+}
+
+// Close tears down every pooled connection.
+func (r *DgraphGRPCRepository) Close() error {
+	var firstErr error
+	for _, conn := range r.conns {
+		if err := conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// client picks the next pooled connection round-robin and wraps it in a fresh dgo client.
+func (r *DgraphGRPCRepository) client() *dgo.Dgraph {
+	idx := r.next.Add(1) % uint64(len(r.conns))
+	return dgo.NewDgraphClient(api.NewDgraphClient(r.conns[idx]))
+}
+
+// Txn wraps a dgo transaction so callers can batch several UpsertWithSession calls into one
+// ACID commit instead of paying a round trip per mutation.
+type Txn struct {
+	dgraphTxn *dgo.Txn
+	repo      *DgraphGRPCRepository
+	pending   []pendingVersionCheck
+}
+
+// pendingVersionCheck records a CAS-guarded write staged on a Txn, so Commit can confirm after
+// the fact that every guarded mutation actually landed instead of silently no-opping.
+type pendingVersionCheck struct {
+	exerciseID      string
+	resourceVersion uint64
+}
+
+// NewTxn starts a transaction against the repository's Dgraph cluster. The caller must call
+// exactly one of Commit or Discard.
+func (r *DgraphGRPCRepository) NewTxn() *Txn {
+	return &Txn{dgraphTxn: r.client().NewTxn(), repo: r}
+}
+
+// Discard aborts the transaction, releasing any locks it holds.
+func (t *Txn) Discard(ctx context.Context) error {
+	return t.dgraphTxn.Discard(ctx)
+}
+
+// Commit finalizes every mutation staged on the transaction. As with the non-batched
+// UpsertWithSession, a CAS-guarded mutation whose @if condition failed is a silent no-op rather
+// than an error, so Commit re-reads every exercise staged with a non-zero ResourceVersion and
+// returns domain.ErrConflict if any of them didn't land at the expected version.
+func (t *Txn) Commit(ctx context.Context) error {
+	if err := t.dgraphTxn.Commit(ctx); err != nil {
+		return err
+	}
+	for _, check := range t.pending {
+		current, err := t.repo.Get(ctx, check.exerciseID)
+		if err != nil {
+			return err
+		}
+		if current == nil || current.ResourceVersion != check.resourceVersion+1 {
+			return domain.ErrConflict
+		}
+	}
+	return nil
+}
+
+// UpsertWithSession stages the exercise and session node within the transaction without
+// committing, so the caller can batch several sessions before a single Commit. Like the
+// non-batched UpsertWithSession, a non-zero exercise.ResourceVersion is a CAS guard: Commit
+// reports domain.ErrConflict for the whole batch unless every such write still matched the
+// stored node's version.
+func (t *Txn) UpsertWithSession(ctx context.Context, exercise domain.Exercise, session domain.ActivitySession) error {
+	if err := validateID(exercise.ID); err != nil {
+		return err
+	}
+	if err := validateID(session.ID); err != nil {
+		return err
+	}
+	mutation, err := upsertSessionMutation(exercise, session)
+	if err != nil {
+		return err
+	}
+	if exercise.ResourceVersion != 0 {
+		mutation.Cond = "@if(eq(len(v), 1))"
+		t.pending = append(t.pending, pendingVersionCheck{exerciseID: exercise.ID, resourceVersion: exercise.ResourceVersion})
+	}
+	_, err = t.dgraphTxn.Do(ctx, &api.Request{
+		Query:     upsertSessionQuery(exercise.ResourceVersion),
+		Vars:      map[string]string{"$exerciseID": exercise.ID, "$sessionID": session.ID},
+		Mutations: []*api.Mutation{mutation},
+	})
+	return err
+}
+
+// UpsertWithSession creates or updates the exercise and records an activity session edge in a
+// single, implicitly-committed transaction. Like Upsert, a non-zero exercise.ResourceVersion is
+// a CAS guard: the write is rejected with domain.ErrConflict unless it still matches the stored
+// node's version, so two concurrent callers racing to append a session can't silently clobber
+// each other's exercise update.
+func (r *DgraphGRPCRepository) UpsertWithSession(ctx context.Context, exercise domain.Exercise, session domain.ActivitySession) error {
+	if err := validateID(exercise.ID); err != nil {
+		return err
+	}
+	if err := validateID(session.ID); err != nil {
+		return err
+	}
+	mutation, err := upsertSessionMutation(exercise, session)
+	if err != nil {
+		return err
+	}
+	txn := r.client().NewTxn()
+	defer txn.Discard(ctx)
+
+	if exercise.ResourceVersion == 0 {
+		if _, err := txn.Do(ctx, &api.Request{
+			Query:     upsertSessionQuery(exercise.ResourceVersion),
+			Vars:      map[string]string{"$exerciseID": exercise.ID, "$sessionID": session.ID},
+			Mutations: []*api.Mutation{mutation},
+			CommitNow: true,
+		}); err != nil {
+			return fmt.Errorf("dgraph grpc upsert with session: %w", err)
+		}
+		return nil
+	}
+
+	mutation.Cond = "@if(eq(len(v), 1))"
+	if _, err := txn.Do(ctx, &api.Request{
+		Query:     upsertSessionQuery(exercise.ResourceVersion),
+		Vars:      map[string]string{"$exerciseID": exercise.ID, "$sessionID": session.ID},
+		Mutations: []*api.Mutation{mutation},
+		CommitNow: true,
+	}); err != nil {
+		return fmt.Errorf("dgraph grpc upsert with session: %w", err)
+	}
+
+	// As with Upsert, a failed @if condition is a silent no-op rather than an error, so confirm
+	// the write landed by re-reading the rev we just attempted to set.
+	current, err := r.Get(ctx, exercise.ID)
+	if err != nil {
+		return err
+	}
+	if current == nil || current.ResourceVersion != exercise.ResourceVersion+1 {
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+func upsertSessionQuery(resourceVersion uint64) string {
+	if resourceVersion == 0 {
+		return `query q($exerciseID: string, $sessionID: string) {
+	  exercise as var(func: eq(exercise_id, $exerciseID))
+	  session as var(func: eq(session_id, $sessionID))
+	}`
+	}
+	return fmt.Sprintf(`query q($exerciseID: string, $sessionID: string) {
+	  exercise as var(func: eq(exercise_id, $exerciseID))
+	  session as var(func: eq(session_id, $sessionID))
+	  v as var(func: uid(exercise)) @filter(eq(rev, %d))
+	}`, resourceVersion)
+}
+
+func upsertSessionMutation(exercise domain.Exercise, session domain.ActivitySession) (*api.Mutation, error) {
+	exerciseNode := buildExerciseMutation(exercise)
+	exerciseNode["rev"] = exercise.ResourceVersion + 1
+	set := []map[string]interface{}{exerciseNode, buildSessionMutation(session)}
+	payload, err := json.Marshal(set)
+	if err != nil {
+		return nil, err
+	}
+	return &api.Mutation{SetJson: payload}, nil
+}
+
+// StreamUpsertSessions consumes sessions until ch is closed, pipelining each one through the
+// same long-lived transaction so that Dgraph doesn't pay a new transaction's start-ts round
+// trip per session. Each session is linked to its exercise by exercise_id, so the exercise node
+// itself must already exist (created via Upsert/UpsertWithSession beforehand). The transaction
+// is committed once ch closes; ctx cancellation discards it instead.
+func (r *DgraphGRPCRepository) StreamUpsertSessions(ctx context.Context, ch <-chan domain.ActivitySession) error {
+	txn := r.client().NewTxn()
+	committed := false
+	defer func() {
+		if !committed {
+			txn.Discard(ctx)
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case session, ok := <-ch:
+			if !ok {
+				if err := txn.Commit(ctx); err != nil {
+					return fmt.Errorf("dgraph grpc stream commit: %w", err)
+				}
+				committed = true
+				return nil
+			}
+
+			if err := validateID(session.ExerciseID); err != nil {
+				return err
+			}
+			payload, err := json.Marshal(buildSessionMutation(session))
+			if err != nil {
+				return err
+			}
+			if _, err := txn.Do(ctx, &api.Request{
+				Query:     `query q($exerciseID: string) { exercise as var(func: eq(exercise_id, $exerciseID)) }`,
+				Vars:      map[string]string{"$exerciseID": session.ExerciseID},
+				Mutations: []*api.Mutation{{SetJson: payload}},
+			}); err != nil {
+				return fmt.Errorf("dgraph grpc stream upsert: %w", err)
+			}
+		}
+	}
+}
+
+// Upsert creates or updates an exercise node, mirroring DgraphRepository.Upsert's
+// optimistic-concurrency semantics over the gRPC transport.
+func (r *DgraphGRPCRepository) Upsert(ctx context.Context, exercise domain.Exercise) error {
+	if err := validateID(exercise.ID); err != nil {
+		return err
+	}
+
+	node := buildExerciseMutation(exercise)
+	node["rev"] = exercise.ResourceVersion + 1
+
+	payload, err := json.Marshal([]map[string]interface{}{node})
+	if err != nil {
+		return err
+	}
+
+	txn := r.client().NewTxn()
+	defer txn.Discard(ctx)
+
+	if exercise.ResourceVersion == 0 {
+		_, err := txn.Do(ctx, &api.Request{
+			Query:     `query q($id: string) { exercise as var(func: eq(exercise_id, $id)) }`,
+			Vars:      map[string]string{"$id": exercise.ID},
+			Mutations: []*api.Mutation{{SetJson: payload}},
+			CommitNow: true,
+		})
+		return err
+	}
+
+	query := fmt.Sprintf(`query q($id: string) {
+	  exercise as var(func: eq(exercise_id, $id))
+	  v as var(func: uid(exercise)) @filter(eq(rev, %d))
+	}`, exercise.ResourceVersion)
+
+	if _, err := txn.Do(ctx, &api.Request{
+		Query:     query,
+		Vars:      map[string]string{"$id": exercise.ID},
+		Mutations: []*api.Mutation{{SetJson: payload, Cond: "@if(eq(len(v), 1))"}},
+		CommitNow: true,
+	}); err != nil {
+		return err
+	}
+
+	// As with DgraphRepository.Upsert, a failed @if condition is a silent no-op rather than an
+	// error, so confirm the write landed by re-reading the rev we just attempted to set.
+	current, err := r.Get(ctx, exercise.ID)
+	if err != nil {
+		return err
+	}
+	if current == nil || current.ResourceVersion != exercise.ResourceVersion+1 {
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+// UpsertBatch writes every exercise in a single Dgraph transaction, mirroring
+// DgraphRepository.UpsertBatch's all-or-nothing semantics over the native gRPC transport. It
+// reuses buildBulkPayload's blank-node aliasing so repeated exercise_ids across the batch collapse
+// onto the same node instead of creating duplicates.
+func (r *DgraphGRPCRepository) UpsertBatch(ctx context.Context, exercises []domain.Exercise) error {
+	if len(exercises) == 0 {
+		return nil
+	}
+	for _, exercise := range exercises {
+		if err := validateID(exercise.ID); err != nil {
+			return err
+		}
+	}
+
+	payload := buildBulkPayload(exercises, nil)
+	setJSON, err := json.Marshal(payload["set"])
+	if err != nil {
+		return err
+	}
+
+	txn := r.client().NewTxn()
+	defer txn.Discard(ctx)
+
+	if _, err := txn.Do(ctx, &api.Request{
+		Query:     payload["query"].(string),
+		Vars:      payload["variables"].(map[string]string),
+		Mutations: []*api.Mutation{{SetJson: setJSON}},
+		CommitNow: true,
+	}); err != nil {
+		return fmt.Errorf("dgraph grpc upsert batch: %w", err)
+	}
+	return nil
+}
+
+// ListPage returns up to limit exercises ordered by exercise_id, starting after cursor
+// (exclusive). An empty cursor starts from the beginning; nextCursor is empty once there are no
+// further pages.
+func (r *DgraphGRPCRepository) ListPage(ctx context.Context, cursor string, limit int) ([]domain.Exercise, string, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := fmt.Sprintf(`query exercises($cursor: string) {
+  exercises(func: type(Exercise), orderasc: exercise_id, first: %d) @filter(gt(exercise_id, $cursor)) {
+    exercise_id
+    name
+    difficulty
+    targets
+    requires
+    contraindicated_with
+    complementary_to
+    last_updated
+    last_seen_at
+    session_count
+    rev
+  }
+}`, limit)
+
+	result, err := r.executeQuery(ctx, query, map[string]string{"$cursor": cursor})
+	if err != nil {
+		return nil, "", err
+	}
+
+	exercises := make([]domain.Exercise, 0, len(result.Exercises))
+	for _, item := range result.Exercises {
+		exercises = append(exercises, item.toDomain())
+	}
+
+	var nextCursor string
+	if len(exercises) == limit {
+		nextCursor = exercises[len(exercises)-1].ID
+	}
+	return exercises, nextCursor, nil
+}
+
+// Get retrieves an exercise by ID.
+func (r *DgraphGRPCRepository) Get(ctx context.Context, id string) (*domain.Exercise, error) {
+	query := `query exercise($id: string) {
+  exercises(func: eq(exercise_id, $id)) {
+    exercise_id
+    name
+    difficulty
+    targets
+    requires
+    contraindicated_with
+    complementary_to
+    last_updated
+    last_seen_at
+    session_count
+    rev
+  }
+}`
+	result, err := r.executeQuery(ctx, query, map[string]string{"$id": id})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Exercises) == 0 {
+		return nil, nil
+	}
+	best := result.Exercises[0].toDomain()
+	for _, node := range result.Exercises[1:] {
+		candidate := node.toDomain()
+		if candidate.LastUpdated.After(best.LastUpdated) {
+			best = candidate
+			continue
+		}
+		if candidate.LastUpdated.Equal(best.LastUpdated) && candidate.SessionCount > best.SessionCount {
+			best = candidate
+		}
+	}
+	return &best, nil
+}
+
+// Search performs a term-matching query over exercise names.
+func (r *DgraphGRPCRepository) Search(ctx context.Context, queryTerm string, limit int) ([]domain.Exercise, error) {
+	query := fmt.Sprintf(`query exercise($term: string) {
+  exercises(func: type(Exercise), first: %d) @filter(anyofterms(name, $term)) {
+    exercise_id
+    name
+    difficulty
+    targets
+    requires
+    contraindicated_with
+    complementary_to
+    last_updated
+    last_seen_at
+    session_count
+    rev
+  }
+}`, limit)
+
+	result, err := r.executeQuery(ctx, query, map[string]string{"$term": queryTerm})
+	if err != nil {
+		return nil, err
+	}
+	exercises := make([]domain.Exercise, 0, len(result.Exercises))
+	for _, item := range result.Exercises {
+		exercises = append(exercises, item.toDomain())
+	}
+	return exercises, nil
+}
+
+// ListAll returns every exercise node, bounded by limit (<=0 means unbounded).
+func (r *DgraphGRPCRepository) ListAll(ctx context.Context, limit int) ([]domain.Exercise, error) {
+	first := limit
+	if first <= 0 {
+		first = -1
+	}
+	query := fmt.Sprintf(`query exercises {
+  exercises(func: type(Exercise), first: %d) {
+    exercise_id
+    name
+    difficulty
+    targets
+    requires
+    contraindicated_with
+    complementary_to
+    last_updated
+    last_seen_at
+    session_count
+    rev
+  }
+}`, first)
+
+	result, err := r.executeQuery(ctx, query, map[string]string{})
+	if err != nil {
+		return nil, err
+	}
+	exercises := make([]domain.Exercise, 0, len(result.Exercises))
+	for _, item := range result.Exercises {
+		exercises = append(exercises, item.toDomain())
+	}
+	return exercises, nil
+}
+
+// ListSessions returns sessions linked to the exercise ordered by recorded time.
+func (r *DgraphGRPCRepository) ListSessions(ctx context.Context, exerciseID string, limit int) ([]domain.ActivitySession, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	query := fmt.Sprintf(`query sessions($id: string) {
+	  exercises(func: eq(exercise_id, $id)) {
+	    sessions: ~exercise(orderdesc: recorded_at, first: %d) {
+	      session_id
+	      activity_id
+	      tenant_id
+	      user_id
+	      source
+	      version
+	      started_at
+	      duration_min
+	      recorded_at
+	    }
+	  }
+	}`, limit)
+
+	resp, err := r.client().NewReadOnlyTxn().QueryWithVars(ctx, query, map[string]string{"$id": exerciseID})
+	if err != nil {
+		return nil, fmt.Errorf("dgraph grpc query: %w", err)
+	}
+
+	var wrapper struct {
+		Exercises []struct {
+			Sessions []sessionNode `json:"sessions"`
+		} `json:"exercises"`
+	}
+	if err := json.Unmarshal(resp.GetJson(), &wrapper); err != nil {
+		return nil, err
+	}
+	if len(wrapper.Exercises) == 0 {
+		return nil, nil
+	}
+	sessions := make([]domain.ActivitySession, 0, len(wrapper.Exercises[0].Sessions))
+	for _, node := range wrapper.Exercises[0].Sessions {
+		sessions = append(sessions, node.toDomain(exerciseID))
+	}
+	return sessions, nil
+}
+
+// Delete removes the exercise and any associated sessions.
+func (r *DgraphGRPCRepository) Delete(ctx context.Context, id string) error {
+	if err := validateID(id); err != nil {
+		return err
+	}
+
+	query := `query q($id: string) {
+	  exercise as var(func: eq(exercise_id, $id))
+	  sessions as var(func: eq(exercise_id, $id)) @filter(type(ActivitySession))
+	}`
+
+	txn := r.client().NewTxn()
+	defer txn.Discard(ctx)
+
+	_, err := txn.Do(ctx, &api.Request{
+		Query: query,
+		Vars:  map[string]string{"$id": id},
+		Mutations: []*api.Mutation{
+			{DelNquads: []byte("uid(sessions) * * .\nuid(exercise) * * .\n")},
+		},
+		CommitNow: true,
+	})
+	if err != nil {
+		return fmt.Errorf("dgraph grpc delete: %w", err)
+	}
+	return nil
+}
+
+// RepairSymmetry treats id's own stored ComplementaryTo/Contraindications as authoritative and
+// pushes any missing back-link onto each referenced neighbor via a plain Upsert, converging an
+// asymmetric graph left behind by an interrupted UpdateRelationships write.
+func (r *DgraphGRPCRepository) RepairSymmetry(ctx context.Context, id string) error {
+	exercise, err := r.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if exercise == nil {
+		return domain.ErrExerciseNotFound
+	}
+
+	for _, ref := range exercise.ComplementaryTo {
+		neighbor, err := r.Get(ctx, ref)
+		if err != nil {
+			return err
+		}
+		if neighbor == nil || slices.Contains(neighbor.ComplementaryTo, id) {
+			continue
+		}
+		neighbor.ComplementaryTo = append(append([]string{}, neighbor.ComplementaryTo...), id)
+		if err := r.Upsert(ctx, *neighbor); err != nil {
+			return err
+		}
+	}
+	for _, ref := range exercise.Contraindications {
+		neighbor, err := r.Get(ctx, ref)
+		if err != nil {
+			return err
+		}
+		if neighbor == nil || slices.Contains(neighbor.Contraindications, id) {
+			continue
+		}
+		neighbor.Contraindications = append(append([]string{}, neighbor.Contraindications...), id)
+		if err := r.Upsert(ctx, *neighbor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *DgraphGRPCRepository) executeQuery(ctx context.Context, query string, variables map[string]string) (queryResponse, error) {
+	resp, err := r.client().NewReadOnlyTxn().QueryWithVars(ctx, query, variables)
+	if err != nil {
+		return queryResponse{}, fmt.Errorf("dgraph grpc query: %w", err)
+	}
+	var result queryResponse
+	if err := json.Unmarshal(resp.GetJson(), &result); err != nil {
+		return queryResponse{}, err
+	}
+	return result, nil
+}
+
@@ -0,0 +1,105 @@
+//go:build integration
+
+package knowledge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"example.com/exerciseontology/internal/cache"
+	"example.com/exerciseontology/internal/domain"
+	"example.com/exerciseontology/internal/testsupport"
+)
+
+func TestDgraphGRPCRepositoryUpsertWithSession(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	container, _, grpcAddr := testsupport.StartDgraphWithGRPC(ctx, t)
+	t.Cleanup(func() { _ = container.Terminate(context.Background()) })
+
+	repo, err := NewDgraphGRPCRepository(grpcAddr)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = repo.Close() })
+
+	service := domain.NewService(repo, cache.NoopInvalidator{})
+
+	exercise := domain.Exercise{
+		ID:           "tenant:tempo-ride",
+		Name:         "Tempo Ride",
+		Difficulty:   "intermediate",
+		Targets:      []string{"cardio"},
+		SessionCount: 1,
+		LastUpdated:  time.Now().UTC(),
+	}
+
+	session := domain.ActivitySession{
+		ID:          "session-1",
+		ExerciseID:  exercise.ID,
+		ActivityID:  "activity-1",
+		TenantID:    "tenant",
+		UserID:      "user",
+		Source:      "integration-test",
+		Version:     "v1",
+		StartedAt:   time.Now().UTC(),
+		DurationMin: 30,
+		RecordedAt:  time.Now().UTC(),
+	}
+
+	_, err = service.UpsertExerciseWithSession(ctx, exercise, session)
+	require.NoError(t, err)
+
+	stored, err := repo.Get(ctx, exercise.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	require.Equal(t, 1, stored.SessionCount)
+
+	sessions, err := repo.ListSessions(ctx, exercise.ID, 5)
+	require.NoError(t, err)
+	require.NotEmpty(t, sessions)
+	require.Equal(t, session.ActivityID, sessions[0].ActivityID)
+}
+
+func TestDgraphGRPCRepositoryTxnBatchesMultipleSessions(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	container, _, grpcAddr := testsupport.StartDgraphWithGRPC(ctx, t)
+	t.Cleanup(func() { _ = container.Terminate(context.Background()) })
+
+	repo, err := NewDgraphGRPCRepository(grpcAddr)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = repo.Close() })
+
+	exercise := domain.Exercise{
+		ID:          "tenant:strength-session",
+		Name:        "Strength Session",
+		Difficulty:  "beginner",
+		LastUpdated: time.Now().UTC(),
+	}
+
+	txn := repo.NewTxn()
+	for i := 0; i < 3; i++ {
+		session := domain.ActivitySession{
+			ID:          "session-" + string(rune('a'+i)),
+			ExerciseID:  exercise.ID,
+			ActivityID:  "activity-" + string(rune('a'+i)),
+			TenantID:    "tenant",
+			RecordedAt:  time.Now().UTC(),
+			DurationMin: 20,
+		}
+		require.NoError(t, txn.UpsertWithSession(ctx, exercise, session))
+	}
+	require.NoError(t, txn.Commit(ctx))
+
+	sessions, err := repo.ListSessions(ctx, exercise.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, sessions, 3)
+}
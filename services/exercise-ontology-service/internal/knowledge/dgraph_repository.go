@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"example.com/exerciseontology/internal/domain"
@@ -14,27 +16,210 @@ import (
 
 // DgraphRepository persists exercises via Dgraph's HTTP API.
 type DgraphRepository struct {
-	endpoint   string
-	httpClient *http.Client
+	endpoint      string
+	httpClient    *http.Client
+	acl           *aclManager
+	maxBatchBytes int
+
+	deadlineMu       sync.RWMutex
+	queryDeadline    time.Duration
+	mutationDeadline time.Duration
+}
+
+// Option configures optional DgraphRepository behaviour.
+type Option func(*DgraphRepository)
+
+// WithRoundTripper overrides the HTTP client's transport, e.g. to inject OAuth2 bearer tokens
+// via a token.Source's RoundTripper.
+func WithRoundTripper(rt http.RoundTripper) Option {
+	return func(r *DgraphRepository) {
+		r.httpClient.Transport = rt
+	}
+}
+
+// WithACL enables per-tenant ACL login. Every request is routed to the Dgraph namespace mapped
+// from the tenant ID on its context (see auth.FromContext), logging into that namespace on first
+// use and refreshing its access JWT in the background from then on.
+func WithACL(cfg DgraphAuth) Option {
+	return func(r *DgraphRepository) {
+		r.acl = newACLManager(r.endpoint, r.httpClient, cfg)
+	}
+}
+
+// WithMaxBatchBytes caps the serialized size of a single BulkUpsert mutation request; batches
+// larger than this are split into multiple requests so we stay under Dgraph's 32 MiB mutation
+// limit. Defaults to 8 MiB.
+func WithMaxBatchBytes(n int) Option {
+	return func(r *DgraphRepository) {
+		r.maxBatchBytes = n
+	}
 }
 
 // NewDgraphRepository constructs the repository.
-func NewDgraphRepository(endpoint string, timeout time.Duration) *DgraphRepository {
-	return &DgraphRepository{
+func NewDgraphRepository(endpoint string, timeout time.Duration, opts ...Option) *DgraphRepository {
+	r := &DgraphRepository{
 		endpoint: strings.TrimRight(endpoint, "/"),
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
+		maxBatchBytes: defaultMaxBatchBytes,
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
 }
 
-// Upsert creates or updates an exercise node by exercise_id.
+// Close stops the background ACL refresh loop, if ACL is enabled. Safe to call even when
+// WithACL was never used.
+func (r *DgraphRepository) Close() error {
+	if r.acl != nil {
+		r.acl.close()
+	}
+	return nil
+}
+
+// Ping reports whether Dgraph's /health endpoint is reachable and healthy. It bypasses ACL,
+// since /health is an unauthenticated admin endpoint.
+func (r *DgraphRepository) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.endpoint+"/health", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("dgraph health check failed: %s", resp.Status)
+	}
+	return nil
+}
+
+// applyACL sets req's ACL headers for ctx's tenant, if ACL is configured.
+func (r *DgraphRepository) applyACL(ctx context.Context, req *http.Request) error {
+	if r.acl == nil {
+		return nil
+	}
+	return r.acl.applyHeaders(ctx, req)
+}
+
+// SetQueryDeadline bounds how long a single Get/Search/ListSessions call may run, independent of
+// the coarser http.Client.Timeout shared by every request. Zero (the default) leaves queries
+// bounded only by the client timeout. Safe to call concurrently with in-flight requests.
+func (r *DgraphRepository) SetQueryDeadline(d time.Duration) {
+	r.deadlineMu.Lock()
+	defer r.deadlineMu.Unlock()
+	r.queryDeadline = d
+}
+
+// SetMutationDeadline bounds how long a single Upsert/UpsertWithSession/Delete call may run.
+// Zero (the default) leaves mutations bounded only by the client timeout. Safe to call
+// concurrently with in-flight requests.
+func (r *DgraphRepository) SetMutationDeadline(d time.Duration) {
+	r.deadlineMu.Lock()
+	defer r.deadlineMu.Unlock()
+	r.mutationDeadline = d
+}
+
+func (r *DgraphRepository) getQueryDeadline() time.Duration {
+	r.deadlineMu.RLock()
+	defer r.deadlineMu.RUnlock()
+	return r.queryDeadline
+}
+
+func (r *DgraphRepository) getMutationDeadline() time.Duration {
+	r.deadlineMu.RLock()
+	defer r.deadlineMu.RUnlock()
+	return r.mutationDeadline
+}
+
+// WithOperationDeadline returns ctx bounded by d counted from now, along with the cancel func
+// the caller must invoke to release it. A non-positive d returns ctx unchanged with a no-op
+// cancel. Deriving the request's context this way (rather than relying solely on
+// http.Client.Timeout) means http.NewRequestWithContext's request is aborted and its underlying
+// connection released as soon as the deadline fires, even if the timeout covers a larger,
+// shared budget.
+func WithOperationDeadline(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, time.Now().Add(d))
+}
+
+// Upsert creates or updates an exercise node by exercise_id. When exercise.ResourceVersion
+// is non-zero the mutation is guarded by `@if(eq(len(v), 1))`, where v matches the node only
+// if its rev predicate still equals what the caller read, so it applies only in that case;
+// otherwise nothing is written and Upsert returns domain.ErrConflict.
 func (r *DgraphRepository) Upsert(ctx context.Context, exercise domain.Exercise) error {
+	if err := validateID(exercise.ID); err != nil {
+		return err
+	}
+
+	node := buildExerciseMutation(exercise)
+	node["rev"] = exercise.ResourceVersion + 1
+
+	if exercise.ResourceVersion == 0 {
+		payload := map[string]interface{}{
+			"query":     `query q($id: string) { exercise as var(func: eq(exercise_id, $id)) }`,
+			"variables": map[string]string{"$id": exercise.ID},
+			"set":       []map[string]interface{}{node},
+		}
+		return r.doMutate(ctx, payload)
+	}
+
+	query := fmt.Sprintf(`query q($id: string) {
+	  exercise as var(func: eq(exercise_id, $id))
+	  v as var(func: uid(exercise)) @filter(eq(rev, %d))
+	}`, exercise.ResourceVersion)
+
 	payload := map[string]interface{}{
-		"query": fmt.Sprintf(`query { exercise as var(func: eq(exercise_id, "%s")) }`, exercise.ID),
-		"set":   []map[string]interface{}{buildExerciseMutation(exercise)},
+		"query":     query,
+		"variables": map[string]string{"$id": exercise.ID},
+		"mutations": []map[string]interface{}{
+			{
+				"set":  []map[string]interface{}{node},
+				"cond": "@if(eq(len(v), 1))",
+			},
+		},
+	}
+	if err := r.doMutate(ctx, payload); err != nil {
+		return err
 	}
 
+	// Dgraph silently no-ops a failed @if condition rather than erroring, so confirm the
+	// write actually landed by re-reading the rev we just attempted to set.
+	current, err := r.Get(ctx, exercise.ID)
+	if err != nil {
+		return err
+	}
+	if current == nil || current.ResourceVersion != exercise.ResourceVersion+1 {
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+// UpsertBatch delegates to BulkUpsert with no sessions, folding any row failures into a single
+// error - domain.Repository.UpsertBatch reports one outcome for the whole call, while BulkUpsert
+// already gives per-row detail to callers (like a migration tool) that want it directly.
+func (r *DgraphRepository) UpsertBatch(ctx context.Context, exercises []domain.Exercise) error {
+	result, err := r.BulkUpsert(ctx, exercises, nil)
+	if err != nil {
+		return err
+	}
+	if len(result.Failed) > 0 {
+		return result.Failed[0]
+	}
+	return nil
+}
+
+func (r *DgraphRepository) doMutate(ctx context.Context, payload map[string]interface{}) error {
+	ctx, cancel := WithOperationDeadline(ctx, r.getMutationDeadline())
+	defer cancel()
+
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return err
@@ -45,6 +230,9 @@ func (r *DgraphRepository) Upsert(ctx context.Context, exercise domain.Exercise)
 		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	if err := r.applyACL(ctx, req); err != nil {
+		return err
+	}
 
 	resp, err := r.httpClient.Do(req)
 	if err != nil {
@@ -53,7 +241,7 @@ func (r *DgraphRepository) Upsert(ctx context.Context, exercise domain.Exercise)
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 300 {
-		return fmt.Errorf("dgraph upsert failed: %s", resp.Status)
+		return fmt.Errorf("dgraph mutate failed: %s", resp.Status)
 	}
 	return nil
 }
@@ -103,39 +291,72 @@ func buildSessionMutation(session domain.ActivitySession) map[string]interface{}
 	return node
 }
 
-// UpsertWithSession creates or updates the exercise and records an activity session edge.
+// UpsertWithSession creates or updates the exercise and records an activity session edge. Like
+// Upsert, a non-zero exercise.ResourceVersion guards the exercise write with
+// `@if(eq(len(v), 1))` over a rev-filtered v, so a caller re-attaching a session to a
+// snapshot that's since been superseded gets domain.ErrConflict instead of silently clobbering
+// whoever wrote the newer version - the session edge itself is part of the same guarded
+// mutation, so it never lands without the exercise write it's meant to accompany.
 func (r *DgraphRepository) UpsertWithSession(ctx context.Context, exercise domain.Exercise, session domain.ActivitySession) error {
-	query := fmt.Sprintf(`query {
-	  exercise as var(func: eq(exercise_id, "%s"))
-	  session as var(func: eq(session_id, "%s"))
-	}`, exercise.ID, session.ID)
+	if err := validateID(exercise.ID); err != nil {
+		return err
+	}
+	if err := validateID(session.ID); err != nil {
+		return err
+	}
 
-	set := []map[string]interface{}{buildExerciseMutation(exercise), buildSessionMutation(session)}
+	ctx, cancel := WithOperationDeadline(ctx, r.getMutationDeadline())
+	defer cancel()
 
-	payload := map[string]interface{}{
-		"query": query,
-		"set":   set,
+	exerciseNode := buildExerciseMutation(exercise)
+	exerciseNode["rev"] = exercise.ResourceVersion + 1
+	set := []map[string]interface{}{exerciseNode, buildSessionMutation(session)}
+
+	variables := map[string]string{
+		"$exerciseID": exercise.ID,
+		"$sessionID":  session.ID,
 	}
 
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return err
+	if exercise.ResourceVersion == 0 {
+		payload := map[string]interface{}{
+			"query": `query q($exerciseID: string, $sessionID: string) {
+	  exercise as var(func: eq(exercise_id, $exerciseID))
+	  session as var(func: eq(session_id, $sessionID))
+	}`,
+			"variables": variables,
+			"set":       set,
+		}
+		return r.doMutate(ctx, payload)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint+"/mutate?commitNow=true", bytes.NewReader(body))
-	if err != nil {
+	query := fmt.Sprintf(`query q($exerciseID: string, $sessionID: string) {
+	  exercise as var(func: eq(exercise_id, $exerciseID))
+	  session as var(func: eq(session_id, $sessionID))
+	  v as var(func: uid(exercise)) @filter(eq(rev, %d))
+	}`, exercise.ResourceVersion)
+
+	payload := map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+		"mutations": []map[string]interface{}{
+			{
+				"set":  set,
+				"cond": "@if(eq(len(v), 1))",
+			},
+		},
+	}
+	if err := r.doMutate(ctx, payload); err != nil {
 		return err
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := r.httpClient.Do(req)
+	// Dgraph silently no-ops a failed @if condition rather than erroring, so confirm the
+	// write actually landed by re-reading the rev we just attempted to set.
+	current, err := r.Get(ctx, exercise.ID)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 300 {
-		return fmt.Errorf("dgraph upsert with session failed: %s", resp.Status)
+	if current == nil || current.ResourceVersion != exercise.ResourceVersion+1 {
+		return domain.ErrConflict
 	}
 	return nil
 }
@@ -154,6 +375,7 @@ func (r *DgraphRepository) Get(ctx context.Context, id string) (*domain.Exercise
     last_updated
     last_seen_at
     session_count
+    rev
   }
 }`
 	variables := map[string]string{"$id": id}
@@ -193,6 +415,7 @@ func (r *DgraphRepository) Search(ctx context.Context, queryTerm string, limit i
     last_updated
     last_seen_at
     session_count
+    rev
   }
 }`, limit)
 	variables := map[string]string{"$term": queryTerm}
@@ -209,11 +432,91 @@ func (r *DgraphRepository) Search(ctx context.Context, queryTerm string, limit i
 	return exercises, nil
 }
 
+// ListAll returns every exercise node, bounded by limit (<=0 means unbounded).
+func (r *DgraphRepository) ListAll(ctx context.Context, limit int) ([]domain.Exercise, error) {
+	first := limit
+	if first <= 0 {
+		first = -1
+	}
+	query := fmt.Sprintf(`query exercises {
+  exercises(func: type(Exercise), first: %d) {
+    exercise_id
+    name
+    difficulty
+    targets
+    requires
+    contraindicated_with
+    complementary_to
+    last_updated
+    last_seen_at
+    session_count
+    rev
+  }
+}`, first)
+
+	result, err := r.executeQuery(ctx, query, map[string]string{})
+	if err != nil {
+		return nil, err
+	}
+
+	exercises := make([]domain.Exercise, 0, len(result.Exercises))
+	for _, item := range result.Exercises {
+		exercises = append(exercises, item.toDomain())
+	}
+	return exercises, nil
+}
+
+// ListPage returns up to limit exercises ordered by exercise_id, starting after cursor
+// (exclusive). An empty cursor starts from the beginning; nextCursor is empty once there are no
+// further pages. Unlike ListAll, this never asks Dgraph to materialize more than one page at a
+// time, so Service.ExportExercises can stream an arbitrarily large ontology.
+func (r *DgraphRepository) ListPage(ctx context.Context, cursor string, limit int) ([]domain.Exercise, string, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := fmt.Sprintf(`query exercises($cursor: string) {
+  exercises(func: type(Exercise), orderasc: exercise_id, first: %d) @filter(gt(exercise_id, $cursor)) {
+    exercise_id
+    name
+    difficulty
+    targets
+    requires
+    contraindicated_with
+    complementary_to
+    last_updated
+    last_seen_at
+    session_count
+    rev
+  }
+}`, limit)
+
+	result, err := r.executeQuery(ctx, query, map[string]string{"$cursor": cursor})
+	if err != nil {
+		return nil, "", err
+	}
+
+	exercises := make([]domain.Exercise, 0, len(result.Exercises))
+	for _, item := range result.Exercises {
+		exercises = append(exercises, item.toDomain())
+	}
+
+	var nextCursor string
+	if len(exercises) == limit {
+		nextCursor = exercises[len(exercises)-1].ID
+	}
+	return exercises, nextCursor, nil
+}
+
 // ListSessions returns sessions linked to the exercise ordered by recorded time.
 func (r *DgraphRepository) ListSessions(ctx context.Context, exerciseID string, limit int) ([]domain.ActivitySession, error) {
 	if limit <= 0 {
 		limit = 10
 	}
+
+	ctx, cancel := WithOperationDeadline(ctx, r.getQueryDeadline())
+	defer cancel()
+
 	query := fmt.Sprintf(`query sessions($id: string) {
 	  exercises(func: eq(exercise_id, $id)) {
 	    sessions: ~exercise(orderdesc: recorded_at, first: %d) {
@@ -247,6 +550,9 @@ func (r *DgraphRepository) ListSessions(ctx context.Context, exerciseID string,
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	if err := r.applyACL(ctx, req); err != nil {
+		return nil, err
+	}
 
 	resp, err := r.httpClient.Do(req)
 	if err != nil {
@@ -280,13 +586,21 @@ func (r *DgraphRepository) ListSessions(ctx context.Context, exerciseID string,
 
 // Delete removes the exercise and any associated sessions.
 func (r *DgraphRepository) Delete(ctx context.Context, id string) error {
-	query := fmt.Sprintf(`query {
-	  exercise as var(func: eq(exercise_id, "%s"))
-	  sessions as var(func: eq(exercise_id, "%s")) @filter(type(ActivitySession))
-	}`, id, id)
+	if err := validateID(id); err != nil {
+		return err
+	}
+
+	ctx, cancel := WithOperationDeadline(ctx, r.getMutationDeadline())
+	defer cancel()
+
+	query := `query q($id: string) {
+	  exercise as var(func: eq(exercise_id, $id))
+	  sessions as var(func: eq(exercise_id, $id)) @filter(type(ActivitySession))
+	}`
 
 	payload := map[string]interface{}{
-		"query": query,
+		"query":     query,
+		"variables": map[string]string{"$id": id},
 		"delete": []map[string]interface{}{
 			{"uid": "uid(sessions)"},
 			{"uid": "uid(exercise)"},
@@ -303,6 +617,9 @@ func (r *DgraphRepository) Delete(ctx context.Context, id string) error {
 		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	if err := r.applyACL(ctx, req); err != nil {
+		return err
+	}
 
 	resp, err := r.httpClient.Do(req)
 	if err != nil {
@@ -316,6 +633,47 @@ func (r *DgraphRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// RepairSymmetry treats id's own stored ComplementaryTo/Contraindications as authoritative and
+// pushes any missing back-link onto each referenced neighbor via a plain Upsert, converging an
+// asymmetric graph left behind by an interrupted UpdateRelationships write.
+func (r *DgraphRepository) RepairSymmetry(ctx context.Context, id string) error {
+	exercise, err := r.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if exercise == nil {
+		return domain.ErrExerciseNotFound
+	}
+
+	for _, ref := range exercise.ComplementaryTo {
+		neighbor, err := r.Get(ctx, ref)
+		if err != nil {
+			return err
+		}
+		if neighbor == nil || slices.Contains(neighbor.ComplementaryTo, id) {
+			continue
+		}
+		neighbor.ComplementaryTo = append(append([]string{}, neighbor.ComplementaryTo...), id)
+		if err := r.Upsert(ctx, *neighbor); err != nil {
+			return err
+		}
+	}
+	for _, ref := range exercise.Contraindications {
+		neighbor, err := r.Get(ctx, ref)
+		if err != nil {
+			return err
+		}
+		if neighbor == nil || slices.Contains(neighbor.Contraindications, id) {
+			continue
+		}
+		neighbor.Contraindications = append(append([]string{}, neighbor.Contraindications...), id)
+		if err := r.Upsert(ctx, *neighbor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type queryResponse struct {
 	Exercises []exerciseNode `json:"exercises"`
 }
@@ -331,6 +689,7 @@ type exerciseNode struct {
 	LastUpdatedISO8601 string   `json:"last_updated"`
 	LastSeenISO8601    string   `json:"last_seen_at"`
 	SessionCount       int      `json:"session_count"`
+	Rev                uint64   `json:"rev"`
 }
 
 func (node exerciseNode) toDomain() domain.Exercise {
@@ -357,6 +716,7 @@ func (node exerciseNode) toDomain() domain.Exercise {
 		LastUpdated:       lastUpdated,
 		SessionCount:      node.SessionCount,
 		LastSeenAt:        lastSeen,
+		ResourceVersion:   node.Rev,
 	}
 }
 
@@ -400,6 +760,9 @@ func (node sessionNode) toDomain(exerciseID string) domain.ActivitySession {
 }
 
 func (r *DgraphRepository) executeQuery(ctx context.Context, query string, variables map[string]string) (queryResponse, error) {
+	ctx, cancel := WithOperationDeadline(ctx, r.getQueryDeadline())
+	defer cancel()
+
 	body := map[string]interface{}{
 		"query":     query,
 		"variables": variables,
@@ -414,6 +777,9 @@ func (r *DgraphRepository) executeQuery(ctx context.Context, query string, varia
 		return queryResponse{}, err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	if err := r.applyACL(ctx, req); err != nil {
+		return queryResponse{}, err
+	}
 
 	resp, err := r.httpClient.Do(req)
 	if err != nil {
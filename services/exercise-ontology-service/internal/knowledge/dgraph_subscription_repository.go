@@ -0,0 +1,217 @@
+package knowledge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"example.com/exerciseontology/internal/subscription"
+)
+
+// DgraphSubscriptionRepository persists subscriptions via Dgraph's HTTP API, alongside
+// DgraphRepository's exercise nodes.
+type DgraphSubscriptionRepository struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+// SubscriptionOption configures optional DgraphSubscriptionRepository behaviour.
+type SubscriptionOption func(*DgraphSubscriptionRepository)
+
+// WithSubscriptionRoundTripper overrides the HTTP client's transport, e.g. to inject OAuth2
+// bearer tokens via a token.Source's RoundTripper.
+func WithSubscriptionRoundTripper(rt http.RoundTripper) SubscriptionOption {
+	return func(r *DgraphSubscriptionRepository) {
+		r.httpClient.Transport = rt
+	}
+}
+
+// NewDgraphSubscriptionRepository constructs the repository.
+func NewDgraphSubscriptionRepository(endpoint string, timeout time.Duration, opts ...SubscriptionOption) *DgraphSubscriptionRepository {
+	r := &DgraphSubscriptionRepository{
+		endpoint:   strings.TrimRight(endpoint, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+const subscriptionFields = `
+    subscription_id
+    info_type_id
+    event_type_glob
+    tenant_id
+    activity_type
+    source
+    delivery_topic
+    delivery_webhook_url
+    status_notification_uri
+    created_at
+`
+
+// Create implements subscription.Repository.
+func (r *DgraphSubscriptionRepository) Create(ctx context.Context, sub subscription.Subscription) error {
+	node := map[string]interface{}{
+		"uid":                     "_:sub",
+		"dgraph.type":             []string{"Subscription"},
+		"subscription_id":         sub.ID,
+		"info_type_id":            sub.InfoTypeID,
+		"event_type_glob":         sub.EventTypeGlob,
+		"tenant_id":               sub.TenantID,
+		"activity_type":           sub.ActivityType,
+		"source":                  sub.Source,
+		"delivery_topic":          sub.Delivery.Topic,
+		"delivery_webhook_url":    sub.Delivery.WebhookURL,
+		"status_notification_uri": sub.StatusNotificationURI,
+		"created_at":              sub.CreatedAt.Format(time.RFC3339Nano),
+	}
+	return r.doMutate(ctx, map[string]interface{}{"set": []map[string]interface{}{node}})
+}
+
+// List implements subscription.Repository.
+func (r *DgraphSubscriptionRepository) List(ctx context.Context) ([]subscription.Subscription, error) {
+	query := fmt.Sprintf(`query { subscriptions(func: type(Subscription)) { %s } }`, subscriptionFields)
+	result, err := r.executeQuery(ctx, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	subs := make([]subscription.Subscription, 0, len(result.Subscriptions))
+	for _, node := range result.Subscriptions {
+		subs = append(subs, node.toDomain())
+	}
+	return subs, nil
+}
+
+// Get implements subscription.Repository.
+func (r *DgraphSubscriptionRepository) Get(ctx context.Context, id string) (*subscription.Subscription, error) {
+	query := fmt.Sprintf(`query subscription($id: string) { subscriptions(func: eq(subscription_id, $id)) { %s } }`, subscriptionFields)
+	result, err := r.executeQuery(ctx, query, map[string]string{"$id": id})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Subscriptions) == 0 {
+		return nil, nil
+	}
+	sub := result.Subscriptions[0].toDomain()
+	return &sub, nil
+}
+
+// Delete implements subscription.Repository.
+func (r *DgraphSubscriptionRepository) Delete(ctx context.Context, id string) error {
+	if err := validateID(id); err != nil {
+		return err
+	}
+
+	query := `query q($id: string) { sub as var(func: eq(subscription_id, $id)) }`
+	payload := map[string]interface{}{
+		"query":     query,
+		"variables": map[string]string{"$id": id},
+		"delete":    []map[string]interface{}{{"uid": "uid(sub)"}},
+	}
+	return r.doMutate(ctx, payload)
+}
+
+func (r *DgraphSubscriptionRepository) doMutate(ctx context.Context, payload map[string]interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint+"/mutate?commitNow=true", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("dgraph mutate failed: %s", resp.Status)
+	}
+	return nil
+}
+
+type subscriptionQueryResponse struct {
+	Subscriptions []subscriptionNode `json:"subscriptions"`
+}
+
+type subscriptionNode struct {
+	SubscriptionID        string `json:"subscription_id"`
+	InfoTypeID            string `json:"info_type_id"`
+	EventTypeGlob         string `json:"event_type_glob"`
+	TenantID              string `json:"tenant_id"`
+	ActivityType          string `json:"activity_type"`
+	Source                string `json:"source"`
+	DeliveryTopic         string `json:"delivery_topic"`
+	DeliveryWebhookURL    string `json:"delivery_webhook_url"`
+	StatusNotificationURI string `json:"status_notification_uri"`
+	CreatedAtISO8601      string `json:"created_at"`
+}
+
+func (node subscriptionNode) toDomain() subscription.Subscription {
+	var createdAt time.Time
+	if node.CreatedAtISO8601 != "" {
+		if parsed, err := time.Parse(time.RFC3339Nano, node.CreatedAtISO8601); err == nil {
+			createdAt = parsed
+		}
+	}
+	return subscription.Subscription{
+		ID:            node.SubscriptionID,
+		InfoTypeID:    node.InfoTypeID,
+		EventTypeGlob: node.EventTypeGlob,
+		TenantID:      node.TenantID,
+		ActivityType:  node.ActivityType,
+		Source:        node.Source,
+		Delivery: subscription.Delivery{
+			Topic:      node.DeliveryTopic,
+			WebhookURL: node.DeliveryWebhookURL,
+		},
+		StatusNotificationURI: node.StatusNotificationURI,
+		CreatedAt:             createdAt,
+	}
+}
+
+func (r *DgraphSubscriptionRepository) executeQuery(ctx context.Context, query string, variables map[string]string) (subscriptionQueryResponse, error) {
+	body := map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return subscriptionQueryResponse{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint+"/query", bytes.NewReader(payload))
+	if err != nil {
+		return subscriptionQueryResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return subscriptionQueryResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return subscriptionQueryResponse{}, fmt.Errorf("dgraph query failed: %s", resp.Status)
+	}
+
+	var wrapper struct {
+		Data subscriptionQueryResponse `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&wrapper); err != nil {
+		return subscriptionQueryResponse{}, err
+	}
+	return wrapper.Data, nil
+}
@@ -0,0 +1,33 @@
+package knowledge
+
+import (
+	"fmt"
+	"strings"
+)
+
+// validateID rejects IDs that have no legitimate place in an opaque entity identifier: empty
+// strings, double quotes, backslashes, and control characters (including newlines). These are
+// exactly the characters that would let a value break out of a DQL string literal if it were
+// ever interpolated directly into a query instead of passed as a query variable, so rejecting
+// them up front catches both malformed input and injection attempts before any query is built.
+func validateID(id string) error {
+	if strings.TrimSpace(id) == "" {
+		return fmt.Errorf("id must not be empty")
+	}
+	for _, r := range id {
+		if r == '"' || r == '\\' || r < 0x20 {
+			return fmt.Errorf("id contains disallowed character %q", r)
+		}
+	}
+	return nil
+}
+
+// quoteDQL escapes s for safe embedding as a double-quoted DQL string literal, for the rare
+// case a caller must build DQL query text directly rather than via a query variable (which
+// Dgraph substitutes safely on its own and should be preferred wherever possible).
+func quoteDQL(s string) string {
+	escaped := strings.ReplaceAll(s, `\`, `\\`)
+	escaped = strings.ReplaceAll(escaped, `"`, `\"`)
+	escaped = strings.ReplaceAll(escaped, "\n", `\n`)
+	return `"` + escaped + `"`
+}
@@ -0,0 +1,116 @@
+package knowledge
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"example.com/exerciseontology/internal/domain"
+)
+
+func TestValidateIDRejectsDangerousInput(t *testing.T) {
+	cases := []struct {
+		name    string
+		id      string
+		wantErr bool
+	}{
+		{name: "plain id", id: "tenant:tempo-ride", wantErr: false},
+		{name: "empty", id: "", wantErr: true},
+		{name: "whitespace only", id: "   ", wantErr: true},
+		{name: "double quote", id: `tenant:"injected"`, wantErr: true},
+		{name: "backslash", id: `tenant:\injected`, wantErr: true},
+		{name: "newline", id: "tenant:injected\nexercise as var(func: has(exercise_id))", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateID(tc.id)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestQuoteDQLEscapesSpecialCharacters(t *testing.T) {
+	require.Equal(t, `"plain"`, quoteDQL("plain"))
+	require.Equal(t, `"a\"b"`, quoteDQL(`a"b`))
+	require.Equal(t, `"a\\b"`, quoteDQL(`a\b`))
+	require.Equal(t, `"a\nb"`, quoteDQL("a\nb"))
+}
+
+// dangerousIDs are fed into every mutation path below; none of them should ever reach the
+// Dgraph HTTP call, since validateID must reject them up front.
+var dangerousIDs = []string{
+	`tenant:"breakout"`,
+	`tenant:\breakout`,
+	"tenant:breakout\nexercise as var(func: has(exercise_id))",
+}
+
+func TestDgraphRepositoryMutationsRejectDangerousIDs(t *testing.T) {
+	for _, id := range dangerousIDs {
+		t.Run(id, func(t *testing.T) {
+			called := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			repo := NewDgraphRepository(server.URL, time.Second)
+
+			err := repo.Upsert(context.Background(), domain.Exercise{ID: id})
+			require.Error(t, err)
+			require.False(t, called, "Upsert must not call Dgraph with an unvalidated id")
+
+			err = repo.UpsertWithSession(context.Background(), domain.Exercise{ID: "tenant:valid"}, domain.ActivitySession{ID: id, ExerciseID: "tenant:valid"})
+			require.Error(t, err)
+			require.False(t, called, "UpsertWithSession must not call Dgraph with an unvalidated session id")
+
+			err = repo.Delete(context.Background(), id)
+			require.Error(t, err)
+			require.False(t, called, "Delete must not call Dgraph with an unvalidated id")
+		})
+	}
+}
+
+func TestDgraphSubscriptionRepositoryDeleteRejectsDangerousIDs(t *testing.T) {
+	for _, id := range dangerousIDs {
+		t.Run(id, func(t *testing.T) {
+			called := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			repo := NewDgraphSubscriptionRepository(server.URL, time.Second)
+
+			err := repo.Delete(context.Background(), id)
+			require.Error(t, err)
+			require.False(t, called, "Delete must not call Dgraph with an unvalidated id")
+		})
+	}
+}
+
+func TestDgraphGRPCRepositoryMutationsRejectDangerousIDs(t *testing.T) {
+	// These conns point at an address nothing listens on; validateID must reject the id
+	// before any gRPC call is attempted, so no dial ever happens.
+	repo, err := NewDgraphGRPCRepository("127.0.0.1:0")
+	require.NoError(t, err)
+	defer repo.Close()
+
+	for _, id := range dangerousIDs {
+		t.Run(id, func(t *testing.T) {
+			require.Error(t, repo.Upsert(context.Background(), domain.Exercise{ID: id}))
+			require.Error(t, repo.UpsertWithSession(context.Background(), domain.Exercise{ID: "tenant:valid"}, domain.ActivitySession{ID: id, ExerciseID: "tenant:valid"}))
+			require.Error(t, repo.Delete(context.Background(), id))
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package knowledge
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"example.com/exerciseontology/internal/domain"
+)
+
+func TestDgraphRepositoryPingSucceedsWhenHealthy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/health", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	repo := NewDgraphRepository(server.URL, 10*time.Second)
+	require.NoError(t, repo.Ping(context.Background()))
+}
+
+func TestDgraphRepositoryPingFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	repo := NewDgraphRepository(server.URL, 10*time.Second)
+	require.Error(t, repo.Ping(context.Background()))
+}
+
+func TestInMemoryRepositoryLenReflectsStoredExercises(t *testing.T) {
+	repo := NewInMemoryRepository()
+	before := repo.Len()
+	require.NoError(t, repo.Upsert(context.Background(), domain.Exercise{ID: "additional-exercise", Name: "Additional Exercise"}))
+	require.Equal(t, before+1, repo.Len())
+}
@@ -0,0 +1,121 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"example.com/exerciseontology/internal/domain"
+)
+
+// Stage identifies a point in a repository's lifecycle at which hooks run.
+type Stage string
+
+const (
+	// StageStartup runs once a repository is constructed and ready to serve traffic.
+	StageStartup Stage = "startup"
+	// StagePostMigrate runs once any schema/data migration the backend requires is believed
+	// current - for backends with no separate migration step (e.g. InMemoryRepository) this
+	// fires immediately alongside StageStartup.
+	StagePostMigrate Stage = "post-migrate"
+	// StagePreShutdown runs while a repository is still reachable but about to be torn down.
+	StagePreShutdown Stage = "pre-shutdown"
+)
+
+// Hook inspects or mutates a single stored Exercise at Stage. Apply returns the exercise RunHooks
+// should persist; returning it unchanged is a no-op (RunHooks only calls Upsert when the result
+// differs from what was passed in).
+type Hook interface {
+	Stage() Stage
+	Apply(ctx context.Context, exercise domain.Exercise) (domain.Exercise, error)
+}
+
+// hookRegistration pairs a Hook with the exercise kind it applies to.
+type hookRegistration struct {
+	kind string
+	hook Hook
+}
+
+var (
+	hookRegistryMu sync.Mutex
+	hookRegistry   []hookRegistration
+)
+
+// AllKinds matches every exercise regardless of its Targets, when passed to RegisterHook.
+const AllKinds = "*"
+
+// RegisterHook associates hook with kind, one of an exercise's Targets entries (e.g. "cardio",
+// "flexibility"), or AllKinds to match every exercise. RunHooks calls hook for each stored
+// exercise whose Targets contains kind (or unconditionally, for AllKinds) and whose Stage
+// matches. Intended to be called from init() functions, the same way repositories are wired in
+// cmd/*/main.go, so registration happens once at process startup before any repository is
+// constructed.
+func RegisterHook(kind string, hook Hook) {
+	hookRegistryMu.Lock()
+	defer hookRegistryMu.Unlock()
+	hookRegistry = append(hookRegistry, hookRegistration{kind: kind, hook: hook})
+}
+
+// RunHooks runs every hook registered for stage against each exercise currently stored in repo,
+// persisting the result back via Upsert whenever a hook returns a changed exercise. Hooks run in
+// registration order, each one applied to the previous matching hook's output, and an exercise
+// is written once after all matching hooks have run. RunHooks stops at and returns the first
+// Apply or Upsert error (including Upsert's ErrConflict) without retrying it.
+func RunHooks(ctx context.Context, repo domain.Repository, stage Stage) error {
+	hookRegistryMu.Lock()
+	registrations := make([]hookRegistration, len(hookRegistry))
+	copy(registrations, hookRegistry)
+	hookRegistryMu.Unlock()
+
+	var matching []hookRegistration
+	for _, reg := range registrations {
+		if reg.hook.Stage() == stage {
+			matching = append(matching, reg)
+		}
+	}
+	if len(matching) == 0 {
+		return nil
+	}
+
+	exercises, err := repo.ListAll(ctx, 0)
+	if err != nil {
+		return fmt.Errorf("list exercises for %s hooks: %w", stage, err)
+	}
+
+	for _, exercise := range exercises {
+		updated := exercise
+		changed := false
+		for _, reg := range matching {
+			if reg.kind != AllKinds && !slices.Contains(updated.Targets, reg.kind) {
+				continue
+			}
+			next, err := reg.hook.Apply(ctx, updated)
+			if err != nil {
+				return fmt.Errorf("apply %s hook to exercise %s: %w", stage, exercise.ID, err)
+			}
+			if !exercisesEqual(updated, next) {
+				updated = next
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+		if err := repo.Upsert(ctx, updated); err != nil {
+			return fmt.Errorf("persist %s hook result for exercise %s: %w", stage, exercise.ID, err)
+		}
+	}
+	return nil
+}
+
+// exercisesEqual compares the fields hooks are expected to mutate. LastUpdated and
+// ResourceVersion are excluded since Upsert manages both itself.
+func exercisesEqual(a, b domain.Exercise) bool {
+	return a.Name == b.Name &&
+		a.Difficulty == b.Difficulty &&
+		slices.Equal(a.Targets, b.Targets) &&
+		slices.Equal(a.Requires, b.Requires) &&
+		slices.Equal(a.Contraindications, b.Contraindications) &&
+		slices.Equal(a.ComplementaryTo, b.ComplementaryTo)
+}
@@ -0,0 +1,87 @@
+package knowledge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"example.com/exerciseontology/internal/domain"
+)
+
+type fixedHook struct {
+	stage Stage
+	apply func(exercise domain.Exercise) domain.Exercise
+}
+
+func (h fixedHook) Stage() Stage { return h.stage }
+
+func (h fixedHook) Apply(ctx context.Context, exercise domain.Exercise) (domain.Exercise, error) {
+	return h.apply(exercise), nil
+}
+
+func TestRunHooksPersistsMutationsForMatchingKind(t *testing.T) {
+	resetHookRegistry(t)
+
+	repo := &InMemoryRepository{exercises: make(map[string]domain.Exercise), sessions: make(map[string][]domain.ActivitySession)}
+	require.NoError(t, repo.Upsert(context.Background(), domain.Exercise{
+		ID:      "ex-1",
+		Name:    "Tempo Ride",
+		Targets: []string{"cardio"},
+	}))
+	require.NoError(t, repo.Upsert(context.Background(), domain.Exercise{
+		ID:      "ex-2",
+		Name:    "Yoga Flow",
+		Targets: []string{"flexibility"},
+	}))
+
+	RegisterHook("cardio", fixedHook{
+		stage: StageStartup,
+		apply: func(exercise domain.Exercise) domain.Exercise {
+			exercise.Difficulty = "backfilled"
+			return exercise
+		},
+	})
+
+	require.NoError(t, RunHooks(context.Background(), repo, StageStartup))
+
+	cardio, err := repo.Get(context.Background(), "ex-1")
+	require.NoError(t, err)
+	require.Equal(t, "backfilled", cardio.Difficulty)
+
+	flexibility, err := repo.Get(context.Background(), "ex-2")
+	require.NoError(t, err)
+	require.Empty(t, flexibility.Difficulty)
+}
+
+func TestRunHooksSkipsUpsertWhenUnchanged(t *testing.T) {
+	resetHookRegistry(t)
+
+	repo := &InMemoryRepository{exercises: make(map[string]domain.Exercise), sessions: make(map[string][]domain.ActivitySession)}
+	require.NoError(t, repo.Upsert(context.Background(), domain.Exercise{ID: "ex-1", Name: "Bodyweight Squat"}))
+
+	RegisterHook(AllKinds, fixedHook{
+		stage: StageStartup,
+		apply: func(exercise domain.Exercise) domain.Exercise { return exercise },
+	})
+
+	require.NoError(t, RunHooks(context.Background(), repo, StageStartup))
+
+	stored, err := repo.Get(context.Background(), "ex-1")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), stored.ResourceVersion, "Upsert should not have run again for an unchanged exercise")
+}
+
+func resetHookRegistry(t *testing.T) {
+	t.Helper()
+	hookRegistryMu.Lock()
+	previous := hookRegistry
+	hookRegistry = nil
+	hookRegistryMu.Unlock()
+
+	t.Cleanup(func() {
+		hookRegistryMu.Lock()
+		hookRegistry = previous
+		hookRegistryMu.Unlock()
+	})
+}
@@ -2,6 +2,8 @@ package knowledge
 
 import (
 	"context"
+	"log"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -18,16 +20,33 @@ type InMemoryRepository struct {
 	sessions  map[string][]domain.ActivitySession
 }
 
-// NewInMemoryRepository constructs repository populated with a seed ontology.
+// NewInMemoryRepository constructs repository populated with a seed ontology, then runs any
+// registered StageStartup and StagePostMigrate hooks against it - this repository has no separate
+// migration step, so both fire immediately. Tests and tenants wanting different fixture data
+// should register a hook via RegisterHook rather than forking seed().
 func NewInMemoryRepository() *InMemoryRepository {
 	repo := &InMemoryRepository{
 		exercises: make(map[string]domain.Exercise),
 		sessions:  make(map[string][]domain.ActivitySession),
 	}
 	repo.seed()
+	ctx := context.Background()
+	if err := RunHooks(ctx, repo, StageStartup); err != nil {
+		log.Printf("knowledge: startup hooks failed: %v", err)
+	}
+	if err := RunHooks(ctx, repo, StagePostMigrate); err != nil {
+		log.Printf("knowledge: post-migrate hooks failed: %v", err)
+	}
 	return repo
 }
 
+// Len reports how many exercises are currently stored.
+func (r *InMemoryRepository) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.exercises)
+}
+
 func (r *InMemoryRepository) seed() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -44,7 +63,8 @@ func (r *InMemoryRepository) seed() {
 	}
 }
 
-// Upsert implements domain.Repository.
+// Upsert implements domain.Repository. When exercise.ResourceVersion is non-zero, the write
+// is rejected with domain.ErrConflict unless it still matches the stored node's version.
 func (r *InMemoryRepository) Upsert(ctx context.Context, exercise domain.Exercise) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -59,11 +79,54 @@ func (r *InMemoryRepository) Upsert(ctx context.Context, exercise domain.Exercis
 		exercise.LastSeenAt = exercise.LastUpdated
 	}
 
+	if exercise.ResourceVersion != 0 {
+		existing, ok := r.exercises[exercise.ID]
+		if !ok || existing.ResourceVersion != exercise.ResourceVersion {
+			return domain.ErrConflict
+		}
+	}
+	exercise.ResourceVersion++
+
 	r.exercises[exercise.ID] = exercise
 	return nil
 }
 
-// UpsertWithSession records the exercise and appends a session entry.
+// UpsertBatch applies every exercise under a single lock, so the whole batch is visible to
+// readers atomically - the same all-or-nothing contract the Dgraph/etcd backends give a single
+// transaction or mutation.
+func (r *InMemoryRepository) UpsertBatch(ctx context.Context, exercises []domain.Exercise) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Check every version guard before writing anything, so a conflict leaves the batch unwritten.
+	for _, exercise := range exercises {
+		if exercise.ResourceVersion == 0 {
+			continue
+		}
+		existing, ok := r.exercises[exercise.ID]
+		if !ok || existing.ResourceVersion != exercise.ResourceVersion {
+			return domain.ErrConflict
+		}
+	}
+	for _, exercise := range exercises {
+		if strings.TrimSpace(exercise.ID) == "" {
+			exercise.ID = uuid.NewString()
+		}
+		if exercise.LastUpdated.IsZero() {
+			exercise.LastUpdated = time.Now().UTC()
+		}
+		if exercise.LastSeenAt.IsZero() {
+			exercise.LastSeenAt = exercise.LastUpdated
+		}
+		exercise.ResourceVersion++
+		r.exercises[exercise.ID] = exercise
+	}
+	return nil
+}
+
+// UpsertWithSession records the exercise and appends a session entry. Like Upsert, a non-zero
+// exercise.ResourceVersion is a CAS guard: the write is rejected with domain.ErrConflict unless
+// it still matches the stored node's version.
 func (r *InMemoryRepository) UpsertWithSession(ctx context.Context, exercise domain.Exercise, session domain.ActivitySession) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -71,6 +134,13 @@ func (r *InMemoryRepository) UpsertWithSession(ctx context.Context, exercise dom
 	if strings.TrimSpace(exercise.ID) == "" {
 		exercise.ID = uuid.NewString()
 	}
+	if exercise.ResourceVersion != 0 {
+		existing, ok := r.exercises[exercise.ID]
+		if !ok || existing.ResourceVersion != exercise.ResourceVersion {
+			return domain.ErrConflict
+		}
+	}
+	exercise.ResourceVersion++
 	if strings.TrimSpace(session.ID) == "" {
 		session.ID = uuid.NewString()
 	}
@@ -129,6 +199,52 @@ func (r *InMemoryRepository) Search(ctx context.Context, query string, limit int
 	return results, nil
 }
 
+// ListAll returns every stored exercise, bounded by limit (<=0 means unbounded).
+func (r *InMemoryRepository) ListAll(ctx context.Context, limit int) ([]domain.Exercise, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results := make([]domain.Exercise, 0, len(r.exercises))
+	for _, exercise := range r.exercises {
+		results = append(results, exercise)
+		if limit > 0 && len(results) >= limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// ListPage returns up to limit exercises ordered by ID, starting after cursor (exclusive). An
+// empty cursor starts from the beginning; nextCursor is empty once there are no further pages.
+func (r *InMemoryRepository) ListPage(ctx context.Context, cursor string, limit int) ([]domain.Exercise, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]string, 0, len(r.exercises))
+	for id := range r.exercises {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	if limit <= 0 {
+		limit = len(ids)
+	}
+
+	page := make([]domain.Exercise, 0, limit)
+	var nextCursor string
+	for _, id := range ids {
+		if id <= cursor {
+			continue
+		}
+		if len(page) >= limit {
+			nextCursor = page[len(page)-1].ID
+			break
+		}
+		page = append(page, r.exercises[id])
+	}
+	return page, nextCursor, nil
+}
+
 // ListSessions returns in-memory sessions for the exercise.
 func (r *InMemoryRepository) ListSessions(ctx context.Context, exerciseID string, limit int) ([]domain.ActivitySession, error) {
 	r.mu.RLock()
@@ -155,3 +271,36 @@ func (r *InMemoryRepository) Delete(ctx context.Context, id string) error {
 	delete(r.sessions, id)
 	return nil
 }
+
+// RepairSymmetry implements domain.Repository. It treats id's own stored ComplementaryTo and
+// Contraindications as authoritative and pushes any missing back-link onto each referenced
+// neighbor, so an interrupted UpdateRelationships write converges back to a symmetric graph.
+func (r *InMemoryRepository) RepairSymmetry(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	exercise, ok := r.exercises[id]
+	if !ok {
+		return domain.ErrExerciseNotFound
+	}
+
+	for _, ref := range exercise.ComplementaryTo {
+		neighbor, ok := r.exercises[ref]
+		if !ok || slices.Contains(neighbor.ComplementaryTo, id) {
+			continue
+		}
+		neighbor.ComplementaryTo = append(append([]string{}, neighbor.ComplementaryTo...), id)
+		slices.Sort(neighbor.ComplementaryTo)
+		r.exercises[ref] = neighbor
+	}
+	for _, ref := range exercise.Contraindications {
+		neighbor, ok := r.exercises[ref]
+		if !ok || slices.Contains(neighbor.Contraindications, id) {
+			continue
+		}
+		neighbor.Contraindications = append(append([]string{}, neighbor.Contraindications...), id)
+		slices.Sort(neighbor.Contraindications)
+		r.exercises[ref] = neighbor
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package knowledge
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"example.com/exerciseontology/internal/subscription"
+)
+
+// InMemorySubscriptionRepository stores subscriptions in memory for local development.
+type InMemorySubscriptionRepository struct {
+	mu            sync.RWMutex
+	subscriptions map[string]subscription.Subscription
+}
+
+// NewInMemorySubscriptionRepository constructs an empty repository.
+func NewInMemorySubscriptionRepository() *InMemorySubscriptionRepository {
+	return &InMemorySubscriptionRepository{subscriptions: make(map[string]subscription.Subscription)}
+}
+
+// Create implements subscription.Repository.
+func (r *InMemorySubscriptionRepository) Create(ctx context.Context, sub subscription.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if sub.ID == "" {
+		sub.ID = uuid.NewString()
+	}
+	r.subscriptions[sub.ID] = sub
+	return nil
+}
+
+// List implements subscription.Repository.
+func (r *InMemorySubscriptionRepository) List(ctx context.Context) ([]subscription.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]subscription.Subscription, 0, len(r.subscriptions))
+	for _, sub := range r.subscriptions {
+		out = append(out, sub)
+	}
+	return out, nil
+}
+
+// Get implements subscription.Repository.
+func (r *InMemorySubscriptionRepository) Get(ctx context.Context, id string) (*subscription.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sub, ok := r.subscriptions[id]
+	if !ok {
+		return nil, nil
+	}
+	return &sub, nil
+}
+
+// Delete implements subscription.Repository.
+func (r *InMemorySubscriptionRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.subscriptions, id)
+	return nil
+}
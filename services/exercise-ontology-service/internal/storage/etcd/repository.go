@@ -0,0 +1,431 @@
+// Package etcd implements domain.Repository on top of etcd's key-value store, as an
+// alternative to the Dgraph-backed internal/knowledge repositories.
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"example.com/exerciseontology/internal/domain"
+)
+
+const (
+	exercisePrefix = "/fitpulse/exercises/"
+	sessionPrefix  = "/fitpulse/sessions/"
+	indexPrefix    = "/fitpulse/index/name/"
+	// nameIndexPrefixLen is how many leading runes of a lowercased exercise name key into the
+	// secondary index. It's a coarse prefix bucket, not a full-text index - Search still scans
+	// every member of the matched bucket(s) and filters with strings.Contains.
+	nameIndexPrefixLen = 3
+)
+
+// Repository persists exercises and sessions via etcd's v3 API.
+type Repository struct {
+	client  *clientv3.Client
+	timeout time.Duration
+}
+
+// Option configures optional Repository behaviour.
+type Option func(*Repository)
+
+// WithTimeout bounds how long a single etcd call may run. Zero (the default) leaves calls
+// bounded only by the client's own dial/keepalive settings.
+func WithTimeout(d time.Duration) Option {
+	return func(r *Repository) {
+		r.timeout = d
+	}
+}
+
+// NewRepository constructs a Repository backed by client.
+func NewRepository(client *clientv3.Client, opts ...Option) *Repository {
+	r := &Repository{client: client}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *Repository) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, r.timeout)
+}
+
+func exerciseKey(id string) string {
+	return exercisePrefix + id
+}
+
+func sessionKey(exerciseID, sessionID string, recordedAt time.Time) string {
+	return fmt.Sprintf("%s%s/%020d-%s", sessionPrefix, exerciseID, recordedAt.UnixNano(), sessionID)
+}
+
+func sessionKeyPrefix(exerciseID string) string {
+	return sessionPrefix + exerciseID + "/"
+}
+
+func indexKey(name, id string) string {
+	return indexPrefix + namePrefix(name) + "/" + id
+}
+
+func namePrefix(name string) string {
+	lower := strings.ToLower(strings.TrimSpace(name))
+	if len(lower) > nameIndexPrefixLen {
+		lower = lower[:nameIndexPrefixLen]
+	}
+	return lower
+}
+
+// Upsert creates or updates exercise. If exercise.ResourceVersion is non-zero, the write is
+// conditional on the key's etcd mod-revision still matching it; a mismatch (or the key having
+// disappeared) returns domain.ErrConflict without writing anything. A zero ResourceVersion
+// writes unconditionally, same as the Dgraph repository's first-write convention.
+func (r *Repository) Upsert(ctx context.Context, exercise domain.Exercise) error {
+	if strings.TrimSpace(exercise.ID) == "" {
+		return fmt.Errorf("exercise id must not be empty")
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	body, err := json.Marshal(exercise)
+	if err != nil {
+		return err
+	}
+
+	key := exerciseKey(exercise.ID)
+	ops := []clientv3.Op{
+		clientv3.OpPut(key, string(body)),
+		clientv3.OpPut(indexKey(exercise.Name, exercise.ID), ""),
+	}
+
+	if exercise.ResourceVersion == 0 {
+		_, err := r.client.Txn(ctx).Then(ops...).Commit()
+		return err
+	}
+
+	resp, err := r.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.ModRevision(key), "=", int64(exercise.ResourceVersion))).
+		Then(ops...).
+		Commit()
+	if err != nil {
+		return err
+	}
+	if !resp.Succeeded {
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+// UpsertBatch writes every exercise in a single etcd transaction, so the whole batch commits or
+// none of it does. Unlike Upsert it ignores ResourceVersion - bulk imports are expected to be
+// seeding or replacing rows wholesale, not reconciling a concurrent edit.
+func (r *Repository) UpsertBatch(ctx context.Context, exercises []domain.Exercise) error {
+	if len(exercises) == 0 {
+		return nil
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	ops := make([]clientv3.Op, 0, len(exercises)*2)
+	for _, exercise := range exercises {
+		if strings.TrimSpace(exercise.ID) == "" {
+			return fmt.Errorf("exercise id must not be empty")
+		}
+		body, err := json.Marshal(exercise)
+		if err != nil {
+			return err
+		}
+		ops = append(ops,
+			clientv3.OpPut(exerciseKey(exercise.ID), string(body)),
+			clientv3.OpPut(indexKey(exercise.Name, exercise.ID), ""),
+		)
+	}
+
+	_, err := r.client.Txn(ctx).Then(ops...).Commit()
+	return err
+}
+
+// UpsertWithSession creates or updates the exercise and records an activity session in a single
+// etcd transaction, so a reader never observes the session without its exercise or vice versa.
+// Like Upsert, a non-zero exercise.ResourceVersion is conditional on the key's etcd mod-revision
+// still matching it; a mismatch (or the key having disappeared) returns domain.ErrConflict
+// without writing anything, so two callers racing to append a session can't silently clobber
+// each other's exercise update.
+func (r *Repository) UpsertWithSession(ctx context.Context, exercise domain.Exercise, session domain.ActivitySession) error {
+	if strings.TrimSpace(exercise.ID) == "" {
+		return fmt.Errorf("exercise id must not be empty")
+	}
+	if strings.TrimSpace(session.ID) == "" {
+		return fmt.Errorf("session id must not be empty")
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	exerciseBody, err := json.Marshal(exercise)
+	if err != nil {
+		return err
+	}
+	sessionBody, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	recordedAt := session.RecordedAt
+	if recordedAt.IsZero() {
+		recordedAt = time.Now().UTC()
+	}
+
+	key := exerciseKey(exercise.ID)
+	ops := []clientv3.Op{
+		clientv3.OpPut(key, string(exerciseBody)),
+		clientv3.OpPut(indexKey(exercise.Name, exercise.ID), ""),
+		clientv3.OpPut(sessionKey(exercise.ID, session.ID, recordedAt), string(sessionBody)),
+	}
+
+	if exercise.ResourceVersion == 0 {
+		_, err := r.client.Txn(ctx).Then(ops...).Commit()
+		return err
+	}
+
+	resp, err := r.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.ModRevision(key), "=", int64(exercise.ResourceVersion))).
+		Then(ops...).
+		Commit()
+	if err != nil {
+		return err
+	}
+	if !resp.Succeeded {
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+// Get retrieves an exercise by ID, populating ResourceVersion from the key's etcd mod-revision.
+func (r *Repository) Get(ctx context.Context, id string) (*domain.Exercise, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := r.client.Get(ctx, exerciseKey(id))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, nil
+	}
+
+	var exercise domain.Exercise
+	if err := json.Unmarshal(resp.Kvs[0].Value, &exercise); err != nil {
+		return nil, err
+	}
+	exercise.ResourceVersion = uint64(resp.Kvs[0].ModRevision)
+	return &exercise, nil
+}
+
+// Search looks up exercises by name via the secondary name-prefix index, falling back to a
+// substring match within each matched bucket. It's bounded by the same coarse bucketing the
+// index was built with, so a short query term can return exercises outside its own bucket.
+func (r *Repository) Search(ctx context.Context, query string, limit int) ([]domain.Exercise, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	bucket := indexPrefix + namePrefix(query)
+	resp, err := r.client.Get(ctx, bucket, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	lowerQuery := strings.ToLower(strings.TrimSpace(query))
+	exercises := make([]domain.Exercise, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		id := kv.Key[strings.LastIndex(string(kv.Key), "/")+1:]
+		exercise, err := r.Get(ctx, string(id))
+		if err != nil || exercise == nil {
+			continue
+		}
+		if lowerQuery == "" || strings.Contains(strings.ToLower(exercise.Name), lowerQuery) {
+			exercises = append(exercises, *exercise)
+		}
+		if limit > 0 && len(exercises) >= limit {
+			break
+		}
+	}
+	return exercises, nil
+}
+
+// ListAll returns every exercise under exercisePrefix, bounded by limit (<=0 means unbounded).
+func (r *Repository) ListAll(ctx context.Context, limit int) ([]domain.Exercise, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	opts := []clientv3.OpOption{clientv3.WithPrefix()}
+	if limit > 0 {
+		opts = append(opts, clientv3.WithLimit(int64(limit)))
+	}
+	resp, err := r.client.Get(ctx, exercisePrefix, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	exercises := make([]domain.Exercise, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var exercise domain.Exercise
+		if err := json.Unmarshal(kv.Value, &exercise); err != nil {
+			return nil, err
+		}
+		exercise.ResourceVersion = uint64(kv.ModRevision)
+		exercises = append(exercises, exercise)
+	}
+	return exercises, nil
+}
+
+// ListPage returns up to limit exercises ordered by key (and therefore by ID, since exercisePrefix
+// is a fixed prefix), starting after cursor (exclusive). An empty cursor starts from the
+// beginning; nextCursor is empty once there are no further pages. Unlike ListAll, this never
+// holds more than one page in memory, so Service.ExportExercises can stream an arbitrarily large
+// ontology.
+func (r *Repository) ListPage(ctx context.Context, cursor string, limit int) ([]domain.Exercise, string, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	if limit <= 0 {
+		limit = 100
+	}
+
+	start := exercisePrefix
+	if cursor != "" {
+		start = exerciseKey(cursor) + "\x00"
+	}
+	rangeEnd := clientv3.GetPrefixRangeEnd(exercisePrefix)
+
+	resp, err := r.client.Get(ctx, start,
+		clientv3.WithRange(rangeEnd),
+		clientv3.WithLimit(int64(limit)),
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	exercises := make([]domain.Exercise, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var exercise domain.Exercise
+		if err := json.Unmarshal(kv.Value, &exercise); err != nil {
+			return nil, "", err
+		}
+		exercise.ResourceVersion = uint64(kv.ModRevision)
+		exercises = append(exercises, exercise)
+	}
+
+	var nextCursor string
+	if len(exercises) == limit {
+		nextCursor = exercises[len(exercises)-1].ID
+	}
+	return exercises, nextCursor, nil
+}
+
+// ListSessions returns sessions linked to exerciseID ordered by most recent first, as a bounded
+// reverse range scan over the /fitpulse/sessions/<exerciseID>/ prefix - the <recordedAtNanos>
+// component of the key sorts lexically in recorded-time order, so SortTarget=KEY,
+// SortOrder=DESCEND needs no secondary index.
+func (r *Repository) ListSessions(ctx context.Context, exerciseID string, limit int) ([]domain.ActivitySession, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := r.client.Get(ctx, sessionKeyPrefix(exerciseID),
+		clientv3.WithPrefix(),
+		clientv3.WithSort(clientv3.SortByKey, clientv3.SortDescend),
+		clientv3.WithLimit(int64(limit)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]domain.ActivitySession, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var session domain.ActivitySession
+		if err := json.Unmarshal(kv.Value, &session); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// Delete removes the exercise and every session recorded under it.
+func (r *Repository) Delete(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return fmt.Errorf("exercise id must not be empty")
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	exercise, err := r.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	ops := []clientv3.Op{
+		clientv3.OpDelete(exerciseKey(id)),
+		clientv3.OpDelete(sessionKeyPrefix(id), clientv3.WithPrefix()),
+	}
+	if exercise != nil {
+		ops = append(ops, clientv3.OpDelete(indexKey(exercise.Name, id)))
+	}
+
+	_, err = r.client.Txn(ctx).Then(ops...).Commit()
+	return err
+}
+
+// RepairSymmetry treats id's own stored ComplementaryTo/Contraindications as authoritative and
+// pushes any missing back-link onto each referenced neighbor via a plain Upsert, converging an
+// asymmetric graph left behind by an interrupted UpdateRelationships write.
+func (r *Repository) RepairSymmetry(ctx context.Context, id string) error {
+	exercise, err := r.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if exercise == nil {
+		return domain.ErrExerciseNotFound
+	}
+
+	for _, ref := range exercise.ComplementaryTo {
+		neighbor, err := r.Get(ctx, ref)
+		if err != nil {
+			return err
+		}
+		if neighbor == nil || slices.Contains(neighbor.ComplementaryTo, id) {
+			continue
+		}
+		neighbor.ComplementaryTo = append(append([]string{}, neighbor.ComplementaryTo...), id)
+		if err := r.Upsert(ctx, *neighbor); err != nil {
+			return err
+		}
+	}
+	for _, ref := range exercise.Contraindications {
+		neighbor, err := r.Get(ctx, ref)
+		if err != nil {
+			return err
+		}
+		if neighbor == nil || slices.Contains(neighbor.Contraindications, id) {
+			continue
+		}
+		neighbor.Contraindications = append(append([]string{}, neighbor.Contraindications...), id)
+		if err := r.Upsert(ctx, *neighbor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
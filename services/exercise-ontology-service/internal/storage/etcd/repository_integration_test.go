@@ -0,0 +1,150 @@
+//go:build integration
+
+package etcd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"example.com/exerciseontology/internal/cache"
+	"example.com/exerciseontology/internal/domain"
+	"example.com/exerciseontology/internal/testsupport"
+)
+
+func TestEtcdRepositoryUpsertWithSession(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	container, endpoint := testsupport.StartEtcd(ctx, t)
+	t.Cleanup(func() {
+		_ = container.Terminate(context.Background())
+	})
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{endpoint},
+		DialTimeout: 10 * time.Second,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	repo := NewRepository(client, WithTimeout(10*time.Second))
+	service := domain.NewService(repo, cache.NoopInvalidator{})
+
+	exercise := domain.Exercise{
+		ID:           "tenant:tempo-ride",
+		Name:         "Tempo Ride",
+		Difficulty:   "intermediate",
+		Targets:      []string{"cardio"},
+		SessionCount: 1,
+		LastUpdated:  time.Now().UTC(),
+	}
+
+	session := domain.ActivitySession{
+		ID:          "session-1",
+		ExerciseID:  exercise.ID,
+		ActivityID:  "activity-1",
+		TenantID:    "tenant",
+		UserID:      "user",
+		Source:      "integration-test",
+		Version:     "v1",
+		StartedAt:   time.Now().UTC(),
+		DurationMin: 30,
+		RecordedAt:  time.Now().UTC(),
+	}
+
+	_, err = service.UpsertExerciseWithSession(ctx, exercise, session)
+	require.NoError(t, err)
+
+	stored, err := repo.Get(ctx, exercise.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	require.Equal(t, exercise.Name, stored.Name)
+	require.NotZero(t, stored.ResourceVersion)
+
+	sessions, err := repo.ListSessions(ctx, exercise.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, session.ID, sessions[0].ID)
+
+	found, err := repo.Search(ctx, "Tempo", 10)
+	require.NoError(t, err)
+	require.NotEmpty(t, found)
+}
+
+func TestEtcdRepositoryUpsertConflict(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	container, endpoint := testsupport.StartEtcd(ctx, t)
+	t.Cleanup(func() {
+		_ = container.Terminate(context.Background())
+	})
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{endpoint},
+		DialTimeout: 10 * time.Second,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	repo := NewRepository(client, WithTimeout(10*time.Second))
+
+	exercise := domain.Exercise{ID: "conflict-test", Name: "Plank"}
+	require.NoError(t, repo.Upsert(ctx, exercise))
+
+	current, err := repo.Get(ctx, exercise.ID)
+	require.NoError(t, err)
+	require.NotNil(t, current)
+
+	stale := *current
+	stale.ResourceVersion--
+
+	err = repo.Upsert(ctx, stale)
+	require.ErrorIs(t, err, domain.ErrConflict)
+}
+
+func TestEtcdRepositoryUpsertWithSessionConflict(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	container, endpoint := testsupport.StartEtcd(ctx, t)
+	t.Cleanup(func() {
+		_ = container.Terminate(context.Background())
+	})
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{endpoint},
+		DialTimeout: 10 * time.Second,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	repo := NewRepository(client, WithTimeout(10*time.Second))
+
+	exercise := domain.Exercise{ID: "session-conflict-test", Name: "Plank"}
+	require.NoError(t, repo.Upsert(ctx, exercise))
+
+	current, err := repo.Get(ctx, exercise.ID)
+	require.NoError(t, err)
+	require.NotNil(t, current)
+
+	stale := *current
+	stale.ResourceVersion--
+
+	err = repo.UpsertWithSession(ctx, stale, domain.ActivitySession{ID: "session-1", ExerciseID: exercise.ID})
+	require.ErrorIs(t, err, domain.ErrConflict)
+
+	sessions, err := repo.ListSessions(ctx, exercise.ID, 10)
+	require.NoError(t, err)
+	require.Empty(t, sessions, "a conflicting write must not record the session either")
+}
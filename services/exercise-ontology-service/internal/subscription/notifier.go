@@ -0,0 +1,60 @@
+package subscription
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"example.com/platform/libs/go/logging"
+)
+
+// HTTPNotifier POSTs lifecycle callbacks to each subscription's status_notification_uri.
+// Delivery is best-effort: failures are logged, never returned to the Service call that
+// triggered the lifecycle event.
+type HTTPNotifier struct {
+	client *http.Client
+	logger *slog.Logger
+}
+
+// NewHTTPNotifier constructs an HTTPNotifier. A nil logger falls back to slog.Default().
+func NewHTTPNotifier(timeout time.Duration, logger *slog.Logger) *HTTPNotifier {
+	return &HTTPNotifier{client: &http.Client{Timeout: timeout}, logger: logging.OrDefault(logger)}
+}
+
+type lifecycleCallback struct {
+	Event          string `json:"event"`
+	SubscriptionID string `json:"subscription_id"`
+	Detail         string `json:"detail,omitempty"`
+}
+
+// Notify implements Notifier. A subscription without a status_notification_uri is skipped.
+func (n *HTTPNotifier) Notify(ctx context.Context, sub Subscription, event, detail string) {
+	if sub.StatusNotificationURI == "" {
+		return
+	}
+
+	body, err := json.Marshal(lifecycleCallback{Event: event, SubscriptionID: sub.ID, Detail: detail})
+	if err != nil {
+		n.logger.Error("marshal lifecycle callback failed", "subscription_id", sub.ID, "event", event, "error", err)
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.StatusNotificationURI, bytes.NewReader(body))
+	if err != nil {
+		n.logger.Error("build lifecycle callback request failed", "subscription_id", sub.ID, "event", event, "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		n.logger.Warn("lifecycle callback delivery failed", "subscription_id", sub.ID, "event", event, "error", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		n.logger.Warn("lifecycle callback rejected", "subscription_id", sub.ID, "event", event, "status", resp.StatusCode)
+	}
+}
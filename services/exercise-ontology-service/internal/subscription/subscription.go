@@ -0,0 +1,141 @@
+// Package subscription manages operator-defined enrichment fan-out jobs. A subscription
+// matches a subset of decoded consumer messages by tenant, activity type, source and event
+// type glob, and routes matches to a delivery topic or webhook, so the ontology consumer can
+// serve ad hoc fan-out destinations instead of only its hard-coded EnrichmentHandler pipeline.
+package subscription
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Delivery configures where a subscription's matched events are sent. Exactly one of Topic or
+// WebhookURL must be set.
+type Delivery struct {
+	Topic      string `json:"topic,omitempty"`
+	WebhookURL string `json:"webhook_url,omitempty"`
+}
+
+// Subscription is an operator-defined enrichment fan-out job. Empty predicate fields (all but
+// EventTypeGlob) match anything.
+type Subscription struct {
+	ID                    string    `json:"id"`
+	InfoTypeID            string    `json:"info_type_id"`
+	EventTypeGlob         string    `json:"event_type_glob"`
+	TenantID              string    `json:"tenant_id"`
+	ActivityType          string    `json:"activity_type"`
+	Source                string    `json:"source"`
+	Delivery              Delivery  `json:"delivery"`
+	StatusNotificationURI string    `json:"status_notification_uri,omitempty"`
+	CreatedAt             time.Time `json:"created_at"`
+}
+
+// Repository persists subscriptions so they survive a consumer restart.
+type Repository interface {
+	Create(ctx context.Context, sub Subscription) error
+	List(ctx context.Context) ([]Subscription, error)
+	Get(ctx context.Context, id string) (*Subscription, error)
+	Delete(ctx context.Context, id string) error
+}
+
+var (
+	// ErrNotFound indicates the subscription does not exist.
+	ErrNotFound = errors.New("subscription not found")
+	// ErrInvalidDelivery indicates Delivery didn't set exactly one of Topic or WebhookURL.
+	ErrInvalidDelivery = errors.New("delivery must set exactly one of topic or webhook_url")
+)
+
+// Notifier delivers subscription lifecycle callbacks (REGISTERED, DELETED, ERROR) to a
+// subscription's status_notification_uri.
+type Notifier interface {
+	Notify(ctx context.Context, sub Subscription, event, detail string)
+}
+
+// NoopNotifier discards lifecycle callbacks.
+type NoopNotifier struct{}
+
+// Notify implements Notifier.
+func (NoopNotifier) Notify(context.Context, Subscription, string, string) {}
+
+// Service manages subscription lifecycle and exposes the matching used by the consumer-side
+// fan-out handler.
+type Service struct {
+	repo     Repository
+	notifier Notifier
+}
+
+// NewService constructs a Service. A nil notifier falls back to NoopNotifier.
+func NewService(repo Repository, notifier Notifier) *Service {
+	if notifier == nil {
+		notifier = NoopNotifier{}
+	}
+	return &Service{repo: repo, notifier: notifier}
+}
+
+// Register validates and persists a new subscription, then notifies status_notification_uri
+// with REGISTERED, or ERROR if persistence failed.
+func (s *Service) Register(ctx context.Context, sub Subscription) (Subscription, error) {
+	if strings.TrimSpace(sub.EventTypeGlob) == "" {
+		return Subscription{}, errors.New("event_type_glob is required")
+	}
+	if (sub.Delivery.Topic == "") == (sub.Delivery.WebhookURL == "") {
+		return Subscription{}, ErrInvalidDelivery
+	}
+	if strings.TrimSpace(sub.ID) == "" {
+		sub.ID = uuid.NewString()
+	}
+	sub.CreatedAt = time.Now().UTC()
+
+	if err := s.repo.Create(ctx, sub); err != nil {
+		s.notifier.Notify(ctx, sub, "ERROR", err.Error())
+		return Subscription{}, err
+	}
+	s.notifier.Notify(ctx, sub, "REGISTERED", "")
+	return sub, nil
+}
+
+// List returns every active subscription; FanOutHandler calls this on every message, so
+// Repository implementations should keep it cheap.
+func (s *Service) List(ctx context.Context) ([]Subscription, error) {
+	return s.repo.List(ctx)
+}
+
+// Unregister removes a subscription and notifies DELETED, or ERROR if deletion failed.
+func (s *Service) Unregister(ctx context.Context, id string) error {
+	sub, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if sub == nil {
+		return ErrNotFound
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		s.notifier.Notify(ctx, *sub, "ERROR", err.Error())
+		return err
+	}
+	s.notifier.Notify(ctx, *sub, "DELETED", "")
+	return nil
+}
+
+// Matches reports whether a decoded event's attributes satisfy sub's predicate.
+func (sub Subscription) Matches(eventType, tenantID, activityType, source string) bool {
+	if sub.TenantID != "" && sub.TenantID != tenantID {
+		return false
+	}
+	if sub.ActivityType != "" && sub.ActivityType != activityType {
+		return false
+	}
+	if sub.Source != "" && sub.Source != source {
+		return false
+	}
+	if sub.EventTypeGlob == "" {
+		return true
+	}
+	matched, err := path.Match(sub.EventTypeGlob, eventType)
+	return err == nil && matched
+}
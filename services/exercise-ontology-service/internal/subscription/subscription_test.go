@@ -0,0 +1,109 @@
+package subscription
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type memRepository struct {
+	subs map[string]Subscription
+}
+
+func newMemRepository() *memRepository {
+	return &memRepository{subs: make(map[string]Subscription)}
+}
+
+func (r *memRepository) Create(ctx context.Context, sub Subscription) error {
+	r.subs[sub.ID] = sub
+	return nil
+}
+
+func (r *memRepository) List(ctx context.Context) ([]Subscription, error) {
+	out := make([]Subscription, 0, len(r.subs))
+	for _, sub := range r.subs {
+		out = append(out, sub)
+	}
+	return out, nil
+}
+
+func (r *memRepository) Get(ctx context.Context, id string) (*Subscription, error) {
+	sub, ok := r.subs[id]
+	if !ok {
+		return nil, nil
+	}
+	return &sub, nil
+}
+
+func (r *memRepository) Delete(ctx context.Context, id string) error {
+	delete(r.subs, id)
+	return nil
+}
+
+type recordingNotifier struct {
+	events []string
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, _ Subscription, event, _ string) {
+	n.events = append(n.events, event)
+}
+
+func TestServiceRegisterListUnregister(t *testing.T) {
+	repo := newMemRepository()
+	notifier := &recordingNotifier{}
+	service := NewService(repo, notifier)
+
+	sub, err := service.Register(context.Background(), Subscription{
+		EventTypeGlob: "activity.*",
+		Delivery:      Delivery{Topic: "ontology.fanout"},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, sub.ID)
+
+	subs, err := service.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+
+	err = service.Unregister(context.Background(), sub.ID)
+	require.NoError(t, err)
+
+	subs, err = service.List(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, subs)
+
+	require.Equal(t, []string{"REGISTERED", "DELETED"}, notifier.events)
+}
+
+func TestServiceRegisterRejectsAmbiguousDelivery(t *testing.T) {
+	service := NewService(newMemRepository(), nil)
+
+	_, err := service.Register(context.Background(), Subscription{
+		EventTypeGlob: "activity.*",
+		Delivery:      Delivery{Topic: "a", WebhookURL: "https://example.com/hook"},
+	})
+	require.ErrorIs(t, err, ErrInvalidDelivery)
+
+	_, err = service.Register(context.Background(), Subscription{EventTypeGlob: "activity.*"})
+	require.ErrorIs(t, err, ErrInvalidDelivery)
+}
+
+func TestServiceUnregisterMissingReturnsNotFound(t *testing.T) {
+	service := NewService(newMemRepository(), nil)
+
+	err := service.Unregister(context.Background(), "missing")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSubscriptionMatches(t *testing.T) {
+	sub := Subscription{
+		EventTypeGlob: "activity.*",
+		TenantID:      "tenant-1",
+		ActivityType:  "Tempo Ride",
+	}
+
+	require.True(t, sub.Matches("activity.created", "tenant-1", "Tempo Ride", "mobile"))
+	require.False(t, sub.Matches("session.created", "tenant-1", "Tempo Ride", "mobile"))
+	require.False(t, sub.Matches("activity.created", "tenant-2", "Tempo Ride", "mobile"))
+	require.False(t, sub.Matches("activity.created", "tenant-1", "Recovery Ride", "mobile"))
+}
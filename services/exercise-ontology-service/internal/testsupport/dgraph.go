@@ -37,14 +37,33 @@ func init() {
 // applies the exercise ontology schema, and returns the running container plus the HTTP endpoint.
 func StartDgraph(ctx context.Context, t *testing.T) (testcontainers.Container, string) {
 	t.Helper()
+	container, endpoint, _ := StartDgraphWithGRPC(ctx, t)
+	return container, endpoint
+}
+
+// StartDgraphWithGRPC is StartDgraph but also returns the container's mapped gRPC (9080)
+// address, for tests exercising DgraphGRPCRepository.
+func StartDgraphWithGRPC(ctx context.Context, t *testing.T) (testcontainers.Container, string, string) {
+	t.Helper()
+	return startDgraphWithGRPC(ctx, t, nil)
+}
+
+// startDgraphWithGRPC is StartDgraphWithGRPC's implementation, additionally joining nw (when
+// non-nil) under the "dgraph" network alias.
+func startDgraphWithGRPC(ctx context.Context, t *testing.T, nw *testcontainers.DockerNetwork) (testcontainers.Container, string, string) {
+	t.Helper()
 
 	req := testcontainers.ContainerRequest{
 		Image:        "dgraph/standalone:v23.1.0",
-		ExposedPorts: []string{"8080/tcp"},
+		ExposedPorts: []string{"8080/tcp", "9080/tcp"},
 		WaitingFor: wait.ForHTTP("/health").
 			WithPort("8080/tcp").
 			WithStatusCodeMatcher(func(status int) bool { return status >= 200 && status < 500 }),
 	}
+	if nw != nil {
+		req.Networks = []string{nw.Name}
+		req.NetworkAliases = map[string][]string{nw.Name: {"dgraph"}}
+	}
 
 	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
 		ContainerRequest: req,
@@ -55,32 +74,43 @@ func StartDgraph(ctx context.Context, t *testing.T) (testcontainers.Container, s
 	host, err := container.Host(ctx)
 	require.NoError(t, err)
 
-	mappedPort, err := container.MappedPort(ctx, "8080/tcp")
+	httpPort, err := container.MappedPort(ctx, "8080/tcp")
+	require.NoError(t, err)
+	grpcPort, err := container.MappedPort(ctx, "9080/tcp")
 	require.NoError(t, err)
 
-	endpoint := fmt.Sprintf("http://%s:%s", host, mappedPort.Port())
+	endpoint := fmt.Sprintf("http://%s:%s", host, httpPort.Port())
+	grpcAddr := fmt.Sprintf("%s:%s", host, grpcPort.Port())
 	applySchema(ctx, t, endpoint)
 
-	return container, endpoint
+	return container, endpoint, grpcAddr
 }
 
 func applySchema(ctx context.Context, t *testing.T, endpoint string) {
 	t.Helper()
-
-	client := &http.Client{Timeout: 5 * time.Second}
 	require.Eventually(t, func() bool {
-		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/alter", strings.NewReader(exerciseSchema))
-		if err != nil {
-			return false
-		}
-		req.Header.Set("Content-Type", "application/dql")
-
-		resp, err := client.Do(req)
-		if err != nil {
-			return false
-		}
-		defer resp.Body.Close()
-
-		return resp.StatusCode < 300
+		return applySchemaTo(ctx, endpoint) == nil
 	}, 30*time.Second, time.Second, "dgraph schema failed to apply")
 }
+
+// applySchemaTo POSTs exerciseSchema to endpoint's /alter, for callers (applySchema, SeedOntology)
+// that either don't have a *testing.T to retry against or apply their own retry strategy.
+func applySchemaTo(ctx context.Context, endpoint string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/alter", strings.NewReader(exerciseSchema))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/dql")
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("dgraph schema apply failed: %s", resp.Status)
+	}
+	return nil
+}
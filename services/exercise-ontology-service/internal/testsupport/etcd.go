@@ -0,0 +1,40 @@
+package testsupport
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// StartEtcd launches a single-node bitnami/etcd container with authentication disabled and
+// returns its client endpoint (host:port).
+func StartEtcd(ctx context.Context, t *testing.T) (testcontainers.Container, string) {
+	t.Helper()
+
+	req := testcontainers.ContainerRequest{
+		Image:        "bitnami/etcd:3.5",
+		ExposedPorts: []string{"2379/tcp"},
+		Env: map[string]string{
+			"ALLOW_NONE_AUTHENTICATION": "yes",
+		},
+		WaitingFor: wait.ForListeningPort("2379/tcp").WithStartupTimeout(60 * time.Second),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	require.NoError(t, err)
+
+	host, err := container.Host(ctx)
+	require.NoError(t, err)
+	port, err := container.MappedPort(ctx, "2379/tcp")
+	require.NoError(t, err)
+
+	return container, fmt.Sprintf("%s:%s", host, port.Port())
+}
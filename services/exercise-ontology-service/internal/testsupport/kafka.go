@@ -0,0 +1,45 @@
+//go:build integration
+
+package testsupport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	kafkacontainer "github.com/testcontainers/testcontainers-go/modules/kafka"
+	"github.com/testcontainers/testcontainers-go/network"
+)
+
+// StartKafka launches a single-broker Kafka container with auto topic creation enabled and
+// returns the running container plus its host-reachable bootstrap address.
+func StartKafka(ctx context.Context, t *testing.T) (testcontainers.Container, string) {
+	t.Helper()
+	return startKafka(ctx, t, nil)
+}
+
+// startKafka is StartKafka's implementation, additionally joining nw (when non-nil) under the
+// "kafka" network alias so other containers on the same network - notably Schema Registry - can
+// reach this broker without going through its host-mapped port.
+func startKafka(ctx context.Context, t *testing.T, nw *testcontainers.DockerNetwork) (testcontainers.Container, string) {
+	t.Helper()
+
+	opts := []testcontainers.ContainerCustomizer{
+		testcontainers.WithEnv(map[string]string{
+			"KAFKA_AUTO_CREATE_TOPICS_ENABLE": "true",
+		}),
+	}
+	if nw != nil {
+		opts = append(opts, network.WithNetwork([]string{"kafka"}, nw))
+	}
+
+	container, err := kafkacontainer.RunContainer(ctx, opts...)
+	require.NoError(t, err)
+
+	brokers, err := container.Brokers(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, brokers)
+
+	return container, brokers[0]
+}
@@ -0,0 +1,51 @@
+//go:build integration
+
+package testsupport
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	postgrescontainer "github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// StartPostgres launches a Postgres container and returns the running container plus a
+// connection string. No migrations are applied; this service has no Postgres-backed storage of
+// its own, so StartPostgres exists purely to let StartStack assemble a stack that also covers
+// cross-service integration tests exercising activity-service's outbox alongside this service's
+// enrichment consumer.
+func StartPostgres(ctx context.Context, t *testing.T) (testcontainers.Container, string) {
+	t.Helper()
+	return startPostgres(ctx, t, nil)
+}
+
+// startPostgres is StartPostgres's implementation, additionally joining nw (when non-nil) under
+// the "postgres" network alias.
+func startPostgres(ctx context.Context, t *testing.T, nw *testcontainers.DockerNetwork) (testcontainers.Container, string) {
+	t.Helper()
+
+	opts := []testcontainers.ContainerCustomizer{
+		postgrescontainer.WithDatabase("ontology"),
+		postgrescontainer.WithUsername("platform"),
+		postgrescontainer.WithPassword("platform"),
+		testcontainers.WithWaitStrategy(
+			wait.ForExec([]string{"pg_isready", "-U", "platform"}).WithStartupTimeout(60 * time.Second),
+		),
+	}
+	if nw != nil {
+		opts = append(opts, network.WithNetwork([]string{"postgres"}, nw))
+	}
+
+	container, err := postgrescontainer.RunContainer(ctx, opts...)
+	require.NoError(t, err)
+
+	connString, err := container.ConnectionString(ctx, "sslmode=disable")
+	require.NoError(t, err)
+
+	return container, connString
+}
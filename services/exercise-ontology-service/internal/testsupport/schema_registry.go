@@ -0,0 +1,58 @@
+//go:build integration
+
+package testsupport
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// StartSchemaRegistry launches a Confluent Schema Registry container wired to kafkaBootstrap and
+// returns the running container plus its HTTP endpoint.
+func StartSchemaRegistry(ctx context.Context, t *testing.T, kafkaBootstrap string) (testcontainers.Container, string) {
+	t.Helper()
+	return startSchemaRegistry(ctx, t, kafkaBootstrap, nil)
+}
+
+// startSchemaRegistry is StartSchemaRegistry's implementation, additionally joining nw (when
+// non-nil) so the registry can resolve kafkaBootstrap as an in-network address (e.g. "kafka:9092")
+// rather than a host-mapped one, which it cannot reach from inside its own container.
+func startSchemaRegistry(ctx context.Context, t *testing.T, kafkaBootstrap string, nw *testcontainers.DockerNetwork) (testcontainers.Container, string) {
+	t.Helper()
+
+	req := testcontainers.ContainerRequest{
+		Image:        "confluentinc/cp-schema-registry:7.5.0",
+		ExposedPorts: []string{"8081/tcp"},
+		Env: map[string]string{
+			"SCHEMA_REGISTRY_HOST_NAME":                    "schema-registry",
+			"SCHEMA_REGISTRY_LISTENERS":                    "http://0.0.0.0:8081",
+			"SCHEMA_REGISTRY_KAFKASTORE_BOOTSTRAP_SERVERS": "PLAINTEXT://" + kafkaBootstrap,
+		},
+		WaitingFor: wait.ForHTTP("/subjects").
+			WithPort("8081/tcp").
+			WithStatusCodeMatcher(func(status int) bool { return status == 200 }).
+			WithStartupTimeout(60 * time.Second),
+	}
+	if nw != nil {
+		req.Networks = []string{nw.Name}
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	require.NoError(t, err)
+
+	host, err := container.Host(ctx)
+	require.NoError(t, err)
+	port, err := container.MappedPort(ctx, "8081/tcp")
+	require.NoError(t, err)
+
+	return container, fmt.Sprintf("http://%s:%s", host, port.Port())
+}
@@ -0,0 +1,53 @@
+//go:build integration
+
+package testsupport
+
+import (
+	"context"
+	"time"
+
+	"example.com/exerciseontology/internal/consumer"
+	"example.com/exerciseontology/internal/domain"
+	"example.com/exerciseontology/internal/knowledge"
+)
+
+// seedTenant is the tenant canonicalSeedExercises are written under; it matches the tenant the
+// enrichment handler's integration tests have historically hand-seeded against.
+const seedTenant = "tenant"
+
+// canonicalSeedExercises is the fixture set SeedOntology writes into a fresh Dgraph instance - the
+// same two exercises integration tests used to hand-roll per test file - so every test asserting
+// against a real stack starts from one shared, known-good baseline.
+var canonicalSeedExercises = []domain.Exercise{
+	{
+		Name:       "Tempo Ride",
+		Difficulty: "intermediate",
+		Targets:    []string{"cardio"},
+		Requires:   []string{"bike"},
+	},
+	{
+		Name:       "Yoga Flow",
+		Difficulty: "beginner",
+		Targets:    []string{"flexibility"},
+		Requires:   []string{"mat"},
+	},
+}
+
+// SeedOntology applies the exercise ontology schema to the Dgraph instance at endpoint and writes
+// canonicalSeedExercises into it, so enrichment consumer tests can assert end-to-end behavior
+// against real Dgraph + Kafka rather than reaching for InMemoryRepository.
+func SeedOntology(ctx context.Context, endpoint string) error {
+	if err := applySchemaTo(ctx, endpoint); err != nil {
+		return err
+	}
+
+	repo := knowledge.NewDgraphRepository(endpoint, 10*time.Second)
+	for _, exercise := range canonicalSeedExercises {
+		exercise.ID = consumer.ActivityExerciseID(seedTenant, exercise.Name)
+		exercise.LastUpdated = time.Now().UTC()
+		if err := repo.Upsert(ctx, exercise); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+//go:build integration
+
+package testsupport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go/network"
+)
+
+// kafkaNetworkAddr is the address Kafka advertises under the "kafka" alias to other containers
+// on a StartStack network, as opposed to the host-mapped address StartKafka returns for the test
+// process itself to dial.
+const kafkaNetworkAddr = "kafka:9092"
+
+// Options selects which dependencies StartStack brings up. A false field is skipped entirely and
+// leaves the corresponding Stack field at its zero value.
+type Options struct {
+	Dgraph         bool
+	Kafka          bool
+	SchemaRegistry bool
+	Postgres       bool
+}
+
+// Stack is the set of endpoints StartStack assembled for the dependencies requested in Options.
+type Stack struct {
+	DgraphEndpoint         string
+	KafkaBootstrap         string
+	SchemaRegistryEndpoint string
+	PostgresConnString     string
+}
+
+// StartStack brings up the containers selected by opts on a shared Docker network and returns
+// their endpoints. Dependencies start in dependency order - Kafka before Schema Registry, since
+// the registry needs a bootstrap address to reach before it can serve requests - and every
+// container is registered for cleanup via t.Cleanup, so callers never terminate them by hand.
+func StartStack(ctx context.Context, t *testing.T, opts Options) *Stack {
+	t.Helper()
+
+	stack := &Stack{}
+	if !opts.Dgraph && !opts.Kafka && !opts.SchemaRegistry && !opts.Postgres {
+		return stack
+	}
+
+	nw, err := network.New(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = nw.Remove(context.Background()) })
+
+	if opts.Dgraph {
+		container, endpoint, _ := startDgraphWithGRPC(ctx, t, nw)
+		t.Cleanup(func() { _ = container.Terminate(context.Background()) })
+		stack.DgraphEndpoint = endpoint
+	}
+
+	if opts.Kafka {
+		container, bootstrap := startKafka(ctx, t, nw)
+		t.Cleanup(func() { _ = container.Terminate(context.Background()) })
+		stack.KafkaBootstrap = bootstrap
+	}
+
+	if opts.SchemaRegistry {
+		require.True(t, opts.Kafka, "StartStack: SchemaRegistry requires Kafka to also be enabled")
+		container, endpoint := startSchemaRegistry(ctx, t, kafkaNetworkAddr, nw)
+		t.Cleanup(func() { _ = container.Terminate(context.Background()) })
+		stack.SchemaRegistryEndpoint = endpoint
+	}
+
+	if opts.Postgres {
+		container, connString := startPostgres(ctx, t, nw)
+		t.Cleanup(func() { _ = container.Terminate(context.Background()) })
+		stack.PostgresConnString = connString
+	}
+
+	return stack
+}
@@ -0,0 +1,32 @@
+//go:build integration
+
+package testsupport
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestStartStackWiresDgraphAndKafkaOnSharedNetwork(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	stack := StartStack(ctx, t, Options{Dgraph: true, Kafka: true})
+	if stack.DgraphEndpoint == "" || stack.KafkaBootstrap == "" {
+		t.Fatalf("expected both endpoints to be populated: %+v", stack)
+	}
+
+	if err := SeedOntology(ctx, stack.DgraphEndpoint); err != nil {
+		t.Fatalf("seed ontology: %v", err)
+	}
+
+	conn, err := kafka.Dial("tcp", stack.KafkaBootstrap)
+	if err != nil {
+		t.Fatalf("dial kafka bootstrap: %v", err)
+	}
+	defer conn.Close()
+}
@@ -29,7 +29,7 @@ func StartEnrichmentConsumer(ctx context.Context, brokers []string, topic string
 
 	repo := knowledge.NewDgraphRepository(endpoint, 10*time.Second)
 	service := domain.NewService(repo, cache.NoopInvalidator{})
-	handler := consumer.NewEnrichmentHandler(service)
+	handler := consumer.NewEnrichmentHandler(service, nil)
 
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:        brokers,